@@ -21,6 +21,10 @@ interface Match {
   status: string
 }
 
+interface Team {
+  id: number
+  name: string
+}
 
 const MatchesPage: Component = () => {
   const [selectedSeason, setSelectedSeason] = createSignal<number | null>(null)
@@ -54,25 +58,17 @@ const MatchesPage: Component = () => {
   // Teams query for filter dropdown - only teams from selected season
   const seasonTeamsQuery = createQuery(() => ({
     queryKey: ['season-teams', effectiveSeasonId()],
-    queryFn: async (): Promise<string[]> => {
+    queryFn: async (): Promise<Team[]> => {
       const seasonId = effectiveSeasonId()
       if (!seasonId) return []
       
-      // Fetch matches for the season to get unique teams
-      const response = await fetch(apiUrl(`/matches?season=${seasonId}&limit=1000`))
+      const response = await fetch(apiUrl(`/teams?season=${seasonId}`))
       if (!response.ok) {
-        throw new Error('Failed to fetch matches for season teams')
+        throw new Error('Failed to fetch season teams')
       }
       const result = await response.json()
       
-      // Extract unique team names from matches
-      const teams = new Set<string>()
-      result.data.matches.forEach((match: Match) => {
-        teams.add(match.homeTeam)
-        teams.add(match.awayTeam)
-      })
-      
-      return Array.from(teams).sort()
+      return [...result.data.teams].sort((a: Team, b: Team) => a.name.localeCompare(b.name))
     },
     get enabled() {
       return effectiveSeasonId() !== null
@@ -85,30 +81,24 @@ const MatchesPage: Component = () => {
       const seasonId = effectiveSeasonId()
       if (!seasonId) throw new Error('No season selected')
       
-      // Fetch ALL matches for the season first
-      const response = await fetch(apiUrl(`/matches?season=${seasonId}&limit=1000`))
+      // The API filters by team and returns the newest matches first
+      const params = new URLSearchParams({
+        season: String(seasonId),
+        limit: String(limit),
+        offset: String((currentPage() - 1) * limit)
+      })
+      if (selectedTeam()) {
+        params.set('team', selectedTeam()!)
+      }
+      
+      const response = await fetch(apiUrl(`/matches?${params}`))
       if (!response.ok) {
         throw new Error('Failed to fetch matches')
       }
       const result = await response.json()
-      let allMatches = result.data.matches.sort((a: Match, b: Match) => 
-        new Date(b.date).getTime() - new Date(a.date).getTime()
-      )
-      
-      // Filter by team if selected
-      if (selectedTeam()) {
-        allMatches = allMatches.filter((match: Match) => 
-          match.homeTeam === selectedTeam() || match.awayTeam === selectedTeam()
-        )
-      }
-      
-      // Apply pagination
-      const startIndex = (currentPage() - 1) * limit
-      const endIndex = startIndex + limit
-      const paginatedMatches = allMatches.slice(startIndex, endIndex)
       
-      console.log(`Loaded ${paginatedMatches.length} matches (page ${currentPage()}) for season ${seasonId}`)
-      return { matches: paginatedMatches, total: allMatches.length }
+      console.log(`Loaded ${result.data.matches.length} matches (page ${currentPage()}) for season ${seasonId}`)
+      return { matches: result.data.matches, total: result.meta.totalItems }
     },
     get enabled() {
       return effectiveSeasonId() !== null
@@ -120,7 +110,7 @@ const MatchesPage: Component = () => {
 
   // Clear selected team if it's not in the current season
   const clearInvalidTeamSelection = () => {
-    if (selectedTeam() && seasonTeamsQuery.data && !seasonTeamsQuery.data.includes(selectedTeam()!)) {
+    if (selectedTeam() && seasonTeamsQuery.data && !seasonTeamsQuery.data.some((team) => String(team.id) === selectedTeam())) {
       setSelectedTeam(null)
     }
   }
@@ -132,6 +122,8 @@ const MatchesPage: Component = () => {
   }
   _() // Call to establish reactivity
 
+  const selectedTeamName = () => seasonTeamsQuery.data?.find((team) => String(team.id) === selectedTeam())?.name
+
   // Calculate pagination info
   const totalPages = () => Math.ceil((matchesQuery.data?.total || 0) / limit)
   const startResult = () => (currentPage() - 1) * limit + 1
@@ -193,8 +185,8 @@ const MatchesPage: Component = () => {
             >
               <option value="">All Teams</option>
               <For each={seasonTeamsQuery.data || []}>
-                {(teamName) => (
-                  <option value={teamName}>{teamName}</option>
+                {(team) => (
+                  <option value={team.id}>{team.name}</option>
                 )}
               </For>
             </select>
@@ -206,7 +198,7 @@ const MatchesPage: Component = () => {
           <div class="flex justify-between items-center text-sm text-muted-foreground">
             <div>
               Showing {startResult()}-{endResult()} of {matchesQuery.data.total} matches
-              {selectedTeam() && ` for ${selectedTeam()}`}
+              {selectedTeamName() && ` for ${selectedTeamName()}`}
             </div>
             <div>
               Page {currentPage()} of {totalPages()}
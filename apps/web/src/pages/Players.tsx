@@ -81,7 +81,7 @@ const PlayersPage: Component = () => {
         throw new Error('Failed to fetch players')
       }
       const result = await response.json()
-      return { players: result.data.players, total: result.data.total || result.data.players.length }
+      return { players: result.data.players, total: result.meta?.totalItems ?? result.data.players.length }
     }
   }))
 
@@ -5,10 +5,13 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
-	"github.com/gorilla/mux"
+	"github.com/premstats/api/internal/cache"
 	"github.com/premstats/api/internal/database"
 	"github.com/premstats/api/internal/handlers"
+	"github.com/premstats/api/internal/query"
+	"github.com/premstats/api/internal/repository"
 	"github.com/premstats/api/internal/services"
 	"github.com/rs/cors"
 )
@@ -26,73 +29,66 @@ func main() {
 	}
 	defer db.Close()
 
-	// Initialize services
-	teamService := services.NewTeamService(db)
-	matchService := services.NewMatchService(db)
-	standingsService := services.NewStandingsService(db)
-	seasonService := services.NewSeasonService(db)
-	playerService := services.NewPlayerService(db)
-
-	// Initialize handlers
-	teamHandler := handlers.NewTeamHandler(teamService)
-	matchHandler := handlers.NewMatchHandler(matchService)
-	standingsHandler := handlers.NewStandingsHandler(standingsService)
-	seasonHandler := handlers.NewSeasonHandler(seasonService)
-	playerHandler := handlers.NewPlayerHandler(playerService)
-	reportsHandler := &handlers.Handler{DB: db}
-
-	router := mux.NewRouter()
-
-	// API routes
-	api := router.PathPrefix("/api/v1").Subrouter()
-
-	// Health check
-	api.HandleFunc("/health", handlers.HealthHandler).Methods("GET")
-
-	// Teams endpoints
-	api.HandleFunc("/teams", teamHandler.GetTeams).Methods("GET")
-	api.HandleFunc("/teams/{id:[0-9]+}", teamHandler.GetTeamByID).Methods("GET")
-
-	// Seasons endpoints
-	api.HandleFunc("/seasons", seasonHandler.GetSeasons).Methods("GET")
-	api.HandleFunc("/seasons/{id:[0-9]+}", seasonHandler.GetSeasonByID).Methods("GET")
-	api.HandleFunc("/seasons/{id:[0-9]+}/summary", seasonHandler.GetSeasonSummary).Methods("GET")
-
-	// Matches endpoints
-	api.HandleFunc("/matches", matchHandler.GetMatches).Methods("GET")
-	api.HandleFunc("/matches/{id:[0-9]+}", matchHandler.GetMatchByID).Methods("GET")
-	api.HandleFunc("/matches/{id:[0-9]+}/events", matchHandler.GetMatchEvents).Methods("GET")
-	api.HandleFunc("/matches/season/{seasonId:[0-9]+}", matchHandler.GetMatchesBySeason).Methods("GET")
-
-	// Standings endpoints
-	api.HandleFunc("/standings", standingsHandler.GetStandings).Methods("GET")
-	api.HandleFunc("/standings/{seasonId:[0-9]+}", standingsHandler.GetStandingsBySeasonID).Methods("GET")
-	api.HandleFunc("/standings/seasons", standingsHandler.GetAvailableSeasons).Methods("GET")
-	api.HandleFunc("/standings/team/{teamId:[0-9]+}/season/{seasonId:[0-9]+}", standingsHandler.GetTeamStats).Methods("GET")
-
-	// Statistics endpoints (legacy compatibility)
-	api.HandleFunc("/stats/standings", standingsHandler.GetStandings).Methods("GET")
-	api.HandleFunc("/stats/top-scorers", playerHandler.GetTopScorers).Methods("GET")
-
-	// Player endpoints
-	api.HandleFunc("/players", playerHandler.GetPlayers).Methods("GET")
-	api.HandleFunc("/players/{id:[0-9]+}", playerHandler.GetPlayerByID).Methods("GET")
-	api.HandleFunc("/players/positions", playerHandler.GetPlayerPositions).Methods("GET")
-	api.HandleFunc("/players/nationalities", playerHandler.GetPlayerNationalities).Methods("GET")
-
-	// Search endpoint
-	api.HandleFunc("/search", playerHandler.SearchPlayers).Methods("GET")
+	// Computed aggregates are cached for CACHE_TTL (a Go duration such as
+	// "10m"); "0" turns the cache off
+	ttl := cache.DefaultTTL
+	if ttlStr := os.Getenv("CACHE_TTL"); ttlStr != "" {
+		if ttl, err = time.ParseDuration(ttlStr); err != nil {
+			log.Fatal("Invalid CACHE_TTL:", err)
+		}
+	}
+	var responseCache *cache.Cache
+	if ttl > 0 {
+		responseCache = cache.New(ttl)
+		fmt.Printf("🗄️  Caching computed aggregates for %s\n", ttl)
+
+		// Importers and rebuild commands announce the seasons they change
+		listener, err := responseCache.Listen(database.URL())
+		if err != nil {
+			log.Fatal("Failed to listen for cache invalidations:", err)
+		}
+		defer listener.Close()
+	}
 
-	// Reports endpoints
-	api.HandleFunc("/reports/data-completeness", reportsHandler.GetDataCompletenessReport).Methods("GET")
-	api.HandleFunc("/reports/season-completeness", reportsHandler.GetSeasonCompleteness).Methods("GET")
+	// Endpoints that change data or drop the cache need ADMIN_TOKEN as a
+	// bearer token; without it they are refused
+	adminToken := os.Getenv("ADMIN_TOKEN")
+	if adminToken == "" {
+		fmt.Println("🔒 ADMIN_TOKEN is not set, admin endpoints are disabled")
+	}
 
-	// Natural language query endpoint (placeholder)
-	api.HandleFunc("/query", queryHandler).Methods("POST")
+	// Initialize services
+	repo := repository.NewPostgres(db)
+	teamService := services.NewTeamService(repo)
+	matchService := services.NewMatchService(repo, repo, repo)
+	standingsService := services.NewStandingsService(repo, repo, repo, repo, responseCache)
+	seasonService := services.NewSeasonService(repo, repo, standingsService, responseCache)
+	playerService := services.NewPlayerService(repo, repo)
+	playerIdentityService := services.NewPlayerIdentityService(repo, repo, responseCache)
+	playerStatsService := services.NewPlayerStatsService(repo)
+	leaderboardService := services.NewLeaderboardService(repo, repo, responseCache)
+	transferService := services.NewTransferService(repo, repo, repo, repo)
+	reconciliationService := services.NewReconciliationService(repo, standingsService)
+	formService := services.NewFormService(repo, repo, repo)
 
-	// Set up error handlers
-	router.NotFoundHandler = http.HandlerFunc(handlers.NotFoundHandler)
-	router.MethodNotAllowedHandler = http.HandlerFunc(handlers.MethodNotAllowedHandler)
+	// Initialize handlers
+	router := handlers.NewRouter(handlers.Routes{
+		Teams:          handlers.NewTeamHandler(teamService),
+		Matches:        handlers.NewMatchHandler(matchService),
+		Standings:      handlers.NewStandingsHandler(standingsService),
+		Seasons:        handlers.NewSeasonHandler(seasonService),
+		Players:        handlers.NewPlayerHandler(playerService),
+		PlayerIdentity: handlers.NewPlayerIdentityHandler(playerIdentityService),
+		PlayerStats:    handlers.NewPlayerStatsHandler(playerStatsService),
+		Leaderboards:   handlers.NewLeaderboardHandler(leaderboardService),
+		Transfers:      handlers.NewTransferHandler(transferService),
+		Reconciliation: handlers.NewReconciliationHandler(reconciliationService),
+		Form:           handlers.NewFormHandler(formService),
+		Query:          handlers.NewQueryHandler(query.NewEngine(repo, playerService, standingsService, matchService, seasonService, responseCache)),
+		Reports:        &handlers.Handler{DB: db, Cache: responseCache},
+		Cache:          handlers.NewCacheHandler(responseCache),
+		AdminToken:     adminToken,
+	})
 
 	// CORS middleware
 	c := cors.New(cors.Options{
@@ -106,16 +102,3 @@ func main() {
 	fmt.Printf("🚀 PremStats API server starting on port %s\n", port)
 	log.Fatal(http.ListenAndServe(":"+port, handler))
 }
-
-func queryHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	fmt.Fprintf(w, `{
-		"query": "Who is the top scorer?",
-		"answer": "Erling Haaland is currently the top scorer with 15 goals",
-		"data": {
-			"player": "Erling Haaland",
-			"team": "Manchester City",
-			"goals": 15
-		}
-	}`)
-}
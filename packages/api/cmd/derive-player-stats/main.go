@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/repository"
+	"github.com/premstats/api/internal/services"
+)
+
+func main() {
+	seasonID := flag.Int("season", 0, "season ID to derive; 0 derives every season")
+	checkOnly := flag.Bool("check", false, "compare stored player stats with the match data without writing")
+	flag.Parse()
+
+	db, err := database.NewConnection()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	service := services.NewPlayerStatsService(repository.NewPostgres(db))
+
+	scope := "every season"
+	if *seasonID > 0 {
+		scope = fmt.Sprintf("season %d", *seasonID)
+	}
+
+	if !*checkOnly {
+		written, err := service.RebuildStats(*seasonID)
+		if err != nil {
+			log.Fatal("Derivation failed: ", err)
+		}
+		fmt.Printf("🔄 Derived %d player season lines for %s\n", written, scope)
+		if err := cache.Notify(db, *seasonID); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	diffs, err := service.DiffStats(*seasonID)
+	if err != nil {
+		log.Fatal("Comparison failed: ", err)
+	}
+	for _, d := range diffs {
+		switch {
+		case d.Stored == nil:
+			fmt.Printf("⚠️  %s, %s (%s): no stats stored\n", d.SeasonName, d.PlayerName, d.TeamName)
+		case d.Derived == nil:
+			fmt.Printf("⚠️  %s, %s (%s): stats stored without match data\n", d.SeasonName, d.PlayerName, d.TeamName)
+		default:
+			fmt.Printf("⚠️  %s, %s (%s): %s differ from match data\n", d.SeasonName, d.PlayerName, d.TeamName, strings.Join(d.Fields, ", "))
+		}
+	}
+	if len(diffs) > 0 {
+		fmt.Printf("❌ %d player season lines disagree with the match data for %s\n", len(diffs), scope)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Player stats match the goals, events and lineups for %s\n", scope)
+}
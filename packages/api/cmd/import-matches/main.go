@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/importer"
+)
+
+func main() {
+	file := flag.String("file", "../../data/processed/matches/matches-sample.csv", "processed matches CSV to import")
+	dryRun := flag.Bool("dry-run", false, "parse the file and report problems without writing to the database")
+	flag.Parse()
+
+	records, rowErrors, err := importer.ReadMatchFile(*file)
+	if err != nil {
+		log.Fatal("Failed to read matches: ", err)
+	}
+	fmt.Printf("📂 Parsed %d matches from %s\n", len(records), *file)
+	for _, rowErr := range rowErrors {
+		fmt.Printf("⚠️  %v\n", rowErr)
+	}
+
+	if *dryRun {
+		goals, lineups := 0, 0
+		for _, record := range records {
+			goals += len(record.Home.Goals) + len(record.Away.Goals)
+			lineups += len(record.Home.Lineup) + len(record.Away.Lineup)
+		}
+		fmt.Printf("✅ %d goals and %d lineup entries parsed, %d row errors (dry run)\n",
+			goals, lineups, len(rowErrors))
+		return
+	}
+
+	db, err := database.NewConnection()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	teams, err := importer.NewTeamResolver(db)
+	if err != nil {
+		log.Fatal("Failed to load teams: ", err)
+	}
+
+	result, err := importer.NewMatchImporter(db, teams, importer.NewPlayerResolver(db)).Import(records)
+	if result != nil {
+		for _, rowErr := range result.RowErrors {
+			fmt.Printf("⚠️  %v\n", rowErr)
+		}
+	}
+	if err != nil {
+		log.Fatal("Import failed: ", err)
+	}
+	if err := cache.Notify(db, cache.AllSeasons); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("✅ Imported %d matches: %d goals, %d substitutions, %d lineup entries (%d unresolved player names)\n",
+		result.Matches, result.Goals, result.Substitutions, result.LineupEntries, result.UnresolvedPlayers)
+	if skipped := len(rowErrors) + len(result.RowErrors); skipped > 0 {
+		fmt.Printf("⚠️  %d rows skipped\n", skipped)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/importer"
+)
+
+func main() {
+	dir := flag.String("dir", "../../data/sources/kaggle-premier-league/DATA_CSV", "directory containing Season_YYYY squad folders")
+	season := flag.Int("season", 0, "only import this season start year (e.g. 2003)")
+	dryRun := flag.Bool("dry-run", false, "parse files and report problems without writing to the database")
+	flag.Parse()
+
+	files, err := importer.FindSquadFiles(*dir, *season)
+	if err != nil {
+		log.Fatal("Failed to find squad files: ", err)
+	}
+	if len(files) == 0 {
+		log.Fatalf("No squad files found in %s", *dir)
+	}
+	fmt.Printf("📂 Found %d squad files\n", len(files))
+
+	if *dryRun {
+		rows, problems := 0, 0
+		for _, file := range files {
+			records, rowErrors, err := importer.ReadSquadFile(file.Path)
+			if err != nil {
+				log.Fatal(err)
+			}
+			rows += len(records)
+			for _, rowErr := range rowErrors {
+				fmt.Printf("⚠️  %v\n", rowErr)
+				problems++
+			}
+		}
+		fmt.Printf("✅ Parsed %d players with %d row errors (dry run)\n", rows, problems)
+		return
+	}
+
+	db, err := database.NewConnection()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	teams, err := importer.NewTeamResolver(db)
+	if err != nil {
+		log.Fatal("Failed to load teams: ", err)
+	}
+
+	result, err := importer.NewSquadImporter(db, teams).Import(files)
+	if result != nil {
+		for _, team := range result.UnresolvedTeams {
+			fmt.Printf("⚠️  Unknown team: %s\n", team)
+		}
+		for _, year := range result.MissingSeasons {
+			fmt.Printf("⚠️  No season found for %d\n", year)
+		}
+		for _, rowErr := range result.RowErrors {
+			fmt.Printf("⚠️  %s\n", rowErr)
+		}
+	}
+	if err != nil {
+		log.Fatal("Import failed: ", err)
+	}
+	if err := cache.Notify(db, cache.AllSeasons); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("✅ Imported %d files: %d players, %d squad memberships, %d spells\n",
+		result.Files, result.Players, result.Memberships, result.Spells)
+}
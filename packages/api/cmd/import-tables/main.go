@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/importer"
+)
+
+func main() {
+	file := flag.String("file", "../../data/processed/tables/all_tables.csv", "final league tables CSV to import")
+	dryRun := flag.Bool("dry-run", false, "parse the file and report problems without writing to the database")
+	flag.Parse()
+
+	records, rowErrors, err := importer.ReadTablesFile(*file)
+	if err != nil {
+		log.Fatal("Failed to read tables: ", err)
+	}
+	fmt.Printf("📂 Parsed %d table rows from %s\n", len(records), *file)
+	for _, rowErr := range rowErrors {
+		fmt.Printf("⚠️  %v\n", rowErr)
+	}
+
+	if *dryRun {
+		seasons := make(map[int]bool)
+		for _, record := range records {
+			seasons[record.SeasonYear] = true
+		}
+		fmt.Printf("✅ %d seasons parsed, %d row errors (dry run)\n", len(seasons), len(rowErrors))
+		return
+	}
+
+	db, err := database.NewConnection()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	teams, err := importer.NewTeamResolver(db)
+	if err != nil {
+		log.Fatal("Failed to load teams: ", err)
+	}
+
+	result, err := importer.NewTablesImporter(db, teams).Import(records, filepath.Base(*file))
+	if result != nil {
+		for _, code := range result.UnknownCodes {
+			fmt.Printf("⚠️  Unknown team code: %s\n", code)
+		}
+		for _, year := range result.MissingSeasons {
+			fmt.Printf("⚠️  No season found for %d\n", year)
+		}
+	}
+	if err != nil {
+		log.Fatal("Import failed: ", err)
+	}
+	if err := cache.Notify(db, cache.AllSeasons); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("✅ Imported %d official standings rows\n", result.Rows)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/premstats/api/internal/database"
+)
+
+const usage = `Usage: migrate <command> [arg]
+
+Commands:
+  up [version]   Apply pending migrations (optionally only up to version)
+  down [steps]   Roll back the last applied migration, or the last N; the
+                 baseline (0001) cannot be rolled back
+  status         List migrations and whether they are applied
+  version        Print the current schema version
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command := os.Args[1]
+	arg := 0
+	if len(os.Args) > 2 {
+		n, err := strconv.Atoi(os.Args[2])
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid argument %q for %s", os.Args[2], command)
+		}
+		arg = n
+	}
+
+	// Open without the schema check, since fixing the schema is the point
+	db, err := database.Open()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	switch command {
+	case "up":
+		applied, err := db.MigrateUp(arg)
+		for _, m := range applied {
+			fmt.Printf("⬆️  Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("✅ Schema is already up to date")
+		}
+
+	case "down":
+		if arg == 0 {
+			arg = 1
+		}
+		reverted, err := db.MigrateDown(arg)
+		for _, m := range reverted {
+			fmt.Printf("⬇️  Reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal("Rollback failed: ", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("Nothing to roll back")
+		}
+
+	case "status":
+		statuses, err := db.MigrationStatus()
+		if err != nil {
+			log.Fatal("Failed to read migration status: ", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+
+	case "version":
+		version, err := db.SchemaVersion()
+		if err != nil {
+			log.Fatal("Failed to read schema version: ", err)
+		}
+		latest, err := database.LatestVersion()
+		if err != nil {
+			log.Fatal("Failed to load migrations: ", err)
+		}
+		fmt.Printf("Schema version %d (latest %d)\n", version, latest)
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/repository"
+)
+
+func main() {
+	seasonID := flag.Int("season", 0, "season ID to rebuild; 0 rebuilds every season")
+	checkOnly := flag.Bool("check", false, "compare stored aggregates with the matches without rebuilding")
+	flag.Parse()
+
+	db, err := database.NewConnection()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	repo := repository.NewPostgres(db)
+
+	scope := "every season"
+	if *seasonID > 0 {
+		scope = fmt.Sprintf("season %d", *seasonID)
+	}
+
+	if !*checkOnly {
+		written, err := repo.RebuildAggregates(*seasonID)
+		if err != nil {
+			log.Fatal("Rebuild failed: ", err)
+		}
+		fmt.Printf("🔄 Rebuilt %d team season aggregates for %s\n", written, scope)
+		if err := cache.Notify(db, *seasonID); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	mismatches, err := repo.CheckAggregates(*seasonID)
+	if err != nil {
+		log.Fatal("Consistency check failed: ", err)
+	}
+	for _, m := range mismatches {
+		switch {
+		case m.Missing:
+			fmt.Printf("⚠️  Season %d, %s (%d): no aggregates stored\n", m.SeasonID, m.Team, m.TeamID)
+		case m.Orphaned:
+			fmt.Printf("⚠️  Season %d, %s (%d): aggregates stored without matches\n", m.SeasonID, m.Team, m.TeamID)
+		default:
+			fmt.Printf("⚠️  Season %d, %s (%d): %s differ from matches\n", m.SeasonID, m.Team, m.TeamID, strings.Join(m.Columns, ", "))
+		}
+	}
+	if len(mismatches) > 0 {
+		fmt.Printf("❌ %d team season aggregates disagree with the matches for %s\n", len(mismatches), scope)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Aggregates match the matches table for %s\n", scope)
+}
@@ -0,0 +1,100 @@
+// Package apperrors defines the domain errors services return so handlers can
+// map them to HTTP status codes without inspecting error strings.
+package apperrors
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// Sentinel kinds; test with errors.Is
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrInvalidArgument = errors.New("invalid argument")
+	ErrUnavailable     = errors.New("unavailable")
+)
+
+// Machine-readable codes returned to API clients
+const (
+	CodeNotFound        = "NOT_FOUND"
+	CodeInvalidArgument = "INVALID_ARGUMENT"
+	CodeUnavailable     = "UNAVAILABLE"
+	CodeUnauthorized    = "UNAUTHORIZED"
+	CodeInternal        = "INTERNAL"
+)
+
+// Error is a domain error of a given kind with a client-safe message
+type Error struct {
+	kind    error
+	message string
+	cause   error
+}
+
+// Error returns the client-safe message
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%s: %v", e.message, e.cause)
+	}
+	return e.message
+}
+
+// Message returns the message without the underlying cause
+func (e *Error) Message() string {
+	return e.message
+}
+
+// Is matches the error's kind sentinel
+func (e *Error) Is(target error) bool {
+	return target == e.kind
+}
+
+// Unwrap returns the underlying cause, if any
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// NotFound reports a missing resource
+func NotFound(format string, args ...interface{}) error {
+	return &Error{kind: ErrNotFound, message: fmt.Sprintf(format, args...)}
+}
+
+// InvalidArgument reports a request that cannot be satisfied as asked
+func InvalidArgument(format string, args ...interface{}) error {
+	return &Error{kind: ErrInvalidArgument, message: fmt.Sprintf(format, args...)}
+}
+
+// Unavailable reports that a dependency such as the database cannot be reached
+func Unavailable(cause error, format string, args ...interface{}) error {
+	return &Error{kind: ErrUnavailable, message: fmt.Sprintf(format, args...), cause: cause}
+}
+
+// Code returns the machine-readable code for err. Connection failures from
+// the database driver count as unavailable even when not wrapped explicitly.
+func Code(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, ErrInvalidArgument):
+		return CodeInvalidArgument
+	case errors.Is(err, ErrUnavailable),
+		errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, driver.ErrBadConn),
+		errors.As(err, &netErr):
+		return CodeUnavailable
+	}
+	return CodeInternal
+}
+
+// Message returns the client-safe message of a domain error, or fallback
+// for any other error
+func Message(err error, fallback string) string {
+	var appErr *Error
+	if errors.As(err, &appErr) && appErr.kind != ErrUnavailable {
+		return appErr.Message()
+	}
+	return fallback
+}
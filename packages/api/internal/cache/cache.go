@@ -0,0 +1,128 @@
+// Package cache keeps computed aggregates such as standings and season
+// summaries in memory, so repeated requests skip the queries behind them.
+// Entries expire after a TTL and can be dropped early for a single season
+// when its data changes.
+package cache
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DefaultTTL is how long entries live when no TTL is configured
+const DefaultTTL = 10 * time.Minute
+
+// AllSeasons tags entries built from every season's data; they are dropped
+// whenever any season is invalidated
+const AllSeasons = 0
+
+// Cache is an in-process store of computed values. A nil *Cache is valid and
+// caches nothing, so services can run without one.
+type Cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	entries map[string]entry
+}
+
+// entry is one cached value and the season it was built from
+type entry struct {
+	value    interface{}
+	seasonID int
+	expires  time.Time
+}
+
+// New creates a cache whose entries live for ttl
+func New(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, now: time.Now, entries: make(map[string]entry)}
+}
+
+// Get returns the value stored under key, if it has not expired
+func (c *Cache) Get(key string) (interface{}, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores a value built from one season's data, or from AllSeasons
+func (c *Cache) Set(key string, seasonID int, value interface{}) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry{value: value, seasonID: seasonID, expires: c.now().Add(c.ttl)}
+}
+
+// InvalidateSeason drops every entry built from a season's data, along with
+// those built from all seasons, and returns how many were dropped
+func (c *Cache) InvalidateSeason(seasonID int) int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropped := 0
+	for key, e := range c.entries {
+		if e.seasonID == seasonID || e.seasonID == AllSeasons {
+			delete(c.entries, key)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// InvalidateAll empties the cache and returns how many entries were dropped
+func (c *Cache) InvalidateAll() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dropped := len(c.entries)
+	c.entries = make(map[string]entry)
+	return dropped
+}
+
+// Fetch returns the value cached under key, or loads and caches it. Errors
+// are not cached. Values are stored as JSON and decoded afresh for every hit,
+// so callers own what they get back and may modify it.
+func Fetch[T any](c *Cache, key string, seasonID int, load func() (T, error)) (T, error) {
+	if value, ok := c.Get(key); ok {
+		if data, ok := value.([]byte); ok {
+			var v T
+			if err := json.Unmarshal(data, &v); err == nil {
+				return v, nil
+			}
+		}
+	}
+
+	value, err := load()
+	if err != nil || c == nil {
+		return value, err
+	}
+	// A value that cannot be encoded is simply not cached
+	if data, err := json.Marshal(value); err == nil {
+		c.Set(key, seasonID, data)
+	}
+	return value, nil
+}
@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestCache returns a cache whose clock is moved by advancing the returned time
+func newTestCache(ttl time.Duration) (*Cache, *time.Time) {
+	now := time.Date(2024, 5, 19, 16, 0, 0, 0, time.UTC)
+	c := New(ttl)
+	c.now = func() time.Time { return now }
+	return c, &now
+}
+
+func TestFetchCachesUntilExpiry(t *testing.T) {
+	c, now := newTestCache(time.Minute)
+
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		return loads, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if v, err := Fetch(c, "standings:1", 1, load); err != nil || v != 1 {
+			t.Fatalf("Fetch = %d, %v; want the first load", v, err)
+		}
+	}
+
+	*now = now.Add(time.Minute)
+	if v, _ := Fetch(c, "standings:1", 1, load); v != 2 {
+		t.Errorf("Fetch after TTL = %d, want a fresh load", v)
+	}
+}
+
+func TestFetchReturnsCopies(t *testing.T) {
+	c, _ := newTestCache(time.Minute)
+
+	type table struct{ Teams []string }
+	load := func() (*table, error) { return &table{Teams: []string{"Arsenal", "Liverpool"}}, nil }
+
+	first, _ := Fetch(c, "standings:1", 1, load)
+	first.Teams[0] = "Changed"
+	cached, _ := Fetch(c, "standings:1", 1, load)
+	cached.Teams[1] = "Changed"
+
+	if again, _ := Fetch(c, "standings:1", 1, load); again.Teams[0] != "Arsenal" || again.Teams[1] != "Liverpool" {
+		t.Errorf("cached table = %v, changed by an earlier caller", again.Teams)
+	}
+}
+
+func TestFetchDoesNotCacheErrors(t *testing.T) {
+	c, _ := newTestCache(time.Minute)
+
+	failing := func() (string, error) { return "", errors.New("database down") }
+	if _, err := Fetch(c, "summary:1", 1, failing); err == nil {
+		t.Fatal("expected the load error")
+	}
+	if _, ok := c.Get("summary:1"); ok {
+		t.Error("an error was cached")
+	}
+}
+
+func TestInvalidateSeason(t *testing.T) {
+	c, _ := newTestCache(time.Minute)
+	c.Set("standings:1", 1, "a")
+	c.Set("standings:2", 2, "b")
+	c.Set("report", AllSeasons, "c")
+
+	if dropped := c.InvalidateSeason(1); dropped != 2 {
+		t.Errorf("InvalidateSeason dropped %d entries, want 2", dropped)
+	}
+	if _, ok := c.Get("standings:1"); ok {
+		t.Error("season 1 entry survived invalidation")
+	}
+	if _, ok := c.Get("report"); ok {
+		t.Error("all-seasons entry survived invalidation")
+	}
+	if _, ok := c.Get("standings:2"); !ok {
+		t.Error("season 2 entry was dropped")
+	}
+
+	if dropped := c.InvalidateAll(); dropped != 1 {
+		t.Errorf("InvalidateAll dropped %d entries, want 1", dropped)
+	}
+}
+
+func TestNilCacheLoadsEveryTime(t *testing.T) {
+	var c *Cache
+
+	loads := 0
+	for i := 0; i < 2; i++ {
+		Fetch(c, "key", 1, func() (int, error) { loads++; return loads, nil })
+	}
+	if loads != 2 {
+		t.Errorf("nil cache loaded %d times, want 2", loads)
+	}
+	if c.InvalidateSeason(1) != 0 || c.InvalidateAll() != 0 {
+		t.Error("nil cache reported dropped entries")
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Channel is the Postgres notification channel that announces data changes to
+// every running API process. The payload is the season that changed, or
+// AllSeasons.
+const Channel = "premstats_cache"
+
+// execer is the part of *sql.DB and *sql.Tx that Notify needs
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// Notify tells listening caches that a season's data changed. Sent inside a
+// transaction, the notification is delivered only if it commits.
+func Notify(db execer, seasonID int) error {
+	if _, err := db.Exec("SELECT pg_notify($1, $2)", Channel, strconv.Itoa(seasonID)); err != nil {
+		return fmt.Errorf("failed to notify cache of season %d: %w", seasonID, err)
+	}
+	return nil
+}
+
+// Listen invalidates the cache whenever a notification arrives on Channel,
+// until the returned listener is closed. Notifications may be lost while the
+// connection is down, so the whole cache is dropped on reconnecting.
+func (c *Cache) Listen(dbURL string) (*pq.Listener, error) {
+	listener := pq.NewListener(dbURL, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Cache listener: %v", err)
+		}
+	})
+	if err := listener.Listen(Channel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen for cache notifications: %w", err)
+	}
+
+	go func() {
+		for n := range listener.Notify {
+			c.invalidate(n)
+		}
+	}()
+	return listener, nil
+}
+
+// invalidate drops the entries a notification makes stale. A nil
+// notification follows a reconnection.
+func (c *Cache) invalidate(n *pq.Notification) {
+	if n == nil {
+		c.InvalidateAll()
+		return
+	}
+	seasonID, err := strconv.Atoi(n.Extra)
+	if err != nil || seasonID == AllSeasons {
+		c.InvalidateAll()
+		return
+	}
+	c.InvalidateSeason(seasonID)
+}
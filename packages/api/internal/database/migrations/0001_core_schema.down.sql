@@ -0,0 +1,9 @@
+-- The core schema may have been adopted from a database created before the
+-- migrator, so rolling it back would drop data the migrator never owned.
+-- Drop the tables by hand if that is really what is wanted.
+
+DO $$
+BEGIN
+  RAISE EXCEPTION '0001_core_schema is the baseline and cannot be rolled back';
+END
+$$;
@@ -0,0 +1,80 @@
+-- Core PremStats schema. Written with IF NOT EXISTS so that databases
+-- created by scripts/database/init-db.sql can be adopted without changes.
+
+CREATE TABLE IF NOT EXISTS teams (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(100) NOT NULL UNIQUE,
+  short_name VARCHAR(50),
+  stadium VARCHAR(100),
+  founded INTEGER,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS seasons (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(50) NOT NULL UNIQUE,
+  start_date DATE NOT NULL,
+  end_date DATE NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE seasons ADD COLUMN IF NOT EXISTS year INTEGER;
+
+CREATE TABLE IF NOT EXISTS players (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(100) NOT NULL,
+  date_of_birth DATE,
+  nationality VARCHAR(100),
+  position VARCHAR(50),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE players ADD COLUMN IF NOT EXISTS current_team_id INTEGER REFERENCES teams(id);
+
+CREATE TABLE IF NOT EXISTS matches (
+  id SERIAL PRIMARY KEY,
+  season_id INTEGER REFERENCES seasons(id),
+  home_team_id INTEGER REFERENCES teams(id),
+  away_team_id INTEGER REFERENCES teams(id),
+  match_date TIMESTAMP NOT NULL,
+  home_score INTEGER,
+  away_score INTEGER,
+  status VARCHAR(50) DEFAULT 'scheduled',
+  matchday INTEGER,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS goals (
+  id SERIAL PRIMARY KEY,
+  match_id INTEGER REFERENCES matches(id),
+  player_id INTEGER REFERENCES players(id),
+  team_id INTEGER REFERENCES teams(id),
+  minute INTEGER NOT NULL,
+  is_own_goal BOOLEAN DEFAULT FALSE,
+  is_penalty BOOLEAN DEFAULT FALSE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS player_stats (
+  id SERIAL PRIMARY KEY,
+  player_id INTEGER REFERENCES players(id),
+  season_id INTEGER REFERENCES seasons(id),
+  team_id INTEGER REFERENCES teams(id),
+  appearances INTEGER DEFAULT 0,
+  goals INTEGER DEFAULT 0,
+  assists INTEGER DEFAULT 0,
+  yellow_cards INTEGER DEFAULT 0,
+  red_cards INTEGER DEFAULT 0,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(player_id, season_id, team_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_matches_date ON matches(match_date);
+CREATE INDEX IF NOT EXISTS idx_matches_season ON matches(season_id);
+CREATE INDEX IF NOT EXISTS idx_goals_match ON goals(match_id);
+CREATE INDEX IF NOT EXISTS idx_goals_player ON goals(player_id);
+CREATE INDEX IF NOT EXISTS idx_player_stats_season ON player_stats(season_id);
@@ -0,0 +1,17 @@
+ALTER TABLE matches
+  DROP COLUMN IF EXISTS half_time_home,
+  DROP COLUMN IF EXISTS half_time_away,
+  DROP COLUMN IF EXISTS referee,
+  DROP COLUMN IF EXISTS attendance,
+  DROP COLUMN IF EXISTS home_shots,
+  DROP COLUMN IF EXISTS away_shots,
+  DROP COLUMN IF EXISTS home_shots_on_target,
+  DROP COLUMN IF EXISTS away_shots_on_target,
+  DROP COLUMN IF EXISTS home_corners,
+  DROP COLUMN IF EXISTS away_corners,
+  DROP COLUMN IF EXISTS home_fouls,
+  DROP COLUMN IF EXISTS away_fouls,
+  DROP COLUMN IF EXISTS home_yellow_cards,
+  DROP COLUMN IF EXISTS away_yellow_cards,
+  DROP COLUMN IF EXISTS home_red_cards,
+  DROP COLUMN IF EXISTS away_red_cards;
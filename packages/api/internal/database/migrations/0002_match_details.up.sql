@@ -0,0 +1,19 @@
+-- Half-time scores, officials and per-match statistics read by MatchService.
+
+ALTER TABLE matches
+  ADD COLUMN IF NOT EXISTS half_time_home INTEGER,
+  ADD COLUMN IF NOT EXISTS half_time_away INTEGER,
+  ADD COLUMN IF NOT EXISTS referee VARCHAR(100),
+  ADD COLUMN IF NOT EXISTS attendance INTEGER,
+  ADD COLUMN IF NOT EXISTS home_shots INTEGER,
+  ADD COLUMN IF NOT EXISTS away_shots INTEGER,
+  ADD COLUMN IF NOT EXISTS home_shots_on_target INTEGER,
+  ADD COLUMN IF NOT EXISTS away_shots_on_target INTEGER,
+  ADD COLUMN IF NOT EXISTS home_corners INTEGER,
+  ADD COLUMN IF NOT EXISTS away_corners INTEGER,
+  ADD COLUMN IF NOT EXISTS home_fouls INTEGER,
+  ADD COLUMN IF NOT EXISTS away_fouls INTEGER,
+  ADD COLUMN IF NOT EXISTS home_yellow_cards INTEGER,
+  ADD COLUMN IF NOT EXISTS away_yellow_cards INTEGER,
+  ADD COLUMN IF NOT EXISTS home_red_cards INTEGER,
+  ADD COLUMN IF NOT EXISTS away_red_cards INTEGER;
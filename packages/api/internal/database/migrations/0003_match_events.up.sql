@@ -0,0 +1,16 @@
+-- Non-goal match events (cards, substitutions) read by MatchService.GetMatchEvents.
+
+CREATE TABLE IF NOT EXISTS match_events (
+  id SERIAL PRIMARY KEY,
+  match_id INTEGER REFERENCES matches(id),
+  event_type VARCHAR(50) NOT NULL,
+  minute INTEGER NOT NULL,
+  player_id INTEGER REFERENCES players(id),
+  team_id INTEGER REFERENCES teams(id),
+  detail VARCHAR(255),
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_events_match ON match_events(match_id);
+CREATE INDEX IF NOT EXISTS idx_match_events_player ON match_events(player_id);
+CREATE INDEX IF NOT EXISTS idx_match_events_type ON match_events(event_type);
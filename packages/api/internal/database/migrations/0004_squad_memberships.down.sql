@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS squad_memberships;
+
+ALTER TABLE players
+  DROP COLUMN IF EXISTS transfermarkt_id,
+  DROP COLUMN IF EXISTS second_nationality,
+  DROP COLUMN IF EXISTS height_cm,
+  DROP COLUMN IF EXISTS preferred_foot;
@@ -0,0 +1,22 @@
+-- Per-season squads imported from the kaggle squad files (cmd/import-squads).
+
+ALTER TABLE players
+  ADD COLUMN IF NOT EXISTS transfermarkt_id INTEGER UNIQUE,
+  ADD COLUMN IF NOT EXISTS second_nationality VARCHAR(100),
+  ADD COLUMN IF NOT EXISTS height_cm INTEGER,
+  ADD COLUMN IF NOT EXISTS preferred_foot VARCHAR(10);
+
+CREATE TABLE IF NOT EXISTS squad_memberships (
+  id SERIAL PRIMARY KEY,
+  player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+  team_id INTEGER NOT NULL REFERENCES teams(id),
+  season_id INTEGER NOT NULL REFERENCES seasons(id),
+  position VARCHAR(50),
+  source VARCHAR(50) NOT NULL DEFAULT 'kaggle',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(player_id, team_id, season_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_squad_memberships_season_team ON squad_memberships(season_id, team_id);
+CREATE INDEX IF NOT EXISTS idx_squad_memberships_player ON squad_memberships(player_id);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS match_lineups;
+
+ALTER TABLE match_events DROP COLUMN IF EXISTS source_player_name;
+ALTER TABLE goals DROP COLUMN IF EXISTS source_player_name;
+
+ALTER TABLE matches
+  DROP COLUMN IF EXISTS venue,
+  DROP COLUMN IF EXISTS home_formation,
+  DROP COLUMN IF EXISTS away_formation;
@@ -0,0 +1,25 @@
+-- Columns and tables populated by the match CSV importer (cmd/import-matches).
+
+ALTER TABLE matches
+  ADD COLUMN IF NOT EXISTS venue VARCHAR(150),
+  ADD COLUMN IF NOT EXISTS home_formation VARCHAR(20),
+  ADD COLUMN IF NOT EXISTS away_formation VARCHAR(20);
+
+-- Keep the scorer name as given by the source when no player row matches
+ALTER TABLE goals ADD COLUMN IF NOT EXISTS source_player_name VARCHAR(100);
+ALTER TABLE match_events ADD COLUMN IF NOT EXISTS source_player_name VARCHAR(100);
+
+CREATE TABLE IF NOT EXISTS match_lineups (
+  id SERIAL PRIMARY KEY,
+  match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+  team_id INTEGER NOT NULL REFERENCES teams(id),
+  player_id INTEGER REFERENCES players(id),
+  source_player_name VARCHAR(100) NOT NULL,
+  shirt_number INTEGER,
+  is_starter BOOLEAN NOT NULL,
+  minute_on INTEGER,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_lineups_match ON match_lineups(match_id);
+CREATE INDEX IF NOT EXISTS idx_match_lineups_player ON match_lineups(player_id);
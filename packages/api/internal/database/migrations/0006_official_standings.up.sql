@@ -0,0 +1,21 @@
+-- Published final league tables, used to reconcile standings computed from matches.
+
+CREATE TABLE IF NOT EXISTS official_standings (
+  id SERIAL PRIMARY KEY,
+  season_id INTEGER NOT NULL REFERENCES seasons(id),
+  team_id INTEGER NOT NULL REFERENCES teams(id),
+  position INTEGER NOT NULL,
+  played INTEGER NOT NULL,
+  won INTEGER NOT NULL,
+  drawn INTEGER NOT NULL,
+  lost INTEGER NOT NULL,
+  goals_for INTEGER NOT NULL,
+  goals_against INTEGER NOT NULL,
+  goal_difference INTEGER NOT NULL,
+  points INTEGER NOT NULL,
+  source VARCHAR(100) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(season_id, team_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_official_standings_season ON official_standings(season_id);
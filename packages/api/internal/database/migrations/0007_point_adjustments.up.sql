@@ -0,0 +1,31 @@
+-- Points deductions and other administrative adjustments applied on top of
+-- the points earned from results. Negative values are deductions.
+
+CREATE TABLE IF NOT EXISTS point_adjustments (
+  id SERIAL PRIMARY KEY,
+  team_id INTEGER NOT NULL REFERENCES teams(id),
+  season_id INTEGER NOT NULL REFERENCES seasons(id),
+  points INTEGER NOT NULL,
+  reason TEXT NOT NULL,
+  applied_on DATE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_point_adjustments_season ON point_adjustments(season_id);
+
+-- Known Premier League adjustments
+INSERT INTO point_adjustments (team_id, season_id, points, reason, applied_on)
+SELECT t.id, s.id, adj.points, adj.reason, adj.applied_on
+FROM (VALUES
+  ('Middlesbrough', '1996/97', -3, 'Failure to fulfil fixture against Blackburn Rovers', NULL::DATE),
+  ('Portsmouth', '2009/10', -9, 'Entering administration', DATE '2010-03-17'),
+  ('Everton', '2023/24', -6, 'Breach of profitability and sustainability rules (reduced from -10 on appeal)', DATE '2024-02-26'),
+  ('Everton', '2023/24', -2, 'Breach of profitability and sustainability rules', DATE '2024-04-08'),
+  ('Nottingham Forest', '2023/24', -4, 'Breach of profitability and sustainability rules', DATE '2024-03-18')
+) AS adj(team_name, season_name, points, reason, applied_on)
+JOIN teams t ON t.name IN (adj.team_name, adj.team_name || ' FC')
+JOIN seasons s ON s.name = adj.season_name
+WHERE NOT EXISTS (
+  SELECT 1 FROM point_adjustments pa
+  WHERE pa.team_id = t.id AND pa.season_id = s.id AND pa.reason = adj.reason
+);
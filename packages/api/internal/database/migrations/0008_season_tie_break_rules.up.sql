@@ -0,0 +1,9 @@
+-- Ordered, comma-separated tie-break criteria per season. NULL falls back to
+-- the default rules in services/tiebreak.go.
+
+ALTER TABLE seasons ADD COLUMN IF NOT EXISTS tie_break_rules TEXT;
+
+-- Head-to-head criteria were added to the Premier League handbook in 2019/20
+UPDATE seasons
+SET tie_break_rules = 'points,goalDifference,goalsFor,headToHeadPoints,headToHeadAwayGoals,playoff'
+WHERE start_date >= DATE '2019-07-01' AND tie_break_rules IS NULL;
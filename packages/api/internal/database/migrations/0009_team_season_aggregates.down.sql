@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS matches_refresh_aggregates_delete ON matches;
+DROP TRIGGER IF EXISTS matches_refresh_aggregates_update ON matches;
+DROP TRIGGER IF EXISTS matches_refresh_aggregates_insert ON matches;
+DROP FUNCTION IF EXISTS matches_refresh_aggregates();
+DROP FUNCTION IF EXISTS refresh_season_aggregates(INTEGER);
+DROP FUNCTION IF EXISTS refresh_team_season_aggregates(INTEGER[], INTEGER[]);
+DROP INDEX IF EXISTS idx_matches_season_away;
+DROP INDEX IF EXISTS idx_matches_season_home;
+DROP TABLE IF EXISTS team_season_aggregates;
+DROP VIEW IF EXISTS team_season_aggregates_live;
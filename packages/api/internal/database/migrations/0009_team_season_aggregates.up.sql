@@ -0,0 +1,208 @@
+-- Per-team season totals read by standings and season summaries instead of
+-- aggregating every match on each request. Statement triggers on matches
+-- refresh the teams a statement's changed matches involve; rebuild-aggregates
+-- recomputes seasons from scratch and checks the stored totals against the
+-- matches.
+
+-- Totals computed straight from matches. Only completed matches count towards
+-- the record, but every team with a fixture in the season gets a row.
+CREATE OR REPLACE VIEW team_season_aggregates_live AS
+SELECT
+  s.season_id,
+  s.team_id,
+  COUNT(*) FILTER (WHERE s.completed) AS played,
+  COUNT(*) FILTER (WHERE s.completed AND s.goals_for > s.goals_against) AS won,
+  COUNT(*) FILTER (WHERE s.completed AND s.goals_for = s.goals_against) AS drawn,
+  COUNT(*) FILTER (WHERE s.completed AND s.goals_for < s.goals_against) AS lost,
+  COALESCE(SUM(s.goals_for) FILTER (WHERE s.completed), 0) AS goals_for,
+  COALESCE(SUM(s.goals_against) FILTER (WHERE s.completed), 0) AS goals_against,
+  MAX(s.match_date) FILTER (WHERE s.completed) AS last_match,
+  COUNT(*) FILTER (WHERE s.completed AND s.is_home) AS home_played,
+  COUNT(*) FILTER (WHERE s.completed AND s.is_home AND s.goals_for > s.goals_against) AS home_won,
+  COUNT(*) FILTER (WHERE s.completed AND s.is_home AND s.goals_for = s.goals_against) AS home_drawn,
+  COUNT(*) FILTER (WHERE s.completed AND s.is_home AND s.goals_for < s.goals_against) AS home_lost,
+  COALESCE(SUM(s.goals_for) FILTER (WHERE s.completed AND s.is_home), 0) AS home_goals_for,
+  COALESCE(SUM(s.goals_against) FILTER (WHERE s.completed AND s.is_home), 0) AS home_goals_against,
+  MAX(s.match_date) FILTER (WHERE s.completed AND s.is_home) AS home_last_match,
+  COUNT(*) FILTER (WHERE s.completed AND NOT s.is_home) AS away_played,
+  COUNT(*) FILTER (WHERE s.completed AND NOT s.is_home AND s.goals_for > s.goals_against) AS away_won,
+  COUNT(*) FILTER (WHERE s.completed AND NOT s.is_home AND s.goals_for = s.goals_against) AS away_drawn,
+  COUNT(*) FILTER (WHERE s.completed AND NOT s.is_home AND s.goals_for < s.goals_against) AS away_lost,
+  COALESCE(SUM(s.goals_for) FILTER (WHERE s.completed AND NOT s.is_home), 0) AS away_goals_for,
+  COALESCE(SUM(s.goals_against) FILTER (WHERE s.completed AND NOT s.is_home), 0) AS away_goals_against,
+  MAX(s.match_date) FILTER (WHERE s.completed AND NOT s.is_home) AS away_last_match,
+  COALESCE(SUM(s.yellow_cards), 0) AS yellow_cards,
+  COALESCE(SUM(s.red_cards), 0) AS red_cards,
+  COALESCE(SUM(s.shots), 0) AS shots,
+  COALESCE(SUM(s.shots_on_target), 0) AS shots_on_target
+FROM (
+  SELECT m.season_id, m.home_team_id AS team_id, TRUE AS is_home, m.match_date,
+    m.home_score IS NOT NULL AND m.away_score IS NOT NULL AS completed,
+    m.home_score AS goals_for, m.away_score AS goals_against,
+    m.home_yellow_cards AS yellow_cards, m.home_red_cards AS red_cards,
+    m.home_shots AS shots, m.home_shots_on_target AS shots_on_target
+  FROM matches m
+  WHERE m.season_id IS NOT NULL
+  UNION ALL
+  SELECT m.season_id, m.away_team_id, FALSE, m.match_date,
+    m.home_score IS NOT NULL AND m.away_score IS NOT NULL,
+    m.away_score, m.home_score,
+    m.away_yellow_cards, m.away_red_cards,
+    m.away_shots, m.away_shots_on_target
+  FROM matches m
+  WHERE m.season_id IS NOT NULL
+) s
+GROUP BY s.season_id, s.team_id;
+
+CREATE TABLE IF NOT EXISTS team_season_aggregates (
+  season_id INTEGER NOT NULL REFERENCES seasons(id),
+  team_id INTEGER NOT NULL REFERENCES teams(id),
+  played INTEGER NOT NULL,
+  won INTEGER NOT NULL,
+  drawn INTEGER NOT NULL,
+  lost INTEGER NOT NULL,
+  goals_for INTEGER NOT NULL,
+  goals_against INTEGER NOT NULL,
+  last_match TIMESTAMP,
+  home_played INTEGER NOT NULL,
+  home_won INTEGER NOT NULL,
+  home_drawn INTEGER NOT NULL,
+  home_lost INTEGER NOT NULL,
+  home_goals_for INTEGER NOT NULL,
+  home_goals_against INTEGER NOT NULL,
+  home_last_match TIMESTAMP,
+  away_played INTEGER NOT NULL,
+  away_won INTEGER NOT NULL,
+  away_drawn INTEGER NOT NULL,
+  away_lost INTEGER NOT NULL,
+  away_goals_for INTEGER NOT NULL,
+  away_goals_against INTEGER NOT NULL,
+  away_last_match TIMESTAMP,
+  yellow_cards INTEGER NOT NULL,
+  red_cards INTEGER NOT NULL,
+  shots INTEGER NOT NULL,
+  shots_on_target INTEGER NOT NULL,
+  updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (season_id, team_id)
+);
+
+-- Refreshing one team looks up its home and away matches separately
+CREATE INDEX IF NOT EXISTS idx_matches_season_home ON matches(season_id, home_team_id);
+CREATE INDEX IF NOT EXISTS idx_matches_season_away ON matches(season_id, away_team_id);
+
+-- Recomputes the rows of the given season and team pairs, removing those
+-- whose team has no matches left in the season
+CREATE OR REPLACE FUNCTION refresh_team_season_aggregates(p_season_ids INTEGER[], p_team_ids INTEGER[])
+RETURNS VOID AS $$
+BEGIN
+  DELETE FROM team_season_aggregates a
+  USING unnest(p_season_ids, p_team_ids) AS p(season_id, team_id)
+  WHERE a.season_id = p.season_id AND a.team_id = p.team_id;
+
+  INSERT INTO team_season_aggregates
+  SELECT l.*, CURRENT_TIMESTAMP
+  FROM team_season_aggregates_live l
+  WHERE l.season_id = ANY(p_season_ids)
+    AND (l.season_id, l.team_id) IN (SELECT * FROM unnest(p_season_ids, p_team_ids));
+END;
+$$ LANGUAGE plpgsql;
+
+-- Recomputes every row of one season; importers that load a season match by
+-- match call this once at the end instead of refreshing after each match
+CREATE OR REPLACE FUNCTION refresh_season_aggregates(p_season_id INTEGER)
+RETURNS VOID AS $$
+BEGIN
+  DELETE FROM team_season_aggregates WHERE season_id = p_season_id;
+
+  INSERT INTO team_season_aggregates
+  SELECT l.*, CURRENT_TIMESTAMP
+  FROM team_season_aggregates_live l
+  WHERE l.season_id = p_season_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Refreshes the teams a statement's changed matches involve, once per
+-- statement. Transactions that set premstats.defer_aggregates to on skip the
+-- refresh and call refresh_season_aggregates themselves.
+CREATE OR REPLACE FUNCTION matches_refresh_aggregates()
+RETURNS TRIGGER AS $$
+DECLARE
+  season_ids INTEGER[];
+  team_ids INTEGER[];
+BEGIN
+  IF current_setting('premstats.defer_aggregates', true) = 'on' THEN
+    RETURN NULL;
+  END IF;
+
+  -- Each branch reads only the transition tables its trigger defines
+  IF TG_OP = 'INSERT' THEN
+    SELECT array_agg(c.season_id), array_agg(c.team_id) INTO season_ids, team_ids
+    FROM (
+      SELECT season_id, home_team_id AS team_id FROM new_rows
+      UNION SELECT season_id, away_team_id FROM new_rows
+    ) c
+    WHERE c.season_id IS NOT NULL;
+  ELSIF TG_OP = 'DELETE' THEN
+    SELECT array_agg(c.season_id), array_agg(c.team_id) INTO season_ids, team_ids
+    FROM (
+      SELECT season_id, home_team_id AS team_id FROM old_rows
+      UNION SELECT season_id, away_team_id FROM old_rows
+    ) c
+    WHERE c.season_id IS NOT NULL;
+  ELSE
+    -- Rows whose aggregated columns did not change are skipped; the others
+    -- refresh the teams of both their old and new versions
+    WITH changed_ids AS (
+      SELECT o.id
+      FROM old_rows o JOIN new_rows n ON n.id = o.id
+      WHERE (o.season_id, o.home_team_id, o.away_team_id, o.match_date, o.home_score, o.away_score,
+             o.home_yellow_cards, o.away_yellow_cards, o.home_red_cards, o.away_red_cards,
+             o.home_shots, o.away_shots, o.home_shots_on_target, o.away_shots_on_target)
+            IS DISTINCT FROM
+            (n.season_id, n.home_team_id, n.away_team_id, n.match_date, n.home_score, n.away_score,
+             n.home_yellow_cards, n.away_yellow_cards, n.home_red_cards, n.away_red_cards,
+             n.home_shots, n.away_shots, n.home_shots_on_target, n.away_shots_on_target)
+    ), changed AS (
+      SELECT season_id, home_team_id, away_team_id FROM old_rows WHERE id IN (SELECT id FROM changed_ids)
+      UNION ALL
+      SELECT season_id, home_team_id, away_team_id FROM new_rows WHERE id IN (SELECT id FROM changed_ids)
+    )
+    SELECT array_agg(c.season_id), array_agg(c.team_id) INTO season_ids, team_ids
+    FROM (
+      SELECT season_id, home_team_id AS team_id FROM changed
+      UNION SELECT season_id, away_team_id FROM changed
+    ) c
+    WHERE c.season_id IS NOT NULL;
+  END IF;
+
+  IF season_ids IS NOT NULL THEN
+    PERFORM refresh_team_season_aggregates(season_ids, team_ids);
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Transition tables need one trigger per event
+DROP TRIGGER IF EXISTS matches_refresh_aggregates_insert ON matches;
+CREATE TRIGGER matches_refresh_aggregates_insert
+AFTER INSERT ON matches
+REFERENCING NEW TABLE AS new_rows
+FOR EACH STATEMENT EXECUTE FUNCTION matches_refresh_aggregates();
+
+DROP TRIGGER IF EXISTS matches_refresh_aggregates_update ON matches;
+CREATE TRIGGER matches_refresh_aggregates_update
+AFTER UPDATE ON matches
+REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+FOR EACH STATEMENT EXECUTE FUNCTION matches_refresh_aggregates();
+
+DROP TRIGGER IF EXISTS matches_refresh_aggregates_delete ON matches;
+CREATE TRIGGER matches_refresh_aggregates_delete
+AFTER DELETE ON matches
+REFERENCING OLD TABLE AS old_rows
+FOR EACH STATEMENT EXECUTE FUNCTION matches_refresh_aggregates();
+
+-- Populate every season from the matches already loaded
+INSERT INTO team_season_aggregates
+SELECT l.*, CURRENT_TIMESTAMP
+FROM team_season_aggregates_live l
+ON CONFLICT (season_id, team_id) DO NOTHING;
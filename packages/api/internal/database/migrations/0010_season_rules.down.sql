@@ -0,0 +1,4 @@
+ALTER TABLE seasons DROP COLUMN IF EXISTS european_places;
+ALTER TABLE seasons DROP COLUMN IF EXISTS promotion_places;
+ALTER TABLE seasons DROP COLUMN IF EXISTS relegation_places;
+ALTER TABLE seasons DROP COLUMN IF EXISTS team_count;
@@ -0,0 +1,40 @@
+-- League format per season: how many teams take part, how many went down and
+-- how many came up into it, and which league positions qualify for Europe. European places
+-- are an ordered, comma-separated list of competition:places pairs handed
+-- out from the top of the table. NULLs fall back to the defaults in
+-- services/seasons.go.
+
+ALTER TABLE seasons ADD COLUMN IF NOT EXISTS team_count INTEGER;
+ALTER TABLE seasons ADD COLUMN IF NOT EXISTS relegation_places INTEGER;
+ALTER TABLE seasons ADD COLUMN IF NOT EXISTS promotion_places INTEGER;
+ALTER TABLE seasons ADD COLUMN IF NOT EXISTS european_places TEXT;
+
+-- 22 teams until 1994/95, when four were relegated and two promoted to cut
+-- the league to 20
+UPDATE seasons SET team_count = CASE WHEN start_date < DATE '1995-07-01' THEN 22 ELSE 20 END
+WHERE team_count IS NULL;
+
+UPDATE seasons SET relegation_places = CASE
+    WHEN start_date >= DATE '1994-07-01' AND start_date < DATE '1995-07-01' THEN 4
+    ELSE 3
+END
+WHERE relegation_places IS NULL;
+
+UPDATE seasons SET promotion_places = CASE
+    WHEN start_date >= DATE '1995-07-01' AND start_date < DATE '1996-07-01' THEN 2
+    ELSE 3
+END
+WHERE promotion_places IS NULL;
+
+-- Places earned through league position for the following season's
+-- competitions; cup winners' places and Fair Play spots are not tied to the
+-- table
+UPDATE seasons SET european_places = CASE
+    WHEN start_date < DATE '1996-07-01' THEN 'championsLeague:1,uefaCup:2'
+    WHEN start_date < DATE '1998-07-01' THEN 'championsLeague:2,uefaCup:2'
+    WHEN start_date < DATE '2001-07-01' THEN 'championsLeague:3,uefaCup:2'
+    WHEN start_date < DATE '2008-07-01' THEN 'championsLeague:4,uefaCup:1'
+    WHEN start_date >= DATE '2024-07-01' AND start_date < DATE '2025-07-01' THEN 'championsLeague:5,europaLeague:1'
+    ELSE 'championsLeague:4,europaLeague:1'
+END
+WHERE european_places IS NULL;
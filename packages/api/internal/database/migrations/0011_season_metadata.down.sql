@@ -0,0 +1,2 @@
+ALTER TABLE seasons DROP COLUMN IF EXISTS points_for_win;
+ALTER TABLE seasons DROP COLUMN IF EXISTS sponsor;
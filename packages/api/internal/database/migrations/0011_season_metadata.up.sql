@@ -0,0 +1,18 @@
+-- Title sponsor and points for a win per season. NULL points fall back to
+-- three in services/seasons.go.
+
+ALTER TABLE seasons ADD COLUMN IF NOT EXISTS sponsor TEXT;
+ALTER TABLE seasons ADD COLUMN IF NOT EXISTS points_for_win INTEGER;
+
+UPDATE seasons SET sponsor = CASE
+    WHEN start_date >= DATE '1993-07-01' AND start_date < DATE '2001-07-01' THEN 'Carling'
+    WHEN start_date >= DATE '2001-07-01' AND start_date < DATE '2004-07-01' THEN 'Barclaycard'
+    WHEN start_date >= DATE '2004-07-01' AND start_date < DATE '2016-07-01' THEN 'Barclays'
+END
+WHERE sponsor IS NULL;
+
+-- Three points for a win throughout the Premier League era
+UPDATE seasons SET points_for_win = 3 WHERE points_for_win IS NULL;
+
+-- The year column was only set by some loaders
+UPDATE seasons SET year = EXTRACT(YEAR FROM start_date)::INTEGER WHERE year IS NULL;
@@ -0,0 +1,110 @@
+-- Other names a team is known by. kind is 'name' for a former official
+-- name, 'code' for a short code and 'alias' for anything else. source limits
+-- an alias to one data source (e.g. 'all_tables', 'kaggle'); an empty source
+-- applies to all of them. valid_from and valid_to bound when a name was in
+-- use, both inclusive, and are NULL when open-ended.
+
+CREATE TABLE IF NOT EXISTS team_aliases (
+  id SERIAL PRIMARY KEY,
+  team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+  alias VARCHAR(100) NOT NULL,
+  kind VARCHAR(10) NOT NULL DEFAULT 'alias' CHECK (kind IN ('name', 'code', 'alias')),
+  source VARCHAR(50) NOT NULL DEFAULT '',
+  valid_from DATE,
+  valid_to DATE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(team_id, alias, source)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_aliases_team ON team_aliases(team_id);
+CREATE INDEX IF NOT EXISTS idx_team_aliases_alias ON team_aliases(LOWER(alias));
+
+-- Official three-letter codes already held as short names
+INSERT INTO team_aliases (team_id, alias, kind)
+SELECT id, short_name, 'code'
+FROM teams
+WHERE short_name ~ '^[A-Z]{3}$'
+ON CONFLICT (team_id, alias, source) DO NOTHING;
+
+-- Codes used by all_tables.csv. Teams are stored under either their short
+-- or their full name, with or without an FC suffix, so clubs whose short
+-- name differs are listed under both.
+INSERT INTO team_aliases (team_id, alias, kind, source)
+SELECT t.id, v.code, 'code', 'all_tables'
+FROM (VALUES
+  ('ARS', 'Arsenal'),
+  ('AVL', 'Aston Villa'),
+  ('BHA', 'Brighton'),
+  ('BHA', 'Brighton & Hove Albion'),
+  ('BIR', 'Birmingham City'),
+  ('BLK', 'Blackburn'),
+  ('BLK', 'Blackburn Rovers'),
+  ('BLP', 'Blackpool'),
+  ('BOL', 'Bolton'),
+  ('BOL', 'Bolton Wanderers'),
+  ('BOU', 'Bournemouth'),
+  ('BOU', 'AFC Bournemouth'),
+  ('BRE', 'Brentford'),
+  ('BUR', 'Burnley'),
+  ('CAR', 'Cardiff City'),
+  ('CHA', 'Charlton'),
+  ('CHA', 'Charlton Athletic'),
+  ('CHE', 'Chelsea'),
+  ('CRY', 'Crystal Palace'),
+  ('DER', 'Derby County'),
+  ('EVE', 'Everton'),
+  ('FUL', 'Fulham'),
+  ('HUD', 'Huddersfield'),
+  ('HUD', 'Huddersfield Town AFC'),
+  ('HUL', 'Hull City'),
+  ('HUL', 'Hull City AFC'),
+  ('IPS', 'Ipswich'),
+  ('IPS', 'Ipswich Town'),
+  ('LEE', 'Leeds United'),
+  ('LEI', 'Leicester City'),
+  ('LIV', 'Liverpool'),
+  ('MAN', 'Manchester United'),
+  ('MID', 'Middlesbrough'),
+  ('MNC', 'Manchester City'),
+  ('NEW', 'Newcastle United'),
+  ('NOR', 'Norwich City'),
+  ('POR', 'Portsmouth'),
+  ('QPR', 'QPR'),
+  ('QPR', 'Queens Park Rangers'),
+  ('REA', 'Reading'),
+  ('SHU', 'Sheffield United'),
+  ('SOU', 'Southampton'),
+  ('STK', 'Stoke City'),
+  ('SUN', 'Sunderland'),
+  ('SUN', 'Sunderland AFC'),
+  ('SWA', 'Swansea'),
+  ('SWA', 'Swansea City AFC'),
+  ('TOT', 'Tottenham'),
+  ('TOT', 'Tottenham Hotspur'),
+  ('WAT', 'Watford'),
+  ('WBA', 'West Brom'),
+  ('WBA', 'West Bromwich Albion'),
+  ('WGA', 'Wigan'),
+  ('WGA', 'Wigan Athletic'),
+  ('WHU', 'West Ham'),
+  ('WHU', 'West Ham United'),
+  ('WOL', 'Wolverhampton'),
+  ('WOL', 'Wolverhampton Wanderers')
+) AS v(code, name)
+JOIN teams t ON t.name IN (v.name, v.name || ' FC')
+ON CONFLICT (team_id, alias, source) DO NOTHING;
+
+-- Common alternative names and the dated names used by the kaggle files
+INSERT INTO team_aliases (team_id, alias, kind, source, valid_from, valid_to)
+SELECT t.id, v.alias, v.kind, v.source, v.valid_from::DATE, v.valid_to::DATE
+FROM (VALUES
+  ('Manchester United', 'Manchester Utd', 'alias', '', NULL, NULL),
+  ('Manchester City', 'Man City', 'alias', '', NULL, NULL),
+  ('Nottingham Forest', 'Nottm Forest', 'alias', '', NULL, NULL),
+  ('Sheffield Wednesday', 'Sheffield Weds', 'alias', '', NULL, NULL),
+  ('Tottenham', 'Spurs', 'alias', '', NULL, NULL),
+  ('Tottenham Hotspur', 'Spurs', 'alias', '', NULL, NULL),
+  ('Wimbledon', 'Wimbledon FC (- 2004)', 'name', 'kaggle', NULL, '2004-06-21')
+) AS v(name, alias, kind, source, valid_from, valid_to)
+JOIN teams t ON t.name IN (v.name, v.name || ' FC')
+ON CONFLICT (team_id, alias, source) DO NOTHING;
@@ -0,0 +1,16 @@
+-- Decisions taken on suspected duplicate players. player_id is the record
+-- that was kept and duplicate_id the other one; a merged duplicate no longer
+-- exists, so duplicate_id has no foreign key. Rejected pairs are left out of
+-- the review queue.
+
+CREATE TABLE IF NOT EXISTS player_match_reviews (
+  id SERIAL PRIMARY KEY,
+  player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+  duplicate_id INTEGER NOT NULL,
+  decision VARCHAR(10) NOT NULL CHECK (decision IN ('merged', 'rejected')),
+  confidence NUMERIC(4, 3),
+  decided_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE(player_id, duplicate_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_match_reviews_duplicate ON player_match_reviews(duplicate_id);
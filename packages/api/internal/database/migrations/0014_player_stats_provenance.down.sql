@@ -0,0 +1,16 @@
+DROP VIEW IF EXISTS player_season_stats;
+
+DELETE FROM player_stats WHERE source = 'derived';
+
+ALTER TABLE player_stats DROP CONSTRAINT IF EXISTS player_stats_player_id_season_id_team_id_source_key;
+ALTER TABLE player_stats
+  ADD CONSTRAINT player_stats_player_id_season_id_team_id_key UNIQUE (player_id, season_id, team_id);
+
+DROP INDEX IF EXISTS idx_player_stats_source;
+
+ALTER TABLE player_stats
+  DROP COLUMN IF EXISTS derived_at,
+  DROP COLUMN IF EXISTS source,
+  DROP COLUMN IF EXISTS minutes,
+  DROP COLUMN IF EXISTS own_goals,
+  DROP COLUMN IF EXISTS penalties;
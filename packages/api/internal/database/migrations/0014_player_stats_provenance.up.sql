@@ -0,0 +1,44 @@
+-- Player season lines can now be derived from goals, match events and
+-- lineups (cmd/derive-player-stats). source records where a line came from:
+-- 'external' for lines loaded from outside data, 'derived' for lines the job
+-- wrote, with derived_at set to when it last did. Minutes are NULL when no
+-- lineup was recorded for any of the player's matches.
+--
+-- A player can have both an external and a derived line for a season and
+-- team, so the job never overwrites outside data and the two can be compared.
+-- player_season_stats shows one line per player, season and team: the
+-- derived numbers where there are some, with the external assists, which
+-- match data does not record.
+
+ALTER TABLE player_stats
+  ADD COLUMN IF NOT EXISTS penalties INTEGER DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS own_goals INTEGER DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS minutes INTEGER,
+  ADD COLUMN IF NOT EXISTS source VARCHAR(10) NOT NULL DEFAULT 'external'
+    CHECK (source IN ('external', 'derived')),
+  ADD COLUMN IF NOT EXISTS derived_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_player_stats_source ON player_stats(source);
+
+ALTER TABLE player_stats DROP CONSTRAINT IF EXISTS player_stats_player_id_season_id_team_id_key;
+ALTER TABLE player_stats DROP CONSTRAINT IF EXISTS player_stats_player_id_season_id_team_id_source_key;
+ALTER TABLE player_stats
+  ADD CONSTRAINT player_stats_player_id_season_id_team_id_source_key UNIQUE (player_id, season_id, team_id, source);
+
+CREATE OR REPLACE VIEW player_season_stats AS
+SELECT COALESCE(d.id, e.id) as id,
+       COALESCE(d.player_id, e.player_id) as player_id,
+       COALESCE(d.season_id, e.season_id) as season_id,
+       COALESCE(d.team_id, e.team_id) as team_id,
+       COALESCE(d.appearances, e.appearances) as appearances,
+       COALESCE(d.goals, e.goals) as goals,
+       COALESCE(e.assists, d.assists) as assists,
+       COALESCE(d.yellow_cards, e.yellow_cards) as yellow_cards,
+       COALESCE(d.red_cards, e.red_cards) as red_cards,
+       COALESCE(d.penalties, e.penalties) as penalties,
+       COALESCE(d.own_goals, e.own_goals) as own_goals,
+       CASE WHEN d.id IS NOT NULL THEN d.minutes ELSE e.minutes END as minutes,
+       COALESCE(d.source, e.source) as source
+FROM (SELECT * FROM player_stats WHERE source = 'external') e
+FULL JOIN (SELECT * FROM player_stats WHERE source = 'derived') d
+  ON d.player_id = e.player_id AND d.season_id = e.season_id AND d.team_id = e.team_id;
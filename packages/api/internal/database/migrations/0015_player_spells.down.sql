@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS player_spells;
+
+ALTER TABLE squad_memberships
+  DROP COLUMN IF EXISTS joined_text,
+  DROP COLUMN IF EXISTS signed_from,
+  DROP COLUMN IF EXISTS joined_on;
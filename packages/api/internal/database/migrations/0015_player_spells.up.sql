@@ -0,0 +1,29 @@
+-- Spells are the periods a player spent at one club, derived from squad
+-- memberships by cmd/import-squads. The squad files' transfer columns are
+-- kept on each membership so spells can be rebuilt without the files.
+-- from_date and to_date are NULL when unknown; fee is in euros, with
+-- fee_text keeping the source's wording ("free transfer", "End of loan").
+
+ALTER TABLE squad_memberships
+  ADD COLUMN IF NOT EXISTS joined_on DATE,
+  ADD COLUMN IF NOT EXISTS signed_from VARCHAR(255),
+  ADD COLUMN IF NOT EXISTS joined_text TEXT;
+
+CREATE TABLE IF NOT EXISTS player_spells (
+  id SERIAL PRIMARY KEY,
+  player_id INTEGER NOT NULL REFERENCES players(id) ON DELETE CASCADE,
+  team_id INTEGER NOT NULL REFERENCES teams(id),
+  from_date DATE,
+  to_date DATE,
+  first_season_id INTEGER NOT NULL REFERENCES seasons(id),
+  last_season_id INTEGER NOT NULL REFERENCES seasons(id),
+  signed_from VARCHAR(255),
+  fee BIGINT,
+  fee_text VARCHAR(50),
+  loan BOOLEAN NOT NULL DEFAULT FALSE,
+  source VARCHAR(50) NOT NULL DEFAULT 'kaggle',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_spells_player ON player_spells(player_id);
+CREATE INDEX IF NOT EXISTS idx_player_spells_team ON player_spells(team_id);
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// RequireAdmin lets a request through to next only when it carries the admin
+// token as "Authorization: Bearer <token>". With no token configured every
+// request is refused, so admin endpoints are off unless ADMIN_TOKEN is set.
+func RequireAdmin(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			respondWithError(w, http.StatusUnauthorized, "Admin token required", errors.New("missing or wrong admin token"))
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/models"
+)
+
+// CacheHandler handles requests to drop cached aggregates
+type CacheHandler struct {
+	cache *cache.Cache
+}
+
+// NewCacheHandler creates a new cache handler
+func NewCacheHandler(c *cache.Cache) *CacheHandler {
+	return &CacheHandler{cache: c}
+}
+
+// Invalidate handles DELETE /api/v1/cache, which needs the admin token. With
+// ?season=N only entries built from that season (and from every season) are
+// dropped. The importers and rebuild commands invalidate the cache themselves
+// through cache.Notify, so this is for changes made by hand.
+func (h *CacheHandler) Invalidate(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{}
+
+	if seasonIDStr := r.URL.Query().Get("season"); seasonIDStr != "" {
+		seasonID, err := strconv.Atoi(seasonIDStr)
+		if err != nil || seasonID <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid season ID", err)
+			return
+		}
+		data["seasonId"] = seasonID
+		data["dropped"] = h.cache.InvalidateSeason(seasonID)
+	} else {
+		data["dropped"] = h.cache.InvalidateAll()
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    data,
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/services"
+)
+
+// FormHandler handles form guide and streak HTTP requests
+type FormHandler struct {
+	formService *services.FormService
+}
+
+// NewFormHandler creates a new form handler
+func NewFormHandler(formService *services.FormService) *FormHandler {
+	return &FormHandler{formService: formService}
+}
+
+// GetTeamForm handles GET /api/v1/teams/{id}/form
+func (h *FormHandler) GetTeamForm(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	teamID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid team ID", err)
+		return
+	}
+
+	seasonID := 0
+	if seasonIDStr := r.URL.Query().Get("season"); seasonIDStr != "" {
+		seasonID, err = strconv.Atoi(seasonIDStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid season ID", err)
+			return
+		}
+	}
+
+	last := services.DefaultFormLength
+	if lastStr := r.URL.Query().Get("last"); lastStr != "" {
+		last, err = strconv.Atoi(lastStr)
+		if err != nil || last < 1 || last > 50 {
+			respondWithError(w, http.StatusBadRequest, "Invalid last parameter, expected 1-50", err)
+			return
+		}
+	}
+
+	form, err := h.formService.GetTeamForm(teamID, seasonID, last)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch team form", err)
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    form,
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
+}
+
+// GetTeamStreaks handles GET /api/v1/teams/{id}/streaks
+func (h *FormHandler) GetTeamStreaks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	teamID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid team ID", err)
+		return
+	}
+
+	streaks, err := h.formService.GetTeamStreaks(teamID)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch team streaks", err)
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    streaks,
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
+}
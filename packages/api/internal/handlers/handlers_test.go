@@ -0,0 +1,779 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/query"
+	"github.com/premstats/api/internal/repository"
+	"github.com/premstats/api/internal/services"
+)
+
+// fixtureSeasonID is 2001/02, the season covered by the sample matches fixture
+const fixtureSeasonID = 10
+
+// testAdminToken is the admin token the test router's admin endpoints expect
+const testAdminToken = "test-admin-token"
+
+// newTestRouter wires the handlers to an in-memory store seeded from the
+// repository's CSV fixtures, serving the routes cmd/api serves. The reports
+// handler reads the database directly, so its routes are not usable here.
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+	repo, err := repository.LoadFixtures("../../../../data")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+
+	responseCache := cache.New(time.Minute)
+	standingsService := services.NewStandingsService(repo, repo, repo, repo, responseCache)
+	seasonService := services.NewSeasonService(repo, repo, standingsService, responseCache)
+	matchService := services.NewMatchService(repo, repo, repo)
+	playerService := services.NewPlayerService(repo, repo)
+
+	return NewRouter(Routes{
+		Teams:          NewTeamHandler(services.NewTeamService(repo)),
+		Matches:        NewMatchHandler(matchService),
+		Standings:      NewStandingsHandler(standingsService),
+		Seasons:        NewSeasonHandler(seasonService),
+		Players:        NewPlayerHandler(playerService),
+		PlayerIdentity: NewPlayerIdentityHandler(services.NewPlayerIdentityService(repo, repo, responseCache)),
+		PlayerStats:    NewPlayerStatsHandler(services.NewPlayerStatsService(repo)),
+		Leaderboards:   NewLeaderboardHandler(services.NewLeaderboardService(repo, repo, responseCache)),
+		Transfers:      NewTransferHandler(services.NewTransferService(repo, repo, repo, repo)),
+		Reconciliation: NewReconciliationHandler(services.NewReconciliationService(repo, standingsService)),
+		Form:           NewFormHandler(services.NewFormService(repo, repo, repo)),
+		Query:          NewQueryHandler(query.NewEngine(repo, playerService, standingsService, matchService, seasonService, responseCache)),
+		Reports:        &Handler{Cache: responseCache},
+		Cache:          NewCacheHandler(responseCache),
+		AdminToken:     testAdminToken,
+	})
+}
+
+// response mirrors models.APIResponse and models.PaginatedResponse with the
+// payload left for the test to decode
+type response struct {
+	Success bool                  `json:"success"`
+	Data    json.RawMessage       `json:"data"`
+	Meta    models.PaginationMeta `json:"meta"`
+	Error   string                `json:"error"`
+	Code    string                `json:"code"`
+}
+
+// get performs a request and decodes the envelope, checking the status code
+func get(t *testing.T, router http.Handler, path string, status int) response {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if rec.Code != status {
+		t.Fatalf("GET %s status = %d, want %d: %s", path, rec.Code, status, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET %s Content-Type = %q", path, ct)
+	}
+
+	var body response
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("GET %s returned invalid JSON: %v", path, err)
+	}
+	return body
+}
+
+// decode unmarshals a response payload
+func decode(t *testing.T, body response, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(body.Data, v); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+}
+
+func TestTeamsEndpoints(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := get(t, router, "/api/v1/teams", http.StatusOK)
+	var list struct {
+		Teams []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"teams"`
+	}
+	decode(t, body, &list)
+	if len(list.Teams) == 0 {
+		t.Fatal("expected teams from the fixtures")
+	}
+	// Without a limit every team is listed, as before the list was paginated
+	if len(list.Teams) != body.Meta.TotalItems {
+		t.Errorf("listed %d of %d teams", len(list.Teams), body.Meta.TotalItems)
+	}
+
+	first := list.Teams[0]
+	body = get(t, router, "/api/v1/teams/"+strconv.Itoa(first.ID), http.StatusOK)
+	var team struct {
+		Name string `json:"name"`
+	}
+	decode(t, body, &team)
+	if team.Name != first.Name {
+		t.Errorf("team name = %q, want %q", team.Name, first.Name)
+	}
+
+	// The fixtures register each team's code from the tables file
+	body = get(t, router, "/api/v1/teams/"+strconv.Itoa(first.ID)+"/aliases", http.StatusOK)
+	var aliases struct {
+		TeamID  int                `json:"teamId"`
+		Aliases []models.TeamAlias `json:"aliases"`
+	}
+	decode(t, body, &aliases)
+	if aliases.TeamID != first.ID || len(aliases.Aliases) != 1 || aliases.Aliases[0].Kind != models.AliasCode || aliases.Aliases[0].Source != "all_tables" {
+		t.Errorf("aliases = %+v, want the tables code for team %d", aliases, first.ID)
+	}
+}
+
+func TestNotFoundErrors(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, path := range []string{
+		"/api/v1/teams/9999",
+		"/api/v1/teams/9999/aliases",
+		"/api/v1/seasons/9999",
+		"/api/v1/matches/9999",
+		"/api/v1/standings/9999",
+	} {
+		body := get(t, router, path, http.StatusNotFound)
+		if body.Success || body.Code != apperrors.CodeNotFound || body.Error == "" {
+			t.Errorf("GET %s = %+v, want a NOT_FOUND error", path, body)
+		}
+	}
+}
+
+func TestInvalidStandingsView(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := get(t, router, "/api/v1/standings/10?view=sideways", http.StatusBadRequest)
+	if body.Code != apperrors.CodeInvalidArgument {
+		t.Errorf("code = %q, want %q", body.Code, apperrors.CodeInvalidArgument)
+	}
+}
+
+func TestSeasonMatchesAndStandings(t *testing.T) {
+	router := newTestRouter(t)
+	season := strconv.Itoa(fixtureSeasonID)
+
+	body := get(t, router, "/api/v1/matches/season/"+season, http.StatusOK)
+	var matches struct {
+		Matches []struct {
+			ID        int    `json:"id"`
+			Status    string `json:"status"`
+			HomeScore *int   `json:"homeScore"`
+			AwayScore *int   `json:"awayScore"`
+		} `json:"matches"`
+	}
+	decode(t, body, &matches)
+	if len(matches.Matches) == 0 {
+		t.Fatal("expected fixture matches")
+	}
+	goals := 0
+	for _, m := range matches.Matches {
+		if m.Status != "completed" {
+			t.Errorf("match %d status = %q, want completed", m.ID, m.Status)
+		}
+		goals += *m.HomeScore + *m.AwayScore
+	}
+
+	body = get(t, router, "/api/v1/matches/"+strconv.Itoa(matches.Matches[0].ID)+"/events", http.StatusOK)
+	var events struct {
+		Events []json.RawMessage `json:"events"`
+	}
+	decode(t, body, &events)
+	first := matches.Matches[0]
+	if len(events.Events) != *first.HomeScore+*first.AwayScore {
+		t.Errorf("match %d has %d events, want one per goal", first.ID, len(events.Events))
+	}
+
+	body = get(t, router, "/api/v1/standings/"+season, http.StatusOK)
+	var standings struct {
+		Season string `json:"season"`
+		Table  []struct {
+			Position int `json:"position"`
+			Played   int `json:"played"`
+			GoalsFor int `json:"goalsFor"`
+			Points   int `json:"points"`
+		} `json:"table"`
+	}
+	decode(t, body, &standings)
+	if standings.Season != "2001/02" {
+		t.Errorf("season = %q, want 2001/02", standings.Season)
+	}
+	played, scored := 0, 0
+	for i, entry := range standings.Table {
+		if entry.Position != i+1 {
+			t.Errorf("row %d has position %d", i, entry.Position)
+		}
+		if i > 0 && entry.Points > standings.Table[i-1].Points {
+			t.Errorf("row %d has more points than the row above", i)
+		}
+		played += entry.Played
+		scored += entry.GoalsFor
+	}
+	if played != 2*len(matches.Matches) || scored != goals {
+		t.Errorf("table counts %d appearances and %d goals, want %d and %d", played, scored, 2*len(matches.Matches), goals)
+	}
+
+	body = get(t, router, "/api/v1/seasons/"+season+"/summary", http.StatusOK)
+	var summary struct {
+		TotalMatches int      `json:"totalMatches"`
+		TotalGoals   int      `json:"totalGoals"`
+		Completed    bool     `json:"completed"`
+		Leader       string   `json:"leader"`
+		Champion     string   `json:"champion"`
+		Relegated    []string `json:"relegated"`
+	}
+	decode(t, body, &summary)
+	if summary.TotalMatches != len(matches.Matches) || summary.TotalGoals != goals {
+		t.Errorf("summary = %+v, want %d matches and %d goals", summary, len(matches.Matches), goals)
+	}
+	if summary.Completed || summary.Leader == "" || summary.Champion != "" {
+		t.Errorf("summary = %+v, want a leader and no champion for a partial season", summary)
+	}
+	// The sample only holds part of the season, so nobody is relegated yet
+	if len(summary.Relegated) != 0 {
+		t.Errorf("relegated = %v, want none for a partial season", summary.Relegated)
+	}
+}
+
+func TestSeasonMetadata(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := get(t, router, "/api/v1/seasons/"+strconv.Itoa(fixtureSeasonID), http.StatusOK)
+	var season models.Season
+	decode(t, body, &season)
+	if season.Year != 2001 || season.StartDate.Year() != 2001 || season.EndDate.Year() != 2002 {
+		t.Errorf("season = %+v, want 2001/02 dates", season)
+	}
+	if season.TeamCount != 20 || season.ExpectedMatches != 380 || season.PointsForWin != 3 {
+		t.Errorf("season = %+v, want the default 20-team format", season)
+	}
+	// The sample holds part of the season, but it finished long ago
+	if season.PlayedMatches == 0 || season.Status != models.SeasonComplete {
+		t.Errorf("season played %d matches with status %q, want a complete season with results", season.PlayedMatches, season.Status)
+	}
+
+	body = get(t, router, "/api/v1/seasons/current", http.StatusOK)
+	var current models.Season
+	decode(t, body, &current)
+	if current.ID == 0 || current.Status == models.SeasonUpcoming {
+		t.Fatalf("current season = %+v, want one that has started", current)
+	}
+
+	// Top scorers default to the current season
+	body = get(t, router, "/api/v1/stats/top-scorers", http.StatusOK)
+	var scorers struct {
+		SeasonID int `json:"seasonId"`
+	}
+	decode(t, body, &scorers)
+	if scorers.SeasonID != current.ID {
+		t.Errorf("top scorers season = %d, want the current season %d", scorers.SeasonID, current.ID)
+	}
+}
+
+func TestStandingsReconciliation(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := get(t, router, "/api/v1/reports/standings-reconciliation?season="+strconv.Itoa(fixtureSeasonID), http.StatusOK)
+	var report struct {
+		Status         string `json:"status"`
+		TeamsChecked   int    `json:"teamsChecked"`
+		MissingMatches int    `json:"missingMatches"`
+	}
+	decode(t, body, &report)
+	// Only a handful of matches are in the sample, so the computed table
+	// falls well short of the official one
+	if report.Status != "mismatch" || report.TeamsChecked != 20 || report.MissingMatches == 0 {
+		t.Errorf("report = %+v, want a mismatch across 20 teams", report)
+	}
+}
+
+func TestMatchesCursorPagination(t *testing.T) {
+	router := newTestRouter(t)
+
+	// Walk forwards through every page, then back again from the last one
+	var forward []int
+	var pages []response
+	path := "/api/v1/matches?season=" + strconv.Itoa(fixtureSeasonID) + "&limit=4"
+	for path != "" {
+		body := get(t, router, path, http.StatusOK)
+		var page struct {
+			Matches []struct {
+				ID int `json:"id"`
+			} `json:"matches"`
+		}
+		decode(t, body, &page)
+		for _, m := range page.Matches {
+			forward = append(forward, m.ID)
+		}
+		pages = append(pages, body)
+		path = body.Meta.Next
+	}
+
+	total := pages[0].Meta.TotalItems
+	if len(forward) != total || total == 0 {
+		t.Fatalf("walked %d matches, totalItems = %d", len(forward), total)
+	}
+	if want := (total + 3) / 4; len(pages) != want || pages[0].Meta.TotalPages != want {
+		t.Errorf("got %d pages, totalPages = %d, want %d", len(pages), pages[0].Meta.TotalPages, want)
+	}
+	if pages[0].Meta.Prev != "" {
+		t.Errorf("first page has a prev link %q", pages[0].Meta.Prev)
+	}
+
+	seen := make(map[int]bool)
+	for _, id := range forward {
+		if seen[id] {
+			t.Fatalf("match %d appeared on two pages", id)
+		}
+		seen[id] = true
+	}
+
+	var backward []int
+	for path = pages[len(pages)-1].Meta.Prev; path != ""; {
+		body := get(t, router, path, http.StatusOK)
+		var page struct {
+			Matches []struct {
+				ID int `json:"id"`
+			} `json:"matches"`
+		}
+		decode(t, body, &page)
+		ids := make([]int, 0, len(page.Matches))
+		for _, m := range page.Matches {
+			ids = append(ids, m.ID)
+		}
+		backward = append(ids, backward...)
+		path = body.Meta.Prev
+	}
+	lastPage := len(forward) - len(backward)
+	for i, id := range backward {
+		if forward[i] != id {
+			t.Fatalf("walking back gave %v, want %v", backward, forward[:lastPage])
+		}
+	}
+}
+
+func TestPaginationErrors(t *testing.T) {
+	router := newTestRouter(t)
+
+	for _, path := range []string{
+		"/api/v1/matches?cursor=garbage!",
+		"/api/v1/players?limit=-1",
+		"/api/v1/players?season=2003/04",
+		"/api/v1/players?team=arsenal",
+		"/api/v1/search?q=a&offset=x",
+	} {
+		body := get(t, router, path, http.StatusBadRequest)
+		if body.Code != apperrors.CodeInvalidArgument {
+			t.Errorf("GET %s code = %q, want %q", path, body.Code, apperrors.CodeInvalidArgument)
+		}
+	}
+}
+
+func TestSearchAndPlayersReportTotals(t *testing.T) {
+	router := newTestRouter(t)
+
+	players := get(t, router, "/api/v1/players?limit=2", http.StatusOK)
+	if players.Meta.TotalItems <= 2 || players.Meta.Next == "" || players.Meta.ItemsPerPage != 2 {
+		t.Errorf("players meta = %+v, want more than one page of scorers", players.Meta)
+	}
+
+	search := get(t, router, "/api/v1/search?q=a&limit=1", http.StatusOK)
+	var data struct {
+		Results []models.SearchResult `json:"results"`
+		Count   int                   `json:"count"`
+	}
+	decode(t, search, &data)
+	if len(data.Results) != 1 || data.Count != 1 || search.Meta.TotalItems <= 1 {
+		t.Errorf("search = %+v with meta %+v", data, search.Meta)
+	}
+
+	next := get(t, router, search.Meta.Next, http.StatusOK)
+	var nextData struct {
+		Results []models.SearchResult `json:"results"`
+	}
+	decode(t, next, &nextData)
+	if len(nextData.Results) != 1 || nextData.Results[0] == data.Results[0] {
+		t.Errorf("second search page = %+v", nextData.Results)
+	}
+}
+
+func TestMatchFilters(t *testing.T) {
+	router := newTestRouter(t)
+	season := "/api/v1/matches?season=" + strconv.Itoa(fixtureSeasonID)
+
+	all := get(t, router, season, http.StatusOK)
+	high := get(t, router, season+"&minGoals=3&sort=goals", http.StatusOK)
+	var data struct {
+		Matches []models.Match         `json:"matches"`
+		Filters map[string]interface{} `json:"filters"`
+	}
+	decode(t, high, &data)
+	if high.Meta.TotalItems == 0 || high.Meta.TotalItems >= all.Meta.TotalItems {
+		t.Fatalf("minGoals=3 kept %d of %d matches", high.Meta.TotalItems, all.Meta.TotalItems)
+	}
+	for i, match := range data.Matches {
+		goals := *match.HomeScore + *match.AwayScore
+		if goals < 3 {
+			t.Errorf("match %d has %d goals", match.ID, goals)
+		}
+		if i > 0 && goals > *data.Matches[i-1].HomeScore+*data.Matches[i-1].AwayScore {
+			t.Errorf("matches not sorted by goals at %d", i)
+		}
+	}
+	if data.Filters["minGoals"] != "3" || data.Filters["sort"] != "goals" || data.Filters["limit"] != float64(50) {
+		t.Errorf("filters = %v", data.Filters)
+	}
+
+	for _, path := range []string{
+		"/api/v1/matches?season=2003/04",
+		"/api/v1/matches?venue=home",
+		"/api/v1/matches?team=1&venue=neutral",
+		"/api/v1/matches?team=1&result=X",
+		"/api/v1/matches?team=1&opponent=1",
+		"/api/v1/matches?from=2001-13-01",
+		"/api/v1/matches?from=2002-01-01&to=2001-12-31",
+		"/api/v1/matches?scoreline=2:1",
+		"/api/v1/matches?minGoals=-1",
+		"/api/v1/matches?comeback=maybe",
+		"/api/v1/matches?sort=attendance",
+		"/api/v1/matches?order=up",
+	} {
+		body := get(t, router, path, http.StatusBadRequest)
+		if body.Code != apperrors.CodeInvalidArgument {
+			t.Errorf("GET %s code = %q, want %q", path, body.Code, apperrors.CodeInvalidArgument)
+		}
+	}
+}
+
+func TestConditionalGet(t *testing.T) {
+	router := newTestRouter(t)
+	path := "/api/v1/seasons/" + strconv.Itoa(fixtureSeasonID) + "/summary"
+
+	serve := func(method string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := serve(http.MethodGet, nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("first GET = %d with ETag %q, want 200 with an ETag", first.Code, etag)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Cache-Control = %q, want no-cache", got)
+	}
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{"matching etag", http.Header{"If-None-Match": {etag}}, http.StatusNotModified},
+		{"weak etag in a list", http.Header{"If-None-Match": {`"other", W/` + etag}}, http.StatusNotModified},
+		{"stale etag", http.Header{"If-None-Match": {`"other"`}}, http.StatusOK},
+		// Without a Last-Modified header the date alone never matches
+		{"if-modified-since", http.Header{"If-Modified-Since": {time.Now().UTC().Format(http.TimeFormat)}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(http.MethodGet, tt.header)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Errorf("304 response has a %d byte body", rec.Body.Len())
+			}
+			if rec.Header().Get("ETag") != etag {
+				t.Errorf("ETag changed between identical responses")
+			}
+		})
+	}
+
+	// Errors are never conditional
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/seasons/999/summary", nil)
+	req.Header.Set("If-None-Match", "*")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound || rec.Header().Get("ETag") != "" {
+		t.Errorf("missing season = %d with ETag %q, want a plain 404", rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2024, 5, 19, 16, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+
+	tests := []struct {
+		name  string
+		since string
+		want  bool
+	}{
+		{"unchanged since", modified, true},
+		{"changed after", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat), false},
+		{"unparsable", "yesterday", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("If-Modified-Since", tt.since)
+		if got := notModified(req, `"etag"`, modified); got != tt.want {
+			t.Errorf("%s: notModified = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInvalidateCache(t *testing.T) {
+	router := newTestRouter(t)
+	get(t, router, "/api/v1/standings/"+strconv.Itoa(fixtureSeasonID), http.StatusOK)
+	get(t, router, "/api/v1/seasons/"+strconv.Itoa(fixtureSeasonID)+"/summary", http.StatusOK)
+
+	serveAs := func(token, path string, status int) response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != status {
+			t.Fatalf("DELETE %s = %d, want %d: %s", path, rec.Code, status, rec.Body)
+		}
+		var body response
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decoding DELETE %s: %v", path, err)
+		}
+		return body
+	}
+	serve := func(path string, status int) response {
+		t.Helper()
+		return serveAs(testAdminToken, path, status)
+	}
+
+	for _, token := range []string{"", "wrong"} {
+		if body := serveAs(token, "/api/v1/cache", http.StatusUnauthorized); body.Code != apperrors.CodeUnauthorized {
+			t.Errorf("code without the admin token = %q, want %q", body.Code, apperrors.CodeUnauthorized)
+		}
+	}
+
+	var data struct {
+		Dropped int `json:"dropped"`
+	}
+	decode(t, serve("/api/v1/cache?season=1", http.StatusOK), &data)
+	if data.Dropped != 0 {
+		t.Errorf("invalidating an uncached season dropped %d entries", data.Dropped)
+	}
+	// The summary caches both itself and the table it was built from
+	decode(t, serve("/api/v1/cache?season="+strconv.Itoa(fixtureSeasonID), http.StatusOK), &data)
+	if data.Dropped != 2 {
+		t.Errorf("invalidating season %d dropped %d entries, want 2", fixtureSeasonID, data.Dropped)
+	}
+	serve("/api/v1/cache?season=abc", http.StatusBadRequest)
+}
+
+func TestPlayerDuplicates(t *testing.T) {
+	router := newTestRouter(t)
+
+	var data struct {
+		Candidates    []models.PlayerMatchCandidate `json:"candidates"`
+		MinConfidence float64                       `json:"minConfidence"`
+	}
+	decode(t, get(t, router, "/api/v1/players/duplicates?minConfidence=0.3&limit=5", http.StatusOK), &data)
+	if data.MinConfidence != 0.3 {
+		t.Errorf("minConfidence = %v, want 0.3", data.MinConfidence)
+	}
+	for i := 1; i < len(data.Candidates); i++ {
+		if data.Candidates[i].Confidence > data.Candidates[i-1].Confidence {
+			t.Errorf("candidates not ordered by confidence: %+v", data.Candidates)
+		}
+	}
+	get(t, router, "/api/v1/players/duplicates?minConfidence=abc", http.StatusBadRequest)
+	get(t, router, "/api/v1/players/duplicates?minConfidence=2", http.StatusBadRequest)
+
+	postAs := func(token, path, body string, status int) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != status {
+			t.Errorf("POST %s %s = %d, want %d: %s", path, body, rec.Code, status, rec.Body)
+		}
+	}
+	post := func(path, body string, status int) {
+		t.Helper()
+		postAs(testAdminToken, path, body, status)
+	}
+	postAs("", "/api/v1/players/1/merge", `{"duplicateId": 2}`, http.StatusUnauthorized)
+	postAs("wrong", "/api/v1/players/duplicates/reject", `{"playerId": 1, "duplicateId": 2}`, http.StatusUnauthorized)
+	post("/api/v1/players/1/merge", `{"duplicateId": 1}`, http.StatusBadRequest)
+	post("/api/v1/players/1/merge", `{}`, http.StatusBadRequest)
+	post("/api/v1/players/1/merge", `{"duplicateId": 999999}`, http.StatusNotFound)
+	post("/api/v1/players/duplicates/reject", `{"playerId": 1}`, http.StatusBadRequest)
+}
+
+func TestPlayerCareer(t *testing.T) {
+	router := newTestRouter(t)
+
+	var list struct {
+		Players []models.Player `json:"players"`
+	}
+	decode(t, get(t, router, "/api/v1/players?limit=1", http.StatusOK), &list)
+	if len(list.Players) != 1 {
+		t.Fatalf("no fixture players")
+	}
+
+	var career models.PlayerCareer
+	decode(t, get(t, router, "/api/v1/players/"+strconv.Itoa(list.Players[0].ID)+"/career", http.StatusOK), &career)
+	if career.Player.ID != list.Players[0].ID {
+		t.Errorf("career player = %+v, want %+v", career.Player, list.Players[0])
+	}
+	// Fixture players are scorers without season lines
+	if career.Seasons == nil || career.FirstGoal == nil || career.Debut == nil {
+		t.Errorf("career = %+v, want empty seasons and goal milestones", career)
+	}
+	if career.LastGoal.Date.Before(career.FirstGoal.Date) {
+		t.Errorf("last goal %v is before first goal %v", career.LastGoal.Date, career.FirstGoal.Date)
+	}
+
+	get(t, router, "/api/v1/players/999999/career", http.StatusNotFound)
+}
+
+func TestPlayerStatsDiff(t *testing.T) {
+	router := newTestRouter(t)
+
+	// The fixture's scorers have goals but no stored season lines
+	body := get(t, router, "/api/v1/reports/player-stats-diff?season="+strconv.Itoa(fixtureSeasonID)+"&limit=5", http.StatusOK)
+	var data struct {
+		SeasonID    int                      `json:"seasonId"`
+		Differences []models.PlayerStatsDiff `json:"differences"`
+	}
+	decode(t, body, &data)
+	if data.SeasonID != fixtureSeasonID || len(data.Differences) != 5 || body.Meta.TotalItems <= 5 {
+		t.Fatalf("report = %+v with meta %+v, want a page of unstored lines", data, body.Meta)
+	}
+	for _, diff := range data.Differences {
+		if diff.Stored != nil || diff.Derived == nil || diff.Derived.Goals == 0 {
+			t.Errorf("difference = %+v, want a derived line with goals and nothing stored", diff)
+		}
+	}
+
+	get(t, router, "/api/v1/reports/player-stats-diff?season=abc", http.StatusBadRequest)
+}
+
+func TestLeaderboards(t *testing.T) {
+	router := newTestRouter(t)
+
+	body := get(t, router, "/api/v1/stats/leaderboards", http.StatusOK)
+	var metrics struct {
+		Metrics []string `json:"metrics"`
+	}
+	decode(t, body, &metrics)
+	if !slices.Contains(metrics.Metrics, services.MetricGoalsPer90) {
+		t.Errorf("metrics = %v, want goalsPer90 listed", metrics.Metrics)
+	}
+
+	body = get(t, router, "/api/v1/stats/leaderboards/goalsPer90?fromSeason=1&toSeason="+strconv.Itoa(fixtureSeasonID), http.StatusOK)
+	var leaderboard models.Leaderboard
+	decode(t, body, &leaderboard)
+	if leaderboard.Metric != services.MetricGoalsPer90 || leaderboard.MinMinutes != services.DefaultMinMinutes ||
+		leaderboard.ToSeasonID != fixtureSeasonID || leaderboard.Entries == nil {
+		t.Errorf("leaderboard = %+v, want a goals per 90 range with the default minutes threshold", leaderboard)
+	}
+
+	for _, path := range []string{
+		"/api/v1/stats/leaderboards/shots",
+		"/api/v1/stats/leaderboards/goals?season=1&fromSeason=1",
+		"/api/v1/stats/leaderboards/goals?fromSeason=12&toSeason=11",
+		"/api/v1/stats/leaderboards/goals?team=abc",
+	} {
+		if body := get(t, router, path, http.StatusBadRequest); body.Code != apperrors.CodeInvalidArgument {
+			t.Errorf("%s: code = %q, want %q", path, body.Code, apperrors.CodeInvalidArgument)
+		}
+	}
+}
+
+func TestTransfers(t *testing.T) {
+	router := newTestRouter(t)
+
+	var list struct {
+		Players []models.Player `json:"players"`
+	}
+	decode(t, get(t, router, "/api/v1/players?limit=1", http.StatusOK), &list)
+	if len(list.Players) != 1 {
+		t.Fatalf("no fixture players")
+	}
+
+	var history models.PlayerTransfers
+	decode(t, get(t, router, "/api/v1/players/"+strconv.Itoa(list.Players[0].ID)+"/transfers", http.StatusOK), &history)
+	// Fixtures hold no squad files, so no spells
+	if history.Player.ID != list.Players[0].ID || history.Spells == nil {
+		t.Errorf("history = %+v, want the player with empty spells", history)
+	}
+	get(t, router, "/api/v1/players/999999/transfers", http.StatusNotFound)
+
+	var teams struct {
+		Teams []models.Team `json:"teams"`
+	}
+	decode(t, get(t, router, "/api/v1/teams?limit=1", http.StatusOK), &teams)
+	if len(teams.Teams) != 1 {
+		t.Fatalf("no fixture teams")
+	}
+	teamPath := "/api/v1/teams/" + strconv.Itoa(teams.Teams[0].ID) + "/transfers"
+
+	var transfers models.TeamTransfers
+	decode(t, get(t, router, teamPath+"?season="+strconv.Itoa(fixtureSeasonID), http.StatusOK), &transfers)
+	if transfers.SeasonID != fixtureSeasonID || transfers.SeasonName == "" || transfers.In == nil || transfers.Out == nil {
+		t.Errorf("transfers = %+v, want the fixture season with empty ins and outs", transfers)
+	}
+
+	get(t, router, teamPath+"?season=abc", http.StatusBadRequest)
+	get(t, router, "/api/v1/teams/999999/transfers", http.StatusNotFound)
+}
+
+func TestQuery(t *testing.T) {
+	router := newTestRouter(t)
+
+	ask := func(question string, status int) models.QueryResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/query", strings.NewReader(`{"query": "`+question+`"}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != status {
+			t.Fatalf("POST /api/v1/query %q = %d, want %d: %s", question, rec.Code, status, rec.Body)
+		}
+		var body response
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("query returned invalid JSON: %v", err)
+		}
+		var answer models.QueryResponse
+		decode(t, body, &answer)
+		return answer
+	}
+
+	// The lexicon is built from the fixture teams and seasons
+	answer := ask("league table 2001/02", http.StatusOK)
+	if answer.Intent == nil || answer.Intent.SeasonID != fixtureSeasonID {
+		t.Errorf("intent = %+v, want season %d", answer.Intent, fixtureSeasonID)
+	}
+	ask("what is the weather like", http.StatusUnprocessableEntity)
+}
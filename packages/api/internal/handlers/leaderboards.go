@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/services"
+)
+
+// LeaderboardHandler handles player leaderboards
+type LeaderboardHandler struct {
+	leaderboardService *services.LeaderboardService
+}
+
+// NewLeaderboardHandler creates a new leaderboard handler
+func NewLeaderboardHandler(leaderboardService *services.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{leaderboardService: leaderboardService}
+}
+
+// GetLeaderboard handles GET /api/v1/stats/leaderboards/{metric}. Without
+// season, fromSeason or toSeason the leaderboard covers all time.
+func (h *LeaderboardHandler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	opts := services.LeaderboardOptions{Metric: mux.Vars(r)["metric"]}
+
+	params := []struct {
+		name  string
+		value *int
+	}{
+		{"season", &opts.SeasonID},
+		{"fromSeason", &opts.FromSeasonID},
+		{"toSeason", &opts.ToSeasonID},
+		{"team", &opts.TeamID},
+		{"minMinutes", &opts.MinMinutes},
+	}
+	for _, param := range params {
+		s := r.URL.Query().Get(param.name)
+		if s == "" {
+			continue
+		}
+		value, err := strconv.Atoi(s)
+		if err != nil || value < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid "+param.name, err)
+			return
+		}
+		*param.value = value
+	}
+
+	opts.Limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	if opts.Limit <= 0 || opts.Limit > 100 {
+		opts.Limit = 20
+	}
+
+	leaderboard, err := h.leaderboardService.GetLeaderboard(opts)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch leaderboard", err)
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    leaderboard,
+	})
+}
+
+// GetLeaderboardMetrics handles GET /api/v1/stats/leaderboards
+func (h *LeaderboardHandler) GetLeaderboardMetrics(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, r, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"metrics":    services.LeaderboardMetrics(),
+			"minMinutes": services.DefaultMinMinutes,
+		},
+	})
+}
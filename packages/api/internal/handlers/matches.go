@@ -3,9 +3,13 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/premstats/api/internal/apperrors"
 	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
 	"github.com/premstats/api/internal/services"
 )
 
@@ -21,69 +25,157 @@ func NewMatchHandler(matchService *services.MatchService) *MatchHandler {
 
 // GetMatches handles GET /api/v1/matches
 func (h *MatchHandler) GetMatches(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	seasonIDStr := r.URL.Query().Get("season")
-	teamIDStr := r.URL.Query().Get("team")
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	opts, err := parseMatchOptions(r)
+	if err != nil {
+		respondWithServiceError(w, "Invalid match filters", err)
+		return
+	}
+
+	req, err := pagination.ParseRequest(r.URL.Query(), pagination.ListLimits)
+	if err != nil {
+		respondWithServiceError(w, "Invalid pagination parameters", err)
+		return
+	}
+
+	page, err := h.matchService.GetMatches(opts, req)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch matches", err)
+		return
+	}
+
+	respondWithPage(w, r, "matches", page, map[string]interface{}{
+		"filters": matchFilters(r, req),
+	})
+}
+
+// matchFilterParams are the query parameters GetMatches filters and sorts by
+var matchFilterParams = []string{
+	"season", "team", "opponent", "venue", "from", "to", "result",
+	"referee", "scoreline", "minGoals", "comeback", "sort", "order",
+}
+
+// matchFilters echoes the filter parameters a request set, with the page's
+// limit and offset
+func matchFilters(r *http.Request, req pagination.Request) map[string]interface{} {
+	filters := map[string]interface{}{"limit": req.Limit, "offset": req.Offset}
+	for _, param := range matchFilterParams {
+		if value := r.URL.Query().Get(param); value != "" {
+			filters[param] = value
+		}
+	}
+	return filters
+}
 
-	var seasonID, teamID, limit, offset int
+// parseMatchOptions reads and validates the GET /matches filters: season,
+// team, opponent, venue (home/away), from and to (YYYY-MM-DD), result
+// (W/D/L), referee, scoreline (e.g. 2-1), minGoals, comeback (true/false),
+// sort (date/goals/margin) and order (asc/desc)
+func parseMatchOptions(r *http.Request) (services.MatchOptions, error) {
+	query := r.URL.Query()
+	var opts services.MatchOptions
 	var err error
 
-	if seasonIDStr != "" {
-		seasonID, err = strconv.Atoi(seasonIDStr)
-		if err != nil {
-			respondWithError(w, http.StatusBadRequest, "Invalid season ID", err)
-			return
+	ids := []struct {
+		param string
+		dest  *int
+	}{
+		{"season", &opts.SeasonID},
+		{"team", &opts.TeamID},
+		{"opponent", &opts.OpponentID},
+	}
+	for _, id := range ids {
+		if s := query.Get(id.param); s != "" {
+			if *id.dest, err = strconv.Atoi(s); err != nil || *id.dest <= 0 {
+				return opts, apperrors.InvalidArgument("invalid %s %q, expected a positive ID", id.param, s)
+			}
 		}
 	}
 
-	if teamIDStr != "" {
-		teamID, err = strconv.Atoi(teamIDStr)
-		if err != nil {
-			respondWithError(w, http.StatusBadRequest, "Invalid team ID", err)
-			return
+	for _, bound := range []struct {
+		param string
+		dest  **time.Time
+	}{
+		{"from", &opts.From},
+		{"to", &opts.To},
+	} {
+		if s := query.Get(bound.param); s != "" {
+			date, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return opts, apperrors.InvalidArgument("invalid %s date %q, expected YYYY-MM-DD", bound.param, s)
+			}
+			*bound.dest = &date
 		}
 	}
+	if opts.From != nil && opts.To != nil && opts.To.Before(*opts.From) {
+		return opts, apperrors.InvalidArgument("to date must not be before from date")
+	}
 
-	if limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil {
-			respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
-			return
+	opts.Venue = query.Get("venue")
+	switch opts.Venue {
+	case "", "home", "away":
+	default:
+		return opts, apperrors.InvalidArgument("invalid venue %q, expected home or away", opts.Venue)
+	}
+
+	opts.Result = strings.ToUpper(query.Get("result"))
+	switch opts.Result {
+	case "", "W", "D", "L":
+	default:
+		return opts, apperrors.InvalidArgument("invalid result %q, expected W, D or L", query.Get("result"))
+	}
+
+	// Opponent, venue and result only make sense from one team's point of view
+	if opts.TeamID == 0 {
+		for _, param := range []string{"opponent", "venue", "result"} {
+			if query.Get(param) != "" {
+				return opts, apperrors.InvalidArgument("%s filter requires a team", param)
+			}
 		}
-	} else {
-		limit = 50 // Default limit
+	}
+	if opts.OpponentID > 0 && opts.OpponentID == opts.TeamID {
+		return opts, apperrors.InvalidArgument("opponent must be a different team")
 	}
 
-	if offsetStr != "" {
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil {
-			respondWithError(w, http.StatusBadRequest, "Invalid offset", err)
-			return
+	opts.Referee = strings.TrimSpace(query.Get("referee"))
+
+	if s := query.Get("scoreline"); s != "" {
+		goalsFor, goalsAgainst, ok := strings.Cut(s, "-")
+		gf, errFor := strconv.Atoi(goalsFor)
+		ga, errAgainst := strconv.Atoi(goalsAgainst)
+		if !ok || errFor != nil || errAgainst != nil || gf < 0 || ga < 0 {
+			return opts, apperrors.InvalidArgument("invalid scoreline %q, expected goals-goals such as 2-1", s)
 		}
+		opts.GoalsFor, opts.GoalsAgainst = &gf, &ga
 	}
 
-	matches, err := h.matchService.GetMatches(teamID, seasonID, limit, offset)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch matches", err)
-		return
+	if s := query.Get("minGoals"); s != "" {
+		if opts.MinGoals, err = strconv.Atoi(s); err != nil || opts.MinGoals < 0 {
+			return opts, apperrors.InvalidArgument("invalid minGoals %q, expected a non-negative number", s)
+		}
 	}
 
-	response := models.APIResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"matches": matches,
-			"filters": map[string]interface{}{
-				"season": seasonID,
-				"team":   teamID,
-				"limit":  limit,
-				"offset": offset,
-			},
-		},
-	}
-
-	respondWithJSON(w, http.StatusOK, response)
+	if s := query.Get("comeback"); s != "" {
+		if opts.Comeback, err = strconv.ParseBool(s); err != nil {
+			return opts, apperrors.InvalidArgument("invalid comeback %q, expected true or false", s)
+		}
+	}
+
+	opts.Sort = query.Get("sort")
+	switch opts.Sort {
+	case "", services.MatchSortDate, services.MatchSortGoals, services.MatchSortMargin:
+	default:
+		return opts, apperrors.InvalidArgument("invalid sort %q, expected date, goals or margin", opts.Sort)
+	}
+
+	switch order := query.Get("order"); order {
+	case "", "desc":
+	case "asc":
+		opts.Ascending = true
+	default:
+		return opts, apperrors.InvalidArgument("invalid order %q, expected asc or desc", order)
+	}
+
+	return opts, nil
 }
 
 // GetMatchByID handles GET /api/v1/matches/{id}
@@ -97,7 +189,7 @@ func (h *MatchHandler) GetMatchByID(w http.ResponseWriter, r *http.Request) {
 
 	match, err := h.matchService.GetMatchByID(matchID)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Match not found", err)
+		respondWithServiceError(w, "Failed to fetch match", err)
 		return
 	}
 
@@ -106,7 +198,7 @@ func (h *MatchHandler) GetMatchByID(w http.ResponseWriter, r *http.Request) {
 		Data:    match,
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
 // GetMatchesBySeason handles GET /api/v1/matches/season/{seasonId}
@@ -118,71 +210,101 @@ func (h *MatchHandler) GetMatchesBySeason(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Parse pagination parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
-
-	limit := 50 // Default limit
-	offset := 0 // Default offset
+	req, err := pagination.ParseRequest(r.URL.Query(), pagination.ListLimits)
+	if err != nil {
+		respondWithServiceError(w, "Invalid pagination parameters", err)
+		return
+	}
 
-	if limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil {
-			respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
-			return
-		}
+	page, err := h.matchService.GetMatchesBySeasonID(seasonID, req)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch matches for season", err)
+		return
 	}
 
-	if offsetStr != "" {
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil {
-			respondWithError(w, http.StatusBadRequest, "Invalid offset", err)
-			return
-		}
+	respondWithPage(w, r, "matches", page, map[string]interface{}{
+		"seasonId":   seasonID,
+		"pagination": map[string]interface{}{"limit": req.Limit, "offset": req.Offset},
+	})
+}
+
+// GetMatchEvents handles GET /api/v1/matches/{id}/events
+func (h *MatchHandler) GetMatchEvents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid match ID", err)
+		return
 	}
 
-	matches, err := h.matchService.GetMatchesBySeasonID(seasonID, limit, offset)
+	req, err := pagination.ParseRequest(r.URL.Query(), pagination.WholeLimits)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch matches for season", err)
+		respondWithServiceError(w, "Invalid pagination parameters", err)
 		return
 	}
 
-	response := models.APIResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"matches":  matches,
-			"seasonId": seasonID,
-			"pagination": map[string]interface{}{
-				"limit":  limit,
-				"offset": offset,
-			},
-		},
+	page, err := h.matchService.GetMatchEvents(id, req)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch match events", err)
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithPage(w, r, "events", page, map[string]interface{}{})
 }
 
-// GetMatchEvents handles GET /api/v1/matches/{id}/events
-func (h *MatchHandler) GetMatchEvents(w http.ResponseWriter, r *http.Request) {
+// GetHeadToHead handles GET /api/v1/teams/{id}/head-to-head/{opponentId}
+func (h *MatchHandler) GetHeadToHead(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	id, err := strconv.Atoi(vars["id"])
+	teamID, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid match ID", err)
+		respondWithError(w, http.StatusBadRequest, "Invalid team ID", err)
+		return
+	}
+
+	opponentID, err := strconv.Atoi(vars["opponentId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid opponent ID", err)
+		return
+	}
+	if opponentID == teamID {
+		respondWithError(w, http.StatusBadRequest, "Opponent must be a different team", nil)
+		return
+	}
+
+	var opts services.HeadToHeadOptions
+	if fromStr := r.URL.Query().Get("fromSeason"); fromStr != "" {
+		opts.FromSeasonID, err = strconv.Atoi(fromStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid fromSeason", err)
+			return
+		}
+	}
+	if toStr := r.URL.Query().Get("toSeason"); toStr != "" {
+		opts.ToSeasonID, err = strconv.Atoi(toStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid toSeason", err)
+			return
+		}
+	}
+
+	opts.Venue = r.URL.Query().Get("venue")
+	switch opts.Venue {
+	case "", "all", "home", "away":
+	default:
+		respondWithError(w, http.StatusBadRequest, "Invalid venue, expected all, home or away", nil)
 		return
 	}
 
-	events, err := h.matchService.GetMatchEvents(id)
+	h2h, err := h.matchService.GetHeadToHead(teamID, opponentID, opts)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch match events", err)
+		respondWithServiceError(w, "Failed to fetch head-to-head record", err)
 		return
 	}
 
 	response := models.APIResponse{
 		Success: true,
-		Data: map[string]interface{}{
-			"events": events,
-		},
+		Data:    h2h,
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+	"github.com/premstats/api/internal/services"
+)
+
+// PlayerIdentityHandler handles the duplicate player review queue
+type PlayerIdentityHandler struct {
+	identityService *services.PlayerIdentityService
+}
+
+// NewPlayerIdentityHandler creates a new player identity handler
+func NewPlayerIdentityHandler(identityService *services.PlayerIdentityService) *PlayerIdentityHandler {
+	return &PlayerIdentityHandler{identityService: identityService}
+}
+
+// playerPairRequest is the body of the merge and reject endpoints
+type playerPairRequest struct {
+	PlayerID    int `json:"playerId"`
+	DuplicateID int `json:"duplicateId"`
+}
+
+// GetDuplicates handles GET /api/v1/players/duplicates
+func (h *PlayerIdentityHandler) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	minConfidence := services.DefaultMatchConfidence
+	if s := r.URL.Query().Get("minConfidence"); s != "" {
+		value, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid minConfidence", err)
+			return
+		}
+		minConfidence = value
+	}
+
+	req, err := pagination.ParseRequest(r.URL.Query(), pagination.ListLimits)
+	if err != nil {
+		respondWithServiceError(w, "Invalid pagination parameters", err)
+		return
+	}
+
+	page, err := h.identityService.GetReviewQueue(minConfidence, req)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch duplicate players", err)
+		return
+	}
+
+	respondWithPage(w, r, "candidates", page, map[string]interface{}{
+		"minConfidence": minConfidence,
+	})
+}
+
+// MergePlayer handles POST /api/v1/players/{id}/merge, folding the player
+// given as duplicateId in the body into {id}
+func (h *PlayerIdentityHandler) MergePlayer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playerID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid player ID", err)
+		return
+	}
+
+	var req playerPairRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DuplicateID <= 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body, expected {\"duplicateId\": N}", err)
+		return
+	}
+
+	result, err := h.identityService.MergePlayers(playerID, req.DuplicateID)
+	if err != nil {
+		respondWithServiceError(w, "Failed to merge players", err)
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    result,
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
+}
+
+// RejectDuplicate handles POST /api/v1/players/duplicates/reject, marking a
+// pair as different people
+func (h *PlayerIdentityHandler) RejectDuplicate(w http.ResponseWriter, r *http.Request) {
+	var req playerPairRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PlayerID <= 0 || req.DuplicateID <= 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body, expected {\"playerId\": N, \"duplicateId\": N}", err)
+		return
+	}
+
+	if err := h.identityService.RejectCandidate(req.PlayerID, req.DuplicateID); err != nil {
+		respondWithServiceError(w, "Failed to reject duplicate", err)
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data: map[string]interface{}{
+			"playerId":    req.PlayerID,
+			"duplicateId": req.DuplicateID,
+		},
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
+}
@@ -1,12 +1,12 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
 	"github.com/premstats/api/internal/services"
 )
 
@@ -23,53 +23,34 @@ func NewPlayerHandler(service *services.PlayerService) *PlayerHandler {
 // GetPlayers handles GET /api/v1/players
 func (h *PlayerHandler) GetPlayers(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit <= 0 || limit > 100 {
-		limit = 50
+	req, err := pagination.ParseRequest(r.URL.Query(), pagination.PlayerLimits)
+	if err != nil {
+		respondWithServiceError(w, "Invalid pagination parameters", err)
+		return
 	}
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
 	search := r.URL.Query().Get("search")
 	position := r.URL.Query().Get("position")
 	nationality := r.URL.Query().Get("nationality")
 	team := r.URL.Query().Get("team")
+	season := r.URL.Query().Get("season")
 
-	// Get players from service
-	players, err := h.service.GetPlayers(limit, offset, search, position, nationality, team)
+	// Get a page of players, with the total count, from the service
+	page, err := h.service.GetPlayers(req, search, position, nationality, team, season)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.APIResponse{
-			Success: false,
-			Error:   "Failed to fetch players",
-		})
+		respondWithServiceError(w, "Failed to fetch players", err)
 		return
 	}
 
-	// Get total count for pagination
-	total, err := h.service.GetPlayersCount(search, position, nationality, team)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.APIResponse{
-			Success: false,
-			Error:   "Failed to count players",
-		})
-		return
-	}
-
-	// Return success response
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.APIResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"players": players,
-			"total":   total,
-			"filters": map[string]interface{}{
-				"limit":       limit,
-				"offset":      offset,
-				"search":      search,
-				"position":    position,
-				"nationality": nationality,
-				"team":        team,
-			},
+	respondWithPage(w, r, "players", page, map[string]interface{}{
+		"total": page.Total,
+		"filters": map[string]interface{}{
+			"limit":       req.Limit,
+			"offset":      req.Offset,
+			"search":      search,
+			"position":    position,
+			"nationality": nationality,
+			"team":        team,
+			"season":      season,
 		},
 	})
 }
@@ -79,29 +60,13 @@ func (h *PlayerHandler) GetPlayerByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.APIResponse{
-			Success: false,
-			Error:   "Invalid player ID",
-		})
+		respondWithError(w, http.StatusBadRequest, "Invalid player ID", err)
 		return
 	}
 
 	player, err := h.service.GetPlayerByID(id)
 	if err != nil {
-		if err.Error() == "player not found" {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(models.APIResponse{
-				Success: false,
-				Error:   "Player not found",
-			})
-			return
-		}
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.APIResponse{
-			Success: false,
-			Error:   "Failed to fetch player",
-		})
+		respondWithServiceError(w, "Failed to fetch player", err)
 		return
 	}
 
@@ -109,8 +74,7 @@ func (h *PlayerHandler) GetPlayerByID(w http.ResponseWriter, r *http.Request) {
 	seasonID, _ := strconv.Atoi(r.URL.Query().Get("season"))
 	stats, _ := h.service.GetPlayerStats(id, seasonID)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.APIResponse{
+	respondWithJSON(w, r, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
 			"player": player,
@@ -119,13 +83,35 @@ func (h *PlayerHandler) GetPlayerByID(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GetPlayerCareer handles GET /api/v1/players/{id}/career
+func (h *PlayerHandler) GetPlayerCareer(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid player ID", err)
+		return
+	}
+
+	career, err := h.service.GetPlayerCareer(id)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch player career", err)
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    career,
+	})
+}
+
 // GetTopScorers handles GET /api/v1/stats/top-scorers
 func (h *PlayerHandler) GetTopScorers(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	seasonID, _ := strconv.Atoi(r.URL.Query().Get("season"))
-	if seasonID <= 0 {
-		// Default to current season (2024/25 = ID 33)
-		seasonID = 33
+	seasonID, err := h.service.ResolveSeasonID(seasonID)
+	if err != nil {
+		respondWithServiceError(w, "Failed to resolve current season", err)
+		return
 	}
 	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
 	if limit <= 0 || limit > 50 {
@@ -135,16 +121,11 @@ func (h *PlayerHandler) GetTopScorers(w http.ResponseWriter, r *http.Request) {
 	// Get top scorers from service
 	scorers, err := h.service.GetTopScorers(seasonID, limit)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.APIResponse{
-			Success: false,
-			Error:   "Failed to fetch top scorers",
-		})
+		respondWithServiceError(w, "Failed to fetch top scorers", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.APIResponse{
+	respondWithJSON(w, r, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
 			"topScorers": scorers,
@@ -158,37 +139,25 @@ func (h *PlayerHandler) GetTopScorers(w http.ResponseWriter, r *http.Request) {
 func (h *PlayerHandler) SearchPlayers(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(models.APIResponse{
-			Success: false,
-			Error:   "Search query is required",
-		})
+		respondWithError(w, http.StatusBadRequest, "Search query is required", nil)
 		return
 	}
 
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit <= 0 || limit > 50 {
-		limit = 20
+	req, err := pagination.ParseRequest(r.URL.Query(), pagination.SearchLimits)
+	if err != nil {
+		respondWithServiceError(w, "Invalid pagination parameters", err)
+		return
 	}
 
-	results, err := h.service.SearchPlayers(query, limit)
+	page, err := h.service.SearchPlayers(query, req)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.APIResponse{
-			Success: false,
-			Error:   "Search failed",
-		})
+		respondWithServiceError(w, "Search failed", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.APIResponse{
-		Success: true,
-		Data: map[string]interface{}{
-			"results": results,
-			"query":   query,
-			"count":   len(results),
-		},
+	respondWithPage(w, r, "results", page, map[string]interface{}{
+		"query": query,
+		"count": len(page.Items),
 	})
 }
 
@@ -196,16 +165,11 @@ func (h *PlayerHandler) SearchPlayers(w http.ResponseWriter, r *http.Request) {
 func (h *PlayerHandler) GetPlayerPositions(w http.ResponseWriter, r *http.Request) {
 	positions, err := h.service.GetPlayerPositions()
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.APIResponse{
-			Success: false,
-			Error:   "Failed to fetch positions",
-		})
+		respondWithServiceError(w, "Failed to fetch positions", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.APIResponse{
+	respondWithJSON(w, r, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
 			"positions": positions,
@@ -217,16 +181,11 @@ func (h *PlayerHandler) GetPlayerPositions(w http.ResponseWriter, r *http.Reques
 func (h *PlayerHandler) GetPlayerNationalities(w http.ResponseWriter, r *http.Request) {
 	nationalities, err := h.service.GetPlayerNationalities()
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(models.APIResponse{
-			Success: false,
-			Error:   "Failed to fetch nationalities",
-		})
+		respondWithServiceError(w, "Failed to fetch nationalities", err)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.APIResponse{
+	respondWithJSON(w, r, http.StatusOK, models.APIResponse{
 		Success: true,
 		Data: map[string]interface{}{
 			"nationalities": nationalities,
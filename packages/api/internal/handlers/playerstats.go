@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/premstats/api/internal/pagination"
+	"github.com/premstats/api/internal/services"
+)
+
+// PlayerStatsHandler handles reports on player season lines
+type PlayerStatsHandler struct {
+	statsService *services.PlayerStatsService
+}
+
+// NewPlayerStatsHandler creates a new player stats handler
+func NewPlayerStatsHandler(statsService *services.PlayerStatsService) *PlayerStatsHandler {
+	return &PlayerStatsHandler{statsService: statsService}
+}
+
+// GetPlayerStatsDiff handles GET /api/v1/reports/player-stats-diff, listing
+// the season lines whose stored numbers disagree with the match data
+func (h *PlayerStatsHandler) GetPlayerStatsDiff(w http.ResponseWriter, r *http.Request) {
+	data := map[string]interface{}{}
+
+	seasonID := 0
+	if seasonIDStr := r.URL.Query().Get("season"); seasonIDStr != "" {
+		var err error
+		seasonID, err = strconv.Atoi(seasonIDStr)
+		if err != nil || seasonID <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid season ID", err)
+			return
+		}
+		data["seasonId"] = seasonID
+	}
+
+	req, err := pagination.ParseRequest(r.URL.Query(), pagination.ListLimits)
+	if err != nil {
+		respondWithServiceError(w, "Invalid pagination parameters", err)
+		return
+	}
+
+	page, err := h.statsService.GetStatsDiff(seasonID, req)
+	if err != nil {
+		respondWithServiceError(w, "Failed to compare player stats", err)
+		return
+	}
+
+	respondWithPage(w, r, "differences", page, data)
+}
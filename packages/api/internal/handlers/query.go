@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/query"
+)
+
+// QueryHandler handles natural-language query HTTP requests
+type QueryHandler struct {
+	engine *query.Engine
+}
+
+// NewQueryHandler creates a new query handler
+func NewQueryHandler(engine *query.Engine) *QueryHandler {
+	return &QueryHandler{engine: engine}
+}
+
+// queryRequest is the body of POST /api/v1/query
+type queryRequest struct {
+	Query string `json:"query"`
+}
+
+// Query handles POST /api/v1/query
+func (h *QueryHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req queryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body, expected {\"query\": \"...\"}", err)
+		return
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		respondWithError(w, http.StatusBadRequest, "Query is required", nil)
+		return
+	}
+
+	answer, err := h.engine.Answer(req.Query)
+	if err != nil {
+		respondWithServiceError(w, "Failed to answer query", err)
+		return
+	}
+
+	if !answer.Understood {
+		respondWithJSON(w, r, http.StatusUnprocessableEntity, models.APIResponse{
+			Success: false,
+			Data:    answer,
+			Error:   "Could not understand the question",
+		})
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    answer,
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
+}
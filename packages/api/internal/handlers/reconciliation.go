@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/services"
+)
+
+// ReconciliationHandler handles standings reconciliation HTTP requests
+type ReconciliationHandler struct {
+	reconciliationService *services.ReconciliationService
+}
+
+// NewReconciliationHandler creates a new reconciliation handler
+func NewReconciliationHandler(reconciliationService *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciliationService: reconciliationService}
+}
+
+// GetStandingsReconciliation handles GET /api/v1/reports/standings-reconciliation
+func (h *ReconciliationHandler) GetStandingsReconciliation(w http.ResponseWriter, r *http.Request) {
+	seasonIDStr := r.URL.Query().Get("season")
+	if seasonIDStr == "" {
+		reports, err := h.reconciliationService.ReconcileAllSeasons()
+		if err != nil {
+			respondWithServiceError(w, "Failed to reconcile standings", err)
+			return
+		}
+
+		response := models.APIResponse{
+			Success: true,
+			Data:    map[string]interface{}{"seasons": reports},
+		}
+
+		respondWithJSON(w, r, http.StatusOK, response)
+		return
+	}
+
+	seasonID, err := strconv.Atoi(seasonIDStr)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid season ID", err)
+		return
+	}
+
+	report, err := h.reconciliationService.ReconcileSeason(seasonID)
+	if err != nil {
+		respondWithServiceError(w, "Failed to reconcile standings", err)
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    report,
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
+}
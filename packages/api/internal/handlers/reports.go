@@ -1,18 +1,20 @@
 package handlers
 
 import (
-	"encoding/json"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
 	
+	"github.com/premstats/api/internal/cache"
 	"github.com/premstats/api/internal/database"
 )
 
 // Handler provides access to database for reports
 type Handler struct {
 	DB *database.DB
+	// Cache keeps generated reports; it may be nil
+	Cache *cache.Cache
 }
 
 // SeasonCompleteness represents data completeness for a single season
@@ -86,16 +88,33 @@ type ActivityLog struct {
 	Source      string    `json:"source"`
 }
 
-// GetDataCompletenessReport generates a comprehensive data completeness report
+// GetDataCompletenessReport serves the data completeness report, generating
+// it when no cached copy is current
 func (h *Handler) GetDataCompletenessReport(w http.ResponseWriter, r *http.Request) {
+	report, err := cache.Fetch(h.Cache, "reports:data-completeness", cache.AllSeasons, h.generateDataCompletenessReport)
+	if err != nil {
+		respondWithServiceError(w, "Error generating season data", err)
+		return
+	}
+
+	// Clients revalidate the report against the time it was generated
+	w.Header().Set("Cache-Control", "no-cache")
+	setLastModified(w, report.GeneratedAt)
+
+	respondWithJSON(w, r, http.StatusOK, map[string]interface{}{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// generateDataCompletenessReport builds a comprehensive data completeness report
+func (h *Handler) generateDataCompletenessReport() (*DataCompletenessReport, error) {
 	log.Println("🔍 Generating live data completeness report...")
 
 	// Generate season-by-season analysis
 	seasonData, err := h.getSeasonCompleteness()
 	if err != nil {
-		log.Printf("❌ Error getting season completeness: %v", err)
-		http.Error(w, "Error generating season data", http.StatusInternalServerError)
-		return
+		return nil, err
 	}
 
 	// Calculate overall statistics
@@ -115,29 +134,19 @@ func (h *Handler) GetDataCompletenessReport(w http.ResponseWriter, r *http.Reque
 		recentActivity = []ActivityLog{}
 	}
 
-	report := DataCompletenessReport{
+	report := &DataCompletenessReport{
 		OverallStats:   overallStats,
 		SeasonData:     seasonData,
 		EraStats:       eraStats,
 		BestSeasons:    bestSeasons,
 		WorstSeasons:   worstSeasons,
 		RecentActivity: recentActivity,
-		GeneratedAt:    time.Now(),
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Cache-Control", "no-cache")
-	
-	if err := json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    report,
-	}); err != nil {
-		log.Printf("❌ Error encoding report response: %v", err)
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
-		return
+		// Whole seconds, as Last-Modified cannot carry more
+		GeneratedAt:    time.Now().Truncate(time.Second),
 	}
 
 	log.Printf("✅ Data completeness report generated successfully with %d seasons", len(seasonData))
+	return report, nil
 }
 
 // getSeasonCompleteness retrieves detailed completeness data for each season
@@ -145,8 +154,9 @@ func (h *Handler) getSeasonCompleteness() ([]SeasonCompleteness, error) {
 	query := `
 		SELECT 
 			s.id,
-			s.year,
+			COALESCE(s.year, EXTRACT(YEAR FROM s.start_date)::INTEGER) as year,
 			s.name,
+			COALESCE(s.team_count, 20) * (COALESCE(s.team_count, 20) - 1) as expected_matches,
 			COUNT(DISTINCT m.id) as total_matches,
 			COUNT(DISTINCT CASE WHEN m.home_score IS NOT NULL AND m.away_score IS NOT NULL THEN m.id END) as matches_with_scores,
 			COUNT(DISTINCT CASE WHEN g.id IS NOT NULL THEN m.id END) as matches_with_goals,
@@ -160,8 +170,8 @@ func (h *Handler) getSeasonCompleteness() ([]SeasonCompleteness, error) {
 		FROM seasons s
 		LEFT JOIN matches m ON s.id = m.season_id
 		LEFT JOIN goals g ON m.id = g.match_id
-		GROUP BY s.id, s.year, s.name
-		ORDER BY s.year
+		GROUP BY s.id, s.year, s.start_date, s.name, s.team_count
+		ORDER BY s.start_date
 	`
 
 	rows, err := h.DB.Query(query)
@@ -179,6 +189,7 @@ func (h *Handler) getSeasonCompleteness() ([]SeasonCompleteness, error) {
 			&season.ID,
 			&season.Year,
 			&season.Name,
+			&season.ExpectedMatches,
 			&season.TotalMatches,
 			&season.MatchesWithScores,
 			&season.MatchesWithGoals,
@@ -196,7 +207,6 @@ func (h *Handler) getSeasonCompleteness() ([]SeasonCompleteness, error) {
 
 		// Calculate derived metrics
 		season.TeamsCount = teamsCount / 2 // Divide by 2 since we count home and away separately
-		season.ExpectedMatches = getExpectedMatchesForSeason(season.Year)
 		
 		if season.TotalMatches > 0 {
 			season.MatchCompleteness = float64(season.MatchesWithScores) / float64(season.TotalMatches) * 100
@@ -406,19 +416,6 @@ func (h *Handler) getRecentActivity() ([]ActivityLog, error) {
 
 // Helper functions
 
-func getExpectedMatchesForSeason(year int) int {
-	if year >= 1992 && year <= 1994 {
-		return 462 // 22 teams, 42 matches each
-	}
-	if year >= 1995 && year <= 2024 {
-		return 380 // 20 teams, 38 matches each
-	}
-	if year >= 2025 {
-		return 380 // Projected for future seasons
-	}
-	return 0 // Pre-Premier League
-}
-
 func getQualityLevel(goalCompleteness float64) (string, string) {
 	if goalCompleteness >= 95 {
 		return "Excellent", "🌟"
@@ -436,21 +433,20 @@ func getQualityLevel(goalCompleteness float64) (string, string) {
 func (h *Handler) GetSeasonCompleteness(w http.ResponseWriter, r *http.Request) {
 	yearStr := r.URL.Query().Get("year")
 	if yearStr == "" {
-		http.Error(w, "Year parameter required", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Year parameter required", nil)
 		return
 	}
 
 	year, err := strconv.Atoi(yearStr)
 	if err != nil {
-		http.Error(w, "Invalid year parameter", http.StatusBadRequest)
+		respondWithError(w, http.StatusBadRequest, "Invalid year parameter", err)
 		return
 	}
 
 	// Get all seasons to find the specific one
 	seasons, err := h.getSeasonCompleteness()
 	if err != nil {
-		log.Printf("❌ Error getting season completeness: %v", err)
-		http.Error(w, "Error retrieving season data", http.StatusInternalServerError)
+		respondWithServiceError(w, "Error retrieving season data", err)
 		return
 	}
 
@@ -464,16 +460,12 @@ func (h *Handler) GetSeasonCompleteness(w http.ResponseWriter, r *http.Request)
 	}
 
 	if targetSeason == nil {
-		http.Error(w, "Season not found", http.StatusNotFound)
+		respondWithError(w, http.StatusNotFound, "Season not found", nil)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+	respondWithJSON(w, r, http.StatusOK, map[string]interface{}{
 		"success": true,
 		"data":    targetSeason,
-	}); err != nil {
-		log.Printf("❌ Error encoding season response: %v", err)
-		http.Error(w, "Error encoding response", http.StatusInternalServerError)
-	}
+	})
 }
\ No newline at end of file
@@ -0,0 +1,25 @@
+package handlers
+
+import "testing"
+
+func TestGetQualityLevel(t *testing.T) {
+	tests := []struct {
+		completeness float64
+		want         string
+	}{
+		{100, "Excellent"},
+		{95, "Excellent"},
+		{94.9, "Good"},
+		{80, "Good"},
+		{79.9, "Partial"},
+		{50, "Partial"},
+		{49.9, "Minimal"},
+		{0.1, "Minimal"},
+		{0, "No Data"},
+	}
+	for _, tt := range tests {
+		if got, _ := getQualityLevel(tt.completeness); got != tt.want {
+			t.Errorf("getQualityLevel(%v) = %q, want %q", tt.completeness, got, tt.want)
+		}
+	}
+}
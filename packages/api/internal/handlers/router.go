@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Routes holds every handler the API serves, along with the token its admin
+// endpoints expect
+type Routes struct {
+	Teams          *TeamHandler
+	Matches        *MatchHandler
+	Standings      *StandingsHandler
+	Seasons        *SeasonHandler
+	Players        *PlayerHandler
+	PlayerIdentity *PlayerIdentityHandler
+	PlayerStats    *PlayerStatsHandler
+	Leaderboards   *LeaderboardHandler
+	Transfers      *TransferHandler
+	Reconciliation *ReconciliationHandler
+	Form           *FormHandler
+	Query          *QueryHandler
+	Reports        *Handler
+	Cache          *CacheHandler
+	AdminToken     string
+}
+
+// NewRouter registers every API route under /api/v1, so the server and the
+// handler tests serve the same route table
+func NewRouter(h Routes) *mux.Router {
+	router := mux.NewRouter()
+
+	// API routes
+	api := router.PathPrefix("/api/v1").Subrouter()
+
+	// Health check
+	api.HandleFunc("/health", HealthHandler).Methods("GET")
+
+	// Teams endpoints
+	api.HandleFunc("/teams", h.Teams.GetTeams).Methods("GET")
+	api.HandleFunc("/teams/{id:[0-9]+}", h.Teams.GetTeamByID).Methods("GET")
+	api.HandleFunc("/teams/{id:[0-9]+}/aliases", h.Teams.GetTeamAliases).Methods("GET")
+	api.HandleFunc("/teams/{id:[0-9]+}/transfers", h.Transfers.GetTeamTransfers).Methods("GET")
+	api.HandleFunc("/teams/{id:[0-9]+}/form", h.Form.GetTeamForm).Methods("GET")
+	api.HandleFunc("/teams/{id:[0-9]+}/streaks", h.Form.GetTeamStreaks).Methods("GET")
+	api.HandleFunc("/teams/{id:[0-9]+}/head-to-head/{opponentId:[0-9]+}", h.Matches.GetHeadToHead).Methods("GET")
+
+	// Seasons endpoints
+	api.HandleFunc("/seasons", h.Seasons.GetSeasons).Methods("GET")
+	api.HandleFunc("/seasons/{id:[0-9]+}", h.Seasons.GetSeasonByID).Methods("GET")
+	api.HandleFunc("/seasons/current", h.Seasons.GetCurrentSeason).Methods("GET")
+	api.HandleFunc("/seasons/{id:[0-9]+}/summary", h.Seasons.GetSeasonSummary).Methods("GET")
+
+	// Matches endpoints
+	api.HandleFunc("/matches", h.Matches.GetMatches).Methods("GET")
+	api.HandleFunc("/matches/{id:[0-9]+}", h.Matches.GetMatchByID).Methods("GET")
+	api.HandleFunc("/matches/{id:[0-9]+}/events", h.Matches.GetMatchEvents).Methods("GET")
+	api.HandleFunc("/matches/season/{seasonId:[0-9]+}", h.Matches.GetMatchesBySeason).Methods("GET")
+
+	// Standings endpoints
+	api.HandleFunc("/standings", h.Standings.GetStandings).Methods("GET")
+	api.HandleFunc("/standings/{seasonId:[0-9]+}", h.Standings.GetStandingsBySeasonID).Methods("GET")
+	api.HandleFunc("/standings/{seasonId:[0-9]+}/positions", h.Standings.GetPositionSeries).Methods("GET")
+	api.HandleFunc("/standings/seasons", h.Standings.GetAvailableSeasons).Methods("GET")
+	api.HandleFunc("/standings/team/{teamId:[0-9]+}/season/{seasonId:[0-9]+}", h.Standings.GetTeamStats).Methods("GET")
+
+	// Statistics endpoints (legacy compatibility)
+	api.HandleFunc("/stats/standings", h.Standings.GetStandings).Methods("GET")
+	api.HandleFunc("/stats/top-scorers", h.Players.GetTopScorers).Methods("GET")
+	api.HandleFunc("/stats/leaderboards", h.Leaderboards.GetLeaderboardMetrics).Methods("GET")
+	api.HandleFunc("/stats/leaderboards/{metric}", h.Leaderboards.GetLeaderboard).Methods("GET")
+
+	// Player endpoints
+	api.HandleFunc("/players", h.Players.GetPlayers).Methods("GET")
+	api.HandleFunc("/players/{id:[0-9]+}", h.Players.GetPlayerByID).Methods("GET")
+	api.HandleFunc("/players/{id:[0-9]+}/career", h.Players.GetPlayerCareer).Methods("GET")
+	api.HandleFunc("/players/{id:[0-9]+}/transfers", h.Transfers.GetPlayerTransfers).Methods("GET")
+	api.HandleFunc("/players/positions", h.Players.GetPlayerPositions).Methods("GET")
+	api.HandleFunc("/players/nationalities", h.Players.GetPlayerNationalities).Methods("GET")
+	api.HandleFunc("/players/duplicates", h.PlayerIdentity.GetDuplicates).Methods("GET")
+	api.HandleFunc("/players/duplicates/reject", RequireAdmin(h.AdminToken, h.PlayerIdentity.RejectDuplicate)).Methods("POST")
+	api.HandleFunc("/players/{id:[0-9]+}/merge", RequireAdmin(h.AdminToken, h.PlayerIdentity.MergePlayer)).Methods("POST")
+
+	// Search endpoint
+	api.HandleFunc("/search", h.Players.SearchPlayers).Methods("GET")
+
+	// Reports endpoints
+	api.HandleFunc("/reports/data-completeness", h.Reports.GetDataCompletenessReport).Methods("GET")
+	api.HandleFunc("/reports/season-completeness", h.Reports.GetSeasonCompleteness).Methods("GET")
+	api.HandleFunc("/reports/standings-reconciliation", h.Reconciliation.GetStandingsReconciliation).Methods("GET")
+	api.HandleFunc("/reports/player-stats-diff", h.PlayerStats.GetPlayerStatsDiff).Methods("GET")
+
+	// Cache endpoint
+	api.HandleFunc("/cache", RequireAdmin(h.AdminToken, h.Cache.Invalidate)).Methods("DELETE")
+
+	// Natural language query endpoint
+	api.HandleFunc("/query", h.Query.Query).Methods("POST")
+
+	// Set up error handlers
+	router.NotFoundHandler = http.HandlerFunc(NotFoundHandler)
+	router.MethodNotAllowedHandler = http.HandlerFunc(MethodNotAllowedHandler)
+
+	return router
+}
@@ -23,7 +23,7 @@ func NewSeasonHandler(seasonService *services.SeasonService) *SeasonHandler {
 func (h *SeasonHandler) GetSeasons(w http.ResponseWriter, r *http.Request) {
 	seasons, err := h.seasonService.GetAllSeasons()
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch seasons", err)
+		respondWithServiceError(w, "Failed to fetch seasons", err)
 		return
 	}
 
@@ -32,7 +32,7 @@ func (h *SeasonHandler) GetSeasons(w http.ResponseWriter, r *http.Request) {
 		Data:    map[string]interface{}{"seasons": seasons},
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
 // GetSeasonByID handles GET /api/v1/seasons/{id}
@@ -46,7 +46,7 @@ func (h *SeasonHandler) GetSeasonByID(w http.ResponseWriter, r *http.Request) {
 
 	season, err := h.seasonService.GetSeasonByID(seasonID)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Season not found", err)
+		respondWithServiceError(w, "Failed to fetch season", err)
 		return
 	}
 
@@ -55,7 +55,23 @@ func (h *SeasonHandler) GetSeasonByID(w http.ResponseWriter, r *http.Request) {
 		Data:    season,
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
+}
+
+// GetCurrentSeason handles GET /api/v1/seasons/current
+func (h *SeasonHandler) GetCurrentSeason(w http.ResponseWriter, r *http.Request) {
+	season, err := h.seasonService.GetCurrentSeason()
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch current season", err)
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    season,
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
 // GetSeasonSummary handles GET /api/v1/seasons/{id}/summary
@@ -69,7 +85,7 @@ func (h *SeasonHandler) GetSeasonSummary(w http.ResponseWriter, r *http.Request)
 
 	summary, err := h.seasonService.GetSeasonSummary(seasonID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch season summary", err)
+		respondWithServiceError(w, "Failed to fetch season summary", err)
 		return
 	}
 
@@ -78,5 +94,5 @@ func (h *SeasonHandler) GetSeasonSummary(w http.ResponseWriter, r *http.Request)
 		Data:    summary,
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
@@ -3,8 +3,10 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/premstats/api/internal/apperrors"
 	"github.com/premstats/api/internal/models"
 	"github.com/premstats/api/internal/services"
 )
@@ -34,9 +36,15 @@ func (h *StandingsHandler) GetStandings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	standings, err := h.standingsService.GetStandingsBySeasonID(seasonID)
+	opts, err := parseStandingsOptions(r)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch standings", err)
+		respondWithServiceError(w, "Invalid standings options", err)
+		return
+	}
+
+	standings, err := h.standingsService.GetStandings(seasonID, opts)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch standings", err)
 		return
 	}
 
@@ -45,7 +53,7 @@ func (h *StandingsHandler) GetStandings(w http.ResponseWriter, r *http.Request)
 		Data:    standings,
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
 // GetStandingsBySeasonID handles GET /api/v1/standings/{seasonId}
@@ -57,9 +65,15 @@ func (h *StandingsHandler) GetStandingsBySeasonID(w http.ResponseWriter, r *http
 		return
 	}
 
-	standings, err := h.standingsService.GetStandingsBySeasonID(seasonID)
+	opts, err := parseStandingsOptions(r)
+	if err != nil {
+		respondWithServiceError(w, "Invalid standings options", err)
+		return
+	}
+
+	standings, err := h.standingsService.GetStandings(seasonID, opts)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch standings", err)
+		respondWithServiceError(w, "Failed to fetch standings", err)
 		return
 	}
 
@@ -68,14 +82,43 @@ func (h *StandingsHandler) GetStandingsBySeasonID(w http.ResponseWriter, r *http
 		Data:    standings,
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
+}
+
+// GetPositionSeries handles GET /api/v1/standings/{seasonId}/positions
+func (h *StandingsHandler) GetPositionSeries(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	seasonID, err := strconv.Atoi(vars["seasonId"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid season ID", err)
+		return
+	}
+
+	opts, err := parseStandingsOptions(r)
+	if err != nil {
+		respondWithServiceError(w, "Invalid standings options", err)
+		return
+	}
+
+	series, err := h.standingsService.GetPositionSeries(seasonID, opts.View)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch position series", err)
+		return
+	}
+
+	response := models.APIResponse{
+		Success: true,
+		Data:    series,
+	}
+
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
 // GetAvailableSeasons handles GET /api/v1/standings/seasons
 func (h *StandingsHandler) GetAvailableSeasons(w http.ResponseWriter, r *http.Request) {
 	seasons, err := h.standingsService.GetAvailableSeasons()
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch available seasons", err)
+		respondWithServiceError(w, "Failed to fetch available seasons", err)
 		return
 	}
 
@@ -84,7 +127,7 @@ func (h *StandingsHandler) GetAvailableSeasons(w http.ResponseWriter, r *http.Re
 		Data:    map[string]interface{}{"seasons": seasons},
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
 // GetTeamStats handles GET /api/v1/standings/team/{teamId}/season/{seasonId}
@@ -104,7 +147,7 @@ func (h *StandingsHandler) GetTeamStats(w http.ResponseWriter, r *http.Request)
 
 	stats, err := h.standingsService.GetTeamStatsForSeason(teamID, seasonID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch team stats", err)
+		respondWithServiceError(w, "Failed to fetch team stats", err)
 		return
 	}
 
@@ -113,5 +156,35 @@ func (h *StandingsHandler) GetTeamStats(w http.ResponseWriter, r *http.Request)
 		Data:    stats,
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
+}
+
+// parseStandingsOptions reads the asOf (YYYY-MM-DD), matchweek and view query parameters
+func parseStandingsOptions(r *http.Request) (services.StandingsOptions, error) {
+	var opts services.StandingsOptions
+
+	if view := r.URL.Query().Get("view"); view != "" {
+		if !services.ValidStandingsView(view) {
+			return opts, apperrors.InvalidArgument("invalid view %q, expected overall, home, away, firstHalf, secondHalf or halfTime", view)
+		}
+		opts.View = view
+	}
+
+	if asOfStr := r.URL.Query().Get("asOf"); asOfStr != "" {
+		asOf, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			return opts, apperrors.InvalidArgument("invalid asOf date %q, expected YYYY-MM-DD", asOfStr)
+		}
+		opts.AsOf = &asOf
+	}
+
+	if matchweekStr := r.URL.Query().Get("matchweek"); matchweekStr != "" {
+		matchweek, err := strconv.Atoi(matchweekStr)
+		if err != nil || matchweek < 1 {
+			return opts, apperrors.InvalidArgument("invalid matchweek %q, expected a positive number", matchweekStr)
+		}
+		opts.Matchweek = matchweek
+	}
+
+	return opts, nil
 }
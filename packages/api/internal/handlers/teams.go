@@ -6,6 +6,7 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
 	"github.com/premstats/api/internal/services"
 )
 
@@ -19,27 +20,35 @@ func NewTeamHandler(teamService *services.TeamService) *TeamHandler {
 	return &TeamHandler{teamService: teamService}
 }
 
-// GetTeams handles GET /api/v1/teams
+// GetTeams handles GET /api/v1/teams and GET /api/v1/teams?season={seasonId}
 func (h *TeamHandler) GetTeams(w http.ResponseWriter, r *http.Request) {
-	seasonIDStr := r.URL.Query().Get("season")
-	if seasonIDStr != "" {
-		// If season specified, delegate to GetTeamsBySeasonID
-		h.GetTeamsBySeasonID(w, r)
-		return
+	var seasonID int
+	var err error
+	if seasonIDStr := r.URL.Query().Get("season"); seasonIDStr != "" {
+		seasonID, err = strconv.Atoi(seasonIDStr)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid season ID", err)
+			return
+		}
 	}
 
-	teams, err := h.teamService.GetAllTeams()
+	req, err := pagination.ParseRequest(r.URL.Query(), pagination.WholeLimits)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch teams", err)
+		respondWithServiceError(w, "Invalid pagination parameters", err)
 		return
 	}
 
-	response := models.APIResponse{
-		Success: true,
-		Data:    map[string]interface{}{"teams": teams},
+	page, err := h.teamService.GetTeams(seasonID, req)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch teams", err)
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	data := map[string]interface{}{}
+	if seasonID > 0 {
+		data["seasonId"] = seasonID
+	}
+	respondWithPage(w, r, "teams", page, data)
 }
 
 // GetTeamByID handles GET /api/v1/teams/{id}
@@ -53,7 +62,7 @@ func (h *TeamHandler) GetTeamByID(w http.ResponseWriter, r *http.Request) {
 
 	team, err := h.teamService.GetTeamByID(teamID)
 	if err != nil {
-		respondWithError(w, http.StatusNotFound, "Team not found", err)
+		respondWithServiceError(w, "Failed to fetch team", err)
 		return
 	}
 
@@ -62,34 +71,31 @@ func (h *TeamHandler) GetTeamByID(w http.ResponseWriter, r *http.Request) {
 		Data:    team,
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
-// GetTeamsBySeasonID handles GET /api/v1/teams?season={seasonId}
-func (h *TeamHandler) GetTeamsBySeasonID(w http.ResponseWriter, r *http.Request) {
-	seasonIDStr := r.URL.Query().Get("season")
-	if seasonIDStr == "" {
-		// If no season specified, return all teams
-		h.GetTeams(w, r)
-		return
-	}
-
-	seasonID, err := strconv.Atoi(seasonIDStr)
+// GetTeamAliases handles GET /api/v1/teams/{id}/aliases
+func (h *TeamHandler) GetTeamAliases(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	teamID, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Invalid season ID", err)
+		respondWithError(w, http.StatusBadRequest, "Invalid team ID", err)
 		return
 	}
 
-	teams, err := h.teamService.GetTeamsBySeasonID(seasonID)
+	aliases, err := h.teamService.GetTeamAliases(teamID)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Failed to fetch teams for season", err)
+		respondWithServiceError(w, "Failed to fetch team aliases", err)
 		return
 	}
 
 	response := models.APIResponse{
 		Success: true,
-		Data:    map[string]interface{}{"teams": teams, "seasonId": seasonID},
+		Data: map[string]interface{}{
+			"teamId":  teamID,
+			"aliases": aliases,
+		},
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
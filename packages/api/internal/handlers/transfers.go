@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/services"
+)
+
+// TransferHandler handles player transfer histories and club ins and outs
+type TransferHandler struct {
+	transferService *services.TransferService
+}
+
+// NewTransferHandler creates a new transfer handler
+func NewTransferHandler(transferService *services.TransferService) *TransferHandler {
+	return &TransferHandler{transferService: transferService}
+}
+
+// GetPlayerTransfers handles GET /api/v1/players/{id}/transfers
+func (h *TransferHandler) GetPlayerTransfers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playerID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid player ID", err)
+		return
+	}
+
+	transfers, err := h.transferService.GetPlayerTransfers(playerID)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch player transfers", err)
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    transfers,
+	})
+}
+
+// GetTeamTransfers handles GET /api/v1/teams/{id}/transfers, for the current
+// season unless season is given
+func (h *TransferHandler) GetTeamTransfers(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	teamID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid team ID", err)
+		return
+	}
+
+	seasonID := 0
+	if seasonIDStr := r.URL.Query().Get("season"); seasonIDStr != "" {
+		seasonID, err = strconv.Atoi(seasonIDStr)
+		if err != nil || seasonID <= 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid season ID", err)
+			return
+		}
+	}
+
+	transfers, err := h.transferService.GetTeamTransfers(teamID, seasonID)
+	if err != nil {
+		respondWithServiceError(w, "Failed to fetch team transfers", err)
+		return
+	}
+
+	respondWithJSON(w, r, http.StatusOK, models.APIResponse{
+		Success: true,
+		Data:    transfers,
+	})
+}
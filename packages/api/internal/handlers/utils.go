@@ -1,16 +1,25 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/premstats/api/internal/apperrors"
 	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
 )
 
-// respondWithJSON writes a JSON response
-func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+// respondWithJSON writes a JSON response. A successful GET carries an ETag
+// computed from the body, and clients revalidating with If-None-Match, or
+// with If-Modified-Since against a Last-Modified header set by the handler,
+// get 304 Not Modified when nothing changed.
+func respondWithJSON(w http.ResponseWriter, r *http.Request, code int, payload interface{}) {
 	response, err := json.Marshal(payload)
 	if err != nil {
 		log.Printf("Error marshaling JSON: %v", err)
@@ -18,21 +27,166 @@ func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 		return
 	}
 
+	if code == http.StatusOK && (r.Method == http.MethodGet || r.Method == http.MethodHead) {
+		sum := sha256.Sum256(response)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		if w.Header().Get("Cache-Control") == "" {
+			// Let clients keep responses but check back before reusing them
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+
+		if notModified(r, etag, w.Header().Get("Last-Modified")) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	writeJSON(w, code, response)
+}
+
+// writeJSON writes an encoded JSON body
+func writeJSON(w http.ResponseWriter, code int, body []byte) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
-	w.Write(response)
+	w.Write(body)
+}
+
+// notModified reports whether a conditional request's copy is still current.
+// If-None-Match takes precedence; If-Modified-Since is only checked without it.
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	since := r.Header.Get("If-Modified-Since")
+	if since == "" || lastModified == "" {
+		return false
+	}
+	sinceTime, err := http.ParseTime(since)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(sinceTime)
+}
+
+// setLastModified sets the Last-Modified header checked by respondWithJSON
+func setLastModified(w http.ResponseWriter, t time.Time) {
+	w.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// encodeJSON marshals an error payload and writes it. Errors are never
+// conditional, so they skip respondWithJSON's validators.
+func encodeJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling JSON: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, code, response)
 }
 
-// respondWithError writes an error response
+// respondWithError writes an error response for a failure detected in the
+// handler itself, such as an unparsable parameter
 func respondWithError(w http.ResponseWriter, code int, message string, err error) {
 	log.Printf("API Error: %s - %v", message, err)
 
 	response := models.APIResponse{
 		Success: false,
 		Error:   message,
+		Code:    codeForStatus(code),
+	}
+
+	encodeJSON(w, code, response)
+}
+
+// respondWithServiceError maps an error returned by a service to a status
+// code and error code. Domain errors keep their own message; anything else
+// is reported with the generic message.
+func respondWithServiceError(w http.ResponseWriter, message string, err error) {
+	code := apperrors.Code(err)
+	log.Printf("API Error: %s [%s] - %v", message, code, err)
+
+	response := models.APIResponse{
+		Success: false,
+		Error:   apperrors.Message(err, message),
+		Code:    code,
 	}
 
-	respondWithJSON(w, code, response)
+	encodeJSON(w, statusForCode(code), response)
+}
+
+// respondWithPage writes one page of a listing. The page's items are added
+// to data under key, and the meta links repeat the request with the cursor
+// of the neighbouring page.
+func respondWithPage[T any](w http.ResponseWriter, r *http.Request, key string, page *pagination.Page[T], data map[string]interface{}) {
+	data[key] = page.Items
+
+	meta := models.PaginationMeta{
+		TotalItems:   page.Total,
+		TotalPages:   (page.Total + page.Limit - 1) / page.Limit,
+		ItemsPerPage: page.Limit,
+		NextCursor:   page.Next,
+		PrevCursor:   page.Prev,
+		Next:         pageLink(r, page.Next),
+		Prev:         pageLink(r, page.Prev),
+	}
+
+	respondWithJSON(w, r, http.StatusOK, models.PaginatedResponse{
+		Success: true,
+		Data:    data,
+		Meta:    meta,
+	})
+}
+
+// pageLink returns the request's URL with its position replaced by a cursor
+func pageLink(r *http.Request, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	query := r.URL.Query()
+	query.Del("offset")
+	query.Set("cursor", cursor)
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// statusForCode maps error codes to HTTP status codes
+func statusForCode(code string) int {
+	switch code {
+	case apperrors.CodeNotFound:
+		return http.StatusNotFound
+	case apperrors.CodeInvalidArgument:
+		return http.StatusBadRequest
+	case apperrors.CodeUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// codeForStatus maps HTTP status codes to error codes
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusNotFound:
+		return apperrors.CodeNotFound
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return apperrors.CodeInvalidArgument
+	case http.StatusServiceUnavailable:
+		return apperrors.CodeUnavailable
+	case http.StatusUnauthorized:
+		return apperrors.CodeUnauthorized
+	}
+	return apperrors.CodeInternal
 }
 
 // HealthHandler handles health check requests
@@ -47,7 +201,7 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 		Message: "PremStats API is running",
 	}
 
-	respondWithJSON(w, http.StatusOK, response)
+	respondWithJSON(w, r, http.StatusOK, response)
 }
 
 // NotFoundHandler handles 404 errors
@@ -57,7 +211,7 @@ func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
 		Error:   fmt.Sprintf("Endpoint not found: %s %s", r.Method, r.URL.Path),
 	}
 
-	respondWithJSON(w, http.StatusNotFound, response)
+	encodeJSON(w, http.StatusNotFound, response)
 }
 
 // MethodNotAllowedHandler handles 405 errors
@@ -67,5 +221,5 @@ func MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
 		Error:   fmt.Sprintf("Method %s not allowed for endpoint %s", r.Method, r.URL.Path),
 	}
 
-	respondWithJSON(w, http.StatusMethodNotAllowed, response)
+	encodeJSON(w, http.StatusMethodNotAllowed, response)
 }
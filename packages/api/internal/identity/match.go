@@ -0,0 +1,122 @@
+package identity
+
+import (
+	"math"
+	"strings"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// Reasons given for a player match score
+const (
+	MatchSameName           = "same name"
+	MatchSameNameFolded     = "same name after folding accents"
+	MatchSameNameNickname   = "same name after expanding nicknames"
+	MatchNameContained      = "one name contains the other"
+	MatchSameInitial        = "same surname and matching initial"
+	MatchSameSurname        = "same surname"
+	MatchSameDateOfBirth    = "same date of birth"
+	MatchDifferentBirthDate = "different date of birth"
+	MatchSameNationality    = "same nationality"
+	MatchDifferentCountry   = "different nationality"
+)
+
+// PlayerMatch scores how likely two player records are the same person
+type PlayerMatch struct {
+	// Confidence runs from 0 for different people to 1 for certain
+	Confidence float64
+	Reasons    []string
+}
+
+// PlayerBlockingKeys returns the keys two records must share to be compared:
+// the folded surname, and the date of birth when it is known
+func PlayerBlockingKeys(player models.Player) []string {
+	var keys []string
+	if words := strings.Fields(FoldName(player.Name)); len(words) > 0 {
+		keys = append(keys, "surname:"+words[len(words)-1])
+	}
+	if player.DateOfBirth != "" {
+		keys = append(keys, "dob:"+player.DateOfBirth)
+	}
+	return keys
+}
+
+// MatchPlayers compares two player records. The name sets the base score;
+// a shared date of birth or nationality raises it and a conflicting one
+// lowers it, so namesakes born years apart are kept apart.
+func MatchPlayers(a, b models.Player) PlayerMatch {
+	var match PlayerMatch
+	match.Confidence, match.Reasons = nameScore(a.Name, b.Name)
+	if match.Confidence == 0 {
+		return match
+	}
+
+	switch {
+	case a.DateOfBirth == "" || b.DateOfBirth == "":
+	case a.DateOfBirth == b.DateOfBirth:
+		match.Confidence += (1 - match.Confidence) * 0.7
+		match.Reasons = append(match.Reasons, MatchSameDateOfBirth)
+	default:
+		match.Confidence *= 0.2
+		match.Reasons = append(match.Reasons, MatchDifferentBirthDate)
+	}
+
+	switch {
+	case a.Nationality == "" || b.Nationality == "":
+	case FoldName(a.Nationality) == FoldName(b.Nationality):
+		match.Confidence += (1 - match.Confidence) * 0.3
+		match.Reasons = append(match.Reasons, MatchSameNationality)
+	default:
+		match.Confidence *= 0.6
+		match.Reasons = append(match.Reasons, MatchDifferentCountry)
+	}
+
+	match.Confidence = math.Round(match.Confidence*1000) / 1000
+	return match
+}
+
+// nameScore rates how closely two names agree, from 0 when they share no
+// surname to 0.85 when they are identical. Names alone never give certainty
+// because different players share them.
+func nameScore(a, b string) (float64, []string) {
+	if a == b {
+		return 0.85, []string{MatchSameName}
+	}
+	foldedA, foldedB := FoldName(a), FoldName(b)
+	if foldedA == foldedB {
+		return 0.85, []string{MatchSameNameFolded}
+	}
+	if CanonicalName(a) == CanonicalName(b) {
+		return 0.75, []string{MatchSameNameNickname}
+	}
+
+	wordsA, wordsB := strings.Fields(foldedA), strings.Fields(foldedB)
+	if len(wordsA) == 0 || len(wordsB) == 0 || wordsA[len(wordsA)-1] != wordsB[len(wordsB)-1] {
+		return 0, nil
+	}
+	if containsWords(wordsA, wordsB) || containsWords(wordsB, wordsA) {
+		return 0.6, []string{MatchNameContained}
+	}
+	// "T. Henry" is Thierry Henry, but Andy and Ashley Cole share only a surname
+	if len(wordsA) > 1 && len(wordsB) > 1 && (len(wordsA[0]) == 1 || len(wordsB[0]) == 1) && wordsA[0][0] == wordsB[0][0] {
+		return 0.55, []string{MatchSameInitial}
+	}
+	return 0.3, []string{MatchSameSurname}
+}
+
+// containsWords reports whether every word of inner appears in outer
+func containsWords(outer, inner []string) bool {
+	for _, word := range inner {
+		found := false
+		for _, candidate := range outer {
+			if candidate == word {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/premstats/api/internal/models"
+)
+
+func TestMatchPlayers(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    models.Player
+		min     float64
+		max     float64
+		reasons int
+	}{
+		{"accents and birth date",
+			models.Player{Name: "Martin Ødegaard", DateOfBirth: "1998-12-17", Nationality: "Norway"},
+			models.Player{Name: "Martin Odegaard", DateOfBirth: "1998-12-17", Nationality: "Norway"},
+			0.95, 1, 3},
+		{"nickname without corroboration",
+			models.Player{Name: "Andy Cole"},
+			models.Player{Name: "Andrew Cole"},
+			0.75, 0.75, 1},
+		{"namesakes born years apart",
+			models.Player{Name: "Ashley Cole", DateOfBirth: "1980-12-20"},
+			models.Player{Name: "Ashley Cole", DateOfBirth: "1992-03-01"},
+			0, 0.2, 2},
+		{"same surname, different nationality",
+			models.Player{Name: "Joe Cole", Nationality: "England"},
+			models.Player{Name: "Carlton Cole", Nationality: "Sierra Leone"},
+			0, 0.2, 2},
+		{"middle name dropped",
+			models.Player{Name: "Cesc Fàbregas Soler"},
+			models.Player{Name: "Fabregas Soler"},
+			0.6, 0.6, 1},
+		{"initial only",
+			models.Player{Name: "T. Henry"},
+			models.Player{Name: "Thierry Henry"},
+			0.55, 0.55, 1},
+		{"different first names",
+			models.Player{Name: "Andy Cole"},
+			models.Player{Name: "Ashley Cole"},
+			0.3, 0.3, 1},
+		{"different surnames",
+			models.Player{Name: "Alan Shearer", DateOfBirth: "1970-08-13"},
+			models.Player{Name: "Alan Smith", DateOfBirth: "1970-08-13"},
+			0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := MatchPlayers(tt.a, tt.b)
+			if match.Confidence < tt.min || match.Confidence > tt.max {
+				t.Errorf("confidence = %v, want between %v and %v", match.Confidence, tt.min, tt.max)
+			}
+			if len(match.Reasons) != tt.reasons {
+				t.Errorf("reasons = %v, want %d", match.Reasons, tt.reasons)
+			}
+		})
+	}
+}
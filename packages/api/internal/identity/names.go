@@ -0,0 +1,100 @@
+// Package identity normalises player names and scores how likely two player
+// records describe the same person. Importers use it to resolve names in
+// source files and the review queue uses it to find duplicates.
+package identity
+
+import "strings"
+
+// diacritics folds the accented letters common in player names to ASCII
+var diacritics = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a", "ą", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e", "ę", "e", "ě", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i", "ı", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o", "ő", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u", "ů", "u", "ű", "u",
+	"ý", "y", "ÿ", "y",
+	"ç", "c", "ć", "c", "č", "c",
+	"ñ", "n", "ń", "n", "ň", "n",
+	"ś", "s", "š", "s", "ş", "s", "ș", "s",
+	"ź", "z", "ż", "z", "ž", "z",
+	"ł", "l", "ř", "r", "ť", "t", "ț", "t", "ď", "d", "đ", "d", "ğ", "g",
+	"æ", "ae", "œ", "oe", "ß", "ss",
+)
+
+// FoldName lowercases a name, folds diacritics and collapses punctuation and
+// whitespace, so "Martin Ødegaard" and "martin odegaard" compare equal
+func FoldName(name string) string {
+	name = diacritics.Replace(strings.ToLower(name))
+	name = strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' || r == '\'' || r == '’' {
+			return ' '
+		}
+		return r
+	}, name)
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// CanonicalName folds a name and expands a nickname in first position, so
+// "Bobby Zamora" and "Robert Zamora" compare equal
+func CanonicalName(name string) string {
+	words := strings.Fields(FoldName(name))
+	if len(words) > 1 {
+		if full, ok := nicknames[words[0]]; ok {
+			words[0] = full
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// nicknames maps the short forms of first names seen across sources to the
+// full name they stand for
+var nicknames = map[string]string{
+	"alex":   "alexander",
+	"andy":   "andrew",
+	"ben":    "benjamin",
+	"bill":   "william",
+	"billy":  "william",
+	"bob":    "robert",
+	"bobby":  "robert",
+	"cesc":   "francesc",
+	"chris":  "christopher",
+	"dan":    "daniel",
+	"danny":  "daniel",
+	"dave":   "david",
+	"davy":   "david",
+	"ed":     "edward",
+	"eddie":  "edward",
+	"fred":   "frederick",
+	"freddy": "frederick",
+	"greg":   "gregory",
+	"jim":    "james",
+	"jimmy":  "james",
+	"jamie":  "james",
+	"joe":    "joseph",
+	"jon":    "jonathan",
+	"ken":    "kenneth",
+	"kenny":  "kenneth",
+	"les":    "leslie",
+	"matt":   "matthew",
+	"mick":   "michael",
+	"mickey": "michael",
+	"mike":   "michael",
+	"nacho":  "ignacio",
+	"nick":   "nicholas",
+	"paco":   "francisco",
+	"pepe":   "jose",
+	"phil":   "philip",
+	"pete":   "peter",
+	"ray":    "raymond",
+	"rob":    "robert",
+	"robbie": "robert",
+	"ron":    "ronald",
+	"sam":    "samuel",
+	"stan":   "stanley",
+	"steve":  "stephen",
+	"stevie": "stephen",
+	"tom":    "thomas",
+	"tommy":  "thomas",
+	"tony":   "anthony",
+	"will":   "william",
+}
@@ -0,0 +1,19 @@
+package identity
+
+import "testing"
+
+func TestCanonicalName(t *testing.T) {
+	tests := map[string]string{
+		"Bobby Zamora":    "robert zamora",
+		"Robert Zamora":   "robert zamora",
+		"Andy Cole":       "andrew cole",
+		"Martin Ødegaard": "martin odegaard",
+		// A lone name is a surname, not a nickname
+		"Tony": "tony",
+	}
+	for name, want := range tests {
+		if got := CanonicalName(name); got != want {
+			t.Errorf("CanonicalName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
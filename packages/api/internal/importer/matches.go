@@ -0,0 +1,572 @@
+package importer
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/premstats/api/internal/database"
+)
+
+// MatchRecord is a parsed row of the processed matches CSV
+type MatchRecord struct {
+	Line          int
+	SourceID      string
+	HomeTeam      string
+	AwayTeam      string
+	SeasonYear    int
+	Date          time.Time
+	HomeScore     int
+	AwayScore     int
+	Attendance    int
+	Venue         string
+	HomeFormation string
+	AwayFormation string
+	Home          SideRecord
+	Away          SideRecord
+}
+
+// SideRecord holds one team's goals and lineup from a match row
+type SideRecord struct {
+	Goals      []GoalRecord
+	HasScorers bool
+	Lineup     []LineupRecord
+}
+
+// GoalRecord is a goal parsed from the colon-delimited minute/scorer columns
+type GoalRecord struct {
+	Minute  int
+	Scorer  string
+	Penalty bool
+	OwnGoal bool
+}
+
+// LineupRecord is a starter or substitute. MinuteOn is set for substitutes
+// who came on; unused substitutes have MinuteOn 0.
+type LineupRecord struct {
+	Name        string
+	ShirtNumber int
+	Starter     bool
+	MinuteOn    int
+}
+
+// RowError describes a CSV row that could not be imported
+type RowError struct {
+	Line     int
+	SourceID string
+	Err      error
+}
+
+func (e RowError) Error() string {
+	if e.SourceID != "" {
+		return fmt.Sprintf("line %d (match %s): %v", e.Line, e.SourceID, e.Err)
+	}
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// MatchImportResult summarises an import run
+type MatchImportResult struct {
+	Matches           int
+	Goals             int
+	Substitutions     int
+	LineupEntries     int
+	UnresolvedPlayers int
+	RowErrors         []RowError
+}
+
+var (
+	// minutePattern matches "64'", "90'+3'" and "87' PEN" / "12' OG"
+	minutePattern = regexp.MustCompile(`^(\d+)'?(?:\s*\+\s*(\d+)'?)?\s*(PEN|OG)?$`)
+	leaguePattern = regexp.MustCompile(`^(\d{4})-\d{4}`)
+	leadingNumber = regexp.MustCompile(`^\d+`)
+)
+
+// ReadMatchFile parses a processed matches CSV. Rows that fail to parse are
+// returned as RowErrors and do not stop the rest of the file being read.
+func ReadMatchFile(path string) ([]MatchRecord, []RowError, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header of %s: %w", filepath.Base(path), err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	for _, required := range []string{"home", "away", "date", "home_score", "away_score"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("%s is missing the %q column", filepath.Base(path), required)
+		}
+	}
+
+	var records []MatchRecord
+	var rowErrors []RowError
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Err: err})
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record, err := parseMatchRow(field)
+		record.Line = line
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, SourceID: field("id"), Err: err})
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, rowErrors, nil
+}
+
+// parseMatchRow converts one CSV row into a MatchRecord
+func parseMatchRow(field func(string) string) (MatchRecord, error) {
+	record := MatchRecord{
+		SourceID:      field("id"),
+		HomeTeam:      field("home"),
+		AwayTeam:      field("away"),
+		Venue:         field("venue"),
+		HomeFormation: field("home_formation"),
+		AwayFormation: field("away_formation"),
+	}
+	if record.HomeTeam == "" || record.AwayTeam == "" {
+		return record, fmt.Errorf("missing team name")
+	}
+
+	var err error
+	if record.SeasonYear, err = parseSeasonYear(field("league"), field("year")); err != nil {
+		return record, err
+	}
+	if record.Date, err = ParseMatchDate(field("date"), field("time (utc)"), record.SeasonYear); err != nil {
+		return record, err
+	}
+	if record.HomeScore, err = strconv.Atoi(field("home_score")); err != nil {
+		return record, fmt.Errorf("invalid home_score %q", field("home_score"))
+	}
+	if record.AwayScore, err = strconv.Atoi(field("away_score")); err != nil {
+		return record, fmt.Errorf("invalid away_score %q", field("away_score"))
+	}
+	if attendance := strings.ReplaceAll(field("attendance"), ",", ""); attendance != "" {
+		if record.Attendance, err = strconv.Atoi(attendance); err != nil {
+			return record, fmt.Errorf("invalid attendance %q", field("attendance"))
+		}
+	}
+
+	for _, side := range []struct {
+		prefix string
+		score  int
+		record *SideRecord
+	}{
+		{"home", record.HomeScore, &record.Home},
+		{"away", record.AwayScore, &record.Away},
+	} {
+		minutes, scorers := field(side.prefix+"_goal_minutes"), field(side.prefix+"_goal_scorers")
+		side.record.HasScorers = minutes != "" || scorers != "" || side.score == 0
+		if minutes != "" || scorers != "" {
+			if side.record.Goals, err = ParseGoals(minutes, scorers); err != nil {
+				return record, fmt.Errorf("%s goals: %w", side.prefix, err)
+			}
+			if len(side.record.Goals) != side.score {
+				return record, fmt.Errorf("%s goals: %d scorers listed for a score of %d",
+					side.prefix, len(side.record.Goals), side.score)
+			}
+		}
+
+		if side.record.Lineup, err = parseLineup(field, side.prefix); err != nil {
+			return record, fmt.Errorf("%s lineup: %w", side.prefix, err)
+		}
+	}
+
+	return record, nil
+}
+
+// parseSeasonYear takes the start year from "2001-2002 Barclays Premier League",
+// falling back to the year column
+func parseSeasonYear(league, year string) (int, error) {
+	if match := leaguePattern.FindStringSubmatch(league); match != nil {
+		return strconv.Atoi(match[1])
+	}
+	y, err := strconv.Atoi(year)
+	if err != nil {
+		return 0, fmt.Errorf("cannot determine season from league %q / year %q", league, year)
+	}
+	return y, nil
+}
+
+// ParseMatchDate parses dates like "Saturday, August 18" which omit the year.
+// August onwards belongs to the season's start year and January to July to
+// the next (July matches only occur in the extended 2019/20 season).
+func ParseMatchDate(date, clock string, seasonYear int) (time.Time, error) {
+	if _, rest, found := strings.Cut(date, ", "); found {
+		date = rest
+	}
+	dayMonth, err := time.Parse("January 2", strings.TrimSpace(date))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", date)
+	}
+
+	year := seasonYear
+	if dayMonth.Month() < time.August {
+		year++
+	}
+
+	hour, minute := 0, 0
+	if clock != "" {
+		t, err := time.Parse("15:04", clock)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid kick-off time %q", clock)
+		}
+		hour, minute = t.Hour(), t.Minute()
+	}
+
+	return time.Date(year, dayMonth.Month(), dayMonth.Day(), hour, minute, 0, 0, time.UTC), nil
+}
+
+// ParseGoals pairs the colon-delimited minute and scorer columns, e.g.
+// "64' PEN:77'" with "Duncan Ferguson:David Weir". Stoppage time such as
+// "90'+3'" is stored as minute 93.
+func ParseGoals(minutes, scorers string) ([]GoalRecord, error) {
+	minuteParts := splitColonList(minutes)
+	scorerParts := splitColonList(scorers)
+	if len(minuteParts) != len(scorerParts) {
+		return nil, fmt.Errorf("%d goal minutes but %d scorers", len(minuteParts), len(scorerParts))
+	}
+
+	goals := make([]GoalRecord, 0, len(minuteParts))
+	for i, part := range minuteParts {
+		match := minutePattern.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("invalid goal minute %q", part)
+		}
+		minute, _ := strconv.Atoi(match[1])
+		if match[2] != "" {
+			added, _ := strconv.Atoi(match[2])
+			minute += added
+		}
+
+		goals = append(goals, GoalRecord{
+			Minute:  minute,
+			Scorer:  scorerParts[i],
+			Penalty: match[3] == "PEN",
+			OwnGoal: match[3] == "OG",
+		})
+	}
+	return goals, nil
+}
+
+// splitColonList splits a colon-delimited column, trimming each entry
+func splitColonList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	parts := strings.Split(value, ":")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// maxLineupSlots bounds the numbered starting/bench columns scanned per side;
+// exports number them sparsely and inconsistently
+const maxLineupSlots = 20
+
+// parseLineup reads the <side>_starting_N and <side>_bench_N columns
+func parseLineup(field func(string) string, prefix string) ([]LineupRecord, error) {
+	var lineup []LineupRecord
+
+	for _, kind := range []string{"starting", "bench"} {
+		for n := 1; n <= maxLineupSlots; n++ {
+			column := fmt.Sprintf("%s_%s_%d", prefix, kind, n)
+			name := field(column)
+			if name == "" {
+				continue
+			}
+
+			entry := LineupRecord{Name: name, Starter: kind == "starting"}
+			if number := field(column + "_num"); number != "" {
+				shirt, err := parseLooseInt(number)
+				if err != nil {
+					return nil, fmt.Errorf("invalid shirt number %q for %s", number, name)
+				}
+				entry.ShirtNumber = shirt
+			}
+			if kind == "bench" {
+				if minute := field(column + "_minute"); minute != "" {
+					on, err := parseLooseInt(minute)
+					if err != nil {
+						return nil, fmt.Errorf("invalid substitution minute %q for %s", minute, name)
+					}
+					entry.MinuteOn = on
+				}
+			}
+			lineup = append(lineup, entry)
+		}
+	}
+
+	return lineup, nil
+}
+
+// parseLooseInt reads the leading integer of values like "7.0" or "67'"
+func parseLooseInt(value string) (int, error) {
+	digits := leadingNumber.FindString(strings.TrimSpace(value))
+	if digits == "" {
+		return 0, fmt.Errorf("no number in %q", value)
+	}
+	return strconv.Atoi(digits)
+}
+
+// MatchImporter writes parsed match rows into matches, goals, match_events
+// and match_lineups
+type MatchImporter struct {
+	db      *database.DB
+	teams   *TeamResolver
+	players *PlayerResolver
+}
+
+// NewMatchImporter creates a new match importer. Team names are resolved
+// with the aliases registered for the processed match files.
+func NewMatchImporter(db *database.DB, teams *TeamResolver, players *PlayerResolver) *MatchImporter {
+	return &MatchImporter{db: db, teams: teams.ForSource(SourceMatches), players: players}
+}
+
+// scorerTeamID returns the team whose squad a goal's scorer is looked up in.
+// Goals are listed under the side they count for, so an own goal's scorer
+// plays for the opponent.
+func scorerTeamID(goal GoalRecord, teamID, opponentID int) int {
+	if goal.OwnGoal {
+		return opponentID
+	}
+	return teamID
+}
+
+// Import writes each record in its own transaction, then refreshes the team
+// season aggregates of the seasons it wrote to. Records that cannot be
+// resolved or written are reported as RowErrors; database connection
+// failures abort the run, leaving the aggregates for rebuild-aggregates.
+func (m *MatchImporter) Import(records []MatchRecord) (*MatchImportResult, error) {
+	result := &MatchImportResult{}
+	seasonIDs := make(map[int]int)
+
+	for _, record := range records {
+		rowErr := func(err error) {
+			result.RowErrors = append(result.RowErrors, RowError{Line: record.Line, SourceID: record.SourceID, Err: err})
+		}
+
+		homeID, ok := m.teams.ResolveAt(record.HomeTeam, record.Date)
+		if !ok {
+			rowErr(fmt.Errorf("unknown team %q", record.HomeTeam))
+			continue
+		}
+		awayID, ok := m.teams.ResolveAt(record.AwayTeam, record.Date)
+		if !ok {
+			rowErr(fmt.Errorf("unknown team %q", record.AwayTeam))
+			continue
+		}
+
+		seasonID, ok := seasonIDs[record.SeasonYear]
+		if !ok {
+			id, err := SeasonIDForYear(m.db, record.SeasonYear)
+			if err != nil {
+				return result, err
+			}
+			seasonIDs[record.SeasonYear] = id
+			seasonID = id
+		}
+		if seasonID == 0 {
+			rowErr(fmt.Errorf("no season for %d", record.SeasonYear))
+			continue
+		}
+
+		if err := m.importRecord(record, seasonID, homeID, awayID, result); err != nil {
+			rowErr(err)
+			continue
+		}
+		result.Matches++
+	}
+
+	return result, m.refreshAggregates(seasonIDs)
+}
+
+// refreshAggregates recomputes the team season totals of each season a run
+// wrote to. Records are written with the matches trigger's refresh deferred,
+// so a season is totalled once rather than after every match.
+func (m *MatchImporter) refreshAggregates(seasonIDs map[int]int) error {
+	for _, seasonID := range seasonIDs {
+		if seasonID == 0 {
+			continue
+		}
+		if _, err := m.db.Exec("SELECT refresh_season_aggregates($1)", seasonID); err != nil {
+			return fmt.Errorf("failed to refresh aggregates of season %d: %w", seasonID, err)
+		}
+	}
+	return nil
+}
+
+// importRecord upserts the match and replaces its goals, substitutions and
+// lineups so that re-running an import is idempotent
+func (m *MatchImporter) importRecord(record MatchRecord, seasonID, homeID, awayID int, result *MatchImportResult) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("SET LOCAL premstats.defer_aggregates = 'on'"); err != nil {
+		return fmt.Errorf("failed to defer aggregate refresh: %w", err)
+	}
+
+	var attendance sql.NullInt32
+	if record.Attendance > 0 {
+		attendance = sql.NullInt32{Int32: int32(record.Attendance), Valid: true}
+	}
+
+	// A pairing is played once per season, so it identifies the match
+	var matchID int
+	err = tx.QueryRow(`
+		SELECT id FROM matches
+		WHERE season_id = $1 AND home_team_id = $2 AND away_team_id = $3
+	`, seasonID, homeID, awayID).Scan(&matchID)
+
+	switch {
+	case err == sql.ErrNoRows:
+		err = tx.QueryRow(`
+			INSERT INTO matches (season_id, home_team_id, away_team_id, match_date, home_score, away_score,
+			                     status, attendance, venue, home_formation, away_formation)
+			VALUES ($1, $2, $3, $4, $5, $6, 'completed', $7, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''))
+			RETURNING id
+		`, seasonID, homeID, awayID, record.Date, record.HomeScore, record.AwayScore,
+			attendance, record.Venue, record.HomeFormation, record.AwayFormation).Scan(&matchID)
+		if err != nil {
+			return fmt.Errorf("failed to insert match: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to look up match: %w", err)
+	default:
+		_, err = tx.Exec(`
+			UPDATE matches SET
+				match_date = $2, home_score = $3, away_score = $4,
+				attendance = COALESCE($5, attendance),
+				venue = COALESCE(NULLIF($6, ''), venue),
+				home_formation = COALESCE(NULLIF($7, ''), home_formation),
+				away_formation = COALESCE(NULLIF($8, ''), away_formation)
+			WHERE id = $1
+		`, matchID, record.Date, record.HomeScore, record.AwayScore,
+			attendance, record.Venue, record.HomeFormation, record.AwayFormation)
+		if err != nil {
+			return fmt.Errorf("failed to update match: %w", err)
+		}
+	}
+
+	// Only replace goals when the row carries scorer data for both sides,
+	// so rows without it never wipe goals imported from elsewhere
+	if record.Home.HasScorers && record.Away.HasScorers {
+		if _, err := tx.Exec("DELETE FROM goals WHERE match_id = $1", matchID); err != nil {
+			return fmt.Errorf("failed to clear goals: %w", err)
+		}
+		for _, side := range []struct {
+			teamID     int
+			opponentID int
+			goals      []GoalRecord
+		}{{homeID, awayID, record.Home.Goals}, {awayID, homeID, record.Away.Goals}} {
+			for _, goal := range side.goals {
+				playerID, ok, err := m.players.Resolve(goal.Scorer, scorerTeamID(goal, side.teamID, side.opponentID), seasonID)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					result.UnresolvedPlayers++
+				}
+				_, err = tx.Exec(`
+					INSERT INTO goals (match_id, player_id, team_id, minute, is_penalty, is_own_goal, source_player_name)
+					VALUES ($1, $2, $3, $4, $5, $6, $7)
+				`, matchID, nullableID(playerID, ok), side.teamID, goal.Minute, goal.Penalty, goal.OwnGoal, goal.Scorer)
+				if err != nil {
+					return fmt.Errorf("failed to insert goal: %w", err)
+				}
+				result.Goals++
+			}
+		}
+	}
+
+	if len(record.Home.Lineup) > 0 || len(record.Away.Lineup) > 0 {
+		if _, err := tx.Exec("DELETE FROM match_lineups WHERE match_id = $1", matchID); err != nil {
+			return fmt.Errorf("failed to clear lineups: %w", err)
+		}
+		if _, err := tx.Exec("DELETE FROM match_events WHERE match_id = $1 AND event_type = 'substitution'", matchID); err != nil {
+			return fmt.Errorf("failed to clear substitutions: %w", err)
+		}
+
+		for _, side := range []struct {
+			teamID int
+			lineup []LineupRecord
+		}{{homeID, record.Home.Lineup}, {awayID, record.Away.Lineup}} {
+			for _, entry := range side.lineup {
+				playerID, ok, err := m.players.Resolve(entry.Name, side.teamID, seasonID)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					result.UnresolvedPlayers++
+				}
+
+				var shirt, minuteOn sql.NullInt32
+				if entry.ShirtNumber > 0 {
+					shirt = sql.NullInt32{Int32: int32(entry.ShirtNumber), Valid: true}
+				}
+				if entry.MinuteOn > 0 {
+					minuteOn = sql.NullInt32{Int32: int32(entry.MinuteOn), Valid: true}
+				}
+
+				_, err = tx.Exec(`
+					INSERT INTO match_lineups (match_id, team_id, player_id, source_player_name, shirt_number, is_starter, minute_on)
+					VALUES ($1, $2, $3, $4, $5, $6, $7)
+				`, matchID, side.teamID, nullableID(playerID, ok), entry.Name, shirt, entry.Starter, minuteOn)
+				if err != nil {
+					return fmt.Errorf("failed to insert lineup entry: %w", err)
+				}
+				result.LineupEntries++
+
+				if entry.MinuteOn > 0 {
+					_, err = tx.Exec(`
+						INSERT INTO match_events (match_id, event_type, minute, player_id, team_id, detail, source_player_name)
+						VALUES ($1, 'substitution', $2, $3, $4, 'Substitute on', $5)
+					`, matchID, entry.MinuteOn, nullableID(playerID, ok), side.teamID, entry.Name)
+					if err != nil {
+						return fmt.Errorf("failed to insert substitution: %w", err)
+					}
+					result.Substitutions++
+				}
+			}
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReadMatchFile(t *testing.T) {
+	records, rowErrors, err := ReadMatchFile(filepath.Join("testdata", "matches.csv"))
+	if err != nil {
+		t.Fatalf("ReadMatchFile: %v", err)
+	}
+	// Leeds list one scorer for two goals and Ipswich's date is garbled
+	if len(rowErrors) != 2 || rowErrors[0].SourceID != "18125" || rowErrors[1].Line != 5 {
+		t.Errorf("row errors = %v, want matches 18125 and 18126", rowErrors)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records = %+v, want 2", records)
+	}
+
+	charlton := records[0]
+	if charlton.SourceID != "18123" || charlton.SeasonYear != 2001 || charlton.Attendance != 20451 ||
+		!charlton.Date.Equal(time.Date(2001, time.August, 18, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("match = %+v", charlton)
+	}
+	away := charlton.Away.Goals
+	if len(away) != 2 || !away[0].Penalty || away[0].Minute != 64 || away[1].Scorer != "David Weir" || away[1].Minute != 93 {
+		t.Errorf("away goals = %+v, want a Ferguson penalty and a stoppage-time Weir goal", away)
+	}
+
+	lineup := charlton.Home.Lineup
+	if len(lineup) != 4 {
+		t.Fatalf("home lineup = %+v, want two starters and two substitutes", lineup)
+	}
+	if !lineup[0].Starter || lineup[0].ShirtNumber != 1 || lineup[2].Starter || lineup[2].MinuteOn != 67 ||
+		lineup[3].MinuteOn != 0 {
+		t.Errorf("home lineup = %+v", lineup)
+	}
+
+	derby := records[1]
+	if derby.Date.Year() != 2002 || !derby.Home.HasScorers || len(derby.Home.Goals) != 0 {
+		t.Errorf("goalless New Year's Day match = %+v", derby)
+	}
+}
+
+func TestParseGoals(t *testing.T) {
+	goals, err := ParseGoals("12' OG:45'+1'", "Titus Bramble:Alan Shearer")
+	if err != nil || len(goals) != 2 || !goals[0].OwnGoal || goals[1].Minute != 46 {
+		t.Errorf("goals = %+v, %v", goals, err)
+	}
+	// Bramble's own goal counts for this side, but he played for the other
+	const side, opponent = 1, 2
+	if got := scorerTeamID(goals[0], side, opponent); got != opponent {
+		t.Errorf("own goal scorer looked up in team %d, want %d", got, opponent)
+	}
+	if got := scorerTeamID(goals[1], side, opponent); got != side {
+		t.Errorf("scorer looked up in team %d, want %d", got, side)
+	}
+
+	for _, tt := range []struct{ minutes, scorers string }{
+		{"12'", "Alan Shearer:Rob Lee"},
+		{"twelve", "Alan Shearer"},
+	} {
+		if _, err := ParseGoals(tt.minutes, tt.scorers); err == nil {
+			t.Errorf("ParseGoals(%q, %q) succeeded, want an error", tt.minutes, tt.scorers)
+		}
+	}
+}
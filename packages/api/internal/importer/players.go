@@ -0,0 +1,122 @@
+package importer
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/identity"
+)
+
+// PlayerResolver maps player names in match data onto players.id, preferring
+// members of the team's squad for that season
+type PlayerResolver struct {
+	db     *database.DB
+	squads map[[2]int]map[string]int
+	global map[string]int
+}
+
+// NewPlayerResolver creates a new player resolver
+func NewPlayerResolver(db *database.DB) *PlayerResolver {
+	return &PlayerResolver{
+		db:     db,
+		squads: make(map[[2]int]map[string]int),
+		global: make(map[string]int),
+	}
+}
+
+// Resolve returns the player ID for name within a team's season squad,
+// where nicknames also match, falling back to a unique exact name match
+// across all players
+func (r *PlayerResolver) Resolve(name string, teamID, seasonID int) (int, bool, error) {
+	key := identity.FoldName(name)
+	if key == "" {
+		return 0, false, nil
+	}
+
+	squad, err := r.squad(teamID, seasonID)
+	if err != nil {
+		return 0, false, err
+	}
+	if id, ok := squad[key]; ok {
+		return id, true, nil
+	}
+	// Within a squad a nickname is unambiguous enough to match
+	if id, ok := squad[identity.CanonicalName(name)]; ok {
+		return id, true, nil
+	}
+
+	if id, ok := r.global[key]; ok {
+		return id, id != 0, nil
+	}
+
+	rows, err := r.db.Query("SELECT id FROM players WHERE LOWER(name) = LOWER($1) LIMIT 2", strings.TrimSpace(name))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to look up player %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return 0, false, fmt.Errorf("failed to scan player id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, false, fmt.Errorf("error iterating player rows: %w", err)
+	}
+
+	// Cache misses and ambiguous names as 0
+	id := 0
+	if len(ids) == 1 {
+		id = ids[0]
+	}
+	r.global[key] = id
+	return id, id != 0, nil
+}
+
+// squad loads and caches the folded names of a team's squad for a season
+func (r *PlayerResolver) squad(teamID, seasonID int) (map[string]int, error) {
+	cacheKey := [2]int{teamID, seasonID}
+	if squad, ok := r.squads[cacheKey]; ok {
+		return squad, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT p.id, p.name
+		FROM squad_memberships sm
+		JOIN players p ON sm.player_id = p.id
+		WHERE sm.team_id = $1 AND sm.season_id = $2
+	`, teamID, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load squad for team %d season %d: %w", teamID, seasonID, err)
+	}
+	defer rows.Close()
+
+	squad := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan squad player: %w", err)
+		}
+		squad[identity.FoldName(name)] = id
+		if canonical := identity.CanonicalName(name); squad[canonical] == 0 {
+			squad[canonical] = id
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating squad rows: %w", err)
+	}
+
+	r.squads[cacheKey] = squad
+	return squad, nil
+}
+
+// nullableID converts a resolved player ID to a nullable column value
+func nullableID(id int, ok bool) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(id), Valid: ok && id > 0}
+}
@@ -0,0 +1,31 @@
+package importer
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/premstats/api/internal/database"
+)
+
+// SeasonStartDate returns the opening day of the season starting in year,
+// used to date rows from sources that only give the season
+func SeasonStartDate(year int) time.Time {
+	return time.Date(year, time.August, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// SeasonIDForYear finds the season starting in year (e.g. 2003 for 2003/04),
+// returning 0 if there is none
+func SeasonIDForYear(db *database.DB, year int) (int, error) {
+	name := fmt.Sprintf("%d/%02d", year, (year+1)%100)
+
+	var id int
+	err := db.QueryRow("SELECT id FROM seasons WHERE year = $1 OR name = $2 ORDER BY id LIMIT 1", year, name).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up season %s: %w", name, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,329 @@
+package importer
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/premstats/api/internal/models"
+)
+
+// SquadListing is one squad membership with the transfer details its squad
+// file gave, the input to DerivePlayerSpells
+type SquadListing struct {
+	PlayerID    int
+	TeamID      int
+	SeasonID    int
+	SeasonStart time.Time
+	SeasonEnd   time.Time
+	JoinedOn    *time.Time
+	SignedFrom  string
+	Joined      string
+}
+
+// JoinedText is the parsed "joined" column of newer squad files, such as
+// "Joined from Hull City; date: Jul 1, 2016; fee: €14.00m" or
+// "On loan from Chelsea FC until May 31, 2025"
+type JoinedText struct {
+	Club      string
+	Date      *time.Time
+	Fee       string
+	Loan      bool
+	LoanUntil *time.Time
+}
+
+// joinedPrefixes are the ways the joined column introduces the previous club
+var joinedPrefixes = []string{"Joined from ", "Returned after loan spell with ", "Internal transfer: ", "On loan from "}
+
+// ParseJoined parses the joined column. Unrecognised text yields ok false.
+func ParseJoined(value string) (JoinedText, bool) {
+	var joined JoinedText
+	parts := strings.Split(value, ";")
+
+	head := strings.TrimSpace(parts[0])
+	found := false
+	for _, prefix := range joinedPrefixes {
+		if rest, ok := strings.CutPrefix(head, prefix); ok {
+			joined.Club, found = rest, true
+			joined.Loan = prefix == "On loan from "
+			break
+		}
+	}
+	if !found {
+		return joined, false
+	}
+	if club, until, ok := strings.Cut(joined.Club, " until "); ok && joined.Loan {
+		joined.Club = club
+		joined.LoanUntil, _ = ParseSourceDate(until)
+	}
+
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), ":")
+		value = strings.TrimSpace(value)
+		switch key {
+		case "date":
+			joined.Date, _ = ParseSourceDate(value)
+		case "fee":
+			joined.Fee = value
+		}
+	}
+	return joined, true
+}
+
+// ParseSignedFrom splits the signedFrom column into the selling club and the
+// fee. Older files give only the fee, as ": Ablöse €2.00m", and "Unknown" is
+// used when the club was not recorded.
+func ParseSignedFrom(value string) (club, fee string) {
+	value = strings.TrimSpace(value)
+	if rest, ok := strings.CutPrefix(value, ":"); ok {
+		rest = strings.TrimSpace(rest)
+		return "", strings.TrimSpace(strings.TrimPrefix(rest, "Ablöse"))
+	}
+	if value == "Unknown" {
+		return "", ""
+	}
+	return value, ""
+}
+
+// ParseTransferFee converts fee text like "€2.00m", "€750k" or "free
+// transfer" to euros. Unknown fees ("?", "-", "End of loan") yield nil.
+func ParseTransferFee(value string) (*int64, error) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(value, "free transfer") {
+		fee := int64(0)
+		return &fee, nil
+	}
+
+	amount, ok := strings.CutPrefix(value, "€")
+	if !ok {
+		return nil, nil
+	}
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(amount, "bn"):
+		amount, multiplier = strings.TrimSuffix(amount, "bn"), 1e9
+	case strings.HasSuffix(amount, "m"):
+		amount, multiplier = strings.TrimSuffix(amount, "m"), 1e6
+	case strings.HasSuffix(amount, "k"):
+		amount, multiplier = strings.TrimSuffix(amount, "k"), 1e3
+	}
+
+	n, err := strconv.ParseFloat(amount, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transfer fee %q", value)
+	}
+	fee := int64(n*multiplier + 0.5)
+	return &fee, nil
+}
+
+// feeText keeps fee text worth showing, dropping the placeholders for an
+// unknown fee
+func feeText(value string) string {
+	if value == "?" || value == "-" {
+		return ""
+	}
+	return value
+}
+
+// DerivePlayerSpells groups squad listings into spells: unbroken periods a
+// player spent at one club. Squad files were scraped long after most
+// seasons, so a listing's join date and selling club describe the player's
+// latest spell at the club; when that date falls after the listed season it
+// is ignored. A spell ends when the player is listed at another club, and
+// its end date is the next spell's start when that is known.
+func DerivePlayerSpells(listings []SquadListing) []models.PlayerSpell {
+	listings = append([]SquadListing(nil), listings...)
+	sort.SliceStable(listings, func(i, j int) bool {
+		a, b := listings[i], listings[j]
+		if a.PlayerID != b.PlayerID {
+			return a.PlayerID < b.PlayerID
+		}
+		if !a.SeasonStart.Equal(b.SeasonStart) {
+			return a.SeasonStart.Before(b.SeasonStart)
+		}
+		// A January signing follows the club the player left mid-season
+		if (a.JoinedOn == nil) != (b.JoinedOn == nil) {
+			return a.JoinedOn == nil
+		}
+		if a.JoinedOn != nil && !a.JoinedOn.Equal(*b.JoinedOn) {
+			return a.JoinedOn.Before(*b.JoinedOn)
+		}
+		return a.TeamID < b.TeamID
+	})
+
+	var spells []models.PlayerSpell
+	for start := 0; start < len(listings); {
+		end := start
+		for end < len(listings) && listings[end].PlayerID == listings[start].PlayerID {
+			end++
+		}
+		spells = append(spells, derivePlayerSpells(listings[start:end])...)
+		start = end
+	}
+	return spells
+}
+
+// derivePlayerSpells derives one player's spells from their sorted listings
+func derivePlayerSpells(listings []SquadListing) []models.PlayerSpell {
+	var spells []models.PlayerSpell
+	// lastSeen is the index of each spell's latest listing
+	var lastSeen []int
+
+	for n, listing := range listings {
+		joinedOn := listing.JoinedOn
+		signedFrom, fee := ParseSignedFrom(listing.SignedFrom)
+		joined, hasJoined := ParseJoined(listing.Joined)
+		if joinedOn != nil && joinedOn.After(listing.SeasonEnd) {
+			joinedOn, signedFrom, fee, hasJoined = nil, "", "", false
+		}
+		if hasJoined {
+			if signedFrom == "" {
+				signedFrom = joined.Club
+			}
+			if joined.Fee != "" {
+				fee = joined.Fee
+			}
+		}
+
+		if i := openSpell(spells, lastSeen, listing.TeamID, joinedOn); i >= 0 {
+			lastSeen[i] = n
+			spells[i].LastSeasonID = listing.SeasonID
+			if spells[i].From == nil {
+				spells[i].From = joinedOn
+			}
+			continue
+		}
+
+		spell := models.PlayerSpell{
+			PlayerID:      listing.PlayerID,
+			TeamID:        listing.TeamID,
+			From:          joinedOn,
+			FirstSeasonID: listing.SeasonID,
+			LastSeasonID:  listing.SeasonID,
+			SignedFrom:    signedFrom,
+			FeeText:       feeText(fee),
+			Loan:          hasJoined && joined.Loan,
+			Source:        SourceKaggle,
+		}
+		spell.Fee, _ = ParseTransferFee(fee)
+		spells = append(spells, spell)
+		lastSeen = append(lastSeen, n)
+	}
+
+	// Each spell runs until the player's next club signed them
+	for i := range spells {
+		for j := i + 1; j < len(spells); j++ {
+			if spells[j].TeamID == spells[i].TeamID {
+				continue
+			}
+			if next := spells[j].From; next != nil && (spells[i].From == nil || next.After(*spells[i].From)) {
+				spells[i].To = next
+			}
+			break
+		}
+	}
+	return spells
+}
+
+// openSpell returns the index of the spell a listing continues, or -1. The
+// player's latest spell at the club continues unless the player has been
+// listed at another club since, or the listing gives a different join date.
+func openSpell(spells []models.PlayerSpell, lastSeen []int, teamID int, joinedOn *time.Time) int {
+	latest := -1
+	for i := len(spells) - 1; i >= 0 && latest < 0; i-- {
+		if spells[i].TeamID == teamID {
+			latest = i
+		}
+	}
+	if latest < 0 {
+		return -1
+	}
+	if from := spells[latest].From; joinedOn != nil && from != nil && !joinedOn.Equal(*from) {
+		return -1
+	}
+
+	for i := range spells {
+		if spells[i].TeamID != teamID && lastSeen[i] > lastSeen[latest] {
+			return -1
+		}
+	}
+	return latest
+}
+
+// RebuildSpells replaces the kaggle spells of the given players with spells
+// derived from all their squad memberships, returning how many were written
+func (s *SquadImporter) RebuildSpells(playerIDs []int) (int, error) {
+	if len(playerIDs) == 0 {
+		return 0, nil
+	}
+
+	rows, err := s.db.Query(`
+		SELECT sm.player_id, sm.team_id, sm.season_id, s.start_date,
+		       COALESCE(s.end_date, s.start_date + INTERVAL '1 year'),
+		       sm.joined_on, COALESCE(sm.signed_from, ''), COALESCE(sm.joined_text, '')
+		FROM squad_memberships sm
+		JOIN seasons s ON sm.season_id = s.id
+		WHERE sm.source = 'kaggle' AND sm.player_id = ANY($1)
+	`, pq.Array(playerIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to query squad listings: %w", err)
+	}
+	defer rows.Close()
+
+	var listings []SquadListing
+	for rows.Next() {
+		var l SquadListing
+		var joinedOn sql.NullTime
+		if err := rows.Scan(&l.PlayerID, &l.TeamID, &l.SeasonID, &l.SeasonStart, &l.SeasonEnd,
+			&joinedOn, &l.SignedFrom, &l.Joined); err != nil {
+			return 0, fmt.Errorf("failed to scan squad listing: %w", err)
+		}
+		if joinedOn.Valid {
+			l.JoinedOn = &joinedOn.Time
+		}
+		listings = append(listings, l)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating squad listing rows: %w", err)
+	}
+
+	spells := DerivePlayerSpells(listings)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("DELETE FROM player_spells WHERE source = $1 AND player_id = ANY($2)", SourceKaggle, pq.Array(playerIDs))
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear player spells: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO player_spells (player_id, team_id, from_date, to_date, first_season_id, last_season_id,
+		                           signed_from, fee, fee_text, loan, source)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, NULLIF($9, ''), $10, $11)
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare player spell insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, spell := range spells {
+		_, err := stmt.Exec(spell.PlayerID, spell.TeamID, spell.From, spell.To, spell.FirstSeasonID, spell.LastSeasonID,
+			spell.SignedFrom, spell.Fee, spell.FeeText, spell.Loan, spell.Source)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert spell of player %d: %w", spell.PlayerID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit player spells: %w", err)
+	}
+	return len(spells), nil
+}
@@ -0,0 +1,109 @@
+package importer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTransferFee(t *testing.T) {
+	tests := map[string]int64{
+		"€2.00m":        2000000,
+		"€750k":         750000,
+		"€14.50m":       14500000,
+		"free transfer": 0,
+	}
+	for text, want := range tests {
+		fee, err := ParseTransferFee(text)
+		if err != nil || fee == nil || *fee != want {
+			t.Errorf("ParseTransferFee(%q) = %v, %v, want %d", text, fee, err, want)
+		}
+	}
+
+	for _, text := range []string{"?", "-", "", "End of loan"} {
+		if fee, err := ParseTransferFee(text); err != nil || fee != nil {
+			t.Errorf("ParseTransferFee(%q) = %v, %v, want an unknown fee", text, fee, err)
+		}
+	}
+	if _, err := ParseTransferFee("€lotsm"); err == nil {
+		t.Error("ParseTransferFee(€lotsm) succeeded, want an error")
+	}
+}
+
+func TestParseSignedFrom(t *testing.T) {
+	tests := []struct{ value, club, fee string }{
+		{"Blackburn Rovers", "Blackburn Rovers", ""},
+		{": Ablöse €2.00m", "", "€2.00m"},
+		{": Ablöse free transfer", "", "free transfer"},
+		{"Unknown", "", ""},
+	}
+	for _, tt := range tests {
+		if club, fee := ParseSignedFrom(tt.value); club != tt.club || fee != tt.fee {
+			t.Errorf("ParseSignedFrom(%q) = %q, %q, want %q, %q", tt.value, club, fee, tt.club, tt.fee)
+		}
+	}
+}
+
+func TestParseJoined(t *testing.T) {
+	joined, ok := ParseJoined("Joined from Hull City; date: Jul 1, 2016; fee: €14.00m")
+	if !ok || joined.Club != "Hull City" || joined.Fee != "€14.00m" || joined.Loan ||
+		joined.Date == nil || joined.Date.Year() != 2016 {
+		t.Errorf("transfer = %+v, %v", joined, ok)
+	}
+
+	joined, ok = ParseJoined("On loan from Manchester City until Jun 30, 2025")
+	if !ok || joined.Club != "Manchester City" || !joined.Loan || joined.LoanUntil == nil {
+		t.Errorf("loan = %+v, %v", joined, ok)
+	}
+
+	if _, ok := ParseJoined("Team captain"); ok {
+		t.Error("ParseJoined accepted text that is not a transfer")
+	}
+}
+
+func TestDerivePlayerSpells(t *testing.T) {
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	listing := func(team, year int, joinedOn *time.Time, signedFrom string) SquadListing {
+		return SquadListing{
+			PlayerID:    1,
+			TeamID:      team,
+			SeasonID:    year - 1991,
+			SeasonStart: *date(year, time.August, 1),
+			SeasonEnd:   *date(year+1, time.May, 31),
+			JoinedOn:    joinedOn,
+			SignedFrom:  signedFrom,
+		}
+	}
+	const villa, liverpool = 10, 20
+	// Villa's files were scraped after the player returned in 2000, so the
+	// first spell's listings carry the second spell's join date
+	listings := []SquadListing{
+		listing(villa, 2001, date(2000, time.December, 6), ": Ablöse free transfer"),
+		listing(villa, 1992, date(2000, time.December, 6), ": Ablöse free transfer"),
+		listing(villa, 1993, date(2000, time.December, 6), ": Ablöse free transfer"),
+		listing(liverpool, 1998, date(1998, time.July, 1), "Aston Villa"),
+		listing(liverpool, 2000, date(1998, time.July, 1), "Aston Villa"),
+		listing(villa, 2000, date(2000, time.December, 6), ": Ablöse free transfer"),
+	}
+
+	spells := DerivePlayerSpells(listings)
+	if len(spells) != 3 {
+		t.Fatalf("spells = %+v, want Villa, Liverpool, Villa", spells)
+	}
+
+	first, second, third := spells[0], spells[1], spells[2]
+	if first.TeamID != villa || first.From != nil || first.SignedFrom != "" || first.Fee != nil ||
+		first.FirstSeasonID != 1 || first.LastSeasonID != 2 || !first.To.Equal(*date(1998, time.July, 1)) {
+		t.Errorf("first spell = %+v, want 1992-94 at Villa ending with the move to Liverpool", first)
+	}
+	if second.TeamID != liverpool || second.SignedFrom != "Aston Villa" || second.LastSeasonID != 9 ||
+		!second.To.Equal(*date(2000, time.December, 6)) {
+		t.Errorf("second spell = %+v", second)
+	}
+	if third.TeamID != villa || !third.From.Equal(*date(2000, time.December, 6)) || third.To != nil ||
+		third.Fee == nil || *third.Fee != 0 || third.FeeText != "free transfer" || third.LastSeasonID != 10 {
+		t.Errorf("third spell = %+v, want a current free transfer back to Villa", third)
+	}
+}
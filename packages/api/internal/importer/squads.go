@@ -0,0 +1,486 @@
+package importer
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/premstats/api/internal/database"
+)
+
+// SquadFile identifies one kaggle squad file: <Team>_<clubId>_<year>.csv
+type SquadFile struct {
+	Path     string
+	TeamName string
+	ClubID   int
+	Year     int
+}
+
+// SquadRecord is a normalised row from a squad file
+type SquadRecord struct {
+	TransfermarktID int
+	Name            string
+	Position        string
+	Foot            string
+	HeightCm        int
+	Nationalities   []string
+	DateOfBirth     *time.Time
+	JoinedOn        *time.Time
+	SignedFrom      string
+	Joined          string
+}
+
+// SquadImportResult summarises an import run
+type SquadImportResult struct {
+	Files           int
+	Players         int
+	Memberships     int
+	Spells          int
+	UnresolvedTeams []string
+	MissingSeasons  []int
+	RowErrors       []string
+}
+
+var squadFilePattern = regexp.MustCompile(`^(.+)_(\d+)_(\d{4})\.csv$`)
+
+// sourceDateLayout is the date format used throughout the kaggle files
+const sourceDateLayout = "Jan 2, 2006"
+
+// nationalityNames maps source spellings onto the names used elsewhere
+var nationalityNames = map[string]string{
+	"Korea, South":             "South Korea",
+	"Türkiye":                  "Turkey",
+	"Neukaledonien":            "New Caledonia",
+	"St. Vincent & Grenadinen": "St. Vincent and the Grenadines",
+	"St. Kitts & Nevis":        "St. Kitts and Nevis",
+	"Southern Sudan":           "South Sudan",
+	"The Gambia":               "Gambia",
+	"Bosnia-Herzegovina":       "Bosnia and Herzegovina",
+	"Cote d'Ivoire":            "Ivory Coast",
+}
+
+// ParseSquadFileName extracts team, club ID and season year from a file path
+func ParseSquadFileName(path string) (SquadFile, error) {
+	match := squadFilePattern.FindStringSubmatch(filepath.Base(path))
+	if match == nil {
+		return SquadFile{}, fmt.Errorf("unrecognised squad file name %q", filepath.Base(path))
+	}
+
+	clubID, _ := strconv.Atoi(match[2])
+	year, _ := strconv.Atoi(match[3])
+
+	return SquadFile{
+		Path:     path,
+		TeamName: strings.ReplaceAll(match[1], "_", " "),
+		ClubID:   clubID,
+		Year:     year,
+	}, nil
+}
+
+// FindSquadFiles lists squad files under dir (containing Season_YYYY folders),
+// optionally restricted to a single season year
+func FindSquadFiles(dir string, year int) ([]SquadFile, error) {
+	pattern := filepath.Join(dir, "Season_*", "*.csv")
+	if year > 0 {
+		pattern = filepath.Join(dir, fmt.Sprintf("Season_%d", year), "*.csv")
+	}
+
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list squad files: %w", err)
+	}
+	sort.Strings(paths)
+
+	files := make([]SquadFile, 0, len(paths))
+	for _, path := range paths {
+		file, err := ParseSquadFileName(path)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// ReadSquadFile parses every row of a squad file. Rows that cannot be
+// normalised are reported in the returned error slice and skipped.
+func ReadSquadFile(path string) ([]SquadRecord, []error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header of %s: %w", path, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimPrefix(name, "\ufeff")] = i
+	}
+	for _, required := range []string{"id", "name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("%s is missing the %q column", path, required)
+		}
+	}
+
+	var records []SquadRecord
+	var rowErrors []error
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rowErrors = append(rowErrors, fmt.Errorf("%s:%d: %w", filepath.Base(path), line, err))
+			continue
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record, err := parseSquadRow(field)
+		if err != nil {
+			rowErrors = append(rowErrors, fmt.Errorf("%s:%d: %w", filepath.Base(path), line, err))
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return records, rowErrors, nil
+}
+
+// parseSquadRow normalises a single squad row
+func parseSquadRow(field func(string) string) (SquadRecord, error) {
+	var record SquadRecord
+
+	id, err := strconv.Atoi(field("id"))
+	if err != nil {
+		return record, fmt.Errorf("invalid player id %q", field("id"))
+	}
+	record.TransfermarktID = id
+
+	record.Name = field("name")
+	if record.Name == "" {
+		return record, fmt.Errorf("player %d has no name", id)
+	}
+
+	record.Position = field("position")
+	record.Foot = field("foot")
+	record.SignedFrom = field("signedFrom")
+	record.Joined = field("joined")
+	record.Nationalities = ParseNationalities(field("nationality"))
+
+	if record.HeightCm, err = ParseHeight(field("height")); err != nil {
+		return record, err
+	}
+	if record.DateOfBirth, err = ParseSourceDate(field("dateOfBirth")); err != nil {
+		return record, fmt.Errorf("invalid dateOfBirth: %w", err)
+	}
+	if record.JoinedOn, err = ParseSourceDate(field("joinedOn")); err != nil {
+		return record, fmt.Errorf("invalid joinedOn: %w", err)
+	}
+
+	return record, nil
+}
+
+// ParseNationalities parses a Python list literal such as "['England', 'Portugal']"
+func ParseNationalities(value string) []string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimPrefix(value, "[")
+	value = strings.TrimSuffix(value, "]")
+
+	var nationalities []string
+	for _, part := range splitPythonList(value) {
+		name := strings.TrimSpace(part)
+		if name == "" {
+			continue
+		}
+		if mapped, ok := nationalityNames[name]; ok {
+			name = mapped
+		}
+		nationalities = append(nationalities, name)
+	}
+	return nationalities
+}
+
+// splitPythonList splits the inside of a Python list of quoted strings,
+// respecting quotes so that names like "Cote d'Ivoire" and "Korea, South"
+// survive intact
+func splitPythonList(value string) []string {
+	var parts []string
+	var current strings.Builder
+	var quote rune
+	for _, r := range value {
+		switch {
+		case quote == 0 && (r == '\'' || r == '"'):
+			quote = r
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && r == ',':
+			parts = append(parts, current.String())
+			current.Reset()
+		case quote == 0 && r == ' ':
+			// whitespace between items
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	return parts
+}
+
+// ParseHeight converts a height like "1,93m" to centimetres. Empty or "-" yields 0.
+func ParseHeight(value string) (int, error) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "m"))
+	if value == "" || value == "-" {
+		return 0, nil
+	}
+
+	metres, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid height %q", value)
+	}
+	return int(metres*100 + 0.5), nil
+}
+
+// ParseSourceDate parses dates like "Mar 29, 1970". Empty input yields nil.
+func ParseSourceDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "-" {
+		return nil, nil
+	}
+
+	// Some rows append the age, e.g. "Mar 29, 1970 (54)"
+	if i := strings.Index(value, " ("); i > 0 {
+		value = value[:i]
+	}
+
+	t, err := time.Parse(sourceDateLayout, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SquadImporter writes squad files into players and squad_memberships
+type SquadImporter struct {
+	db    *database.DB
+	teams *TeamResolver
+}
+
+// NewSquadImporter creates a new squad importer. Team names are resolved
+// with the aliases registered for the kaggle files.
+func NewSquadImporter(db *database.DB, teams *TeamResolver) *SquadImporter {
+	return &SquadImporter{db: db, teams: teams.ForSource(SourceKaggle)}
+}
+
+// Import loads the given squad files. Files whose team or season cannot be
+// resolved are skipped and reported rather than aborting the run.
+func (s *SquadImporter) Import(files []SquadFile) (*SquadImportResult, error) {
+	result := &SquadImportResult{}
+	seasonIDs := make(map[int]int)
+	players := make(map[int]bool)
+	missingSeasons := make(map[int]bool)
+
+	for _, file := range files {
+		teamID, ok := s.teams.ResolveAt(file.TeamName, SeasonStartDate(file.Year))
+		if !ok {
+			result.UnresolvedTeams = append(result.UnresolvedTeams, fmt.Sprintf("%s (%d)", file.TeamName, file.Year))
+			continue
+		}
+
+		seasonID, ok := seasonIDs[file.Year]
+		if !ok {
+			id, err := SeasonIDForYear(s.db, file.Year)
+			if err != nil {
+				return result, err
+			}
+			seasonIDs[file.Year] = id
+			seasonID = id
+		}
+		if seasonID == 0 {
+			if !missingSeasons[file.Year] {
+				missingSeasons[file.Year] = true
+				result.MissingSeasons = append(result.MissingSeasons, file.Year)
+			}
+			continue
+		}
+
+		records, rowErrors, err := ReadSquadFile(file.Path)
+		if err != nil {
+			return result, err
+		}
+		for _, rowErr := range rowErrors {
+			result.RowErrors = append(result.RowErrors, rowErr.Error())
+		}
+
+		if err := s.importFile(teamID, seasonID, records, players, result); err != nil {
+			return result, fmt.Errorf("failed to import %s: %w", filepath.Base(file.Path), err)
+		}
+		result.Files++
+	}
+
+	if err := s.updateCurrentTeams(); err != nil {
+		return result, err
+	}
+
+	// Spells span seasons, so each imported player's are rebuilt from all
+	// their memberships, including seasons outside this run
+	playerIDs := make([]int, 0, len(players))
+	for id := range players {
+		playerIDs = append(playerIDs, id)
+	}
+	spells, err := s.RebuildSpells(playerIDs)
+	if err != nil {
+		return result, err
+	}
+	result.Spells = spells
+
+	return result, nil
+}
+
+// importFile upserts one team's squad inside a single transaction, adding
+// the players it touched to players
+func (s *SquadImporter) importFile(teamID, seasonID int, records []SquadRecord, players map[int]bool, result *SquadImportResult) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, record := range records {
+		playerID, err := upsertPlayer(tx, record)
+		if err != nil {
+			return err
+		}
+		result.Players++
+		players[playerID] = true
+
+		var joinedOn sql.NullTime
+		if record.JoinedOn != nil {
+			joinedOn = sql.NullTime{Time: *record.JoinedOn, Valid: true}
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO squad_memberships (player_id, team_id, season_id, position, source,
+			                               joined_on, signed_from, joined_text)
+			VALUES ($1, $2, $3, NULLIF($4, ''), 'kaggle', $5, NULLIF($6, ''), NULLIF($7, ''))
+			ON CONFLICT (player_id, team_id, season_id)
+			DO UPDATE SET position = EXCLUDED.position, joined_on = EXCLUDED.joined_on,
+			              signed_from = EXCLUDED.signed_from, joined_text = EXCLUDED.joined_text,
+			              updated_at = CURRENT_TIMESTAMP
+		`, playerID, teamID, seasonID, record.Position, joinedOn, record.SignedFrom, record.Joined)
+		if err != nil {
+			return fmt.Errorf("failed to upsert squad membership for %s: %w", record.Name, err)
+		}
+		result.Memberships++
+	}
+
+	return tx.Commit()
+}
+
+// upsertPlayer finds a player by Transfermarkt ID, then by name and date of
+// birth (to adopt rows created by other importers), inserting if neither matches
+func upsertPlayer(tx *sql.Tx, record SquadRecord) (int, error) {
+	var nationality, secondNationality sql.NullString
+	if len(record.Nationalities) > 0 {
+		nationality = sql.NullString{String: record.Nationalities[0], Valid: true}
+	}
+	if len(record.Nationalities) > 1 {
+		secondNationality = sql.NullString{String: record.Nationalities[1], Valid: true}
+	}
+	var height sql.NullInt32
+	if record.HeightCm > 0 {
+		height = sql.NullInt32{Int32: int32(record.HeightCm), Valid: true}
+	}
+	var dateOfBirth sql.NullTime
+	if record.DateOfBirth != nil {
+		dateOfBirth = sql.NullTime{Time: *record.DateOfBirth, Valid: true}
+	}
+
+	var playerID int
+	err := tx.QueryRow("SELECT id FROM players WHERE transfermarkt_id = $1", record.TransfermarktID).Scan(&playerID)
+	if err == sql.ErrNoRows && dateOfBirth.Valid {
+		err = tx.QueryRow(`
+			SELECT id FROM players
+			WHERE transfermarkt_id IS NULL AND LOWER(name) = LOWER($1) AND date_of_birth = $2
+			ORDER BY id LIMIT 1
+		`, record.Name, dateOfBirth).Scan(&playerID)
+	}
+
+	switch {
+	case err == sql.ErrNoRows:
+		err = tx.QueryRow(`
+			INSERT INTO players (name, date_of_birth, nationality, second_nationality, position,
+			                     height_cm, preferred_foot, transfermarkt_id)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, NULLIF($7, ''), $8)
+			RETURNING id
+		`, record.Name, dateOfBirth, nationality, secondNationality, record.Position,
+			height, record.Foot, record.TransfermarktID).Scan(&playerID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert player %s: %w", record.Name, err)
+		}
+	case err != nil:
+		return 0, fmt.Errorf("failed to look up player %s: %w", record.Name, err)
+	default:
+		_, err = tx.Exec(`
+			UPDATE players SET
+				date_of_birth = COALESCE($2, date_of_birth),
+				nationality = COALESCE($3, nationality),
+				second_nationality = COALESCE($4, second_nationality),
+				position = COALESCE(NULLIF($5, ''), position),
+				height_cm = COALESCE($6, height_cm),
+				preferred_foot = COALESCE(NULLIF($7, ''), preferred_foot),
+				transfermarkt_id = $8
+			WHERE id = $1
+		`, playerID, dateOfBirth, nationality, secondNationality, record.Position,
+			height, record.Foot, record.TransfermarktID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update player %s: %w", record.Name, err)
+		}
+	}
+
+	return playerID, nil
+}
+
+// updateCurrentTeams points players.current_team_id at each player's most
+// recent squad
+func (s *SquadImporter) updateCurrentTeams() error {
+	_, err := s.db.Exec(`
+		UPDATE players p
+		SET current_team_id = latest.team_id
+		FROM (
+			SELECT DISTINCT ON (sm.player_id) sm.player_id, sm.team_id
+			FROM squad_memberships sm
+			JOIN seasons s ON sm.season_id = s.id
+			ORDER BY sm.player_id, s.start_date DESC, sm.joined_on DESC NULLS LAST, sm.id DESC
+		) latest
+		WHERE p.id = latest.player_id
+		AND p.current_team_id IS DISTINCT FROM latest.team_id
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to update current teams: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package importer
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadSquadFile(t *testing.T) {
+	files, err := FindSquadFiles(filepath.Join("testdata", "squads"), 2003)
+	if err != nil {
+		t.Fatalf("FindSquadFiles: %v", err)
+	}
+	if len(files) != 1 || files[0].TeamName != "Arsenal FC" || files[0].ClubID != 11 || files[0].Year != 2003 {
+		t.Fatalf("files = %+v, want Arsenal's 2003 squad", files)
+	}
+
+	records, rowErrors, err := ReadSquadFile(files[0].Path)
+	if err != nil {
+		t.Fatalf("ReadSquadFile: %v", err)
+	}
+	// The nameless goalkeeper and the malformed birth date are skipped
+	if len(rowErrors) != 2 {
+		t.Errorf("row errors = %v, want 2", rowErrors)
+	}
+	if len(records) != 3 {
+		t.Fatalf("records = %+v, want 3", records)
+	}
+
+	toure, henry, reyes := records[0], records[1], records[2]
+	if toure.TransfermarktID != 3202 || toure.HeightCm != 178 || toure.SignedFrom != "ASEC Mimosas" ||
+		!slices.Equal(toure.Nationalities, []string{"Ivory Coast", "England"}) {
+		t.Errorf("Touré = %+v", toure)
+	}
+	if henry.DateOfBirth == nil || henry.DateOfBirth.Year() != 1977 || henry.JoinedOn == nil ||
+		henry.JoinedOn.Year() != 1999 || henry.Foot != "both" {
+		t.Errorf("Henry = %+v", henry)
+	}
+	if reyes.HeightCm != 0 || reyes.JoinedOn != nil || reyes.Position != "Right Winger" {
+		t.Errorf("Reyes = %+v", reyes)
+	}
+
+	if _, err := ParseSquadFileName("arsenal.csv"); err == nil {
+		t.Error("ParseSquadFileName accepted a name without club ID and year")
+	}
+}
@@ -0,0 +1,183 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/premstats/api/internal/database"
+)
+
+// TableRecord is one line of a published final league table
+type TableRecord struct {
+	Line           int
+	SeasonYear     int
+	TeamCode       string
+	Position       int
+	Played         int
+	Won            int
+	Drawn          int
+	Lost           int
+	GoalsFor       int
+	GoalsAgainst   int
+	GoalDifference int
+	Points         int
+}
+
+// TablesImportResult summarises an import run
+type TablesImportResult struct {
+	Rows           int
+	UnknownCodes   []string
+	MissingSeasons []int
+}
+
+// ReadTablesFile parses all_tables.csv (Place,Team,GP,W,D,L,GF,GA,GD,P,Year)
+func ReadTablesFile(path string) ([]TableRecord, []RowError, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header of %s: %w", filepath.Base(path), err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	required := []string{"Place", "Team", "GP", "W", "D", "L", "GF", "GA", "GD", "P", "Year"}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("%s is missing the %q column", filepath.Base(path), name)
+		}
+	}
+
+	var records []TableRecord
+	var rowErrors []RowError
+	line := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Err: err})
+			continue
+		}
+
+		values := make(map[string]int, len(required))
+		var parseErr error
+		for _, name := range required {
+			if name == "Team" {
+				continue
+			}
+			raw := strings.TrimSpace(row[columns[name]])
+			if values[name], parseErr = strconv.Atoi(raw); parseErr != nil {
+				parseErr = fmt.Errorf("invalid %s %q", name, raw)
+				break
+			}
+		}
+		if parseErr != nil {
+			rowErrors = append(rowErrors, RowError{Line: line, Err: parseErr})
+			continue
+		}
+
+		records = append(records, TableRecord{
+			Line:           line,
+			SeasonYear:     values["Year"],
+			TeamCode:       strings.TrimSpace(row[columns["Team"]]),
+			Position:       values["Place"],
+			Played:         values["GP"],
+			Won:            values["W"],
+			Drawn:          values["D"],
+			Lost:           values["L"],
+			GoalsFor:       values["GF"],
+			GoalsAgainst:   values["GA"],
+			GoalDifference: values["GD"],
+			Points:         values["P"],
+		})
+	}
+
+	return records, rowErrors, nil
+}
+
+// TablesImporter writes published tables into official_standings
+type TablesImporter struct {
+	db    *database.DB
+	teams *TeamResolver
+}
+
+// NewTablesImporter creates a new tables importer. Team codes are resolved
+// only through the alias registry, where migration 0012 seeds the codes
+// all_tables.csv uses.
+func NewTablesImporter(db *database.DB, teams *TeamResolver) *TablesImporter {
+	return &TablesImporter{db: db, teams: teams.ForSource(SourceTables)}
+}
+
+// Import upserts every record, tagging rows with source. Unknown team codes
+// and seasons are reported and skipped.
+func (t *TablesImporter) Import(records []TableRecord, source string) (*TablesImportResult, error) {
+	result := &TablesImportResult{}
+	seasonIDs := make(map[int]int)
+	unknown := make(map[string]bool)
+
+	tx, err := t.db.Begin()
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	for _, record := range records {
+		teamID, ok := t.teams.ResolveAlias(record.TeamCode, SeasonStartDate(record.SeasonYear))
+		if !ok {
+			if !unknown[record.TeamCode] {
+				unknown[record.TeamCode] = true
+				result.UnknownCodes = append(result.UnknownCodes, record.TeamCode)
+			}
+			continue
+		}
+
+		seasonID, ok := seasonIDs[record.SeasonYear]
+		if !ok {
+			id, err := SeasonIDForYear(t.db, record.SeasonYear)
+			if err != nil {
+				return result, err
+			}
+			seasonIDs[record.SeasonYear] = id
+			seasonID = id
+			if id == 0 {
+				result.MissingSeasons = append(result.MissingSeasons, record.SeasonYear)
+			}
+		}
+		if seasonID == 0 {
+			continue
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO official_standings (season_id, team_id, position, played, won, drawn, lost,
+			                                goals_for, goals_against, goal_difference, points, source)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (season_id, team_id) DO UPDATE SET
+				position = EXCLUDED.position, played = EXCLUDED.played,
+				won = EXCLUDED.won, drawn = EXCLUDED.drawn, lost = EXCLUDED.lost,
+				goals_for = EXCLUDED.goals_for, goals_against = EXCLUDED.goals_against,
+				goal_difference = EXCLUDED.goal_difference, points = EXCLUDED.points,
+				source = EXCLUDED.source
+		`, seasonID, teamID, record.Position, record.Played, record.Won, record.Drawn, record.Lost,
+			record.GoalsFor, record.GoalsAgainst, record.GoalDifference, record.Points, source)
+		if err != nil {
+			return result, fmt.Errorf("failed to upsert %s %d: %w", record.TeamCode, record.SeasonYear, err)
+		}
+		result.Rows++
+	}
+
+	return result, tx.Commit()
+}
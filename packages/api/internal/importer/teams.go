@@ -0,0 +1,314 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/models"
+)
+
+// Data sources recorded on team aliases
+const (
+	SourceTables  = "all_tables"
+	SourceKaggle  = "kaggle"
+	SourceMatches = "matches"
+)
+
+// TeamResolver maps the team names used by external sources onto teams.id.
+// Registered aliases are tried first, then team names, then looser matches.
+type TeamResolver struct {
+	*teamIndex
+	// source limits aliases to those for one data source, or for all
+	source string
+}
+
+// teamIndex holds the names and aliases a resolver and its source-scoped
+// copies share. Names are indexed under a full key, which keeps club
+// suffixes so "AFC Wimbledon" and "Wimbledon FC" stay apart, and a loose key
+// without them. A key two teams share maps to ambiguousTeam.
+type teamIndex struct {
+	byFull       map[string]int
+	byKey        map[string]int
+	keys         []teamKey
+	aliases      map[string][]models.TeamAlias
+	looseAliases map[string][]models.TeamAlias
+}
+
+// ambiguousTeam marks a name key shared by different teams
+const ambiguousTeam = -1
+
+type teamKey struct {
+	key string
+	id  int
+}
+
+var (
+	parenthesisPattern = regexp.MustCompile(`\([^)]*\)`)
+	nonAlnumPattern    = regexp.MustCompile(`[^a-z0-9 ]+`)
+)
+
+// clubSuffixes are dropped from names before comparison
+var clubSuffixes = map[string]bool{"fc": true, "afc": true}
+
+// knownAliases pairs common abbreviations with full club names; lookups are
+// tried in both directions since the database may store either form
+var knownAliases = map[string]string{
+	"queens park rangers":  "qpr",
+	"west bromwich albion": "west brom",
+	"manchester united":    "man united",
+	"manchester city":      "man city",
+	"nottingham forest":    "nottm forest",
+	"wolverhampton":        "wolves",
+	"sheffield wednesday":  "sheffield weds",
+}
+
+// NewTeamResolver loads every team's name and short name, and the alias
+// registry, from the database
+func NewTeamResolver(db *database.DB) (*TeamResolver, error) {
+	rows, err := db.Query("SELECT id, name, COALESCE(short_name, '') FROM teams")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	r := newTeamResolver()
+	for rows.Next() {
+		var id int
+		var name, shortName string
+		if err := rows.Scan(&id, &name, &shortName); err != nil {
+			return nil, fmt.Errorf("failed to scan team row: %w", err)
+		}
+		r.Add(id, name)
+		if shortName != "" {
+			r.Add(id, shortName)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team rows: %w", err)
+	}
+
+	if err := r.loadAliases(db); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// loadAliases adds every row of team_aliases
+func (r *TeamResolver) loadAliases(db *database.DB) error {
+	rows, err := db.Query(`
+		SELECT id, team_id, alias, kind, source, valid_from, valid_to
+		FROM team_aliases
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to query team aliases: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alias models.TeamAlias
+		if err := rows.Scan(&alias.ID, &alias.TeamID, &alias.Alias, &alias.Kind, &alias.Source, &alias.ValidFrom, &alias.ValidTo); err != nil {
+			return fmt.Errorf("failed to scan team alias row: %w", err)
+		}
+		r.AddAlias(alias)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating team alias rows: %w", err)
+	}
+	return nil
+}
+
+// NewStaticTeamResolver creates a resolver over teams that are already loaded
+func NewStaticTeamResolver(teams []models.Team) *TeamResolver {
+	r := newTeamResolver()
+	for _, team := range teams {
+		r.Add(team.ID, team.Name)
+		if team.ShortName != "" {
+			r.Add(team.ID, team.ShortName)
+		}
+	}
+	return r
+}
+
+func newTeamResolver() *TeamResolver {
+	return &TeamResolver{teamIndex: &teamIndex{
+		byFull:       make(map[string]int),
+		byKey:        make(map[string]int),
+		aliases:      make(map[string][]models.TeamAlias),
+		looseAliases: make(map[string][]models.TeamAlias),
+	}}
+}
+
+// ForSource returns a resolver that also uses the aliases registered for one
+// data source. Teams and aliases added to either resolver are shared.
+func (r *TeamResolver) ForSource(source string) *TeamResolver {
+	return &TeamResolver{teamIndex: r.teamIndex, source: source}
+}
+
+// Add registers an additional name for a team
+func (r *TeamResolver) Add(teamID int, name string) {
+	full, key := teamNameKeys(name)
+	if key == "" {
+		return
+	}
+	indexTeamKey(r.byFull, full, teamID)
+	indexTeamKey(r.byKey, key, teamID)
+	for _, k := range r.keys {
+		if k.key == key && k.id == teamID {
+			return
+		}
+	}
+	r.keys = append(r.keys, teamKey{key: key, id: teamID})
+}
+
+// indexTeamKey records a team under a key, marking keys that different teams
+// share as ambiguous
+func indexTeamKey(index map[string]int, key string, teamID int) {
+	if id, exists := index[key]; !exists {
+		index[key] = teamID
+	} else if id != teamID {
+		index[key] = ambiguousTeam
+	}
+}
+
+// lookupTeamKey returns the team a key names, reporting a key found but
+// shared by several teams
+func lookupTeamKey(index map[string]int, key string) (id int, found, ambiguous bool) {
+	id, found = index[key]
+	if id == ambiguousTeam {
+		return 0, true, true
+	}
+	return id, found, false
+}
+
+// AddAlias registers an alias. Unlike names added with Add, aliases only
+// resolve on an exact normalised match, so short codes such as "MAN" never
+// match by prefix.
+func (r *TeamResolver) AddAlias(alias models.TeamAlias) {
+	full, key := teamNameKeys(alias.Alias)
+	if key == "" {
+		return
+	}
+	r.aliases[full] = append(r.aliases[full], alias)
+	r.looseAliases[key] = append(r.looseAliases[key], alias)
+}
+
+// Resolve returns the team ID for a source name at any date
+func (r *TeamResolver) Resolve(name string) (int, bool) {
+	return r.ResolveAt(name, time.Time{})
+}
+
+// ResolveAt returns the team ID for a name as used on a date. Names are
+// compared with their club suffixes first and without them second, aliases
+// in use on the date ahead of team names at each step, so "AFC Wimbledon"
+// never falls to an alias of "Wimbledon FC". A name two teams share resolves
+// to neither. Otherwise a unique whole-word prefix match is accepted, so that
+// "Tottenham Hotspur" resolves to a team stored as "Tottenham".
+func (r *TeamResolver) ResolveAt(name string, date time.Time) (int, bool) {
+	full, key := teamNameKeys(name)
+	if key == "" {
+		return 0, false
+	}
+	if id, ok := r.resolveAliasKey(r.aliases, full, date); ok {
+		return id, true
+	}
+	if id, found, ambiguous := lookupTeamKey(r.byFull, full); found {
+		return id, !ambiguous
+	}
+	if id, ok := r.resolveAliasKey(r.looseAliases, key, date); ok {
+		return id, true
+	}
+	if id, found, ambiguous := lookupTeamKey(r.byKey, key); found {
+		return id, !ambiguous
+	}
+	for long, short := range knownAliases {
+		if id, found, ambiguous := lookupTeamKey(r.byKey, short); found && !ambiguous && (key == long || strings.HasPrefix(key, long+" ")) {
+			return id, true
+		}
+		if id, found, ambiguous := lookupTeamKey(r.byKey, long); found && !ambiguous && key == short {
+			return id, true
+		}
+	}
+
+	matched := 0
+	for _, k := range r.keys {
+		if strings.HasPrefix(key, k.key+" ") || strings.HasPrefix(k.key, key+" ") {
+			if matched != 0 && matched != k.id {
+				return 0, false // ambiguous
+			}
+			matched = k.id
+		}
+	}
+	return matched, matched != 0
+}
+
+// ResolveAlias returns the team a registered alias names on a date, without
+// falling back to team names
+func (r *TeamResolver) ResolveAlias(name string, date time.Time) (int, bool) {
+	full, key := teamNameKeys(name)
+	if id, ok := r.resolveAliasKey(r.aliases, full, date); ok {
+		return id, true
+	}
+	return r.resolveAliasKey(r.looseAliases, key, date)
+}
+
+// resolveAliasKey returns the team an alias key names on a date, preferring
+// aliases for the resolver's source over those for every source. Aliases
+// naming different teams leave the key unresolved.
+func (r *TeamResolver) resolveAliasKey(aliases map[string][]models.TeamAlias, key string, date time.Time) (int, bool) {
+	for _, source := range []string{r.source, ""} {
+		matched := 0
+		for _, alias := range aliases[key] {
+			if alias.Source != source || !aliasValidOn(alias, date) {
+				continue
+			}
+			if matched != 0 && matched != alias.TeamID {
+				return 0, false // ambiguous
+			}
+			matched = alias.TeamID
+		}
+		if matched != 0 {
+			return matched, true
+		}
+		if source == "" {
+			break
+		}
+	}
+	return 0, false
+}
+
+// aliasValidOn reports whether an alias was in use on a date; a zero date
+// matches any alias
+func aliasValidOn(alias models.TeamAlias, date time.Time) bool {
+	if date.IsZero() {
+		return true
+	}
+	if alias.ValidFrom != nil && date.Before(*alias.ValidFrom) {
+		return false
+	}
+	// ValidTo covers the whole day
+	return alias.ValidTo == nil || date.Before(alias.ValidTo.AddDate(0, 0, 1))
+}
+
+// teamNameKeys lowercases a name and strips punctuation and parenthesised
+// notes such as "(- 2004)", returning the result as the full key and, with
+// club suffixes also dropped, as the loose key
+func teamNameKeys(name string) (full, loose string) {
+	name = strings.ToLower(strings.ReplaceAll(name, "_", " "))
+	name = parenthesisPattern.ReplaceAllString(name, " ")
+	name = strings.ReplaceAll(name, "&", " and ")
+	name = nonAlnumPattern.ReplaceAllString(name, " ")
+
+	words := strings.Fields(name)
+	var kept []string
+	for _, word := range words {
+		if !clubSuffixes[word] {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(words, " "), strings.Join(kept, " ")
+}
@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/models"
+)
+
+func TestTeamResolverAliases(t *testing.T) {
+	r := NewStaticTeamResolver([]models.Team{
+		{ID: 1, Name: "Manchester United FC", ShortName: "MUN"},
+		{ID: 2, Name: "Manchester City FC", ShortName: "MCI"},
+		{ID: 3, Name: "Wimbledon FC"},
+		{ID: 4, Name: "AFC Wimbledon"},
+	})
+	until := time.Date(2004, 6, 21, 0, 0, 0, 0, time.UTC)
+	r.AddAlias(models.TeamAlias{TeamID: 1, Alias: "MAN", Kind: models.AliasCode, Source: SourceTables})
+	r.AddAlias(models.TeamAlias{TeamID: 2, Alias: "MNC", Kind: models.AliasCode, Source: SourceTables})
+	r.AddAlias(models.TeamAlias{TeamID: 1, Alias: "Manchester Utd", Kind: models.AliasOther})
+	r.AddAlias(models.TeamAlias{TeamID: 3, Alias: "Wimbledon FC (- 2004)", Kind: models.AliasName, Source: SourceKaggle, ValidTo: &until})
+
+	tables := r.ForSource(SourceTables)
+	kaggle := r.ForSource(SourceKaggle)
+
+	tests := []struct {
+		name     string
+		resolver *TeamResolver
+		team     string
+		date     time.Time
+		want     int
+	}{
+		{"source code", tables, "MAN", time.Time{}, 1},
+		{"code limited to its source", kaggle, "MNC", time.Time{}, 0},
+		{"alias for every source", kaggle, "Manchester Utd", time.Time{}, 1},
+		{"official code", r, "MCI", time.Time{}, 2},
+		{"dated name in use", kaggle, "Wimbledon", time.Date(2003, 8, 16, 0, 0, 0, 0, time.UTC), 3},
+		{"dated name on its last day", kaggle, "Wimbledon", until, 3},
+		{"full name", r, "Manchester City", time.Time{}, 2},
+		{"club suffix kept", r, "AFC Wimbledon", time.Time{}, 4},
+		{"other club suffix kept", kaggle, "Wimbledon FC", time.Date(2005, 8, 1, 0, 0, 0, 0, time.UTC), 3},
+		{"shared name after the alias lapsed", kaggle, "Wimbledon", time.Date(2005, 8, 1, 0, 0, 0, 0, time.UTC), 0},
+	}
+	for _, tt := range tests {
+		got, ok := tt.resolver.ResolveAt(tt.team, tt.date)
+		if got != tt.want || ok != (tt.want != 0) {
+			t.Errorf("%s: ResolveAt(%q) = %d, %v; want %d", tt.name, tt.team, got, ok, tt.want)
+		}
+	}
+
+	// Once the dated alias lapses the name no longer picks the old club
+	if id, ok := kaggle.ResolveAlias("Wimbledon", time.Date(2005, 8, 1, 0, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("ResolveAlias after the name lapsed = %d, want no alias", id)
+	}
+}
+
+func TestTeamResolverForSourceShares(t *testing.T) {
+	r := NewStaticTeamResolver([]models.Team{{ID: 1, Name: "Arsenal"}})
+	tables := r.ForSource(SourceTables)
+	kaggle := r.ForSource(SourceKaggle)
+
+	tables.Add(2, "Chelsea")
+	kaggle.AddAlias(models.TeamAlias{TeamID: 1, Alias: "The Arsenal"})
+	if id, ok := kaggle.Resolve("Chelsea"); !ok || id != 2 {
+		t.Errorf("Resolve(Chelsea) from another source = %d, %v; want 2", id, ok)
+	}
+	if id, ok := r.Resolve("The Arsenal"); !ok || id != 1 {
+		t.Errorf("Resolve(The Arsenal) from the parent = %d, %v; want 1", id, ok)
+	}
+}
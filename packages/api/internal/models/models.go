@@ -13,10 +13,47 @@ type Team struct {
 	Founded   int    `json:"founded,omitempty"`
 }
 
+// Team alias kinds
+const (
+	AliasName  = "name"
+	AliasCode  = "code"
+	AliasOther = "alias"
+)
+
+// TeamAlias is another name a team is known by, optionally limited to one
+// data source and to the dates it was in use
+type TeamAlias struct {
+	ID     int    `json:"id"`
+	TeamID int    `json:"teamId"`
+	Alias  string `json:"alias"`
+	Kind   string `json:"kind"`
+	// Source is empty when the alias applies to every source
+	Source    string     `json:"source,omitempty"`
+	ValidFrom *time.Time `json:"validFrom,omitempty"`
+	ValidTo   *time.Time `json:"validTo,omitempty"`
+}
+
+// Season statuses, relative to the current date and results
+const (
+	SeasonUpcoming   = "upcoming"
+	SeasonInProgress = "in-progress"
+	SeasonComplete   = "complete"
+)
+
 // Season represents a Premier League season
 type Season struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Year      int       `json:"year,omitempty"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	TeamCount int       `json:"teamCount"`
+	// ExpectedMatches is a full home and away programme for TeamCount teams
+	ExpectedMatches int    `json:"expectedMatches"`
+	PlayedMatches   int    `json:"playedMatches"`
+	Sponsor         string `json:"sponsor,omitempty"`
+	PointsForWin    int    `json:"pointsForWin"`
+	Status          string `json:"status"`
 }
 
 // Match represents a Premier League match
@@ -62,13 +99,51 @@ type StandingsEntry struct {
 	GoalsAgainst   int    `json:"goalsAgainst"`
 	GoalDifference int    `json:"goalDifference"`
 	Points         int    `json:"points"`
+	// PointsAdjustment is the net deduction or award already included in Points
+	PointsAdjustment int               `json:"pointsAdjustment,omitempty"`
+	Adjustments      []PointAdjustment `json:"adjustments,omitempty"`
+	// TieBreak names the criterion that separated this team from the one above
+	TieBreak string `json:"tieBreak,omitempty"`
+	// Form lists recent results oldest first, e.g. "WWDLW"
+	Form string `json:"form,omitempty"`
+}
+
+// PointAdjustment represents a points deduction or award outside match results
+type PointAdjustment struct {
+	ID        int    `json:"id"`
+	TeamID    int    `json:"teamId"`
+	SeasonID  int    `json:"seasonId"`
+	Points    int    `json:"points"`
+	Reason    string `json:"reason"`
+	AppliedOn string `json:"date,omitempty"`
 }
 
 // Standings represents the complete league table
 type Standings struct {
-	SeasonID int              `json:"seasonId"`
-	Season   string           `json:"season"`
-	Table    []StandingsEntry `json:"table"`
+	SeasonID      int              `json:"seasonId"`
+	Season        string           `json:"season"`
+	View          string           `json:"view"`
+	AsOf          string           `json:"asOf,omitempty"`
+	Matchweek     int              `json:"matchweek,omitempty"`
+	TieBreakRules []string         `json:"tieBreakRules,omitempty"`
+	Table         []StandingsEntry `json:"table"`
+}
+
+// PositionSeries tracks every team's league position after each matchweek
+type PositionSeries struct {
+	SeasonID   int                  `json:"seasonId"`
+	Season     string               `json:"season"`
+	View       string               `json:"view"`
+	Matchweeks int                  `json:"matchweeks"`
+	Teams      []TeamPositionSeries `json:"teams"`
+}
+
+// TeamPositionSeries holds one team's position and points, indexed by matchweek
+type TeamPositionSeries struct {
+	TeamID    int    `json:"teamId"`
+	Team      string `json:"team"`
+	Positions []int  `json:"positions"`
+	Points    []int  `json:"points"`
 }
 
 // Player represents a Premier League player
@@ -96,6 +171,35 @@ type PlayerStats struct {
 	Assists     int    `json:"assists"`
 	YellowCards int    `json:"yellowCards"`
 	RedCards    int    `json:"redCards"`
+	Penalties   int    `json:"penalties"`
+	OwnGoals    int    `json:"ownGoals"`
+	// Minutes is nil when no lineup was recorded for the player's matches
+	Minutes *int `json:"minutes,omitempty"`
+	// Source is StatsExternal or StatsDerived
+	Source string `json:"source,omitempty"`
+}
+
+// Where a player's season line came from
+const (
+	StatsExternal = "external"
+	StatsDerived  = "derived"
+)
+
+// PlayerStatsDiff is a player season line whose stored numbers disagree with
+// those derived from goals, match events and lineups. Stored is nil when
+// nothing is stored for a derived line, and Derived is nil when a stored line
+// has no match data behind it.
+type PlayerStatsDiff struct {
+	PlayerID   int          `json:"playerId"`
+	PlayerName string       `json:"playerName"`
+	SeasonID   int          `json:"seasonId"`
+	SeasonName string       `json:"seasonName"`
+	TeamID     int          `json:"teamId"`
+	TeamName   string       `json:"teamName"`
+	Stored     *PlayerStats `json:"stored"`
+	Derived    *PlayerStats `json:"derived"`
+	// Fields lists the differing numbers
+	Fields []string `json:"fields"`
 }
 
 // TopScorer represents a top scorer entry
@@ -110,6 +214,147 @@ type TopScorer struct {
 	Appearances int    `json:"appearances"`
 	Nationality string `json:"nationality,omitempty"`
 	Position    string `json:"position,omitempty"`
+	// Joint is set when other scorers share the rank
+	Joint bool `json:"joint,omitempty"`
+}
+
+// Leaderboard ranks players by one statistic over a season, a range of
+// seasons or all time, optionally at one club
+type Leaderboard struct {
+	Metric       string `json:"metric"`
+	SeasonID     int    `json:"seasonId,omitempty"`
+	FromSeasonID int    `json:"fromSeasonId,omitempty"`
+	ToSeasonID   int    `json:"toSeasonId,omitempty"`
+	TeamID       int    `json:"teamId,omitempty"`
+	// MinMinutes is the playing time needed to appear on per-90 leaderboards
+	MinMinutes int                `json:"minMinutes,omitempty"`
+	Entries    []LeaderboardEntry `json:"entries"`
+}
+
+// LeaderboardEntry is one player's place on a leaderboard. Players with the
+// same value share a rank and are marked Joint.
+type LeaderboardEntry struct {
+	Rank       int    `json:"rank"`
+	Joint      bool   `json:"joint,omitempty"`
+	PlayerID   int    `json:"playerId"`
+	PlayerName string `json:"playerName"`
+	// TeamID and TeamName are set when all the player's lines are for one club
+	TeamID      int     `json:"teamId,omitempty"`
+	TeamName    string  `json:"teamName,omitempty"`
+	Value       float64 `json:"value"`
+	Seasons     int     `json:"seasons"`
+	Appearances int     `json:"appearances"`
+	Minutes     int     `json:"minutes,omitempty"`
+}
+
+// PlayerCareer is a player's whole history: every season and club line,
+// oldest first, with totals per club and for the career
+type PlayerCareer struct {
+	Player  Player        `json:"player"`
+	Seasons []PlayerStats `json:"seasons"`
+	Clubs   []CareerClub  `json:"clubs"`
+	Totals  CareerTotals  `json:"totals"`
+	// Milestones come from lineups, goals and match events, so they are
+	// missing for players without match-level data
+	Debut          *CareerMatch `json:"debut,omitempty"`
+	LastAppearance *CareerMatch `json:"lastAppearance,omitempty"`
+	FirstGoal      *CareerMatch `json:"firstGoal,omitempty"`
+	LastGoal       *CareerMatch `json:"lastGoal,omitempty"`
+}
+
+// CareerTotals adds up a player's season lines
+type CareerTotals struct {
+	Seasons      int     `json:"seasons"`
+	Appearances  int     `json:"appearances"`
+	Goals        int     `json:"goals"`
+	Assists      int     `json:"assists"`
+	YellowCards  int     `json:"yellowCards"`
+	RedCards     int     `json:"redCards"`
+	GoalsPerGame float64 `json:"goalsPerGame"`
+}
+
+// CareerClub is a player's record at one club
+type CareerClub struct {
+	TeamID      int    `json:"teamId"`
+	TeamName    string `json:"teamName"`
+	FirstSeason string `json:"firstSeason"`
+	LastSeason  string `json:"lastSeason"`
+	CareerTotals
+}
+
+// CareerMatch is a match that marks a point in a player's career
+type CareerMatch struct {
+	MatchID   int       `json:"matchId"`
+	SeasonID  int       `json:"seasonId"`
+	Date      time.Time `json:"date"`
+	TeamID    int       `json:"teamId"`
+	HomeTeam  string    `json:"homeTeam"`
+	AwayTeam  string    `json:"awayTeam"`
+	HomeScore *int      `json:"homeScore"`
+	AwayScore *int      `json:"awayScore"`
+	// Minute is set for goals
+	Minute int `json:"minute,omitempty"`
+}
+
+// PlayerMatchCandidate is a pair of player records that may be the same
+// person, awaiting review. Player has the lower ID.
+type PlayerMatchCandidate struct {
+	Player     Player   `json:"player"`
+	Duplicate  Player   `json:"duplicate"`
+	Confidence float64  `json:"confidence"`
+	Reasons    []string `json:"reasons"`
+}
+
+// PlayerMergeResult reports how many rows were moved onto the kept player
+type PlayerMergeResult struct {
+	PlayerID         int `json:"playerId"`
+	MergedID         int `json:"mergedId"`
+	Goals            int `json:"goals"`
+	MatchEvents      int `json:"matchEvents"`
+	PlayerStats      int `json:"playerStats"`
+	SquadMemberships int `json:"squadMemberships"`
+	Lineups          int `json:"lineups"`
+	Spells           int `json:"spells"`
+}
+
+// PlayerSpell is a period a player spent at one club. From and To are nil
+// when unknown; a spell without To may be the player's current club.
+type PlayerSpell struct {
+	ID            int        `json:"id"`
+	PlayerID      int        `json:"playerId"`
+	PlayerName    string     `json:"playerName,omitempty"`
+	TeamID        int        `json:"teamId"`
+	TeamName      string     `json:"teamName,omitempty"`
+	From          *time.Time `json:"from,omitempty"`
+	To            *time.Time `json:"to,omitempty"`
+	FirstSeasonID int        `json:"firstSeasonId"`
+	FirstSeason   string     `json:"firstSeason,omitempty"`
+	LastSeasonID  int        `json:"lastSeasonId"`
+	LastSeason    string     `json:"lastSeason,omitempty"`
+	// SignedFrom is the selling club as the source names it
+	SignedFrom string `json:"signedFrom,omitempty"`
+	// Fee is in euros; FeeText keeps the source's wording
+	Fee     *int64 `json:"fee,omitempty"`
+	FeeText string `json:"feeText,omitempty"`
+	Loan    bool   `json:"loan"`
+	Source  string `json:"source"`
+}
+
+// PlayerTransfers is a player's clubs, oldest first
+type PlayerTransfers struct {
+	Player Player        `json:"player"`
+	Spells []PlayerSpell `json:"spells"`
+}
+
+// TeamTransfers lists the players a club signed and lost over a season,
+// counted from the end of the previous season
+type TeamTransfers struct {
+	TeamID     int           `json:"teamId"`
+	TeamName   string        `json:"teamName"`
+	SeasonID   int           `json:"seasonId"`
+	SeasonName string        `json:"seasonName"`
+	In         []PlayerSpell `json:"in"`
+	Out        []PlayerSpell `json:"out"`
 }
 
 // SearchResult represents a search result item
@@ -149,17 +394,75 @@ type TeamStats struct {
 	Points         int     `json:"points"`
 	WinPercentage  float64 `json:"winPercentage"`
 	PPG            float64 `json:"pointsPerGame"`
+	// PointsAdjustment is the net deduction or award already included in Points
+	PointsAdjustment int               `json:"pointsAdjustment,omitempty"`
+	Adjustments      []PointAdjustment `json:"adjustments,omitempty"`
+	Home             VenueRecord       `json:"home"`
+	Away             VenueRecord       `json:"away"`
+	// Cards and shots count only matches where they were recorded
+	YellowCards   int `json:"yellowCards"`
+	RedCards      int `json:"redCards"`
+	Shots         int `json:"shots"`
+	ShotsOnTarget int `json:"shotsOnTarget"`
 }
 
-// SeasonSummary represents a season's summary statistics
+// VenueRecord is a team's record in its home or away matches
+type VenueRecord struct {
+	Played       int `json:"played"`
+	Won          int `json:"won"`
+	Drawn        int `json:"drawn"`
+	Lost         int `json:"lost"`
+	GoalsFor     int `json:"goalsFor"`
+	GoalsAgainst int `json:"goalsAgainst"`
+}
+
+// SeasonSummary represents a season's summary statistics. Outcomes decided
+// by final positions are only filled in once every match has been played;
+// until then Leader names the team on top.
 type SeasonSummary struct {
-	SeasonID         int      `json:"seasonId"`
-	Season           string   `json:"season"`
-	TotalMatches     int      `json:"totalMatches"`
-	TotalGoals       int      `json:"totalGoals"`
-	AvgGoalsPerMatch float64  `json:"avgGoalsPerMatch"`
-	Champion         string   `json:"champion,omitempty"`
-	Relegated        []string `json:"relegated,omitempty"`
+	SeasonID         int                  `json:"seasonId"`
+	Season           string               `json:"season"`
+	TotalMatches     int                  `json:"totalMatches"`
+	TotalGoals       int                  `json:"totalGoals"`
+	AvgGoalsPerMatch float64              `json:"avgGoalsPerMatch"`
+	Completed        bool                 `json:"completed"`
+	Leader           string               `json:"leader,omitempty"`
+	Champion         string               `json:"champion,omitempty"`
+	European         []EuropeanQualifiers `json:"european,omitempty"`
+	Relegated        []string             `json:"relegated,omitempty"`
+	// Promoted lists the teams that were not in the previous season
+	Promoted []string `json:"promoted,omitempty"`
+	// PlayOffs lists places the tie-break rules leave to a play-off
+	PlayOffs []SeasonPlayOff `json:"playOffs,omitempty"`
+	Rules    SeasonRules     `json:"rules"`
+}
+
+// SeasonRules describes a season's league format
+type SeasonRules struct {
+	TeamCount        int `json:"teamCount"`
+	RelegationPlaces int `json:"relegationPlaces"`
+	// PromotionPlaces is how many teams came up from the division below
+	PromotionPlaces int             `json:"promotionPlaces"`
+	EuropeanPlaces  []EuropeanPlace `json:"europeanPlaces"`
+}
+
+// EuropeanPlace is how many league places qualify for a European competition
+type EuropeanPlace struct {
+	Competition string `json:"competition"`
+	Places      int    `json:"places"`
+}
+
+// EuropeanQualifiers lists the teams that finished in a competition's places
+type EuropeanQualifiers struct {
+	Competition string   `json:"competition"`
+	Teams       []string `json:"teams"`
+}
+
+// SeasonPlayOff is a place shared by teams that are level on every tie-break
+// criterion. Place is champion, relegation or a European competition.
+type SeasonPlayOff struct {
+	Place string   `json:"place"`
+	Teams []string `json:"teams"`
 }
 
 // APIResponse represents a standard API response
@@ -167,6 +470,7 @@ type APIResponse struct {
 	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"` // machine-readable error code
 	Message string      `json:"message,omitempty"`
 }
 
@@ -178,10 +482,163 @@ type PaginatedResponse struct {
 	Error   string         `json:"error,omitempty"`
 }
 
-// PaginationMeta contains pagination metadata
+// PaginationMeta contains pagination metadata. Pages are addressed by opaque
+// cursors; next and prev are links to the neighbouring pages.
 type PaginationMeta struct {
-	CurrentPage  int `json:"currentPage"`
-	TotalPages   int `json:"totalPages"`
-	TotalItems   int `json:"totalItems"`
-	ItemsPerPage int `json:"itemsPerPage"`
+	TotalPages   int    `json:"totalPages"`
+	TotalItems   int    `json:"totalItems"`
+	ItemsPerPage int    `json:"itemsPerPage"`
+	NextCursor   string `json:"nextCursor,omitempty"`
+	PrevCursor   string `json:"prevCursor,omitempty"`
+	Next         string `json:"next,omitempty"`
+	Prev         string `json:"prev,omitempty"`
+}
+
+// StandingsDiscrepancy describes one column where computed and official standings disagree
+type StandingsDiscrepancy struct {
+	Field      string `json:"field"`
+	Computed   int    `json:"computed"`
+	Official   int    `json:"official"`
+	Difference int    `json:"difference"`
+}
+
+// ReconciliationEntry compares a team's computed table line with the official one
+type ReconciliationEntry struct {
+	TeamID         int                    `json:"teamId"`
+	Team           string                 `json:"team"`
+	Status         string                 `json:"status"` // match, mismatch, missing_computed, missing_official
+	Computed       *StandingsEntry        `json:"computed,omitempty"`
+	Official       *StandingsEntry        `json:"official,omitempty"`
+	MissingMatches int                    `json:"missingMatches"`
+	Discrepancies  []StandingsDiscrepancy `json:"discrepancies,omitempty"`
+}
+
+// StandingsReconciliation is the reconciliation report for a single season
+type StandingsReconciliation struct {
+	SeasonID       int                   `json:"seasonId"`
+	Season         string                `json:"season"`
+	Source         string                `json:"source,omitempty"`
+	Status         string                `json:"status"` // match, mismatch, no_official_data
+	TeamsChecked   int                   `json:"teamsChecked"`
+	Mismatches     int                   `json:"mismatches"`
+	MissingMatches int                   `json:"missingMatches"`
+	Entries        []ReconciliationEntry `json:"entries"`
+}
+
+// FormMatch is one result in a team's form guide, from that team's point of view
+type FormMatch struct {
+	MatchID      int       `json:"matchId"`
+	SeasonID     int       `json:"seasonId"`
+	Date         time.Time `json:"date"`
+	OpponentID   int       `json:"opponentId"`
+	Opponent     string    `json:"opponent"`
+	Venue        string    `json:"venue"` // H or A
+	GoalsFor     int       `json:"goalsFor"`
+	GoalsAgainst int       `json:"goalsAgainst"`
+	Result       string    `json:"result"` // W, D or L
+}
+
+// TeamForm summarises a team's most recent results
+type TeamForm struct {
+	TeamID       int         `json:"teamId"`
+	Team         string      `json:"team"`
+	SeasonID     int         `json:"seasonId,omitempty"`
+	Last         int         `json:"last"`
+	Form         string      `json:"form"` // oldest first
+	Points       int         `json:"points"`
+	GoalsFor     int         `json:"goalsFor"`
+	GoalsAgainst int         `json:"goalsAgainst"`
+	Matches      []FormMatch `json:"matches"`
+}
+
+// Streak is a run of consecutive matches satisfying a condition
+type Streak struct {
+	Type      string     `json:"type"` // winning, unbeaten, winless, scoring, cleanSheet
+	Length    int        `json:"length"`
+	StartDate *time.Time `json:"startDate,omitempty"`
+	EndDate   *time.Time `json:"endDate,omitempty"`
+	Active    bool       `json:"active"`
+}
+
+// SeasonStreaks holds a team's longest runs within one season
+type SeasonStreaks struct {
+	SeasonID int      `json:"seasonId"`
+	Season   string   `json:"season"`
+	Streaks  []Streak `json:"streaks"`
+}
+
+// TeamStreaks holds a team's longest runs across all seasons and per season
+type TeamStreaks struct {
+	TeamID  int             `json:"teamId"`
+	Team    string          `json:"team"`
+	AllTime []Streak        `json:"allTime"`
+	Seasons []SeasonStreaks `json:"seasons"`
+}
+
+// HeadToHeadRecord is a W/D/L and goals record from the first team's point of view
+type HeadToHeadRecord struct {
+	Played       int `json:"played"`
+	Wins         int `json:"wins"`
+	Draws        int `json:"draws"`
+	Losses       int `json:"losses"`
+	GoalsFor     int `json:"goalsFor"`
+	GoalsAgainst int `json:"goalsAgainst"`
+}
+
+// HeadToHeadSeason is the head-to-head record within one season
+type HeadToHeadSeason struct {
+	SeasonID int    `json:"seasonId"`
+	Season   string `json:"season"`
+	HeadToHeadRecord
+}
+
+// HeadToHead compares two teams across every meeting
+type HeadToHead struct {
+	TeamID     int    `json:"teamId"`
+	Team       string `json:"team"`
+	OpponentID int    `json:"opponentId"`
+	Opponent   string `json:"opponent"`
+	Venue      string `json:"venue"` // all, home or away, relative to the first team
+	HeadToHeadRecord
+	BiggestWin         *Match             `json:"biggestWin,omitempty"`
+	OpponentBiggestWin *Match             `json:"opponentBiggestWin,omitempty"`
+	Seasons            []HeadToHeadSeason `json:"seasons"`
+	Matches            []Match            `json:"matches"`
+}
+
+// QueryIntent is how a natural-language question was interpreted
+type QueryIntent struct {
+	Type         string   `json:"type"`
+	Teams        []string `json:"teams,omitempty"`
+	TeamIDs      []int    `json:"teamIds,omitempty"`
+	SeasonID     int      `json:"seasonId,omitempty"`
+	Season       string   `json:"season,omitempty"`
+	FromSeasonID int      `json:"fromSeasonId,omitempty"`
+	FromSeason   string   `json:"fromSeason,omitempty"`
+	ToSeasonID   int      `json:"toSeasonId,omitempty"`
+	ToSeason     string   `json:"toSeason,omitempty"`
+	Venue        string   `json:"venue,omitempty"`
+	Metric       string   `json:"metric,omitempty"`
+	Order        string   `json:"order,omitempty"`
+	Limit        int      `json:"limit,omitempty"`
+}
+
+// QueryResponse answers a natural-language question
+type QueryResponse struct {
+	Query       string       `json:"query"`
+	Understood  bool         `json:"understood"`
+	Answer      string       `json:"answer"`
+	Intent      *QueryIntent `json:"intent,omitempty"`
+	Data        interface{}  `json:"data,omitempty"`
+	Suggestions []string     `json:"suggestions,omitempty"`
+}
+
+// TeamDiscipline totals a team's cards and fouls over a season
+type TeamDiscipline struct {
+	TeamID      int    `json:"teamId"`
+	Team        string `json:"team"`
+	Matches     int    `json:"matches"`
+	YellowCards int    `json:"yellowCards"`
+	RedCards    int    `json:"redCards"`
+	Fouls       int    `json:"fouls"`
 }
@@ -0,0 +1,212 @@
+// Package pagination implements keyset pagination for list endpoints. A page
+// is addressed by an opaque cursor holding the sort key of the row it starts
+// after (or ends before), so pages stay stable while rows are added.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/premstats/api/internal/apperrors"
+)
+
+// Limits are an endpoint's default and largest page sizes. Larger limits are
+// clamped rather than rejected so clients that load a whole season in one
+// request keep working.
+type Limits struct {
+	Default int
+	Max     int
+}
+
+// Page sizes of the list endpoints, kept at what each returned before it was
+// paginated
+var (
+	// ListLimits suit long listings such as matches
+	ListLimits = Limits{Default: 50, Max: 500}
+	// PlayerLimits apply to the player listing
+	PlayerLimits = Limits{Default: 50, Max: 100}
+	// SearchLimits keep search results short
+	SearchLimits = Limits{Default: 20, Max: 50}
+	// WholeLimits apply to listings that used to be returned whole, such as
+	// teams and match events, so a request without a limit still gets them all
+	WholeLimits = Limits{Default: 500, Max: 500}
+)
+
+// Seek continues a listing from the row with the given sort key. A forward
+// seek returns the rows after the key, a backward one the rows before it.
+type Seek[K any] struct {
+	Key      K
+	Backward bool
+}
+
+// cursor is the encoded form of a Seek
+type cursor[K any] struct {
+	Key      K    `json:"k"`
+	Backward bool `json:"b,omitempty"`
+}
+
+// Request is a page request read from query parameters
+type Request struct {
+	Limit int
+	// Offset skips rows before the first page; it is ignored once a cursor is given
+	Offset int
+	Cursor string
+}
+
+// ParseRequest reads limit, offset and cursor query parameters
+func ParseRequest(values url.Values, limits Limits) (Request, error) {
+	req := Request{Limit: limits.Default, Cursor: values.Get("cursor")}
+
+	if s := values.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			return req, apperrors.InvalidArgument("limit must be a positive integer")
+		}
+		req.Limit = min(limit, limits.Max)
+	}
+
+	if s := values.Get("offset"); s != "" && req.Cursor == "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return req, apperrors.InvalidArgument("offset must be a non-negative integer")
+		}
+		req.Offset = offset
+	}
+
+	return req, nil
+}
+
+// DecodeSeek returns the seek encoded in a request's cursor, or nil when the
+// request starts from the beginning
+func DecodeSeek[K any](req Request) (*Seek[K], error) {
+	if req.Cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(req.Cursor)
+	if err != nil {
+		return nil, apperrors.InvalidArgument("invalid cursor")
+	}
+	var c cursor[K]
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, apperrors.InvalidArgument("invalid cursor")
+	}
+	return &Seek[K]{Key: c.Key, Backward: c.Backward}, nil
+}
+
+// encode returns the opaque cursor for a seek
+func encode[K any](seek Seek[K]) string {
+	raw, err := json.Marshal(cursor[K]{Key: seek.Key, Backward: seek.Backward})
+	if err != nil {
+		// Keys are plain structs of strings, numbers and times
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// Page is one page of a listing
+type Page[T any] struct {
+	Items []T
+	Total int
+	Limit int
+	// Next and Prev are cursors for the neighbouring pages, empty at either end
+	Next string
+	Prev string
+}
+
+// Fetch returns how many rows to load for a page: one more than the limit,
+// so NewPage can tell whether another page follows
+func (r Request) Fetch() int {
+	return r.Limit + 1
+}
+
+// NewPage builds a page from rows loaded in listing order with Fetch as the
+// limit, trimming the extra row and setting cursors from the first and last
+// rows kept
+func NewPage[T, K any](rows []T, total int, req Request, seek *Seek[K], key func(T) K) Page[T] {
+	backward := seek != nil && seek.Backward
+	more := len(rows) > req.Limit
+	if more {
+		// A backward page is loaded towards the start of the listing, so the
+		// extra row is the first one
+		if backward {
+			rows = rows[len(rows)-req.Limit:]
+		} else {
+			rows = rows[:req.Limit]
+		}
+	}
+
+	page := Page[T]{Items: rows, Total: total, Limit: req.Limit}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(rows) == 0 {
+		return page
+	}
+
+	hasNext, hasPrev := more, seek != nil || req.Offset > 0
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+	if hasNext {
+		page.Next = encode(Seek[K]{Key: key(rows[len(rows)-1])})
+	}
+	if hasPrev {
+		page.Prev = encode(Seek[K]{Key: key(rows[0]), Backward: true})
+	}
+	return page
+}
+
+// Apply pages rows already held in memory, in listing order. compare orders
+// two keys the way the listing does.
+func Apply[T, K any](rows []T, seek *Seek[K], offset, limit int, key func(T) K, compare func(a, b K) int) []T {
+	if seek != nil {
+		var kept []T
+		for _, row := range rows {
+			c := compare(key(row), seek.Key)
+			if (seek.Backward && c < 0) || (!seek.Backward && c > 0) {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+		offset = 0
+	}
+
+	if offset >= len(rows) {
+		return nil
+	}
+	rows = rows[offset:]
+
+	if limit > 0 && len(rows) > limit {
+		// Walking backwards the page ends at the seek key
+		if seek != nil && seek.Backward {
+			return rows[len(rows)-limit:]
+		}
+		return rows[:limit]
+	}
+	return rows
+}
+
+// Load decodes a request's cursor and builds a page. list loads up to limit
+// rows from the seek, and count returns the total the page is part of.
+func Load[T, K any](req Request, key func(T) K, list func(seek *Seek[K], limit int) ([]T, error), count func() (int, error)) (*Page[T], error) {
+	seek, err := DecodeSeek[K](req)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := list(seek, req.Fetch())
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := count()
+	if err != nil {
+		return nil, err
+	}
+
+	page := NewPage(rows, total, req, seek, key)
+	return &page, nil
+}
@@ -0,0 +1,125 @@
+package pagination
+
+import (
+	"cmp"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/premstats/api/internal/apperrors"
+)
+
+func identity(n int) int { return n }
+
+// walk loads one page of the numbers 1..n the way a store would
+func walk(t *testing.T, n int, req Request) *Page[int] {
+	t.Helper()
+	var rows []int
+	for i := 1; i <= n; i++ {
+		rows = append(rows, i)
+	}
+	page, err := Load(req, identity,
+		func(seek *Seek[int], limit int) ([]int, error) {
+			return Apply(rows, seek, req.Offset, limit, identity, cmp.Compare[int]), nil
+		},
+		func() (int, error) { return len(rows), nil },
+	)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return page
+}
+
+func TestWalkForwardAndBack(t *testing.T) {
+	first := walk(t, 7, Request{Limit: 3})
+	if !reflect.DeepEqual(first.Items, []int{1, 2, 3}) || first.Prev != "" || first.Next == "" || first.Total != 7 {
+		t.Fatalf("first page = %+v", first)
+	}
+
+	second := walk(t, 7, Request{Limit: 3, Cursor: first.Next})
+	if !reflect.DeepEqual(second.Items, []int{4, 5, 6}) || second.Prev == "" || second.Next == "" {
+		t.Fatalf("second page = %+v", second)
+	}
+
+	last := walk(t, 7, Request{Limit: 3, Cursor: second.Next})
+	if !reflect.DeepEqual(last.Items, []int{7}) || last.Next != "" {
+		t.Fatalf("last page = %+v", last)
+	}
+
+	back := walk(t, 7, Request{Limit: 3, Cursor: last.Prev})
+	if !reflect.DeepEqual(back.Items, []int{4, 5, 6}) || back.Next == "" || back.Prev == "" {
+		t.Fatalf("page before last = %+v", back)
+	}
+
+	start := walk(t, 7, Request{Limit: 3, Cursor: back.Prev})
+	if !reflect.DeepEqual(start.Items, []int{1, 2, 3}) || start.Prev != "" {
+		t.Fatalf("page before that = %+v", start)
+	}
+}
+
+func TestOffsetStartsFirstPage(t *testing.T) {
+	page := walk(t, 7, Request{Limit: 2, Offset: 2})
+	if !reflect.DeepEqual(page.Items, []int{3, 4}) || page.Prev == "" {
+		t.Fatalf("page = %+v", page)
+	}
+
+	before := walk(t, 7, Request{Limit: 2, Cursor: page.Prev})
+	if !reflect.DeepEqual(before.Items, []int{1, 2}) {
+		t.Fatalf("page before = %+v", before)
+	}
+}
+
+func TestEmptyListing(t *testing.T) {
+	page := walk(t, 0, Request{Limit: 3})
+	if page.Items == nil || len(page.Items) != 0 || page.Next != "" || page.Prev != "" {
+		t.Fatalf("page = %+v", page)
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	tests := []struct {
+		query string
+		want  Request
+		err   bool
+	}{
+		{"", Request{Limit: ListLimits.Default}, false},
+		{"limit=20&offset=40", Request{Limit: 20, Offset: 40}, false},
+		{"limit=5000", Request{Limit: ListLimits.Max}, false},
+		{"limit=10&offset=40&cursor=abc", Request{Limit: 10, Cursor: "abc"}, false},
+		{"limit=0", Request{}, true},
+		{"limit=ten", Request{}, true},
+		{"offset=-1", Request{}, true},
+	}
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+		got, err := ParseRequest(values, ListLimits)
+		if tt.err {
+			if apperrors.Code(err) != apperrors.CodeInvalidArgument {
+				t.Errorf("ParseRequest(%q) error = %v, want an invalid argument", tt.query, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRequest(%q) = %+v, %v, want %+v", tt.query, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseRequestLimits(t *testing.T) {
+	values, _ := url.ParseQuery("limit=80")
+	if got, _ := ParseRequest(values, SearchLimits); got.Limit != SearchLimits.Max {
+		t.Errorf("search limit = %d, want %d", got.Limit, SearchLimits.Max)
+	}
+	if got, _ := ParseRequest(url.Values{}, WholeLimits); got.Limit != WholeLimits.Default {
+		t.Errorf("default limit = %d, want %d", got.Limit, WholeLimits.Default)
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24"} {
+		_, err := DecodeSeek[int](Request{Cursor: cursor})
+		if apperrors.Code(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("DecodeSeek(%q) error = %v, want an invalid argument", cursor, err)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+	"github.com/premstats/api/internal/services"
+)
+
+// Suggestions lists example questions returned when a question is not understood
+var Suggestions = []string{
+	"top scorer 2003/04",
+	"top 5 scorers this season",
+	"Arsenal vs Chelsea at home since 2010",
+	"who won the league in 1998/99",
+	"who was relegated in 2009/10",
+	"most red cards by a team in 1998",
+	"fewest goals conceded 2004/05",
+	"how did Leeds do in 2000/01",
+	"league table 1995/96",
+}
+
+// lexiconCacheKey caches the lexicon, which is built from every team and season
+const lexiconCacheKey = "query:lexicon"
+
+// Engine answers natural-language questions using the existing services
+type Engine struct {
+	teams     repository.TeamRepository
+	players   *services.PlayerService
+	standings *services.StandingsService
+	matches   *services.MatchService
+	seasons   *services.SeasonService
+	cache     *cache.Cache
+}
+
+// NewEngine creates a new query engine. The lexicon questions are parsed
+// against is kept in c, which may be nil, until team or season data changes.
+func NewEngine(teams repository.TeamRepository, players *services.PlayerService, standings *services.StandingsService, matches *services.MatchService, seasons *services.SeasonService, c *cache.Cache) *Engine {
+	return &Engine{teams: teams, players: players, standings: standings, matches: matches, seasons: seasons, cache: c}
+}
+
+// Answer parses a question and runs it against the matching service. A
+// question that cannot be parsed is answered with suggestions rather than an error.
+func (e *Engine) Answer(question string) (*models.QueryResponse, error) {
+	response := &models.QueryResponse{Query: question}
+
+	lex, err := e.lexicon()
+	if err != nil {
+		return nil, err
+	}
+
+	intent := Parse(question, lex)
+	if intent == nil || (intent.SeasonID == 0 && intent.Type != IntentHeadToHead) {
+		response.Answer = "Sorry, I could not understand that question. Try asking about scorers, tables, champions, relegation or two teams' head-to-head record."
+		response.Suggestions = Suggestions
+		return response, nil
+	}
+	response.Intent = intent
+	response.Understood = true
+
+	switch intent.Type {
+	case IntentTopScorers:
+		err = e.answerTopScorers(intent, response)
+	case IntentHeadToHead:
+		err = e.answerHeadToHead(intent, response)
+	case IntentStandings:
+		err = e.answerStandings(intent, response)
+	case IntentChampion:
+		err = e.answerChampion(intent, response)
+	case IntentRelegated:
+		err = e.answerRelegated(intent, response)
+	case IntentTeamSeason:
+		err = e.answerTeamSeason(intent, response)
+	case IntentTeamRanking:
+		err = e.answerTeamRanking(intent, response)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// lexicon returns the cached lexicon, building it from the teams, their
+// aliases and the seasons when it is missing. The lexicon is never modified
+// once built, so the cache holds it as is rather than as JSON.
+func (e *Engine) lexicon() (*Lexicon, error) {
+	if value, ok := e.cache.Get(lexiconCacheKey); ok {
+		if lex, ok := value.(*Lexicon); ok {
+			return lex, nil
+		}
+	}
+
+	teams, err := e.teams.ListTeams(repository.TeamFilter{})
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := e.teams.ListTeamAliases(0)
+	if err != nil {
+		return nil, err
+	}
+	seasons, err := e.seasons.GetAllSeasons()
+	if err != nil {
+		return nil, err
+	}
+	var current *models.Season
+	if len(seasons) > 0 {
+		if current, err = e.seasons.GetCurrentSeason(); err != nil {
+			return nil, err
+		}
+	}
+
+	lex := buildLexicon(teams, aliases, seasons, current)
+	e.cache.Set(lexiconCacheKey, cache.AllSeasons, lex)
+	return lex, nil
+}
+
+// answerTopScorers handles "top scorer 2003/04" and "Arsenal top scorer"
+func (e *Engine) answerTopScorers(intent *models.QueryIntent, response *models.QueryResponse) error {
+	limit := intent.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	// Team filtering happens here, so fetch enough rows to cover every squad
+	fetch := limit
+	if len(intent.TeamIDs) > 0 {
+		fetch = 500
+	}
+	scorers, err := e.players.GetTopScorers(intent.SeasonID, fetch)
+	if err != nil {
+		return err
+	}
+
+	if len(intent.TeamIDs) > 0 {
+		var filtered []models.TopScorer
+		for _, scorer := range scorers {
+			if scorer.TeamID == intent.TeamIDs[0] {
+				filtered = append(filtered, scorer)
+			}
+		}
+		scorers = filtered
+	}
+	if len(scorers) > limit {
+		scorers = scorers[:limit]
+	}
+
+	response.Data = map[string]interface{}{"topScorers": scorers}
+	if len(scorers) == 0 {
+		response.Answer = fmt.Sprintf("No goal scorer data is available for %s.", intent.Season)
+		return nil
+	}
+
+	top := scorers[0]
+	response.Answer = fmt.Sprintf("%s (%s) was the top scorer in %s with %d goals.",
+		top.PlayerName, top.TeamName, intent.Season, top.Goals)
+	return nil
+}
+
+// answerHeadToHead handles "Arsenal vs Chelsea at home since 2010"
+func (e *Engine) answerHeadToHead(intent *models.QueryIntent, response *models.QueryResponse) error {
+	h2h, err := e.matches.GetHeadToHead(intent.TeamIDs[0], intent.TeamIDs[1], services.HeadToHeadOptions{
+		FromSeasonID: intent.FromSeasonID,
+		ToSeasonID:   intent.ToSeasonID,
+		Venue:        intent.Venue,
+	})
+	if err != nil {
+		return err
+	}
+	response.Data = h2h
+
+	scope := ""
+	switch intent.Venue {
+	case "home":
+		scope += " at home"
+	case "away":
+		scope += " away"
+	}
+	switch {
+	case intent.FromSeason != "" && intent.FromSeason == intent.ToSeason:
+		scope += " in " + intent.FromSeason
+	case intent.FromSeason != "" && intent.ToSeason != "":
+		scope += fmt.Sprintf(" between %s and %s", intent.FromSeason, intent.ToSeason)
+	case intent.FromSeason != "":
+		scope += " since " + intent.FromSeason
+	case intent.ToSeason != "":
+		scope += " up to " + intent.ToSeason
+	}
+
+	if h2h.Played == 0 {
+		response.Answer = fmt.Sprintf("%s have not played %s%s.", h2h.Team, h2h.Opponent, scope)
+		return nil
+	}
+
+	response.Answer = fmt.Sprintf("%s have played %s %d times%s: %d wins, %d draws and %d defeats, scoring %d and conceding %d.",
+		h2h.Team, h2h.Opponent, h2h.Played, scope, h2h.Wins, h2h.Draws, h2h.Losses, h2h.GoalsFor, h2h.GoalsAgainst)
+	return nil
+}
+
+// answerStandings handles "league table 1995/96"
+func (e *Engine) answerStandings(intent *models.QueryIntent, response *models.QueryResponse) error {
+	standings, err := e.standings.GetStandingsBySeasonID(intent.SeasonID)
+	if err != nil {
+		return err
+	}
+	response.Data = standings
+
+	if len(standings.Table) == 0 {
+		response.Answer = fmt.Sprintf("No results are available for %s.", intent.Season)
+		return nil
+	}
+
+	leader := standings.Table[0]
+	response.Answer = fmt.Sprintf("%s %s the %s table with %d points from %d games.",
+		leader.Team, verb(standings, "topped", "top"), intent.Season, leader.Points, leader.Played)
+	return nil
+}
+
+// answerChampion handles "who won the league in 1998/99"
+func (e *Engine) answerChampion(intent *models.QueryIntent, response *models.QueryResponse) error {
+	standings, err := e.standings.GetStandingsBySeasonID(intent.SeasonID)
+	if err != nil {
+		return err
+	}
+	if len(standings.Table) == 0 {
+		response.Answer = fmt.Sprintf("No results are available for %s.", intent.Season)
+		return nil
+	}
+
+	champion := standings.Table[0]
+	response.Data = champion
+	if seasonComplete(standings) {
+		response.Answer = fmt.Sprintf("%s won the %s title with %d points.", champion.Team, intent.Season, champion.Points)
+	} else {
+		response.Answer = fmt.Sprintf("%s are top of the %s table with %d points after %d games.",
+			champion.Team, intent.Season, champion.Points, champion.Played)
+	}
+	return nil
+}
+
+// answerRelegated handles "who was relegated in 2009/10"
+func (e *Engine) answerRelegated(intent *models.QueryIntent, response *models.QueryResponse) error {
+	standings, err := e.standings.GetStandingsBySeasonID(intent.SeasonID)
+	if err != nil {
+		return err
+	}
+
+	rules, err := e.seasons.GetSeasonRules(intent.SeasonID, len(standings.Table))
+	if err != nil {
+		return err
+	}
+	slots := rules.RelegationPlaces
+	if len(standings.Table) <= slots {
+		response.Answer = fmt.Sprintf("No results are available for %s.", intent.Season)
+		return nil
+	}
+
+	bottom := standings.Table[len(standings.Table)-slots:]
+	response.Data = bottom
+
+	names := make([]string, len(bottom))
+	for i, entry := range bottom {
+		names[i] = entry.Team
+	}
+	if seasonComplete(standings) {
+		response.Answer = fmt.Sprintf("%s were relegated in %s.", joinNames(names), intent.Season)
+	} else {
+		response.Answer = fmt.Sprintf("%s are currently in the %s relegation places.", joinNames(names), intent.Season)
+	}
+	return nil
+}
+
+// answerTeamSeason handles "how did Leeds do in 2000/01"
+func (e *Engine) answerTeamSeason(intent *models.QueryIntent, response *models.QueryResponse) error {
+	standings, err := e.standings.GetStandingsBySeasonID(intent.SeasonID)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range standings.Table {
+		if entry.TeamID != intent.TeamIDs[0] {
+			continue
+		}
+		response.Data = entry
+		response.Answer = fmt.Sprintf("%s %s %s in %s with %d points (W%d D%d L%d, goals %d-%d).",
+			entry.Team, verb(standings, "finished", "are"), ordinal(entry.Position), intent.Season,
+			entry.Points, entry.Won, entry.Drawn, entry.Lost, entry.GoalsFor, entry.GoalsAgainst)
+		return nil
+	}
+
+	response.Answer = fmt.Sprintf("%s did not play in the Premier League in %s.", intent.Teams[0], intent.Season)
+	return nil
+}
+
+// answerTeamRanking handles "most red cards by a team in 1998" and "fewest goals conceded"
+func (e *Engine) answerTeamRanking(intent *models.QueryIntent, response *models.QueryResponse) error {
+	type ranked struct {
+		TeamID int    `json:"teamId"`
+		Team   string `json:"team"`
+		Value  int    `json:"value"`
+	}
+	var rows []ranked
+
+	switch intent.Metric {
+	case MetricRedCards, MetricYellowCards, MetricFouls:
+		totals, err := e.matches.GetTeamDiscipline(intent.SeasonID)
+		if err != nil {
+			return err
+		}
+		for _, t := range totals {
+			value := t.RedCards
+			if intent.Metric == MetricYellowCards {
+				value = t.YellowCards
+			} else if intent.Metric == MetricFouls {
+				value = t.Fouls
+			}
+			rows = append(rows, ranked{TeamID: t.TeamID, Team: t.Team, Value: value})
+		}
+	default:
+		standings, err := e.standings.GetStandingsBySeasonID(intent.SeasonID)
+		if err != nil {
+			return err
+		}
+		for _, entry := range standings.Table {
+			rows = append(rows, ranked{TeamID: entry.TeamID, Team: entry.Team, Value: standingsMetric(entry, intent.Metric)})
+		}
+	}
+
+	if len(rows) == 0 {
+		response.Answer = fmt.Sprintf("No %s data is available for %s.", metricLabel(intent.Metric), intent.Season)
+		return nil
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if intent.Order == "fewest" {
+			return rows[i].Value < rows[j].Value
+		}
+		return rows[i].Value > rows[j].Value
+	})
+
+	limit := intent.Limit
+	if limit <= 0 {
+		limit = 5
+	}
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+	response.Data = map[string]interface{}{"ranking": rows}
+
+	var leaders []string
+	for _, row := range rows {
+		if row.Value == rows[0].Value {
+			leaders = append(leaders, row.Team)
+		}
+	}
+	response.Answer = fmt.Sprintf("%s had the %s %s in %s with %d.",
+		joinNames(leaders), intent.Order, metricLabel(intent.Metric), intent.Season, rows[0].Value)
+	return nil
+}
+
+// standingsMetric reads a ranking metric from a table entry
+func standingsMetric(entry models.StandingsEntry, metric string) int {
+	switch metric {
+	case MetricGoalsAgainst:
+		return entry.GoalsAgainst
+	case MetricWins:
+		return entry.Won
+	case MetricDraws:
+		return entry.Drawn
+	case MetricLosses:
+		return entry.Lost
+	case MetricPoints:
+		return entry.Points
+	}
+	return entry.GoalsFor
+}
+
+// metricLabel describes a metric in an answer
+func metricLabel(metric string) string {
+	switch metric {
+	case MetricGoalsFor:
+		return "goals scored"
+	case MetricGoalsAgainst:
+		return "goals conceded"
+	case MetricLosses:
+		return "defeats"
+	case MetricRedCards:
+		return "red cards"
+	case MetricYellowCards:
+		return "yellow cards"
+	}
+	return metric
+}
+
+// seasonComplete reports whether every team has played everyone home and away
+func seasonComplete(standings *models.Standings) bool {
+	expected := (len(standings.Table) - 1) * 2
+	for _, entry := range standings.Table {
+		if entry.Played < expected {
+			return false
+		}
+	}
+	return len(standings.Table) > 0
+}
+
+// verb picks past or present tense depending on whether the season is over
+func verb(standings *models.Standings, past, present string) string {
+	if seasonComplete(standings) {
+		return past
+	}
+	return present
+}
+
+// joinNames joins names as "A, B and C"
+func joinNames(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// ordinal formats a position as 1st, 2nd, 3rd...
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,185 @@
+package query
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// teamPhrase is a phrase that identifies a team in a question
+type teamPhrase struct {
+	phrase string
+	id     int
+	name   string
+}
+
+// seasonRef identifies a season by ID, name and start year
+type seasonRef struct {
+	id   int
+	name string
+	year int
+}
+
+// Lexicon holds the team and season vocabulary a question is parsed against
+type Lexicon struct {
+	teams   []teamPhrase
+	seasons map[int]seasonRef
+	current seasonRef
+}
+
+// teamNicknames maps common nicknames and abbreviations to database team names
+var teamNicknames = map[string]string{
+	"man utd":       "Manchester United",
+	"man united":    "Manchester United",
+	"united":        "Manchester United",
+	"man city":      "Manchester City",
+	"city":          "Manchester City",
+	"spurs":         "Tottenham",
+	"gunners":       "Arsenal",
+	"gooners":       "Arsenal",
+	"blues":         "Chelsea",
+	"reds":          "Liverpool",
+	"toffees":       "Everton",
+	"villa":         "Aston Villa",
+	"wolves":        "Wolverhampton",
+	"boro":          "Middlesbrough",
+	"forest":        "Nottingham Forest",
+	"nottm forest":  "Nottingham Forest",
+	"palace":        "Crystal Palace",
+	"hammers":       "West Ham",
+	"west bromwich": "West Brom",
+	"baggies":       "West Brom",
+	"saints":        "Southampton",
+	"magpies":       "Newcastle United",
+	"toon":          "Newcastle United",
+	"foxes":         "Leicester City",
+	"seagulls":      "Brighton",
+	"sheff utd":     "Sheffield United",
+	"sheff wed":     "Sheffield Wednesday",
+	"qpr":           "QPR",
+	"queens park":   "QPR",
+	"cherries":      "Bournemouth",
+	"hornets":       "Watford",
+	"potters":       "Stoke City",
+	"canaries":      "Norwich City",
+}
+
+// clubSuffixes are dropped to form short names like "newcastle" or "stoke"
+var clubSuffixes = []string{
+	"united", "city", "town", "rovers", "athletic", "wanderers", "albion",
+	"hotspur", "county", "wednesday", "forest", "fc", "afc",
+}
+
+// buildLexicon builds the vocabulary from every team, the aliases registered
+// for all sources and every season. current is the season "this season"
+// refers to, or nil when there are no seasons.
+func buildLexicon(teams []models.Team, aliases []models.TeamAlias, seasons []models.Season, current *models.Season) *Lexicon {
+	lex := &Lexicon{seasons: make(map[int]seasonRef)}
+
+	byID := make(map[int]teamPhrase, len(teams))
+	byName := make(map[string]teamPhrase, len(teams))
+	for _, t := range teams {
+		team := teamPhrase{id: t.ID, name: t.Name}
+		byID[t.ID] = team
+		byName[strings.ToLower(t.Name)] = team
+		lex.addTeam(normalize(t.Name), team)
+		// Three-letter codes such as "NEW" collide with ordinary words
+		if len(t.ShortName) > 3 {
+			lex.addTeam(normalize(t.ShortName), team)
+		}
+	}
+
+	// Aliases scoped to one data source describe that source's files rather
+	// than how people ask, and codes collide with words as above
+	for _, alias := range aliases {
+		team, ok := byID[alias.TeamID]
+		if !ok || alias.Source != "" || alias.Kind == models.AliasCode {
+			continue
+		}
+		lex.addTeam(normalize(alias.Alias), team)
+	}
+
+	// Short forms that identify exactly one team
+	shortForms := make(map[string][]teamPhrase)
+	for _, team := range byName {
+		words := strings.Fields(normalize(team.name))
+		if len(words) > 1 && isClubSuffix(words[len(words)-1]) {
+			short := strings.Join(words[:len(words)-1], " ")
+			shortForms[short] = append(shortForms[short], team)
+		}
+	}
+	for short, teams := range shortForms {
+		if len(teams) == 1 {
+			lex.addTeam(short, teams[0])
+		}
+	}
+
+	for nickname, name := range teamNicknames {
+		if team, ok := byName[strings.ToLower(name)]; ok {
+			lex.addTeam(nickname, team)
+		}
+	}
+
+	// Longest phrases first so "manchester united" wins over "united"
+	sort.Slice(lex.teams, func(i, j int) bool {
+		if len(lex.teams[i].phrase) != len(lex.teams[j].phrase) {
+			return len(lex.teams[i].phrase) > len(lex.teams[j].phrase)
+		}
+		return lex.teams[i].phrase < lex.teams[j].phrase
+	})
+
+	for _, s := range seasons {
+		if season, ok := newSeasonRef(s); ok {
+			lex.seasons[season.year] = season
+		}
+	}
+	if current != nil {
+		lex.current, _ = newSeasonRef(*current)
+	}
+
+	return lex
+}
+
+// newSeasonRef reads a season's start year from its name, such as "2003/04"
+func newSeasonRef(season models.Season) (seasonRef, bool) {
+	if len(season.Name) < 4 {
+		return seasonRef{}, false
+	}
+	year, err := strconv.Atoi(season.Name[:4])
+	if err != nil {
+		return seasonRef{}, false
+	}
+	return seasonRef{id: season.ID, name: season.Name, year: year}, true
+}
+
+// addTeam registers a phrase for a team, ignoring duplicates
+func (l *Lexicon) addTeam(phrase string, team teamPhrase) {
+	if phrase == "" {
+		return
+	}
+	for _, existing := range l.teams {
+		if existing.phrase == phrase {
+			return
+		}
+	}
+	team.phrase = phrase
+	l.teams = append(l.teams, team)
+}
+
+// season looks up a season by start year
+func (l *Lexicon) season(year int) (seasonRef, bool) {
+	season, ok := l.seasons[year]
+	return season, ok
+}
+
+// isClubSuffix reports whether word is a generic club suffix
+func isClubSuffix(word string) bool {
+	for _, suffix := range clubSuffixes {
+		if word == suffix {
+			return true
+		}
+	}
+	return false
+}
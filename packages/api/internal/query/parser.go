@@ -0,0 +1,310 @@
+package query
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// Intent types understood by the engine
+const (
+	IntentTopScorers  = "topScorers"
+	IntentHeadToHead  = "headToHead"
+	IntentStandings   = "standings"
+	IntentChampion    = "champion"
+	IntentRelegated   = "relegated"
+	IntentTeamSeason  = "teamSeason"
+	IntentTeamRanking = "teamRanking"
+)
+
+// Team ranking metrics
+const (
+	MetricGoalsFor     = "goalsFor"
+	MetricGoalsAgainst = "goalsAgainst"
+	MetricWins         = "wins"
+	MetricDraws        = "draws"
+	MetricLosses       = "losses"
+	MetricPoints       = "points"
+	MetricRedCards     = "redCards"
+	MetricYellowCards  = "yellowCards"
+	MetricFouls        = "fouls"
+)
+
+var (
+	nonQueryChars = regexp.MustCompile(`[^a-z0-9/\- ]+`)
+	seasonPattern = regexp.MustCompile(`\b((?:19|20)\d{2})\s*[/-]\s*(\d{4}|\d{2})\b`)
+	yearPattern   = regexp.MustCompile(`\b((?:19|20)\d{2})\b`)
+	topNPattern   = regexp.MustCompile(`\btop (\d{1,2})\b`)
+	playerWords   = []string{"scorer", "scorers", "golden boot", "player", "striker", "goalscorer"}
+	teamWords     = []string{"team", "teams", "club", "clubs", "side", "sides"}
+	mostWords     = []string{"most", "highest", "more", "top", "best"}
+	fewestWords   = []string{"fewest", "least", "lowest", "less", "worst"}
+)
+
+// mention is a season year found in a question, with the word before it
+type mention struct {
+	year int
+	pos  int
+	prev string
+}
+
+// normalize lowercases a question and strips punctuation other than the
+// slashes and hyphens used in season names
+func normalize(text string) string {
+	text = strings.ToLower(text)
+	text = strings.NewReplacer("&", " and ", "’", "", "'", "", ".", " ", ",", " ", "?", " ").Replace(text)
+	text = nonQueryChars.ReplaceAllString(text, " ")
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Parse interprets a question against the lexicon. It returns nil when no
+// intent matches.
+func Parse(question string, lex *Lexicon) *models.QueryIntent {
+	text := normalize(question)
+	if text == "" {
+		return nil
+	}
+
+	intent := &models.QueryIntent{}
+	rest := parseSeasons(text, lex, intent)
+	rest = parseTeams(rest, lex, intent)
+	words := " " + rest + " "
+
+	if m := topNPattern.FindStringSubmatch(text); m != nil {
+		intent.Limit, _ = strconv.Atoi(m[1])
+	}
+
+	hasAny := func(phrases []string) bool {
+		for _, phrase := range phrases {
+			if strings.Contains(words, " "+phrase+" ") {
+				return true
+			}
+		}
+		return false
+	}
+	hasPrefix := func(prefixes ...string) bool {
+		for _, prefix := range prefixes {
+			if strings.Contains(words, " "+prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case hasAny(mostWords):
+		intent.Order = "most"
+	case hasAny(fewestWords):
+		intent.Order = "fewest"
+	}
+
+	// Cards and fouls are only recorded per team
+	switch {
+	case hasPrefix("red card", "sending off", "sendings off", "sent off"):
+		intent.Metric = MetricRedCards
+	case hasPrefix("yellow card", "booking", "booked"):
+		intent.Metric = MetricYellowCards
+	case hasPrefix("foul"):
+		intent.Metric = MetricFouls
+	}
+	if intent.Metric != "" {
+		intent.Type = IntentTeamRanking
+		if intent.Order == "" {
+			intent.Order = "most"
+		}
+		return intent
+	}
+
+	// Two teams always means a comparison, whether or not "vs" is used
+	if len(intent.TeamIDs) >= 2 {
+		intent.Type = IntentHeadToHead
+		intent.TeamIDs, intent.Teams = intent.TeamIDs[:2], intent.Teams[:2]
+		// "away from home" mentions home, so away is checked first
+		switch {
+		case hasAny([]string{"away", "away from home"}):
+			intent.Venue = "away"
+		case hasAny([]string{"at home", "home"}):
+			intent.Venue = "home"
+		}
+		// A single season restricts the range to that season
+		if intent.SeasonID > 0 {
+			intent.FromSeasonID, intent.FromSeason = intent.SeasonID, intent.Season
+			intent.ToSeasonID, intent.ToSeason = intent.SeasonID, intent.Season
+			intent.SeasonID, intent.Season = 0, ""
+		}
+		return intent
+	}
+
+	playerQuestion := hasAny(playerWords) || hasPrefix("scorer", "goalscorer")
+	teamQuestion := hasAny(teamWords) || strings.Contains(words, " by a team ")
+
+	switch {
+	case playerQuestion || (hasPrefix("goal") && intent.Order == "most" && !teamQuestion):
+		intent.Type = IntentTopScorers
+		return withCurrentSeason(intent, lex)
+	case hasAny([]string{"champion", "champions", "won the league", "won the title", "title winners", "win the league", "win the title", "won the premier league"}):
+		intent.Type = IntentChampion
+		return withCurrentSeason(intent, lex)
+	case hasAny([]string{"relegated", "went down", "go down", "relegation"}):
+		intent.Type = IntentRelegated
+		return withCurrentSeason(intent, lex)
+	}
+
+	if intent.Order != "" {
+		switch {
+		case hasPrefix("conceded", "concede", "defence", "defense"):
+			intent.Metric = MetricGoalsAgainst
+			if hasAny([]string{"best defence", "best defense"}) {
+				intent.Order = "fewest"
+			}
+		case hasPrefix("goal", "attack"):
+			intent.Metric = MetricGoalsFor
+		case hasPrefix("win", "won", "victor"):
+			intent.Metric = MetricWins
+		case hasPrefix("draw"):
+			intent.Metric = MetricDraws
+		case hasPrefix("defeat", "loss", "lost", "lose"):
+			intent.Metric = MetricLosses
+		case hasPrefix("point"):
+			intent.Metric = MetricPoints
+		}
+		if intent.Metric != "" {
+			intent.Type = IntentTeamRanking
+			return withCurrentSeason(intent, lex)
+		}
+	}
+
+	if len(intent.TeamIDs) == 1 {
+		intent.Type = IntentTeamSeason
+		return withCurrentSeason(intent, lex)
+	}
+
+	if hasAny([]string{"table", "standings", "league", "premier league", "position", "positions"}) {
+		intent.Type = IntentStandings
+		return withCurrentSeason(intent, lex)
+	}
+
+	return nil
+}
+
+// parseSeasons extracts season names, years and ranges, returning the text
+// with them removed
+func parseSeasons(text string, lex *Lexicon, intent *models.QueryIntent) string {
+	var mentions []mention
+	blank := func(loc []int) {
+		text = text[:loc[0]] + strings.Repeat(" ", loc[1]-loc[0]) + text[loc[1]:]
+	}
+
+	for _, loc := range seasonPattern.FindAllStringSubmatchIndex(text, -1) {
+		year, _ := strconv.Atoi(text[loc[2]:loc[3]])
+		mentions = append(mentions, mention{year: year, pos: loc[0], prev: wordBefore(text, loc[0])})
+	}
+	for _, loc := range seasonPattern.FindAllStringIndex(text, -1) {
+		blank(loc)
+	}
+	for _, loc := range yearPattern.FindAllStringSubmatchIndex(text, -1) {
+		year, _ := strconv.Atoi(text[loc[2]:loc[3]])
+		mentions = append(mentions, mention{year: year, pos: loc[0], prev: wordBefore(text, loc[0])})
+	}
+	for _, loc := range yearPattern.FindAllStringIndex(text, -1) {
+		blank(loc)
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].pos < mentions[j].pos })
+
+	setSeason := func(year int, id *int, name *string) {
+		if season, ok := lex.season(year); ok {
+			*id, *name = season.id, season.name
+		}
+	}
+
+	for i, m := range mentions {
+		switch m.prev {
+		case "since", "from":
+			setSeason(m.year, &intent.FromSeasonID, &intent.FromSeason)
+		case "after":
+			setSeason(m.year+1, &intent.FromSeasonID, &intent.FromSeason)
+		case "until", "till", "to":
+			setSeason(m.year, &intent.ToSeasonID, &intent.ToSeason)
+		case "before":
+			setSeason(m.year-1, &intent.ToSeasonID, &intent.ToSeason)
+		case "between":
+			setSeason(m.year, &intent.FromSeasonID, &intent.FromSeason)
+			if i+1 < len(mentions) {
+				mentions[i+1].prev = "until"
+			}
+		default:
+			if intent.SeasonID == 0 {
+				setSeason(m.year, &intent.SeasonID, &intent.Season)
+			}
+		}
+	}
+
+	switch {
+	case strings.Contains(text, "this season"), strings.Contains(text, "current season"):
+		intent.SeasonID, intent.Season = lex.current.id, lex.current.name
+	case strings.Contains(text, "last season"):
+		if season, ok := lex.season(lex.current.year - 1); ok {
+			intent.SeasonID, intent.Season = season.id, season.name
+		}
+	}
+
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// parseTeams extracts team mentions in order of appearance, returning the
+// text with them removed
+func parseTeams(text string, lex *Lexicon, intent *models.QueryIntent) string {
+	type found struct {
+		pos  int
+		team teamPhrase
+	}
+	var teams []found
+
+	padded := " " + text + " "
+	for _, phrase := range lex.teams {
+		for {
+			idx := strings.Index(padded, " "+phrase.phrase+" ")
+			if idx < 0 {
+				break
+			}
+			teams = append(teams, found{pos: idx, team: phrase})
+			padded = padded[:idx+1] + strings.Repeat(" ", len(phrase.phrase)) + padded[idx+1+len(phrase.phrase):]
+		}
+	}
+
+	sort.Slice(teams, func(i, j int) bool { return teams[i].pos < teams[j].pos })
+	for _, f := range teams {
+		duplicate := false
+		for _, id := range intent.TeamIDs {
+			if id == f.team.id {
+				duplicate = true
+			}
+		}
+		if !duplicate {
+			intent.TeamIDs = append(intent.TeamIDs, f.team.id)
+			intent.Teams = append(intent.Teams, f.team.name)
+		}
+	}
+
+	return strings.Join(strings.Fields(padded), " ")
+}
+
+// wordBefore returns the word immediately before position pos
+func wordBefore(text string, pos int) string {
+	words := strings.Fields(text[:pos])
+	if len(words) == 0 {
+		return ""
+	}
+	return words[len(words)-1]
+}
+
+// withCurrentSeason defaults a season-scoped intent to the current season
+func withCurrentSeason(intent *models.QueryIntent, lex *Lexicon) *models.QueryIntent {
+	if intent.SeasonID == 0 && lex.current.id > 0 {
+		intent.SeasonID, intent.Season = lex.current.id, lex.current.name
+	}
+	return intent
+}
@@ -0,0 +1,113 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// testLexicon knows three teams and the seasons 1998/99 to 2003/04, the last
+// being the current season
+func testLexicon() *Lexicon {
+	lex := &Lexicon{seasons: make(map[int]seasonRef)}
+	arsenal := teamPhrase{id: 1, name: "Arsenal"}
+	chelsea := teamPhrase{id: 2, name: "Chelsea"}
+	united := teamPhrase{id: 3, name: "Manchester United"}
+	lex.addTeam("manchester united", united)
+	lex.addTeam("arsenal", arsenal)
+	lex.addTeam("chelsea", chelsea)
+	lex.addTeam("man utd", united)
+
+	for year := 1998; year <= 2003; year++ {
+		name := fmt.Sprintf("%d/%02d", year, (year+1)%100)
+		lex.seasons[year] = seasonRef{id: year - 1990, name: name, year: year}
+	}
+	lex.current = lex.seasons[2003]
+	return lex
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		question string
+		want     *models.QueryIntent
+	}{
+		// Venue
+		{"Arsenal vs Chelsea at home", &models.QueryIntent{
+			Type: IntentHeadToHead, Teams: []string{"Arsenal", "Chelsea"}, TeamIDs: []int{1, 2}, Venue: "home"}},
+		{"Arsenal v Chelsea away from home", &models.QueryIntent{
+			Type: IntentHeadToHead, Teams: []string{"Arsenal", "Chelsea"}, TeamIDs: []int{1, 2}, Venue: "away"}},
+		{"Man Utd against Arsenal away", &models.QueryIntent{
+			Type: IntentHeadToHead, Teams: []string{"Manchester United", "Arsenal"}, TeamIDs: []int{3, 1}, Venue: "away"}},
+		{"Chelsea vs Arsenal", &models.QueryIntent{
+			Type: IntentHeadToHead, Teams: []string{"Chelsea", "Arsenal"}, TeamIDs: []int{2, 1}}},
+
+		// Seasons
+		{"top scorer 2001/02", &models.QueryIntent{Type: IntentTopScorers, SeasonID: 11, Season: "2001/02", Order: "most"}},
+		{"top scorer 2001-2002", &models.QueryIntent{Type: IntentTopScorers, SeasonID: 11, Season: "2001/02", Order: "most"}},
+		{"league table 1999", &models.QueryIntent{Type: IntentStandings, SeasonID: 9, Season: "1999/00"}},
+		{"league table this season", &models.QueryIntent{Type: IntentStandings, SeasonID: 13, Season: "2003/04"}},
+		{"league table last season", &models.QueryIntent{Type: IntentStandings, SeasonID: 12, Season: "2002/03"}},
+		{"Arsenal vs Chelsea in 2000/01", &models.QueryIntent{
+			Type: IntentHeadToHead, Teams: []string{"Arsenal", "Chelsea"}, TeamIDs: []int{1, 2},
+			FromSeasonID: 10, FromSeason: "2000/01", ToSeasonID: 10, ToSeason: "2000/01"}},
+		{"Arsenal vs Chelsea between 1998 and 2000", &models.QueryIntent{
+			Type: IntentHeadToHead, Teams: []string{"Arsenal", "Chelsea"}, TeamIDs: []int{1, 2},
+			FromSeasonID: 8, FromSeason: "1998/99", ToSeasonID: 10, ToSeason: "2000/01"}},
+		{"Arsenal vs Chelsea after 2000 and before 2003", &models.QueryIntent{
+			Type: IntentHeadToHead, Teams: []string{"Arsenal", "Chelsea"}, TeamIDs: []int{1, 2},
+			FromSeasonID: 11, FromSeason: "2001/02", ToSeasonID: 12, ToSeason: "2002/03"}},
+
+		// Negated orderings
+		{"fewest goals conceded 2001/02", &models.QueryIntent{
+			Type: IntentTeamRanking, SeasonID: 11, Season: "2001/02", Metric: MetricGoalsAgainst, Order: "fewest"}},
+		{"best defence 2001/02", &models.QueryIntent{
+			Type: IntentTeamRanking, SeasonID: 11, Season: "2001/02", Metric: MetricGoalsAgainst, Order: "fewest"}},
+		{"which team lost the least in 2001/02", &models.QueryIntent{
+			Type: IntentTeamRanking, SeasonID: 11, Season: "2001/02", Metric: MetricLosses, Order: "fewest"}},
+		{"least red cards 1999/00", &models.QueryIntent{
+			Type: IntentTeamRanking, SeasonID: 9, Season: "1999/00", Metric: MetricRedCards, Order: "fewest"}},
+		{"most red cards 1999/00", &models.QueryIntent{
+			Type: IntentTeamRanking, SeasonID: 9, Season: "1999/00", Metric: MetricRedCards, Order: "most"}},
+
+		{"top 5 scorers this season", &models.QueryIntent{
+			Type: IntentTopScorers, SeasonID: 13, Season: "2003/04", Order: "most", Limit: 5}},
+		{"who was relegated in 2002/03", &models.QueryIntent{Type: IntentRelegated, SeasonID: 12, Season: "2002/03"}},
+		{"what is the weather like", nil},
+		{"", nil},
+	}
+	lex := testLexicon()
+	for _, tt := range tests {
+		if got := Parse(tt.question, lex); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.question, got, tt.want)
+		}
+	}
+}
+
+func TestBuildLexicon(t *testing.T) {
+	teams := []models.Team{{ID: 1, Name: "Arsenal", ShortName: "ARS"}, {ID: 3, Name: "Manchester United", ShortName: "MUN"}}
+	aliases := []models.TeamAlias{
+		{TeamID: 3, Alias: "Red Devils", Kind: models.AliasOther},
+		{TeamID: 3, Alias: "Newton Heath", Kind: models.AliasName, Source: "kaggle"},
+		{TeamID: 1, Alias: "ARSE", Kind: models.AliasCode},
+	}
+	seasons := []models.Season{{ID: 12, Name: "2002/03"}, {ID: 13, Name: "2003/04"}}
+	lex := buildLexicon(teams, aliases, seasons, &seasons[0])
+
+	// Registered aliases for every source are understood
+	want := &models.QueryIntent{Type: IntentHeadToHead, Teams: []string{"Manchester United", "Arsenal"}, TeamIDs: []int{3, 1}}
+	if got := Parse("Red Devils vs Arsenal", lex); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse(alias) = %+v, want %+v", got, want)
+	}
+	for _, team := range lex.teams {
+		if team.phrase == "newton heath" || team.phrase == "arse" {
+			t.Errorf("lexicon has phrase %q, want source-scoped aliases and codes left out", team.phrase)
+		}
+	}
+
+	// "This season" is the current season given, not the latest one listed
+	if got := Parse("league table this season", lex); got == nil || got.SeasonID != 12 {
+		t.Errorf("Parse(this season) = %+v, want season 12", got)
+	}
+}
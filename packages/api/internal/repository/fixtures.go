@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/premstats/api/internal/identity"
+	"github.com/premstats/api/internal/importer"
+	"github.com/premstats/api/internal/models"
+)
+
+// Fixture files under the repository's data directory
+const (
+	FixtureMatchesFile = "processed/matches/matches-sample.csv"
+	FixtureTablesFile  = "processed/tables/all_tables.csv"
+)
+
+// fixtureSource labels official standings loaded from the tables fixture
+const fixtureSource = "all_tables.csv"
+
+// fixtureTeamCodes names the team behind each code in the tables fixture. The
+// database takes these codes from the aliases migration 0012 seeds; fixtures
+// register them the same way.
+var fixtureTeamCodes = map[string]string{
+	"ARS": "Arsenal",
+	"AVL": "Aston Villa",
+	"BHA": "Brighton",
+	"BIR": "Birmingham City",
+	"BLK": "Blackburn",
+	"BLP": "Blackpool",
+	"BOL": "Bolton",
+	"BOU": "Bournemouth",
+	"BRE": "Brentford",
+	"BUR": "Burnley",
+	"CAR": "Cardiff City",
+	"CHA": "Charlton",
+	"CHE": "Chelsea",
+	"CRY": "Crystal Palace",
+	"DER": "Derby County",
+	"EVE": "Everton",
+	"FUL": "Fulham",
+	"HUD": "Huddersfield",
+	"HUL": "Hull City",
+	"IPS": "Ipswich",
+	"LEE": "Leeds United",
+	"LEI": "Leicester City",
+	"LIV": "Liverpool",
+	"MAN": "Manchester United",
+	"MID": "Middlesbrough",
+	"MNC": "Manchester City",
+	"NEW": "Newcastle United",
+	"NOR": "Norwich City",
+	"POR": "Portsmouth",
+	"QPR": "QPR",
+	"REA": "Reading",
+	"SHU": "Sheffield United",
+	"SOU": "Southampton",
+	"STK": "Stoke City",
+	"SUN": "Sunderland",
+	"SWA": "Swansea",
+	"TOT": "Tottenham",
+	"WAT": "Watford",
+	"WBA": "West Brom",
+	"WGA": "Wigan",
+	"WHU": "West Ham",
+	"WOL": "Wolverhampton",
+}
+
+// LoadFixtures creates a memory store seeded from the processed matches and
+// official tables CSVs under dataDir. Seasons get the IDs the database uses,
+// counting 1992/93 as season 1.
+func LoadFixtures(dataDir string) (*Memory, error) {
+	m := NewMemory()
+	teams := importer.NewStaticTeamResolver(nil)
+
+	// Tables first, so teams take the short names the database uses and the
+	// longer names in match rows resolve onto them
+	tables, rowErrors, err := importer.ReadTablesFile(filepath.Join(dataDir, FixtureTablesFile))
+	if err != nil {
+		return nil, err
+	}
+	if len(rowErrors) > 0 {
+		return nil, fmt.Errorf("failed to parse tables fixture: %w", rowErrors[0])
+	}
+	tableTeams := teams.ForSource(importer.SourceTables)
+	for _, record := range tables {
+		teamID, ok := tableTeams.ResolveAlias(record.TeamCode, time.Time{})
+		if !ok {
+			name, known := fixtureTeamCodes[record.TeamCode]
+			if !known {
+				return nil, fmt.Errorf("unknown team code %q in tables fixture", record.TeamCode)
+			}
+			teamID = m.fixtureTeam(teams, name)
+			alias := models.TeamAlias{TeamID: teamID, Alias: record.TeamCode, Kind: models.AliasCode, Source: importer.SourceTables}
+			alias.ID = m.AddTeamAlias(alias)
+			tableTeams.AddAlias(alias)
+		}
+		seasonID := m.fixtureSeason(record.SeasonYear)
+		m.AddOfficialStanding(seasonID, models.StandingsEntry{
+			Position:       record.Position,
+			TeamID:         teamID,
+			Played:         record.Played,
+			Won:            record.Won,
+			Drawn:          record.Drawn,
+			Lost:           record.Lost,
+			GoalsFor:       record.GoalsFor,
+			GoalsAgainst:   record.GoalsAgainst,
+			GoalDifference: record.GoalDifference,
+			Points:         record.Points,
+		}, fixtureSource)
+	}
+
+	matches, rowErrors, err := importer.ReadMatchFile(filepath.Join(dataDir, FixtureMatchesFile))
+	if err != nil {
+		return nil, err
+	}
+	if len(rowErrors) > 0 {
+		return nil, fmt.Errorf("failed to parse matches fixture: %w", rowErrors[0])
+	}
+	players := make(map[string]int)
+	for _, record := range matches {
+		homeScore, awayScore := record.HomeScore, record.AwayScore
+		match := models.Match{
+			SeasonID:   m.fixtureSeason(record.SeasonYear),
+			HomeTeamID: m.fixtureTeam(teams, record.HomeTeam),
+			AwayTeamID: m.fixtureTeam(teams, record.AwayTeam),
+			HomeScore:  &homeScore,
+			AwayScore:  &awayScore,
+			MatchDate:  record.Date,
+		}
+		match.ID = m.AddMatch(match)
+
+		for _, side := range []struct {
+			teamID int
+			goals  []importer.GoalRecord
+		}{{match.HomeTeamID, record.Home.Goals}, {match.AwayTeamID, record.Away.Goals}} {
+			for _, goal := range side.goals {
+				event := models.MatchEvent{
+					MatchID:    match.ID,
+					EventType:  "goal",
+					Minute:     goal.Minute,
+					PlayerName: goal.Scorer,
+					TeamID:     side.teamID,
+				}
+				if goal.Penalty {
+					event.Detail = "Penalty"
+				}
+				// Own goals are credited to the scoring side, not a player of it
+				if !goal.OwnGoal {
+					event.PlayerID = m.fixturePlayer(players, goal.Scorer, side.teamID)
+				}
+				m.AddMatchEvent(event)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// fixtureSeason returns the ID of the season starting in year, adding it if needed
+func (m *Memory) fixtureSeason(year int) int {
+	id := year - 1991
+	if _, err := m.GetSeason(id); err != nil {
+		m.AddSeason(models.Season{
+			ID:        id,
+			Name:      fmt.Sprintf("%d/%02d", year, (year+1)%100),
+			Year:      year,
+			StartDate: importer.SeasonStartDate(year),
+			EndDate:   time.Date(year+1, time.May, 31, 0, 0, 0, 0, time.UTC),
+		})
+	}
+	return id
+}
+
+// fixtureTeam resolves a team name, adding the team if it is new
+func (m *Memory) fixtureTeam(teams *importer.TeamResolver, name string) int {
+	if id, ok := teams.Resolve(name); ok {
+		return id
+	}
+	id := m.AddTeam(models.Team{Name: name})
+	teams.Add(id, name)
+	return id
+}
+
+// fixturePlayer resolves a scorer by name, adding the player if they are new
+func (m *Memory) fixturePlayer(players map[string]int, name string, teamID int) int {
+	key := identity.FoldName(name)
+	if id, ok := players[key]; ok {
+		return id
+	}
+	id := m.AddPlayer(models.Player{Name: name, TeamID: teamID})
+	players[key] = id
+	return id
+}
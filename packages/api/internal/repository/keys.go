@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"cmp"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// MatchKeyOf returns a function giving a match's position in a listing
+// sorted by one of the MatchSort orders
+func MatchKeyOf(sort string) func(models.Match) MatchKey {
+	return func(match models.Match) MatchKey {
+		return MatchKey{Value: matchSortValue(match, sort), Date: match.MatchDate, ID: match.ID}
+	}
+}
+
+// matchSortValue returns the value a match is sorted by ahead of its date:
+// its total goals or winning margin, -1 while it has no result, and 0 when
+// sorting by date
+func matchSortValue(match models.Match, sort string) int {
+	if sort != MatchSortGoals && sort != MatchSortMargin {
+		return 0
+	}
+	if match.HomeScore == nil || match.AwayScore == nil {
+		return -1
+	}
+	if sort == MatchSortGoals {
+		return *match.HomeScore + *match.AwayScore
+	}
+	return max(*match.HomeScore-*match.AwayScore, *match.AwayScore-*match.HomeScore)
+}
+
+// TeamKeyOf returns a team's position in a team listing
+func TeamKeyOf(team models.Team) NameKey {
+	return NameKey{Name: team.Name, ID: team.ID}
+}
+
+// PlayerKeyOf returns a player's position in a player listing
+func PlayerKeyOf(player models.Player) NameKey {
+	return NameKey{Name: player.Name, ID: player.ID}
+}
+
+// SearchKeyOf returns a result's position in search results
+func SearchKeyOf(result models.SearchResult) SearchKey {
+	return SearchKey{Type: result.Type, Name: result.Name, ID: result.ID}
+}
+
+// compareMatchKeys orders match keys by sort value, date then ID, lowest first
+func compareMatchKeys(a, b MatchKey) int {
+	if c := cmp.Compare(a.Value, b.Value); c != 0 {
+		return c
+	}
+	if c := a.Date.Compare(b.Date); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// compareNameKeys orders name keys by name then ID
+func compareNameKeys(a, b NameKey) int {
+	if c := cmp.Compare(a.Name, b.Name); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// compareSearchKeys orders search keys by type, name then ID
+func compareSearchKeys(a, b SearchKey) int {
+	if c := cmp.Compare(a.Type, b.Type); c != 0 {
+		return c
+	}
+	return compareNameKeys(NameKey{a.Name, a.ID}, NameKey{b.Name, b.ID})
+}
+
+// seekDirection returns the key comparison and sort order that walk a listing
+// from a seek: forwards through an ascending listing moves to greater keys,
+// while backwards or through a descending one moves to smaller keys
+func seekDirection(ascending, backward bool) (string, string) {
+	if ascending != backward {
+		return ">", "ASC"
+	}
+	return "<", "DESC"
+}
@@ -0,0 +1,1016 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+)
+
+// Memory implements every repository on in-process data. It mirrors the
+// behaviour of the PostgreSQL queries so services can be tested without a
+// database.
+type Memory struct {
+	mu            sync.RWMutex
+	teams         map[int]models.Team
+	seasons       map[int]models.Season
+	tieBreakRules map[int]string
+	seasonRules   map[int]SeasonRules
+	matches       map[int]models.Match
+	events        []models.MatchEvent
+	players       map[int]models.Player
+	playerStats   []models.PlayerStats
+	spells        []models.PlayerSpell
+	// squads maps season ID to player ID to team ID
+	squads      map[int]map[int]int
+	reviews     []playerReview
+	aliases     []models.TeamAlias
+	adjustments []models.PointAdjustment
+	official    map[int]officialTable
+	nextID      int
+}
+
+// officialTable is a season's published table and where it came from
+type officialTable struct {
+	source  string
+	entries map[int]models.StandingsEntry
+}
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		teams:         make(map[int]models.Team),
+		seasons:       make(map[int]models.Season),
+		tieBreakRules: make(map[int]string),
+		seasonRules:   make(map[int]SeasonRules),
+		matches:       make(map[int]models.Match),
+		players:       make(map[int]models.Player),
+		squads:        make(map[int]map[int]int),
+		official:      make(map[int]officialTable),
+	}
+}
+
+// id returns the given ID, or a fresh one when it is zero
+func (m *Memory) id(id int) int {
+	if id == 0 {
+		m.nextID++
+		return m.nextID
+	}
+	if id > m.nextID {
+		m.nextID = id
+	}
+	return id
+}
+
+// AddTeam stores a team, assigning an ID when it has none
+func (m *Memory) AddTeam(team models.Team) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	team.ID = m.id(team.ID)
+	m.teams[team.ID] = team
+	return team.ID
+}
+
+// AddTeamAlias stores a team alias, assigning an ID when it has none
+func (m *Memory) AddTeamAlias(alias models.TeamAlias) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	alias.ID = m.id(alias.ID)
+	m.aliases = append(m.aliases, alias)
+	return alias.ID
+}
+
+// AddSeason stores a season; its ID must be set so seasons sort chronologically
+func (m *Memory) AddSeason(season models.Season) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seasons[season.ID] = season
+}
+
+// SetTieBreakRules sets a season's raw tie-break rule list
+func (m *Memory) SetTieBreakRules(seasonID int, rules string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tieBreakRules[seasonID] = rules
+}
+
+// SetSeasonRules sets a season's raw league format
+func (m *Memory) SetSeasonRules(seasonID int, rules SeasonRules) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seasonRules[seasonID] = rules
+}
+
+// AddMatch stores a match, assigning an ID when it has none. Team names are
+// filled in from the stored teams when read.
+func (m *Memory) AddMatch(match models.Match) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	match.ID = m.id(match.ID)
+	m.matches[match.ID] = match
+	return match.ID
+}
+
+// AddMatchEvent stores a goal or other match event
+func (m *Memory) AddMatchEvent(event models.MatchEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	event.ID = m.id(event.ID)
+	m.events = append(m.events, event)
+}
+
+// AddPlayer stores a player, assigning an ID when it has none. TeamID is the
+// player's current team.
+func (m *Memory) AddPlayer(player models.Player) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	player.ID = m.id(player.ID)
+	m.players[player.ID] = player
+	return player.ID
+}
+
+// AddPlayerStats stores a player's season line, as external unless its
+// source is set
+func (m *Memory) AddPlayerStats(stats models.PlayerStats) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats.ID = m.id(stats.ID)
+	if stats.Source == "" {
+		stats.Source = models.StatsExternal
+	}
+	m.playerStats = append(m.playerStats, stats)
+}
+
+// AddSquadMember records that a player was in a team's squad for a season
+func (m *Memory) AddSquadMember(seasonID, teamID, playerID int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.squads[seasonID] == nil {
+		m.squads[seasonID] = make(map[int]int)
+	}
+	m.squads[seasonID][playerID] = teamID
+}
+
+// AddPointAdjustment stores a points deduction or award
+func (m *Memory) AddPointAdjustment(adjustment models.PointAdjustment) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	adjustment.ID = m.id(adjustment.ID)
+	m.adjustments = append(m.adjustments, adjustment)
+}
+
+// AddOfficialStanding stores one line of a season's official table
+func (m *Memory) AddOfficialStanding(seasonID int, entry models.StandingsEntry, source string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	table, ok := m.official[seasonID]
+	if !ok {
+		table = officialTable{entries: make(map[int]models.StandingsEntry)}
+	}
+	table.source = source
+	table.entries[entry.TeamID] = entry
+	m.official[seasonID] = table
+}
+
+// ListTeams returns the teams matching a filter, ordered by name
+func (m *Memory) ListTeams(filter TeamFilter) ([]models.Team, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	teams := m.filterTeams(filter)
+	return pagination.Apply(teams, filter.Seek, filter.Offset, filter.Limit, TeamKeyOf, compareNameKeys), nil
+}
+
+// CountTeams returns the number of teams matching a filter
+func (m *Memory) CountTeams(filter TeamFilter) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.filterTeams(filter)), nil
+}
+
+// filterTeams returns the teams matching a filter, ordered by name
+func (m *Memory) filterTeams(filter TeamFilter) []models.Team {
+	var inSeason map[int]bool
+	if filter.SeasonID > 0 {
+		inSeason = m.seasonTeams(filter.SeasonID)
+	}
+
+	var teams []models.Team
+	for _, team := range m.teams {
+		if inSeason == nil || inSeason[team.ID] {
+			teams = append(teams, team)
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return compareNameKeys(TeamKeyOf(teams[i]), TeamKeyOf(teams[j])) < 0
+	})
+	return teams
+}
+
+// GetTeam returns a team by ID
+func (m *Memory) GetTeam(teamID int) (*models.Team, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	team, ok := m.teams[teamID]
+	if !ok {
+		return nil, apperrors.NotFound("team with ID %d not found", teamID)
+	}
+	return &team, nil
+}
+
+// ListTeamAliases returns a team's aliases, or every team's for a zero teamID,
+// ordered by kind and then by when they were in use
+func (m *Memory) ListTeamAliases(teamID int) ([]models.TeamAlias, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	aliases := []models.TeamAlias{}
+	for _, alias := range m.aliases {
+		if teamID == 0 || alias.TeamID == teamID {
+			aliases = append(aliases, alias)
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		a, b := aliases[i], aliases[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if (a.ValidFrom == nil) != (b.ValidFrom == nil) {
+			return a.ValidFrom == nil
+		}
+		if a.ValidFrom != nil && !a.ValidFrom.Equal(*b.ValidFrom) {
+			return a.ValidFrom.Before(*b.ValidFrom)
+		}
+		if a.Alias != b.Alias {
+			return a.Alias < b.Alias
+		}
+		return a.Source < b.Source
+	})
+	return aliases, nil
+}
+
+// seasonTeams returns the IDs of teams with a match in a season
+func (m *Memory) seasonTeams(seasonID int) map[int]bool {
+	ids := make(map[int]bool)
+	for _, match := range m.matches {
+		if match.SeasonID == seasonID {
+			ids[match.HomeTeamID] = true
+			ids[match.AwayTeamID] = true
+		}
+	}
+	return ids
+}
+
+// ListSeasons returns all seasons in chronological order
+func (m *Memory) ListSeasons() ([]models.Season, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.filterSeasons(func(models.Season) bool { return true }), nil
+}
+
+// GetSeason returns a season by ID
+func (m *Memory) GetSeason(seasonID int) (*models.Season, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	season, ok := m.seasons[seasonID]
+	if !ok {
+		return nil, apperrors.NotFound("season with ID %d not found", seasonID)
+	}
+	season = m.withMetadata(season)
+	return &season, nil
+}
+
+// ListSeasonsWithResults returns the seasons that have completed matches
+func (m *Memory) ListSeasonsWithResults() ([]models.Season, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	withResults := make(map[int]bool)
+	for _, match := range m.matches {
+		if completed(match) {
+			withResults[match.SeasonID] = true
+		}
+	}
+	return m.filterSeasons(func(s models.Season) bool { return withResults[s.ID] }), nil
+}
+
+// GetSeasonRules returns a season's raw league format
+func (m *Memory) GetSeasonRules(seasonID int) (SeasonRules, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.seasons[seasonID]; !ok {
+		return SeasonRules{}, apperrors.NotFound("season with ID %d not found", seasonID)
+	}
+	return m.seasonRules[seasonID], nil
+}
+
+// withMetadata fills in a season's played matches, and its team count when
+// it was set with SetSeasonRules, as the PostgreSQL queries do
+func (m *Memory) withMetadata(season models.Season) models.Season {
+	season.PlayedMatches = 0
+	for _, match := range m.matches {
+		if match.SeasonID == season.ID && completed(match) {
+			season.PlayedMatches++
+		}
+	}
+	if rules := m.seasonRules[season.ID]; rules.TeamCount > 0 {
+		season.TeamCount = rules.TeamCount
+	}
+	return season
+}
+
+// inSeasonRange reports whether a season starts between the from and to
+// seasons inclusive, as seasonRange does; zero IDs leave a bound open
+func (m *Memory) inSeasonRange(seasonID, fromSeasonID, toSeasonID int) bool {
+	start := m.seasons[seasonID].StartDate
+	if from, ok := m.seasons[fromSeasonID]; fromSeasonID > 0 && (!ok || start.Before(from.StartDate)) {
+		return false
+	}
+	if to, ok := m.seasons[toSeasonID]; toSeasonID > 0 && (!ok || start.After(to.StartDate)) {
+		return false
+	}
+	return true
+}
+
+// filterSeasons returns the seasons that satisfy keep, ordered by ID
+func (m *Memory) filterSeasons(keep func(models.Season) bool) []models.Season {
+	var seasons []models.Season
+	for _, season := range m.seasons {
+		if keep(season) {
+			seasons = append(seasons, m.withMetadata(season))
+		}
+	}
+	sort.Slice(seasons, func(i, j int) bool {
+		if !seasons[i].StartDate.Equal(seasons[j].StartDate) {
+			return seasons[i].StartDate.Before(seasons[j].StartDate)
+		}
+		return seasons[i].ID < seasons[j].ID
+	})
+	return seasons
+}
+
+// ListMatches returns the matches matching a filter, without statistics
+func (m *Memory) ListMatches(filter MatchFilter) ([]models.Match, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var matches []models.Match
+	for _, match := range m.matches {
+		if m.matchesFilter(match, filter) {
+			matches = append(matches, m.withNames(summary(match)))
+		}
+	}
+
+	key := MatchKeyOf(filter.Sort)
+	compare := compareMatchKeys
+	if !filter.Ascending {
+		compare = func(a, b MatchKey) int { return compareMatchKeys(b, a) }
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return compare(key(matches[i]), key(matches[j])) < 0
+	})
+
+	return pagination.Apply(matches, filter.Seek, filter.Offset, filter.Limit, key, compare), nil
+}
+
+// CountMatches returns the number of matches matching a filter
+func (m *Memory) CountMatches(filter MatchFilter) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, match := range m.matches {
+		if m.matchesFilter(match, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// matchesFilter reports whether a match satisfies a filter
+func (m *Memory) matchesFilter(match models.Match, filter MatchFilter) bool {
+	if filter.SeasonID > 0 && match.SeasonID != filter.SeasonID {
+		return false
+	}
+	if !m.inSeasonRange(match.SeasonID, filter.FromSeasonID, filter.ToSeasonID) {
+		return false
+	}
+	if filter.FromDate != nil && match.MatchDate.Before(*filter.FromDate) {
+		return false
+	}
+	if filter.ToDate != nil && !match.MatchDate.Before(filter.ToDate.AddDate(0, 0, 1)) {
+		return false
+	}
+	if filter.Completed && !completed(match) {
+		return false
+	}
+	if filter.Referee != "" && !strings.Contains(strings.ToLower(match.Referee), strings.ToLower(filter.Referee)) {
+		return false
+	}
+	if filter.TeamID > 0 {
+		home := match.HomeTeamID == filter.TeamID && (filter.OpponentID == 0 || match.AwayTeamID == filter.OpponentID)
+		away := match.AwayTeamID == filter.TeamID && (filter.OpponentID == 0 || match.HomeTeamID == filter.OpponentID)
+		switch filter.Venue {
+		case "home":
+			if !home {
+				return false
+			}
+		case "away":
+			if !away {
+				return false
+			}
+		default:
+			if !home && !away {
+				return false
+			}
+		}
+	}
+	if filter.Result == "" && filter.GoalsFor == nil && filter.GoalsAgainst == nil && filter.MinGoals == 0 && !filter.Comeback {
+		return true
+	}
+
+	// The remaining criteria need a result, read from TeamID's side if set
+	if !completed(match) {
+		return false
+	}
+	goalsFor, goalsAgainst := *match.HomeScore, *match.AwayScore
+	htFor, htAgainst := match.HalfTimeHome, match.HalfTimeAway
+	if filter.TeamID > 0 && match.AwayTeamID == filter.TeamID {
+		goalsFor, goalsAgainst = goalsAgainst, goalsFor
+		htFor, htAgainst = htAgainst, htFor
+	}
+
+	switch filter.Result {
+	case ResultWin:
+		if goalsFor <= goalsAgainst {
+			return false
+		}
+	case ResultDraw:
+		if goalsFor != goalsAgainst {
+			return false
+		}
+	case ResultLoss:
+		if goalsFor >= goalsAgainst {
+			return false
+		}
+	}
+	if filter.GoalsFor != nil && goalsFor != *filter.GoalsFor {
+		return false
+	}
+	if filter.GoalsAgainst != nil && goalsAgainst != *filter.GoalsAgainst {
+		return false
+	}
+	if goalsFor+goalsAgainst < filter.MinGoals {
+		return false
+	}
+	if filter.Comeback {
+		if htFor == nil || htAgainst == nil {
+			return false
+		}
+		ownComeback := *htFor < *htAgainst && goalsFor > goalsAgainst
+		otherComeback := *htFor > *htAgainst && goalsFor < goalsAgainst
+		if !ownComeback && (filter.TeamID > 0 || !otherComeback) {
+			return false
+		}
+	}
+	return true
+}
+
+// completed reports whether a match has a final score
+func completed(match models.Match) bool {
+	return match.HomeScore != nil && match.AwayScore != nil
+}
+
+// summary drops a match's statistics, as listed matches do not carry them
+func summary(match models.Match) models.Match {
+	return models.Match{
+		ID:           match.ID,
+		SeasonID:     match.SeasonID,
+		HomeTeamID:   match.HomeTeamID,
+		AwayTeamID:   match.AwayTeamID,
+		HomeScore:    match.HomeScore,
+		AwayScore:    match.AwayScore,
+		HalfTimeHome: match.HalfTimeHome,
+		HalfTimeAway: match.HalfTimeAway,
+		MatchDate:    match.MatchDate,
+		Referee:      match.Referee,
+	}
+}
+
+// withNames fills in a match's team names
+func (m *Memory) withNames(match models.Match) models.Match {
+	match.HomeTeam = m.teams[match.HomeTeamID].Name
+	match.AwayTeam = m.teams[match.AwayTeamID].Name
+	return match
+}
+
+// paginate applies a limit and offset; a zero limit means no limit
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// GetMatch returns a match with its statistics
+func (m *Memory) GetMatch(matchID int) (*models.Match, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	match, ok := m.matches[matchID]
+	if !ok {
+		return nil, apperrors.NotFound("match with ID %d not found", matchID)
+	}
+	match = m.withNames(match)
+	match.Status = ""
+	return &match, nil
+}
+
+// ListMatchEvents returns a match's goals followed by its other events,
+// each ordered by minute
+func (m *Memory) ListMatchEvents(matchID int) ([]models.MatchEvent, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var goals, others []models.MatchEvent
+	for _, event := range m.events {
+		if event.MatchID != matchID {
+			continue
+		}
+		if player, ok := m.players[event.PlayerID]; ok {
+			event.PlayerName = player.Name
+		}
+		if event.EventType == "goal" {
+			goals = append(goals, event)
+		} else {
+			others = append(others, event)
+		}
+	}
+
+	for _, events := range [][]models.MatchEvent{goals, others} {
+		sort.SliceStable(events, func(i, j int) bool {
+			if events[i].Minute != events[j].Minute {
+				return events[i].Minute < events[j].Minute
+			}
+			return events[i].ID < events[j].ID
+		})
+	}
+
+	return append(goals, others...), nil
+}
+
+// ListResults returns a season's completed matches in date order
+func (m *Memory) ListResults(seasonID int) ([]Result, error) {
+	matches, err := m.ListMatches(MatchFilter{SeasonID: seasonID, Completed: true, Ascending: true})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(matches))
+	for _, match := range matches {
+		results = append(results, Result{
+			MatchID:    match.ID,
+			SeasonID:   match.SeasonID,
+			Date:       match.MatchDate,
+			HomeTeamID: match.HomeTeamID,
+			AwayTeamID: match.AwayTeamID,
+			HomeScore:  *match.HomeScore,
+			AwayScore:  *match.AwayScore,
+		})
+	}
+	return results, nil
+}
+
+// ListTeamDiscipline totals cards and fouls per team for a season, counting
+// only matches where card statistics were recorded
+func (m *Memory) ListTeamDiscipline(seasonID int) ([]models.TeamDiscipline, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	totals := make(map[int]*models.TeamDiscipline)
+	add := func(teamID int, yellow, red, fouls *int) {
+		if yellow == nil {
+			return
+		}
+		d, ok := totals[teamID]
+		if !ok {
+			d = &models.TeamDiscipline{TeamID: teamID, Team: m.teams[teamID].Name}
+			totals[teamID] = d
+		}
+		d.Matches++
+		d.YellowCards += *yellow
+		d.RedCards += valueOf(red)
+		d.Fouls += valueOf(fouls)
+	}
+
+	for _, match := range m.matches {
+		if match.SeasonID != seasonID {
+			continue
+		}
+		add(match.HomeTeamID, match.HomeYellowCards, match.HomeRedCards, match.HomeFouls)
+		add(match.AwayTeamID, match.AwayYellowCards, match.AwayRedCards, match.AwayFouls)
+	}
+
+	var list []models.TeamDiscipline
+	for _, d := range totals {
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Team < list[j].Team })
+	return list, nil
+}
+
+// valueOf returns an optional int's value, or 0
+func valueOf(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+// ListPointAdjustments returns a season's adjustments keyed by team, undated
+// ones first
+func (m *Memory) ListPointAdjustments(seasonID int) (map[int][]models.PointAdjustment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var list []models.PointAdjustment
+	for _, adjustment := range m.adjustments {
+		if adjustment.SeasonID == seasonID {
+			list = append(list, adjustment)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].AppliedOn != list[j].AppliedOn {
+			return list[i].AppliedOn < list[j].AppliedOn
+		}
+		return list[i].ID < list[j].ID
+	})
+
+	adjustments := make(map[int][]models.PointAdjustment)
+	for _, adjustment := range list {
+		adjustments[adjustment.TeamID] = append(adjustments[adjustment.TeamID], adjustment)
+	}
+	return adjustments, nil
+}
+
+// GetTieBreakRules returns a season's raw tie-break rules
+func (m *Memory) GetTieBreakRules(seasonID int) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.seasons[seasonID]; !ok {
+		return "", apperrors.NotFound("season with ID %d not found", seasonID)
+	}
+	return m.tieBreakRules[seasonID], nil
+}
+
+// GetOfficialStandings returns a season's official table keyed by team
+func (m *Memory) GetOfficialStandings(seasonID int) (map[int]*models.StandingsEntry, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	table := m.official[seasonID]
+	official := make(map[int]*models.StandingsEntry, len(table.entries))
+	for teamID, entry := range table.entries {
+		entry := entry
+		entry.Team = m.teams[teamID].Name
+		official[teamID] = &entry
+	}
+	return official, table.source, nil
+}
+
+// ListOfficialSeasonIDs returns the seasons that have an official table
+func (m *Memory) ListOfficialSeasonIDs() ([]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var ids []int
+	for id, table := range m.official {
+		if len(table.entries) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// ListPlayers returns the players matching a filter, ordered by name
+func (m *Memory) ListPlayers(filter PlayerFilter) ([]models.Player, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	players := m.filterPlayers(filter)
+	sort.Slice(players, func(i, j int) bool {
+		return compareNameKeys(PlayerKeyOf(players[i]), PlayerKeyOf(players[j])) < 0
+	})
+	return pagination.Apply(players, filter.Seek, filter.Offset, filter.Limit, PlayerKeyOf, compareNameKeys), nil
+}
+
+// CountPlayers returns the number of players matching a filter
+func (m *Memory) CountPlayers(filter PlayerFilter) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make(map[int]bool)
+	for _, player := range m.filterPlayers(filter) {
+		ids[player.ID] = true
+	}
+	return len(ids), nil
+}
+
+// filterPlayers applies a filter, resolving each player's team from the
+// season's squad when one is given
+func (m *Memory) filterPlayers(filter PlayerFilter) []models.Player {
+	search := strings.ToLower(filter.Search)
+
+	var players []models.Player
+	for _, player := range m.players {
+		if filter.SeasonID > 0 {
+			teamID, ok := m.squads[filter.SeasonID][player.ID]
+			if !ok {
+				continue
+			}
+			player.TeamID = teamID
+		}
+		if search != "" && !strings.Contains(strings.ToLower(player.Name), search) {
+			continue
+		}
+		if filter.Position != "" && player.Position != filter.Position {
+			continue
+		}
+		if filter.Nationality != "" && player.Nationality != filter.Nationality {
+			continue
+		}
+		if filter.TeamID > 0 && player.TeamID != filter.TeamID {
+			continue
+		}
+		player.Team = m.teams[player.TeamID].Name
+		players = append(players, player)
+	}
+	return players
+}
+
+// GetPlayer returns a player by ID, without team details
+func (m *Memory) GetPlayer(playerID int) (*models.Player, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	player, ok := m.players[playerID]
+	if !ok {
+		return nil, apperrors.NotFound("player with ID %d not found", playerID)
+	}
+	player.TeamID, player.Team = 0, ""
+	return &player, nil
+}
+
+// ListPlayerStats returns a player's season lines, most recent first
+func (m *Memory) ListPlayerStats(playerID, seasonID int) ([]models.PlayerStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stats []models.PlayerStats
+	for _, s := range m.seasonLines() {
+		if s.PlayerID != playerID || (seasonID > 0 && s.SeasonID != seasonID) {
+			continue
+		}
+		stats = append(stats, m.withStatsNames(s))
+	}
+	// Season IDs are chronological
+	sort.SliceStable(stats, func(i, j int) bool { return stats[i].SeasonID > stats[j].SeasonID })
+	return stats, nil
+}
+
+// GetPlayerMilestones reads a player's first and last appearances and goals
+// from the stored events, the only match-level data the store keeps
+func (m *Memory) GetPlayerMilestones(playerID int) (PlayerMilestones, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var appearances, goals []models.CareerMatch
+	for _, event := range m.events {
+		match, ok := m.matches[event.MatchID]
+		if event.PlayerID != playerID || !ok {
+			continue
+		}
+		career := models.CareerMatch{
+			MatchID:   match.ID,
+			SeasonID:  match.SeasonID,
+			Date:      match.MatchDate,
+			TeamID:    event.TeamID,
+			HomeTeam:  m.teams[match.HomeTeamID].Name,
+			AwayTeam:  m.teams[match.AwayTeamID].Name,
+			HomeScore: match.HomeScore,
+			AwayScore: match.AwayScore,
+		}
+		appearances = append(appearances, career)
+		if event.EventType == "goal" {
+			career.Minute = event.Minute
+			goals = append(goals, career)
+		}
+	}
+
+	byDate := func(matches []models.CareerMatch) {
+		sort.SliceStable(matches, func(i, j int) bool {
+			if !matches[i].Date.Equal(matches[j].Date) {
+				return matches[i].Date.Before(matches[j].Date)
+			}
+			if matches[i].MatchID != matches[j].MatchID {
+				return matches[i].MatchID < matches[j].MatchID
+			}
+			return matches[i].Minute < matches[j].Minute
+		})
+	}
+	byDate(appearances)
+	byDate(goals)
+
+	var milestones PlayerMilestones
+	if len(appearances) > 0 {
+		milestones.Debut = &appearances[0]
+		milestones.LastAppearance = &appearances[len(appearances)-1]
+	}
+	if len(goals) > 0 {
+		milestones.FirstGoal = &goals[0]
+		milestones.LastGoal = &goals[len(goals)-1]
+	}
+	return milestones, nil
+}
+
+// withStatsNames fills in the player, team and season names of a stats line
+func (m *Memory) withStatsNames(s models.PlayerStats) models.PlayerStats {
+	s.PlayerName = m.players[s.PlayerID].Name
+	s.TeamName = m.teams[s.TeamID].Name
+	s.SeasonName = m.seasons[s.SeasonID].Name
+	return s
+}
+
+// ListTopScorers returns a season's scorers ordered by goals then assists
+func (m *Memory) ListTopScorers(seasonID, limit int) ([]models.TopScorer, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var scorers []models.TopScorer
+	for _, s := range m.seasonLines() {
+		if s.SeasonID != seasonID || s.Goals <= 0 {
+			continue
+		}
+		player := m.players[s.PlayerID]
+		scorers = append(scorers, models.TopScorer{
+			PlayerID:    s.PlayerID,
+			PlayerName:  player.Name,
+			TeamID:      s.TeamID,
+			TeamName:    m.teams[s.TeamID].Name,
+			Goals:       s.Goals,
+			Assists:     s.Assists,
+			Appearances: s.Appearances,
+			Nationality: player.Nationality,
+			Position:    player.Position,
+		})
+	}
+	sort.SliceStable(scorers, func(i, j int) bool {
+		if scorers[i].Goals != scorers[j].Goals {
+			return scorers[i].Goals > scorers[j].Goals
+		}
+		return scorers[i].Assists > scorers[j].Assists
+	})
+	return paginate(scorers, limit, 0), nil
+}
+
+// ListPlayerTotals adds up each player's season lines within a filter
+func (m *Memory) ListPlayerTotals(filter PlayerTotalsFilter) ([]PlayerTotals, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byPlayer := make(map[int]*PlayerTotals)
+	seasons := make(map[[2]int]bool)
+	var order []int
+	for _, s := range m.seasonLines() {
+		if (filter.SeasonID > 0 && s.SeasonID != filter.SeasonID) ||
+			!m.inSeasonRange(s.SeasonID, filter.FromSeasonID, filter.ToSeasonID) ||
+			(filter.TeamID > 0 && s.TeamID != filter.TeamID) {
+			continue
+		}
+
+		t, ok := byPlayer[s.PlayerID]
+		if !ok {
+			t = &PlayerTotals{
+				PlayerID:   s.PlayerID,
+				PlayerName: m.players[s.PlayerID].Name,
+				TeamID:     s.TeamID,
+				TeamName:   m.teams[s.TeamID].Name,
+			}
+			byPlayer[s.PlayerID] = t
+			order = append(order, s.PlayerID)
+		} else if t.TeamID != s.TeamID {
+			t.TeamID, t.TeamName = 0, ""
+		}
+
+		if key := [2]int{s.PlayerID, s.SeasonID}; !seasons[key] {
+			seasons[key] = true
+			t.Seasons++
+		}
+		t.Appearances += s.Appearances
+		t.Goals += s.Goals
+		t.Assists += s.Assists
+		t.Penalties += s.Penalties
+		t.YellowCards += s.YellowCards
+		t.RedCards += s.RedCards
+		if s.Minutes != nil {
+			t.Minutes += *s.Minutes
+			t.TimedGoals += s.Goals
+		}
+	}
+
+	totals := make([]PlayerTotals, 0, len(order))
+	for _, id := range order {
+		totals = append(totals, *byPlayer[id])
+	}
+	return totals, nil
+}
+
+// ListPositions returns all unique player positions
+func (m *Memory) ListPositions() ([]string, error) {
+	return m.distinctPlayerValues(func(p models.Player) string { return p.Position }), nil
+}
+
+// ListNationalities returns all unique player nationalities
+func (m *Memory) ListNationalities() ([]string, error) {
+	return m.distinctPlayerValues(func(p models.Player) string { return p.Nationality }), nil
+}
+
+// distinctPlayerValues lists the sorted non-empty values of a player field
+func (m *Memory) distinctPlayerValues(field func(models.Player) string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var values []string
+	for _, player := range m.players {
+		if value := field(player); value != "" && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// Search finds players and then teams whose names contain the query
+func (m *Memory) Search(filter SearchFilter) ([]models.SearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := m.searchResults(filter.Query)
+	return pagination.Apply(results, filter.Seek, filter.Offset, filter.Limit, SearchKeyOf, compareSearchKeys), nil
+}
+
+// CountSearch returns the number of players and teams whose names, or team
+// aliases, contain the query
+func (m *Memory) CountSearch(query string) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.searchResults(query)), nil
+}
+
+// searchResults lists every player and team whose name, or team alias,
+// contains the query, in search order
+func (m *Memory) searchResults(query string) []models.SearchResult {
+	term := strings.ToLower(strings.TrimSpace(query))
+
+	var results []models.SearchResult
+	for _, player := range m.players {
+		if strings.Contains(strings.ToLower(player.Name), term) {
+			results = append(results, models.SearchResult{Type: "player", ID: player.ID, Name: player.Name, Subtitle: player.Position})
+		}
+	}
+	for _, team := range m.teams {
+		result := models.SearchResult{Type: "team", ID: team.ID, Name: team.Name, Subtitle: team.Stadium}
+		if strings.Contains(strings.ToLower(team.Name), term) {
+			results = append(results, result)
+			continue
+		}
+		// Teams found by an alias show the alphabetically first one that matched
+		for _, alias := range m.aliases {
+			if alias.TeamID == team.ID && strings.Contains(strings.ToLower(alias.Alias), term) && (result.Extra == "" || alias.Alias < result.Extra) {
+				result.Extra = alias.Alias
+			}
+		}
+		if result.Extra != "" {
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return compareSearchKeys(SearchKeyOf(results[i]), SearchKeyOf(results[j])) < 0
+	})
+	return results
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+)
+
+// Decisions recorded on a reviewed pair of players
+const (
+	reviewMerged   = "merged"
+	reviewRejected = "rejected"
+)
+
+// playerReview is a decision on a suspected duplicate player
+type playerReview struct {
+	playerID    int
+	duplicateID int
+	decision    string
+	confidence  float64
+}
+
+// ListRejectedPlayerPairs returns the pairs reviewed as different people
+func (m *Memory) ListRejectedPlayerPairs() ([][2]int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var pairs [][2]int
+	for _, review := range m.reviews {
+		if review.decision == reviewRejected {
+			pairs = append(pairs, [2]int{review.playerID, review.duplicateID})
+		}
+	}
+	return pairs, nil
+}
+
+// RejectPlayerPair records that two players are different people
+func (m *Memory) RejectPlayerPair(playerID, duplicateID int, confidence float64) error {
+	if playerID > duplicateID {
+		playerID, duplicateID = duplicateID, playerID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.review(playerReview{playerID, duplicateID, reviewRejected, confidence})
+	return nil
+}
+
+// review records a decision, replacing any earlier one on the same pair
+func (m *Memory) review(decision playerReview) {
+	for i, review := range m.reviews {
+		if review.playerID == decision.playerID && review.duplicateID == decision.duplicateID {
+			m.reviews[i] = decision
+			return
+		}
+	}
+	m.reviews = append(m.reviews, decision)
+}
+
+// MergePlayers moves a duplicate's events, season lines, squads and spells
+// onto the kept player and deletes the duplicate
+func (m *Memory) MergePlayers(playerID, duplicateID int, confidence float64) (models.PlayerMergeResult, error) {
+	result := models.PlayerMergeResult{PlayerID: playerID, MergedID: duplicateID}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept, ok := m.players[playerID]
+	if !ok {
+		return result, apperrors.NotFound("player with ID %d not found", playerID)
+	}
+	duplicate, ok := m.players[duplicateID]
+	if !ok {
+		return result, apperrors.NotFound("player with ID %d not found", duplicateID)
+	}
+
+	for i, event := range m.events {
+		if event.PlayerID != duplicateID {
+			continue
+		}
+		m.events[i].PlayerID = playerID
+		if event.EventType == "goal" {
+			result.Goals++
+		} else {
+			result.MatchEvents++
+		}
+	}
+
+	// Season lines both players have for the same team and source are added
+	// together
+	type lineKey struct {
+		seasonID, teamID int
+		source           string
+	}
+	lines := make(map[lineKey]int)
+	for i, s := range m.playerStats {
+		if s.PlayerID == playerID {
+			lines[lineKey{s.SeasonID, s.TeamID, s.Source}] = i
+		}
+	}
+	dropped := make(map[int]bool)
+	for i, s := range m.playerStats {
+		if s.PlayerID != duplicateID {
+			continue
+		}
+		result.PlayerStats++
+		j, ok := lines[lineKey{s.SeasonID, s.TeamID, s.Source}]
+		if !ok {
+			m.playerStats[i].PlayerID = playerID
+			continue
+		}
+		line := &m.playerStats[j]
+		line.Appearances += s.Appearances
+		line.Goals += s.Goals
+		line.Assists += s.Assists
+		line.YellowCards += s.YellowCards
+		line.RedCards += s.RedCards
+		line.Penalties += s.Penalties
+		line.OwnGoals += s.OwnGoals
+		if s.Minutes != nil {
+			minutes := *s.Minutes
+			if line.Minutes != nil {
+				minutes += *line.Minutes
+			}
+			line.Minutes = &minutes
+		}
+		dropped[i] = true
+	}
+	stats := m.playerStats[:0]
+	for i, s := range m.playerStats {
+		if !dropped[i] {
+			stats = append(stats, s)
+		}
+	}
+	m.playerStats = stats
+
+	for i, spell := range m.spells {
+		if spell.PlayerID == duplicateID {
+			m.spells[i].PlayerID = playerID
+			result.Spells++
+		}
+	}
+
+	for _, squad := range m.squads {
+		teamID, ok := squad[duplicateID]
+		if !ok {
+			continue
+		}
+		if _, ok := squad[playerID]; !ok {
+			squad[playerID] = teamID
+			result.SquadMemberships++
+		}
+		delete(squad, duplicateID)
+	}
+
+	if kept.DateOfBirth == "" {
+		kept.DateOfBirth = duplicate.DateOfBirth
+	}
+	if kept.Nationality == "" {
+		kept.Nationality = duplicate.Nationality
+	}
+	if kept.Position == "" {
+		kept.Position = duplicate.Position
+	}
+	if kept.TeamID == 0 {
+		kept.TeamID = duplicate.TeamID
+	}
+	m.players[playerID] = kept
+	delete(m.players, duplicateID)
+
+	m.review(playerReview{playerID, duplicateID, reviewMerged, confidence})
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// ListPlayerAppearances reads what each player did in each match from the
+// stored events; the store keeps no lineups
+func (m *Memory) ListPlayerAppearances(seasonID int) ([]PlayerAppearance, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	byMatch := make(map[[2]int]*PlayerAppearance)
+	var keys [][2]int
+	for _, event := range m.events {
+		match, ok := m.matches[event.MatchID]
+		if event.PlayerID == 0 || !ok || (seasonID > 0 && match.SeasonID != seasonID) {
+			continue
+		}
+
+		// Own goals are credited to the side that benefits
+		teamID := event.TeamID
+		if event.EventType == "own_goal" {
+			teamID = match.HomeTeamID
+			if event.TeamID == match.HomeTeamID {
+				teamID = match.AwayTeamID
+			}
+		}
+
+		key := [2]int{event.PlayerID, event.MatchID}
+		a, ok := byMatch[key]
+		if !ok {
+			a = &PlayerAppearance{
+				PlayerID:   event.PlayerID,
+				PlayerName: m.players[event.PlayerID].Name,
+				MatchID:    match.ID,
+				SeasonID:   match.SeasonID,
+				SeasonName: m.seasons[match.SeasonID].Name,
+				TeamID:     teamID,
+				TeamName:   m.teams[teamID].Name,
+			}
+			byMatch[key] = a
+			keys = append(keys, key)
+		}
+
+		switch event.EventType {
+		case "goal":
+			a.Goals++
+		case "penalty":
+			a.Goals++
+			a.Penalties++
+		case "own_goal":
+			a.OwnGoals++
+		case "yellow_card":
+			a.YellowCards++
+		case "red_card":
+			a.RedCards++
+			if a.SentOff == 0 || event.Minute < a.SentOff {
+				a.SentOff = event.Minute
+			}
+		}
+	}
+
+	appearances := make([]PlayerAppearance, 0, len(keys))
+	for _, key := range keys {
+		appearances = append(appearances, *byMatch[key])
+	}
+	sort.SliceStable(appearances, func(i, j int) bool {
+		a, b := appearances[i], appearances[j]
+		if a.SeasonID != b.SeasonID {
+			return a.SeasonID < b.SeasonID
+		}
+		if a.PlayerID != b.PlayerID {
+			return a.PlayerID < b.PlayerID
+		}
+		return m.matches[a.MatchID].MatchDate.Before(m.matches[b.MatchID].MatchDate)
+	})
+	return appearances, nil
+}
+
+// ListSeasonPlayerStats returns the external season lines of one or every
+// season
+func (m *Memory) ListSeasonPlayerStats(seasonID int) ([]models.PlayerStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stats []models.PlayerStats
+	for _, s := range m.playerStats {
+		if s.Source == models.StatsExternal && (seasonID == 0 || s.SeasonID == seasonID) {
+			stats = append(stats, m.withStatsNames(s))
+		}
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.SeasonID != b.SeasonID {
+			return a.SeasonID < b.SeasonID
+		}
+		if a.PlayerID != b.PlayerID {
+			return a.PlayerID < b.PlayerID
+		}
+		return a.TeamID < b.TeamID
+	})
+	return stats, nil
+}
+
+// SaveDerivedPlayerStats replaces the derived lines of one or every season,
+// leaving external lines alone
+func (m *Memory) SaveDerivedPlayerStats(seasonID int, stats []models.PlayerStats) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	derived := make(map[[3]int]models.PlayerStats, len(stats))
+	for _, s := range stats {
+		derived[[3]int{s.PlayerID, s.SeasonID, s.TeamID}] = s
+	}
+
+	var kept []models.PlayerStats
+	for _, s := range m.playerStats {
+		key := [3]int{s.PlayerID, s.SeasonID, s.TeamID}
+		line, ok := derived[key]
+		switch {
+		case s.Source != models.StatsDerived:
+			kept = append(kept, s)
+		case ok:
+			line.ID = s.ID
+			line.Source = models.StatsDerived
+			kept = append(kept, line)
+			delete(derived, key)
+		case seasonID == 0 || s.SeasonID == seasonID:
+			// Derived before, but no longer backed by match data
+		default:
+			kept = append(kept, s)
+		}
+	}
+	for _, s := range stats {
+		if _, ok := derived[[3]int{s.PlayerID, s.SeasonID, s.TeamID}]; ok {
+			s.ID = m.id(0)
+			s.Source = models.StatsDerived
+			kept = append(kept, s)
+		}
+	}
+	m.playerStats = kept
+
+	return len(stats), nil
+}
+
+// seasonLines returns one season line per player, season and team, as the
+// player_season_stats view does: the derived numbers where there are some,
+// with the external line's assists. Callers hold the lock.
+func (m *Memory) seasonLines() []models.PlayerStats {
+	index := make(map[[3]int]int)
+	var lines []models.PlayerStats
+	for _, s := range m.playerStats {
+		key := [3]int{s.PlayerID, s.SeasonID, s.TeamID}
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(lines)
+			lines = append(lines, s)
+			continue
+		}
+		external, derived := lines[i], s
+		if external.Source == models.StatsDerived {
+			external, derived = derived, external
+		}
+		derived.Assists = external.Assists
+		lines[i] = derived
+	}
+	return lines
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// AddPlayerSpell stores a spell and returns its ID
+func (m *Memory) AddPlayerSpell(spell models.PlayerSpell) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	spell.ID = m.id(spell.ID)
+	m.spells = append(m.spells, spell)
+	return spell.ID
+}
+
+// ListPlayerSpells returns a player's spells, oldest first
+func (m *Memory) ListPlayerSpells(playerID int) ([]models.PlayerSpell, error) {
+	return m.listPlayerSpells(func(spell models.PlayerSpell) bool { return spell.PlayerID == playerID }), nil
+}
+
+// ListTeamSpells returns every spell at a club, oldest first
+func (m *Memory) ListTeamSpells(teamID int) ([]models.PlayerSpell, error) {
+	return m.listPlayerSpells(func(spell models.PlayerSpell) bool { return spell.TeamID == teamID }), nil
+}
+
+// listPlayerSpells returns the matching spells with their names filled in,
+// ordered by first season, start date then ID
+func (m *Memory) listPlayerSpells(match func(models.PlayerSpell) bool) []models.PlayerSpell {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	spells := []models.PlayerSpell{}
+	for _, spell := range m.spells {
+		if !match(spell) {
+			continue
+		}
+		spell.PlayerName = m.players[spell.PlayerID].Name
+		spell.TeamName = m.teams[spell.TeamID].Name
+		spell.FirstSeason = m.seasons[spell.FirstSeasonID].Name
+		spell.LastSeason = m.seasons[spell.LastSeasonID].Name
+		spells = append(spells, spell)
+	}
+
+	sort.SliceStable(spells, func(i, j int) bool {
+		a, b := spells[i], spells[j]
+		if a.FirstSeasonID != b.FirstSeasonID {
+			return a.FirstSeasonID < b.FirstSeasonID
+		}
+		if (a.From == nil) != (b.From == nil) {
+			return a.From == nil
+		}
+		if a.From != nil && !a.From.Equal(*b.From) {
+			return a.From.Before(*b.From)
+		}
+		return a.ID < b.ID
+	})
+	return spells
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+)
+
+// teamGame is one match from one team's point of view
+type teamGame struct {
+	matchID      int
+	date         time.Time
+	goalsFor     int
+	goalsAgainst int
+}
+
+// ListTableRows aggregates a season's results into one line per team, for a
+// single table or for every matchweek
+func (m *Memory) ListTableRows(seasonID int, q TableQuery) ([]TableRow, error) {
+	if _, ok := scoreColumns[q.score()]; !ok {
+		return nil, apperrors.InvalidArgument("unknown table score %q", q.Score)
+	}
+	if !q.Home && !q.Away {
+		return nil, apperrors.InvalidArgument("a table needs home or away matches")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	games := make(map[int][]teamGame)
+	for _, match := range m.matches {
+		if match.SeasonID != seasonID || !completed(match) {
+			continue
+		}
+		if q.Before != nil && !match.MatchDate.Before(*q.Before) {
+			continue
+		}
+		home, away, ok := tableScore(match, q.score())
+		if !ok {
+			continue
+		}
+		if q.Home {
+			games[match.HomeTeamID] = append(games[match.HomeTeamID], teamGame{match.ID, match.MatchDate, home, away})
+		}
+		if q.Away {
+			games[match.AwayTeamID] = append(games[match.AwayTeamID], teamGame{match.ID, match.MatchDate, away, home})
+		}
+	}
+
+	mostGames := 0
+	for _, list := range games {
+		sort.Slice(list, func(i, j int) bool {
+			if !list[i].date.Equal(list[j].date) {
+				return list[i].date.Before(list[j].date)
+			}
+			return list[i].matchID < list[j].matchID
+		})
+		if len(list) > mostGames {
+			mostGames = len(list)
+		}
+	}
+
+	// Bucket 0 means "every match"; any other bucket limits each team to
+	// its first N matches
+	buckets := []int{0}
+	if q.PerMatchweek {
+		buckets = nil
+		for week := 1; week <= mostGames; week++ {
+			buckets = append(buckets, week)
+		}
+	} else if q.Matchweek > 0 {
+		buckets = []int{q.Matchweek}
+	}
+
+	teams := m.filterTeams(TeamFilter{SeasonID: seasonID})
+
+	var rows []TableRow
+	for _, week := range buckets {
+		for _, team := range teams {
+			row := TableRow{Matchweek: week}
+			row.Entry.TeamID = team.ID
+			row.Entry.Team = team.Name
+			for i, game := range games[team.ID] {
+				if week > 0 && i >= week {
+					break
+				}
+				row.Entry.Played++
+				row.Entry.GoalsFor += game.goalsFor
+				row.Entry.GoalsAgainst += game.goalsAgainst
+				switch {
+				case game.goalsFor > game.goalsAgainst:
+					row.Entry.Won++
+				case game.goalsFor == game.goalsAgainst:
+					row.Entry.Drawn++
+				default:
+					row.Entry.Lost++
+				}
+				date := game.date
+				row.LastMatch = &date
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return rows, nil
+}
+
+// tableScore returns the home and away goals a match contributes under a
+// score, or false when the match lacks the half-time score it needs
+func tableScore(match models.Match, score string) (int, int, bool) {
+	if score == ScoreFullTime {
+		return *match.HomeScore, *match.AwayScore, true
+	}
+	if match.HalfTimeHome == nil || match.HalfTimeAway == nil {
+		return 0, 0, false
+	}
+	if score == ScoreFirstHalf {
+		return *match.HalfTimeHome, *match.HalfTimeAway, true
+	}
+	return *match.HomeScore - *match.HalfTimeHome, *match.AwayScore - *match.HalfTimeAway, true
+}
+
+// ListTeamSeasonAggregates totals a season's matches per team. The memory
+// store has no stored copy to fall out of date, so totals are computed on read.
+func (m *Memory) ListTeamSeasonAggregates(seasonID int) ([]TeamSeasonAggregate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	teams := m.filterTeams(TeamFilter{SeasonID: seasonID})
+	index := make(map[int]int, len(teams))
+	aggregates := make([]TeamSeasonAggregate, len(teams))
+	for i, team := range teams {
+		index[team.ID] = i
+		aggregates[i] = TeamSeasonAggregate{SeasonID: seasonID, TeamID: team.ID, Team: team.Name}
+	}
+
+	for _, match := range m.matches {
+		if match.SeasonID != seasonID {
+			continue
+		}
+		homeIndex, homeOK := index[match.HomeTeamID]
+		awayIndex, awayOK := index[match.AwayTeamID]
+		if !homeOK || !awayOK {
+			return nil, fmt.Errorf("match %d involves a team missing from season %d", match.ID, seasonID)
+		}
+		home := &aggregates[homeIndex]
+		away := &aggregates[awayIndex]
+		addMatchStats(home, match.HomeYellowCards, match.HomeRedCards, match.HomeShots, match.HomeShotsOnTarget)
+		addMatchStats(away, match.AwayYellowCards, match.AwayRedCards, match.AwayShots, match.AwayShotsOnTarget)
+
+		if !completed(match) {
+			continue
+		}
+		for _, record := range []*AggregateRecord{&home.Overall, &home.Home} {
+			record.add(*match.HomeScore, *match.AwayScore, match.MatchDate)
+		}
+		for _, record := range []*AggregateRecord{&away.Overall, &away.Away} {
+			record.add(*match.AwayScore, *match.HomeScore, match.MatchDate)
+		}
+	}
+
+	return aggregates, nil
+}
+
+// addMatchStats adds one side's recorded cards and shots to its totals
+func addMatchStats(a *TeamSeasonAggregate, yellow, red, shots, onTarget *int) {
+	for _, stat := range []struct {
+		value *int
+		total *int
+	}{{yellow, &a.YellowCards}, {red, &a.RedCards}, {shots, &a.Shots}, {onTarget, &a.ShotsOnTarget}} {
+		if stat.value != nil {
+			*stat.total += *stat.value
+		}
+	}
+}
+
+// add counts one completed match towards a record
+func (r *AggregateRecord) add(goalsFor, goalsAgainst int, date time.Time) {
+	r.Played++
+	r.GoalsFor += goalsFor
+	r.GoalsAgainst += goalsAgainst
+	switch {
+	case goalsFor > goalsAgainst:
+		r.Won++
+	case goalsFor == goalsAgainst:
+		r.Drawn++
+	default:
+		r.Lost++
+	}
+	if r.LastMatch == nil || date.After(*r.LastMatch) {
+		r.LastMatch = &date
+	}
+}
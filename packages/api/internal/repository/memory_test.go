@@ -0,0 +1,343 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+)
+
+// fixtureDataDir is the repository's data directory relative to this package
+const fixtureDataDir = "../../../../data"
+
+func loadFixtures(t *testing.T) *Memory {
+	t.Helper()
+	m, err := LoadFixtures(fixtureDataDir)
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	return m
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func date(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestLoadFixtures(t *testing.T) {
+	m := loadFixtures(t)
+
+	season, err := m.GetSeason(10)
+	if err != nil {
+		t.Fatalf("GetSeason(10): %v", err)
+	}
+	if season.Name != "2001/02" {
+		t.Errorf("season 10 name = %q, want 2001/02", season.Name)
+	}
+
+	matches, err := m.ListMatches(MatchFilter{SeasonID: 10, Ascending: true})
+	if err != nil {
+		t.Fatalf("ListMatches: %v", err)
+	}
+	if len(matches) == 0 {
+		t.Fatal("expected fixture matches in 2001/02")
+	}
+	first := matches[0]
+	if first.HomeTeam == "" || first.AwayTeam == "" {
+		t.Errorf("match %d is missing team names: %+v", first.ID, first)
+	}
+	for i := 1; i < len(matches); i++ {
+		if matches[i].MatchDate.Before(matches[i-1].MatchDate) {
+			t.Fatalf("matches not in date order at %d", i)
+		}
+	}
+
+	events, err := m.ListMatchEvents(first.ID)
+	if err != nil {
+		t.Fatalf("ListMatchEvents: %v", err)
+	}
+	if goals := len(events); goals != *first.HomeScore+*first.AwayScore {
+		t.Errorf("match %d has %d goal events, want %d", first.ID, goals, *first.HomeScore+*first.AwayScore)
+	}
+
+	official, source, err := m.GetOfficialStandings(10)
+	if err != nil {
+		t.Fatalf("GetOfficialStandings: %v", err)
+	}
+	if len(official) != 20 || source == "" {
+		t.Errorf("official 2001/02 table has %d teams from %q, want 20 from a named source", len(official), source)
+	}
+}
+
+func TestMemoryNotFound(t *testing.T) {
+	m := NewMemory()
+
+	if _, err := m.GetTeam(1); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("GetTeam error code = %q, want %q", apperrors.Code(err), apperrors.CodeNotFound)
+	}
+	if _, err := m.GetSeason(1); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("GetSeason error code = %q, want %q", apperrors.Code(err), apperrors.CodeNotFound)
+	}
+	if _, err := m.GetSeasonRules(1); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("GetSeasonRules error code = %q, want %q", apperrors.Code(err), apperrors.CodeNotFound)
+	}
+	if _, err := m.GetMatch(1); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("GetMatch error code = %q, want %q", apperrors.Code(err), apperrors.CodeNotFound)
+	}
+	if _, err := m.GetPlayer(1); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("GetPlayer error code = %q, want %q", apperrors.Code(err), apperrors.CodeNotFound)
+	}
+}
+
+// seedMeetings adds two teams that meet twice in one season, plus a fixture
+// still to be played
+func seedMeetings() (*Memory, int, int) {
+	m := NewMemory()
+	m.AddSeason(models.Season{ID: 1, Name: "1992/93"})
+	home := m.AddTeam(models.Team{Name: "Arsenal"})
+	away := m.AddTeam(models.Team{Name: "Chelsea"})
+	m.AddMatch(models.Match{SeasonID: 1, HomeTeamID: home, AwayTeamID: away, HomeScore: intPtr(2), AwayScore: intPtr(1),
+		HalfTimeHome: intPtr(0), HalfTimeAway: intPtr(1), MatchDate: date("1992-08-15"), Referee: "Graham Poll"})
+	m.AddMatch(models.Match{SeasonID: 1, HomeTeamID: away, AwayTeamID: home, HomeScore: intPtr(0), AwayScore: intPtr(0),
+		HalfTimeHome: intPtr(0), HalfTimeAway: intPtr(0), MatchDate: date("1993-01-09")})
+	m.AddMatch(models.Match{SeasonID: 1, HomeTeamID: home, AwayTeamID: away, MatchDate: date("1993-05-08")})
+	return m, home, away
+}
+
+func TestMemoryListMatchesFilters(t *testing.T) {
+	m, home, away := seedMeetings()
+	opening, midSeason := date("1992-08-15"), date("1992-12-01")
+
+	tests := []struct {
+		name   string
+		filter MatchFilter
+		want   int
+	}{
+		{"all", MatchFilter{}, 3},
+		{"completed", MatchFilter{Completed: true}, 2},
+		{"team at home", MatchFilter{TeamID: home, Venue: "home"}, 2},
+		{"team away", MatchFilter{TeamID: home, Venue: "away"}, 1},
+		{"against opponent at home", MatchFilter{TeamID: away, OpponentID: home, Venue: "home"}, 1},
+		{"other season", MatchFilter{SeasonID: 2}, 0},
+		{"paged", MatchFilter{Limit: 1, Offset: 1}, 1},
+		{"date range", MatchFilter{FromDate: &opening, ToDate: &opening}, 1},
+		{"from date", MatchFilter{FromDate: &midSeason}, 2},
+		{"team wins", MatchFilter{TeamID: home, Result: ResultWin}, 1},
+		{"team losses", MatchFilter{TeamID: away, Result: ResultLoss}, 1},
+		{"draws", MatchFilter{TeamID: away, Result: ResultDraw}, 1},
+		{"scoreline for team", MatchFilter{TeamID: away, GoalsFor: intPtr(1), GoalsAgainst: intPtr(2)}, 1},
+		{"home-away scoreline", MatchFilter{GoalsFor: intPtr(1), GoalsAgainst: intPtr(2)}, 0},
+		{"min goals", MatchFilter{MinGoals: 1}, 1},
+		{"any comeback", MatchFilter{Comeback: true}, 1},
+		{"team comeback", MatchFilter{TeamID: home, Comeback: true}, 1},
+		{"no comeback for the side that led", MatchFilter{TeamID: away, Comeback: true}, 0},
+		{"referee", MatchFilter{Referee: "poll"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := m.ListMatches(tt.filter)
+			if err != nil {
+				t.Fatalf("ListMatches: %v", err)
+			}
+			if len(matches) != tt.want {
+				t.Errorf("got %d matches, want %d", len(matches), tt.want)
+			}
+		})
+	}
+
+	matches, _ := m.ListMatches(MatchFilter{})
+	if !matches[0].MatchDate.Equal(date("1993-05-08")) {
+		t.Errorf("default order should be newest first, got %s", matches[0].MatchDate)
+	}
+}
+
+func TestMemoryListMatchesSeasonRange(t *testing.T) {
+	m := NewMemory()
+	// The later season was loaded first and has the lower ID
+	m.AddSeason(models.Season{ID: 2, Name: "1993/94", StartDate: date("1993-08-14")})
+	m.AddSeason(models.Season{ID: 5, Name: "1992/93", StartDate: date("1992-08-15")})
+	home := m.AddTeam(models.Team{Name: "Arsenal"})
+	away := m.AddTeam(models.Team{Name: "Chelsea"})
+	m.AddMatch(models.Match{SeasonID: 5, HomeTeamID: home, AwayTeamID: away, MatchDate: date("1992-08-15")})
+	m.AddMatch(models.Match{SeasonID: 2, HomeTeamID: away, AwayTeamID: home, MatchDate: date("1993-08-14")})
+
+	tests := []struct {
+		name   string
+		filter MatchFilter
+		want   int
+	}{
+		{"from the earlier season", MatchFilter{FromSeasonID: 5}, 2},
+		{"up to the earlier season", MatchFilter{ToSeasonID: 5}, 1},
+		{"from the later season", MatchFilter{FromSeasonID: 2}, 1},
+		{"unknown bound", MatchFilter{FromSeasonID: 9}, 0},
+	}
+	for _, tt := range tests {
+		matches, err := m.ListMatches(tt.filter)
+		if err != nil {
+			t.Fatalf("ListMatches: %v", err)
+		}
+		if len(matches) != tt.want {
+			t.Errorf("%s: got %d matches, want %d", tt.name, len(matches), tt.want)
+		}
+	}
+}
+
+func TestMemoryListMatchesSort(t *testing.T) {
+	m, _, _ := seedMeetings()
+
+	// Unplayed matches sort below every result
+	tests := []struct {
+		sort      string
+		ascending bool
+		want      []string
+	}{
+		{MatchSortGoals, false, []string{"1992-08-15", "1993-01-09", "1993-05-08"}},
+		{MatchSortGoals, true, []string{"1993-05-08", "1993-01-09", "1992-08-15"}},
+		{MatchSortMargin, false, []string{"1992-08-15", "1993-01-09", "1993-05-08"}},
+		{MatchSortDate, true, []string{"1992-08-15", "1993-01-09", "1993-05-08"}},
+	}
+	for _, tt := range tests {
+		filter := MatchFilter{Sort: tt.sort, Ascending: tt.ascending}
+		matches, err := m.ListMatches(filter)
+		if err != nil {
+			t.Fatalf("ListMatches: %v", err)
+		}
+		var got []string
+		for _, match := range matches {
+			got = append(got, match.MatchDate.Format("2006-01-02"))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("sort %s ascending=%v = %v, want %v", tt.sort, tt.ascending, got, tt.want)
+		}
+
+		// Seeking from the first match continues in the same order
+		filter.Seek = &pagination.Seek[MatchKey]{Key: MatchKeyOf(tt.sort)(matches[0])}
+		rest, err := m.ListMatches(filter)
+		if err != nil {
+			t.Fatalf("ListMatches: %v", err)
+		}
+		if len(rest) != 2 || rest[0].ID != matches[1].ID {
+			t.Errorf("sort %s: seek after first match returned %d matches", tt.sort, len(rest))
+		}
+	}
+}
+
+func TestMemoryListTableRows(t *testing.T) {
+	m, home, away := seedMeetings()
+
+	rows, err := m.ListTableRows(1, TableQuery{Home: true, Away: true})
+	if err != nil {
+		t.Fatalf("ListTableRows: %v", err)
+	}
+	lines := make(map[int]models.StandingsEntry)
+	for _, row := range rows {
+		lines[row.Entry.TeamID] = row.Entry
+	}
+	if got := lines[home]; got.Played != 2 || got.Won != 1 || got.Drawn != 1 || got.GoalsFor != 2 || got.GoalsAgainst != 1 {
+		t.Errorf("home team line = %+v", got)
+	}
+	if got := lines[away]; got.Played != 2 || got.Lost != 1 || got.Drawn != 1 {
+		t.Errorf("away team line = %+v", got)
+	}
+
+	// The first half of the opening match went to the visitors
+	rows, err = m.ListTableRows(1, TableQuery{Home: true, Away: true, Score: ScoreFirstHalf})
+	if err != nil {
+		t.Fatalf("ListTableRows: %v", err)
+	}
+	for _, row := range rows {
+		if row.Entry.TeamID == away && row.Entry.Won != 1 {
+			t.Errorf("first-half line for away team = %+v, want one win", row.Entry)
+		}
+	}
+
+	before := date("1992-09-01")
+	rows, err = m.ListTableRows(1, TableQuery{Home: true, Away: true, Before: &before})
+	if err != nil {
+		t.Fatalf("ListTableRows: %v", err)
+	}
+	for _, row := range rows {
+		if row.Entry.Played != 1 {
+			t.Errorf("line before %s = %+v, want one match", before.Format("2006-01-02"), row.Entry)
+		}
+	}
+}
+
+func TestMemoryTeamSeasonAggregates(t *testing.T) {
+	m, home, away := seedMeetings()
+	m.AddMatch(models.Match{SeasonID: 1, HomeTeamID: home, AwayTeamID: away, MatchDate: date("1993-05-15"),
+		HomeYellowCards: intPtr(2), AwayYellowCards: intPtr(1), HomeShots: intPtr(9), AwayShots: intPtr(4)})
+
+	aggregates, err := m.ListTeamSeasonAggregates(1)
+	if err != nil {
+		t.Fatalf("ListTeamSeasonAggregates: %v", err)
+	}
+	if len(aggregates) != 2 || aggregates[0].TeamID != home {
+		t.Fatalf("got %+v, want Arsenal then Chelsea", aggregates)
+	}
+
+	arsenal := aggregates[0]
+	if arsenal.Overall.Played != 2 || arsenal.Overall.Won != 1 || arsenal.Overall.Drawn != 1 || arsenal.Overall.GoalsFor != 2 {
+		t.Errorf("overall record = %+v", arsenal.Overall)
+	}
+	if arsenal.Home.Played != 1 || arsenal.Home.Won != 1 || arsenal.Away.Played != 1 || arsenal.Away.Drawn != 1 {
+		t.Errorf("home = %+v, away = %+v", arsenal.Home, arsenal.Away)
+	}
+	if last := arsenal.Overall.LastMatch; last == nil || !last.Equal(date("1993-01-09")) {
+		t.Errorf("last match = %v, want the last completed one", last)
+	}
+	// Statistics count even before a result is recorded
+	if arsenal.YellowCards != 2 || arsenal.Shots != 9 || aggregates[1].YellowCards != 1 {
+		t.Errorf("cards and shots = %+v / %+v", arsenal, aggregates[1])
+	}
+}
+
+func TestMemoryTeamSeasonAggregatesUnknownTeam(t *testing.T) {
+	m, home, _ := seedMeetings()
+	m.AddMatch(models.Match{SeasonID: 1, HomeTeamID: home, AwayTeamID: 999, MatchDate: date("1993-05-15"),
+		HomeScore: intPtr(1), AwayScore: intPtr(0)})
+
+	if aggregates, err := m.ListTeamSeasonAggregates(1); err == nil {
+		t.Errorf("got %+v, want an error for the unknown away team", aggregates)
+	}
+}
+
+func TestMemoryTeamAliases(t *testing.T) {
+	m, home, away := seedMeetings()
+	until := date("2004-06-21")
+	m.AddTeamAlias(models.TeamAlias{TeamID: home, Alias: "The Gunners", Kind: models.AliasOther})
+	m.AddTeamAlias(models.TeamAlias{TeamID: home, Alias: "ARS", Kind: models.AliasCode})
+	m.AddTeamAlias(models.TeamAlias{TeamID: home, Alias: "Woolwich Arsenal", Kind: models.AliasName, ValidTo: &until})
+	m.AddTeamAlias(models.TeamAlias{TeamID: away, Alias: "The Blues", Kind: models.AliasOther})
+
+	aliases, err := m.ListTeamAliases(home)
+	if err != nil {
+		t.Fatalf("ListTeamAliases: %v", err)
+	}
+	var names []string
+	for _, alias := range aliases {
+		names = append(names, alias.Alias)
+	}
+	if want := []string{"The Gunners", "ARS", "Woolwich Arsenal"}; !slices.Equal(names, want) {
+		t.Errorf("aliases = %v, want %v", names, want)
+	}
+
+	// Search finds a team by alias and says which one matched
+	results := m.searchResults("gunners")
+	if len(results) != 1 || results[0].ID != home || results[0].Extra != "The Gunners" {
+		t.Errorf("search results = %+v, want Arsenal by its alias", results)
+	}
+	if count, _ := m.CountSearch("the "); count != 2 {
+		t.Errorf("CountSearch = %d, want both teams", count)
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"strconv"
+
+	"github.com/premstats/api/internal/database"
+)
+
+// Postgres implements every repository on the PostgreSQL schema
+type Postgres struct {
+	db *database.DB
+}
+
+// NewPostgres creates repositories backed by a database connection
+func NewPostgres(db *database.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// queryArgs collects positional query arguments
+type queryArgs []interface{}
+
+// add appends a value and returns its placeholder
+func (a *queryArgs) add(value interface{}) string {
+	*a = append(*a, value)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// seasonRange returns conditions keeping rows whose season, in column, starts
+// between the from and to seasons inclusive; zero IDs leave a bound open.
+// Season IDs need not be chronological, so the bounds compare start dates.
+func seasonRange(column string, args *queryArgs, fromSeasonID, toSeasonID int) string {
+	start := "(SELECT start_date FROM seasons WHERE id = " + column + ")"
+	var conditions string
+	if fromSeasonID > 0 {
+		conditions += " AND " + start + " >= (SELECT start_date FROM seasons WHERE id = " + args.add(fromSeasonID) + ")"
+	}
+	if toSeasonID > 0 {
+		conditions += " AND " + start + " <= (SELECT start_date FROM seasons WHERE id = " + args.add(toSeasonID) + ")"
+	}
+	return conditions
+}
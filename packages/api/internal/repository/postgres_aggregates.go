@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// aggregateColumns are the stored columns of team_season_aggregates that are
+// computed from matches, in table order
+var aggregateColumns = []string{
+	"played", "won", "drawn", "lost", "goals_for", "goals_against", "last_match",
+	"home_played", "home_won", "home_drawn", "home_lost", "home_goals_for", "home_goals_against", "home_last_match",
+	"away_played", "away_won", "away_drawn", "away_lost", "away_goals_for", "away_goals_against", "away_last_match",
+	"yellow_cards", "red_cards", "shots", "shots_on_target",
+}
+
+// ListTeamSeasonAggregates reads the stored season totals of every team in a season
+func (p *Postgres) ListTeamSeasonAggregates(seasonID int) ([]TeamSeasonAggregate, error) {
+	query := `
+		SELECT a.season_id, a.team_id, t.name, a.` + strings.Join(aggregateColumns, ", a.") + `
+		FROM team_season_aggregates a
+		JOIN teams t ON a.team_id = t.id
+		WHERE a.season_id = $1
+		ORDER BY t.name ASC
+	`
+
+	rows, err := p.db.Query(query, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aggregates for season %d: %w", seasonID, err)
+	}
+	defer rows.Close()
+
+	var aggregates []TeamSeasonAggregate
+	for rows.Next() {
+		var a TeamSeasonAggregate
+		var lastMatch, homeLastMatch, awayLastMatch sql.NullTime
+		err := rows.Scan(
+			&a.SeasonID, &a.TeamID, &a.Team,
+			&a.Overall.Played, &a.Overall.Won, &a.Overall.Drawn, &a.Overall.Lost,
+			&a.Overall.GoalsFor, &a.Overall.GoalsAgainst, &lastMatch,
+			&a.Home.Played, &a.Home.Won, &a.Home.Drawn, &a.Home.Lost,
+			&a.Home.GoalsFor, &a.Home.GoalsAgainst, &homeLastMatch,
+			&a.Away.Played, &a.Away.Won, &a.Away.Drawn, &a.Away.Lost,
+			&a.Away.GoalsFor, &a.Away.GoalsAgainst, &awayLastMatch,
+			&a.YellowCards, &a.RedCards, &a.Shots, &a.ShotsOnTarget,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate row: %w", err)
+		}
+		for _, date := range []struct {
+			value  sql.NullTime
+			record *AggregateRecord
+		}{{lastMatch, &a.Overall}, {homeLastMatch, &a.Home}, {awayLastMatch, &a.Away}} {
+			if date.value.Valid {
+				t := date.value.Time
+				date.record.LastMatch = &t
+			}
+		}
+		aggregates = append(aggregates, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating aggregate rows: %w", err)
+	}
+
+	return aggregates, nil
+}
+
+// RebuildAggregates recomputes the stored totals of one season, or of every
+// season when seasonID is 0, from its matches and returns the rows written
+func (p *Postgres) RebuildAggregates(seasonID int) (int, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var args []interface{}
+	where := ""
+	if seasonID > 0 {
+		where = " WHERE season_id = $1"
+		args = append(args, seasonID)
+	}
+
+	if _, err := tx.Exec("DELETE FROM team_season_aggregates"+where, args...); err != nil {
+		return 0, fmt.Errorf("failed to clear aggregates: %w", err)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO team_season_aggregates
+		SELECT l.*, CURRENT_TIMESTAMP
+		FROM team_season_aggregates_live l`+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rebuild aggregates: %w", err)
+	}
+	written, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count rebuilt aggregates: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit aggregates: %w", err)
+	}
+	return int(written), nil
+}
+
+// CheckAggregates compares the stored totals of one season, or of every
+// season when seasonID is 0, with totals computed afresh from the matches
+func (p *Postgres) CheckAggregates(seasonID int) ([]AggregateMismatch, error) {
+	var differs []string
+	for _, column := range aggregateColumns {
+		differs = append(differs, fmt.Sprintf("CASE WHEN a.%[1]s IS DISTINCT FROM l.%[1]s THEN '%[1]s' END", column))
+	}
+
+	var args []interface{}
+	where := ""
+	if seasonID > 0 {
+		where = " WHERE COALESCE(a.season_id, l.season_id) = $1"
+		args = append(args, seasonID)
+	}
+
+	query := `
+		SELECT mismatches.* FROM (
+			SELECT COALESCE(a.season_id, l.season_id) as season_id,
+				COALESCE(a.team_id, l.team_id) as team_id,
+				COALESCE(t.name, '') as team_name,
+				a.team_id IS NULL as missing,
+				l.team_id IS NULL as orphaned,
+				ARRAY_REMOVE(ARRAY[` + strings.Join(differs, ",\n\t\t\t\t\t") + `], NULL) as columns
+			FROM team_season_aggregates a
+			FULL OUTER JOIN team_season_aggregates_live l
+				ON a.season_id = l.season_id AND a.team_id = l.team_id
+			LEFT JOIN teams t ON t.id = COALESCE(a.team_id, l.team_id)` + where + `
+		) mismatches
+		WHERE mismatches.missing OR mismatches.orphaned OR cardinality(mismatches.columns) > 0
+		ORDER BY mismatches.season_id ASC, mismatches.team_name ASC
+	`
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	var mismatches []AggregateMismatch
+	for rows.Next() {
+		var m AggregateMismatch
+		if err := rows.Scan(&m.SeasonID, &m.TeamID, &m.Team, &m.Missing, &m.Orphaned, pq.Array(&m.Columns)); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate mismatch: %w", err)
+		}
+		// A missing or orphaned row differs in every column
+		if m.Missing || m.Orphaned {
+			m.Columns = nil
+		}
+		mismatches = append(mismatches, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating aggregate mismatches: %w", err)
+	}
+
+	return mismatches, nil
+}
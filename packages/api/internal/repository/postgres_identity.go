@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/models"
+)
+
+// ListRejectedPlayerPairs returns the pairs reviewed as different people
+func (p *Postgres) ListRejectedPlayerPairs() ([][2]int, error) {
+	rows, err := p.db.Query(`
+		SELECT player_id, duplicate_id
+		FROM player_match_reviews
+		WHERE decision = 'rejected'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rejected player pairs: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs [][2]int
+	for rows.Next() {
+		var pair [2]int
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, fmt.Errorf("failed to scan player pair: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player pair rows: %w", err)
+	}
+
+	return pairs, nil
+}
+
+// RejectPlayerPair records that two players are different people
+func (p *Postgres) RejectPlayerPair(playerID, duplicateID int, confidence float64) error {
+	if playerID > duplicateID {
+		playerID, duplicateID = duplicateID, playerID
+	}
+
+	_, err := p.db.Exec(`
+		INSERT INTO player_match_reviews (player_id, duplicate_id, decision, confidence)
+		VALUES ($1, $2, 'rejected', $3)
+		ON CONFLICT (player_id, duplicate_id) DO UPDATE
+		SET decision = EXCLUDED.decision, confidence = EXCLUDED.confidence, decided_at = CURRENT_TIMESTAMP
+	`, playerID, duplicateID, confidence)
+	if err != nil {
+		return fmt.Errorf("failed to reject player pair: %w", err)
+	}
+	return nil
+}
+
+// MergePlayers moves a duplicate's goals, events, season lines, squads,
+// lineups and spells onto the kept player in one transaction, deletes the
+// duplicate and notifies every listening cache
+func (p *Postgres) MergePlayers(playerID, duplicateID int, confidence float64) (models.PlayerMergeResult, error) {
+	result := models.PlayerMergeResult{PlayerID: playerID, MergedID: duplicateID}
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock both players so a concurrent merge cannot move rows onto either
+	rows, err := tx.Query("SELECT id FROM players WHERE id IN ($1, $2) FOR UPDATE", playerID, duplicateID)
+	if err != nil {
+		return result, fmt.Errorf("failed to lock players: %w", err)
+	}
+	found := map[int]bool{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return result, fmt.Errorf("failed to scan player: %w", err)
+		}
+		found[id] = true
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return result, fmt.Errorf("error iterating player rows: %w", err)
+	}
+	for _, id := range []int{playerID, duplicateID} {
+		if !found[id] {
+			return result, apperrors.NotFound("player with ID %d not found", id)
+		}
+	}
+
+	// Season lines both players have for the same team and source are added
+	// together before the rest are moved, as (player, season, team, source)
+	// is unique
+	merged, err := execCount(tx, `
+		UPDATE player_stats k
+		SET appearances = k.appearances + d.appearances,
+		    goals = k.goals + d.goals,
+		    assists = k.assists + d.assists,
+		    yellow_cards = k.yellow_cards + d.yellow_cards,
+		    red_cards = k.red_cards + d.red_cards,
+		    penalties = COALESCE(k.penalties, 0) + COALESCE(d.penalties, 0),
+		    own_goals = COALESCE(k.own_goals, 0) + COALESCE(d.own_goals, 0),
+		    minutes = CASE WHEN k.minutes IS NULL AND d.minutes IS NULL THEN NULL
+		                   ELSE COALESCE(k.minutes, 0) + COALESCE(d.minutes, 0) END,
+		    updated_at = CURRENT_TIMESTAMP
+		FROM player_stats d
+		WHERE k.player_id = $1 AND d.player_id = $2
+		  AND k.season_id IS NOT DISTINCT FROM d.season_id
+		  AND k.team_id IS NOT DISTINCT FROM d.team_id
+		  AND k.source = d.source
+	`, playerID, duplicateID)
+	if err != nil {
+		return result, fmt.Errorf("failed to merge player stats: %w", err)
+	}
+
+	statements := []struct {
+		name  string
+		query string
+		count *int
+	}{
+		{"player stats", `
+			DELETE FROM player_stats d
+			USING player_stats k
+			WHERE k.player_id = $1 AND d.player_id = $2
+			  AND k.season_id IS NOT DISTINCT FROM d.season_id
+			  AND k.team_id IS NOT DISTINCT FROM d.team_id
+			  AND k.source = d.source
+		`, nil},
+		{"player stats", "UPDATE player_stats SET player_id = $1, updated_at = CURRENT_TIMESTAMP WHERE player_id = $2", &result.PlayerStats},
+		{"goals", "UPDATE goals SET player_id = $1 WHERE player_id = $2", &result.Goals},
+		{"match events", "UPDATE match_events SET player_id = $1 WHERE player_id = $2", &result.MatchEvents},
+		{"lineups", "UPDATE match_lineups SET player_id = $1 WHERE player_id = $2", &result.Lineups},
+		{"spells", "UPDATE player_spells SET player_id = $1 WHERE player_id = $2", &result.Spells},
+		// A squad the kept player is already in needs no second row
+		{"squad memberships", `
+			DELETE FROM squad_memberships d
+			USING squad_memberships k
+			WHERE k.player_id = $1 AND d.player_id = $2
+			  AND k.team_id = d.team_id AND k.season_id = d.season_id
+		`, nil},
+		{"squad memberships", "UPDATE squad_memberships SET player_id = $1, updated_at = CURRENT_TIMESTAMP WHERE player_id = $2", &result.SquadMemberships},
+		// Fill in what only the duplicate knew
+		{"player", `
+			UPDATE players k
+			SET date_of_birth = COALESCE(k.date_of_birth, d.date_of_birth),
+			    nationality = COALESCE(NULLIF(k.nationality, ''), d.nationality),
+			    position = COALESCE(NULLIF(k.position, ''), d.position),
+			    current_team_id = COALESCE(k.current_team_id, d.current_team_id),
+			    updated_at = CURRENT_TIMESTAMP
+			FROM players d
+			WHERE k.id = $1 AND d.id = $2
+		`, nil},
+	}
+	for _, statement := range statements {
+		n, err := execCount(tx, statement.query, playerID, duplicateID)
+		if err != nil {
+			return result, fmt.Errorf("failed to move %s: %w", statement.name, err)
+		}
+		if statement.count != nil {
+			*statement.count = n
+		}
+	}
+	result.PlayerStats += merged
+
+	// The transfermarkt ID is unique, so it moves only once the duplicate is gone
+	var transfermarktID sql.NullInt64
+	err = tx.QueryRow("DELETE FROM players WHERE id = $1 RETURNING transfermarkt_id", duplicateID).Scan(&transfermarktID)
+	if err != nil {
+		return result, fmt.Errorf("failed to delete merged player: %w", err)
+	}
+	if transfermarktID.Valid {
+		_, err = tx.Exec("UPDATE players SET transfermarkt_id = COALESCE(transfermarkt_id, $2) WHERE id = $1", playerID, transfermarktID.Int64)
+		if err != nil {
+			return result, fmt.Errorf("failed to move transfermarkt ID: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO player_match_reviews (player_id, duplicate_id, decision, confidence)
+		VALUES ($1, $2, 'merged', $3)
+		ON CONFLICT (player_id, duplicate_id) DO UPDATE
+		SET decision = EXCLUDED.decision, confidence = EXCLUDED.confidence, decided_at = CURRENT_TIMESTAMP
+	`, playerID, duplicateID, confidence)
+	if err != nil {
+		return result, fmt.Errorf("failed to record merge: %w", err)
+	}
+
+	// Other API processes drop their caches once the merge commits, as they
+	// do after an import
+	if err := cache.Notify(tx, cache.AllSeasons); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return result, fmt.Errorf("failed to commit merge: %w", err)
+	}
+	return result, nil
+}
+
+// execCount runs a statement in a transaction and returns the rows it changed
+func execCount(tx *sql.Tx, query string, args ...interface{}) (int, error) {
+	res, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+)
+
+// matchColumns are the columns read by scanMatch
+const matchColumns = `
+			m.id, m.season_id, m.home_team_id, m.away_team_id,
+			ht.name as home_team, at.name as away_team,
+			m.home_score, m.away_score, m.half_time_home, m.half_time_away,
+			m.match_date, m.referee`
+
+// ListMatches retrieves matches matching a filter
+func (p *Postgres) ListMatches(filter MatchFilter) ([]models.Match, error) {
+	var args queryArgs
+	query := `
+		SELECT ` + matchColumns + `
+		FROM matches m
+		JOIN teams ht ON m.home_team_id = ht.id
+		JOIN teams at ON m.away_team_id = at.id
+		WHERE 1=1` + matchConditions(filter, &args)
+
+	// Sorting by goals or margin puts that value ahead of the date in the key
+	value, sorted := matchSortExpressions[filter.Sort]
+	_, order := seekDirection(filter.Ascending, false)
+	if filter.Seek != nil {
+		var op string
+		op, order = seekDirection(filter.Ascending, filter.Seek.Backward)
+		if sorted {
+			query += " AND (" + value + ", m.match_date, m.id) " + op + " (" + args.add(filter.Seek.Key.Value) + ", " + args.add(filter.Seek.Key.Date) + ", " + args.add(filter.Seek.Key.ID) + ")"
+		} else {
+			query += " AND (m.match_date, m.id) " + op + " (" + args.add(filter.Seek.Key.Date) + ", " + args.add(filter.Seek.Key.ID) + ")"
+		}
+	}
+	query += " ORDER BY "
+	if sorted {
+		query += value + " " + order + ", "
+	}
+	query += "m.match_date " + order + ", m.id " + order
+
+	if filter.Limit > 0 {
+		query += " LIMIT " + args.add(filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		query += " OFFSET " + args.add(filter.Offset)
+	}
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.Match
+	for rows.Next() {
+		match, err := scanMatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match row: %w", err)
+		}
+		matches = append(matches, *match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating match rows: %w", err)
+	}
+
+	if filter.Seek != nil && filter.Seek.Backward {
+		slices.Reverse(matches)
+	}
+	return matches, nil
+}
+
+// CountMatches returns the number of matches matching a filter
+func (p *Postgres) CountMatches(filter MatchFilter) (int, error) {
+	var args queryArgs
+	query := "SELECT COUNT(*) FROM matches m WHERE 1=1" + matchConditions(filter, &args)
+
+	var count int
+	if err := p.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count matches: %w", err)
+	}
+
+	return count, nil
+}
+
+// matchSortExpressions compute the value matches are sorted by ahead of their
+// date, matching matchSortValue
+var matchSortExpressions = map[string]string{
+	MatchSortGoals:  "COALESCE(m.home_score + m.away_score, -1)",
+	MatchSortMargin: "COALESCE(ABS(m.home_score - m.away_score), -1)",
+}
+
+// matchConditions builds the WHERE conditions shared by ListMatches and CountMatches
+func matchConditions(filter MatchFilter, args *queryArgs) string {
+	var query string
+
+	if filter.SeasonID > 0 {
+		query += " AND m.season_id = " + args.add(filter.SeasonID)
+	}
+
+	// Scores are read from TeamID's side when it is set, otherwise home first
+	goalsFor, goalsAgainst := "m.home_score", "m.away_score"
+	htFor, htAgainst := "m.half_time_home", "m.half_time_away"
+
+	if filter.TeamID > 0 {
+		team := args.add(filter.TeamID)
+		goalsFor = "CASE WHEN m.home_team_id = " + team + " THEN m.home_score ELSE m.away_score END"
+		goalsAgainst = "CASE WHEN m.home_team_id = " + team + " THEN m.away_score ELSE m.home_score END"
+		htFor = "CASE WHEN m.home_team_id = " + team + " THEN m.half_time_home ELSE m.half_time_away END"
+		htAgainst = "CASE WHEN m.home_team_id = " + team + " THEN m.half_time_away ELSE m.half_time_home END"
+		if filter.OpponentID > 0 {
+			opponent := args.add(filter.OpponentID)
+			switch filter.Venue {
+			case "home":
+				query += " AND m.home_team_id = " + team + " AND m.away_team_id = " + opponent
+			case "away":
+				query += " AND m.home_team_id = " + opponent + " AND m.away_team_id = " + team
+			default:
+				query += " AND ((m.home_team_id = " + team + " AND m.away_team_id = " + opponent + ") OR (m.home_team_id = " + opponent + " AND m.away_team_id = " + team + "))"
+			}
+		} else {
+			switch filter.Venue {
+			case "home":
+				query += " AND m.home_team_id = " + team
+			case "away":
+				query += " AND m.away_team_id = " + team
+			default:
+				query += " AND (m.home_team_id = " + team + " OR m.away_team_id = " + team + ")"
+			}
+		}
+	}
+
+	query += seasonRange("m.season_id", args, filter.FromSeasonID, filter.ToSeasonID)
+
+	if filter.FromDate != nil {
+		query += " AND m.match_date >= " + args.add(*filter.FromDate)
+	}
+
+	if filter.ToDate != nil {
+		query += " AND m.match_date < " + args.add(filter.ToDate.AddDate(0, 0, 1))
+	}
+
+	if filter.Completed {
+		query += " AND m.home_score IS NOT NULL AND m.away_score IS NOT NULL"
+	}
+
+	if filter.Referee != "" {
+		query += " AND m.referee ILIKE '%' || " + args.add(filter.Referee) + " || '%'"
+	}
+
+	// Comparisons with a missing score are never true, so these criteria
+	// also leave out matches without a result
+	switch filter.Result {
+	case ResultWin:
+		query += " AND " + goalsFor + " > " + goalsAgainst
+	case ResultDraw:
+		query += " AND m.home_score = m.away_score"
+	case ResultLoss:
+		query += " AND " + goalsFor + " < " + goalsAgainst
+	}
+
+	if filter.GoalsFor != nil {
+		query += " AND " + goalsFor + " = " + args.add(*filter.GoalsFor)
+	}
+
+	if filter.GoalsAgainst != nil {
+		query += " AND " + goalsAgainst + " = " + args.add(*filter.GoalsAgainst)
+	}
+
+	if filter.MinGoals > 0 {
+		query += " AND m.home_score + m.away_score >= " + args.add(filter.MinGoals)
+	}
+
+	if filter.Comeback {
+		comeback := "(" + htFor + " < " + htAgainst + " AND " + goalsFor + " > " + goalsAgainst + ")"
+		if filter.TeamID == 0 {
+			comeback += " OR (m.half_time_home > m.half_time_away AND m.home_score < m.away_score)"
+		}
+		query += " AND (" + comeback + ")"
+	}
+
+	return query
+}
+
+// GetMatch retrieves a specific match by ID, including its statistics
+func (p *Postgres) GetMatch(matchID int) (*models.Match, error) {
+	query := `
+		SELECT ` + matchColumns + `,
+			m.home_shots, m.away_shots, m.home_shots_on_target, m.away_shots_on_target,
+			m.home_corners, m.away_corners, m.home_fouls, m.away_fouls,
+			m.home_yellow_cards, m.away_yellow_cards, m.home_red_cards, m.away_red_cards
+		FROM matches m
+		JOIN teams ht ON m.home_team_id = ht.id
+		JOIN teams at ON m.away_team_id = at.id
+		WHERE m.id = $1
+	`
+
+	var stats [12]sql.NullInt32
+	var statArgs []interface{}
+	for i := range stats {
+		statArgs = append(statArgs, &stats[i])
+	}
+
+	match, err := scanMatch(p.db.QueryRow(query, matchID), statArgs...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.NotFound("match with ID %d not found", matchID)
+		}
+		return nil, fmt.Errorf("failed to query match: %w", err)
+	}
+
+	targets := []**int{
+		&match.HomeShots, &match.AwayShots, &match.HomeShotsOnTarget, &match.AwayShotsOnTarget,
+		&match.HomeCorners, &match.AwayCorners, &match.HomeFouls, &match.AwayFouls,
+		&match.HomeYellowCards, &match.AwayYellowCards, &match.HomeRedCards, &match.AwayRedCards,
+	}
+	for i, target := range targets {
+		*target = nullableInt(stats[i])
+	}
+
+	return match, nil
+}
+
+// scanMatch reads the matchColumns of a row, followed by any extra columns
+func scanMatch(row rowScanner, extra ...interface{}) (*models.Match, error) {
+	var match models.Match
+	var homeScore, awayScore, halftimeHome, halftimeAway sql.NullInt32
+	var referee sql.NullString
+
+	dest := []interface{}{
+		&match.ID, &match.SeasonID, &match.HomeTeamID, &match.AwayTeamID,
+		&match.HomeTeam, &match.AwayTeam,
+		&homeScore, &awayScore, &halftimeHome, &halftimeAway,
+		&match.MatchDate, &referee,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	match.HomeScore = nullableInt(homeScore)
+	match.AwayScore = nullableInt(awayScore)
+	match.HalfTimeHome = nullableInt(halftimeHome)
+	match.HalfTimeAway = nullableInt(halftimeAway)
+	if referee.Valid {
+		match.Referee = referee.String
+	}
+
+	return &match, nil
+}
+
+// nullableInt converts a nullable column to an optional int
+func nullableInt(value sql.NullInt32) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int32)
+	return &v
+}
+
+// ListMatchEvents returns a match's goals followed by its other events
+func (p *Postgres) ListMatchEvents(matchID int) ([]models.MatchEvent, error) {
+	// Goals are deduplicated by minute, player and team
+	goalQuery := `
+		SELECT MIN(g.id) as id, g.match_id, 'goal' as event_type, g.minute,
+			   g.player_id, COALESCE(p.name, g.source_player_name) as player_name, g.team_id, 
+			   CASE WHEN bool_or(g.is_penalty) THEN 'Penalty' ELSE NULL END as detail
+		FROM goals g
+		LEFT JOIN players p ON g.player_id = p.id
+		WHERE g.match_id = $1
+		GROUP BY g.match_id, g.minute, g.player_id, COALESCE(p.name, g.source_player_name), g.team_id
+		ORDER BY g.minute, MIN(g.id)
+	`
+
+	events, err := p.queryMatchEvents(goalQuery, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query goals: %w", err)
+	}
+
+	eventQuery := `
+		SELECT me.id, me.match_id, me.event_type, me.minute, 
+			   me.player_id, COALESCE(p.name, me.source_player_name) as player_name, me.team_id, me.detail
+		FROM match_events me
+		LEFT JOIN players p ON me.player_id = p.id
+		WHERE me.match_id = $1
+		ORDER BY me.minute, me.id
+	`
+
+	others, err := p.queryMatchEvents(eventQuery, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query match events: %w", err)
+	}
+
+	return append(events, others...), nil
+}
+
+// queryMatchEvents runs an event query and scans its rows
+func (p *Postgres) queryMatchEvents(query string, matchID int) ([]models.MatchEvent, error) {
+	rows, err := p.db.Query(query, matchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.MatchEvent
+	for rows.Next() {
+		var event models.MatchEvent
+		var playerID sql.NullInt32
+		var playerName sql.NullString
+		var detail sql.NullString
+
+		err := rows.Scan(
+			&event.ID,
+			&event.MatchID,
+			&event.EventType,
+			&event.Minute,
+			&playerID,
+			&playerName,
+			&event.TeamID,
+			&detail,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match event: %w", err)
+		}
+
+		if playerID.Valid {
+			event.PlayerID = int(playerID.Int32)
+		}
+		if playerName.Valid {
+			event.PlayerName = playerName.String
+		}
+		if detail.Valid {
+			event.Detail = detail.String
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// ListResults loads every completed match of a season in date order
+func (p *Postgres) ListResults(seasonID int) ([]Result, error) {
+	rows, err := p.db.Query(`
+		SELECT id, season_id, match_date, home_team_id, away_team_id, home_score, away_score
+		FROM matches
+		WHERE season_id = $1 AND home_score IS NOT NULL AND away_score IS NOT NULL
+		ORDER BY match_date ASC, id ASC
+	`, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results for season %d: %w", seasonID, err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		if err := rows.Scan(&r.MatchID, &r.SeasonID, &r.Date, &r.HomeTeamID, &r.AwayTeamID, &r.HomeScore, &r.AwayScore); err != nil {
+			return nil, fmt.Errorf("failed to scan match result: %w", err)
+		}
+		results = append(results, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating match results: %w", err)
+	}
+
+	return results, nil
+}
+
+// ListTeamDiscipline totals cards and fouls per team for a season, counting
+// only matches where card statistics were recorded
+func (p *Postgres) ListTeamDiscipline(seasonID int) ([]models.TeamDiscipline, error) {
+	query := `
+		WITH sides AS (
+			SELECT home_team_id as team_id, home_yellow_cards as yellow_cards,
+				home_red_cards as red_cards, home_fouls as fouls
+			FROM matches
+			WHERE season_id = $1 AND home_yellow_cards IS NOT NULL
+			UNION ALL
+			SELECT away_team_id, away_yellow_cards, away_red_cards, away_fouls
+			FROM matches
+			WHERE season_id = $1 AND away_yellow_cards IS NOT NULL
+		)
+		SELECT t.id, t.name, COUNT(*) as matches,
+			COALESCE(SUM(sd.yellow_cards), 0) as yellow_cards,
+			COALESCE(SUM(sd.red_cards), 0) as red_cards,
+			COALESCE(SUM(sd.fouls), 0) as fouls
+		FROM sides sd
+		JOIN teams t ON sd.team_id = t.id
+		GROUP BY t.id, t.name
+		ORDER BY t.name ASC
+	`
+
+	rows, err := p.db.Query(query, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query discipline for season %d: %w", seasonID, err)
+	}
+	defer rows.Close()
+
+	var totals []models.TeamDiscipline
+	for rows.Next() {
+		var d models.TeamDiscipline
+		if err := rows.Scan(&d.TeamID, &d.Team, &d.Matches, &d.YellowCards, &d.RedCards, &d.Fouls); err != nil {
+			return nil, fmt.Errorf("failed to scan discipline row: %w", err)
+		}
+		totals = append(totals, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating discipline rows: %w", err)
+	}
+
+	return totals, nil
+}
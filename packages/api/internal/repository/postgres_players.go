@@ -0,0 +1,571 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+)
+
+// ListPlayers returns the players matching a filter, ordered by name
+func (p *Postgres) ListPlayers(filter PlayerFilter) ([]models.Player, error) {
+	from, where, teamColumn, args := playerFilters(filter)
+
+	query := `
+		SELECT DISTINCT p.id, p.name, p.date_of_birth, p.nationality, p.position, 
+		       ` + teamColumn + `, t.name as team_name
+		` + from + `
+		LEFT JOIN teams t ON ` + teamColumn + ` = t.id
+		WHERE 1=1` + where
+	argIndex := len(args) + 1
+
+	order := "ASC"
+	if filter.Seek != nil {
+		var op string
+		op, order = seekDirection(true, filter.Seek.Backward)
+		query += fmt.Sprintf(" AND (p.name, p.id) %s ($%d, $%d)", op, argIndex, argIndex+1)
+		args = append(args, filter.Seek.Key.Name, filter.Seek.Key.ID)
+		argIndex += 2
+	}
+	query += " ORDER BY p.name " + order + ", p.id " + order
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, filter.Limit)
+		argIndex++
+	}
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, filter.Offset)
+	}
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query players: %w", err)
+	}
+	defer rows.Close()
+
+	var players []models.Player
+	for rows.Next() {
+		var teamID sql.NullInt32
+		var teamName sql.NullString
+		player, err := scanPlayer(rows, &teamID, &teamName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan player: %w", err)
+		}
+
+		if teamID.Valid {
+			player.TeamID = int(teamID.Int32)
+		}
+		if teamName.Valid {
+			player.Team = teamName.String
+		}
+
+		players = append(players, *player)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player rows: %w", err)
+	}
+
+	if filter.Seek != nil && filter.Seek.Backward {
+		slices.Reverse(players)
+	}
+	return players, nil
+}
+
+// CountPlayers returns the number of players matching a filter
+func (p *Postgres) CountPlayers(filter PlayerFilter) (int, error) {
+	from, where, _, args := playerFilters(filter)
+	query := "SELECT COUNT(DISTINCT p.id) " + from + " WHERE 1=1" + where
+
+	var count int
+	if err := p.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count players: %w", err)
+	}
+
+	return count, nil
+}
+
+// playerFilters builds the FROM clause, WHERE conditions, team column and
+// arguments shared by ListPlayers and CountPlayers
+func playerFilters(filter PlayerFilter) (string, string, string, []interface{}) {
+	from := "FROM players p"
+	teamColumn := "p.current_team_id"
+	where := ""
+	args := []interface{}{}
+	argIndex := 1
+
+	// Restrict to a season's squads. A player who moved mid-season is listed
+	// once, at the club they joined last, or at the filtered team, ordered as
+	// updateCurrentTeams orders squads. Squads without a join date count as
+	// the earlier club, and insert order only breaks ties, since squad files
+	// are not imported in date order.
+	if filter.SeasonID > 0 {
+		squad := fmt.Sprintf("sm.player_id = p.id AND sm.season_id = $%d", argIndex)
+		args = append(args, filter.SeasonID)
+		argIndex++
+		if filter.TeamID > 0 {
+			squad += fmt.Sprintf(" AND sm.team_id = $%d", argIndex)
+			args = append(args, filter.TeamID)
+			argIndex++
+		}
+		from += " JOIN LATERAL (SELECT sm.team_id FROM squad_memberships sm JOIN seasons s ON sm.season_id = s.id WHERE " + squad +
+			" ORDER BY s.start_date DESC, sm.joined_on DESC NULLS LAST, sm.id DESC LIMIT 1) sm ON true"
+		teamColumn = "sm.team_id"
+	}
+
+	if filter.Search != "" {
+		where += fmt.Sprintf(" AND p.name ILIKE $%d", argIndex)
+		args = append(args, "%"+filter.Search+"%")
+		argIndex++
+	}
+
+	if filter.Position != "" {
+		where += fmt.Sprintf(" AND p.position = $%d", argIndex)
+		args = append(args, filter.Position)
+		argIndex++
+	}
+
+	if filter.Nationality != "" {
+		where += fmt.Sprintf(" AND p.nationality = $%d", argIndex)
+		args = append(args, filter.Nationality)
+		argIndex++
+	}
+
+	if filter.TeamID > 0 && filter.SeasonID == 0 {
+		where += fmt.Sprintf(" AND %s = $%d", teamColumn, argIndex)
+		args = append(args, filter.TeamID)
+	}
+
+	return from, where, teamColumn, args
+}
+
+// GetPlayer returns a single player by ID
+func (p *Postgres) GetPlayer(playerID int) (*models.Player, error) {
+	query := `
+		SELECT id, name, date_of_birth, nationality, position
+		FROM players
+		WHERE id = $1
+	`
+
+	player, err := scanPlayer(p.db.QueryRow(query, playerID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.NotFound("player with ID %d not found", playerID)
+		}
+		return nil, fmt.Errorf("failed to get player: %w", err)
+	}
+
+	return player, nil
+}
+
+// scanPlayer reads id, name, date of birth, nationality and position,
+// followed by any extra columns
+func scanPlayer(row rowScanner, extra ...interface{}) (*models.Player, error) {
+	var player models.Player
+	var dateOfBirth sql.NullString
+	var nationality sql.NullString
+	var position sql.NullString
+
+	dest := []interface{}{&player.ID, &player.Name, &dateOfBirth, &nationality, &position}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	if dateOfBirth.Valid {
+		player.DateOfBirth = dateOfBirth.String
+	}
+	if nationality.Valid {
+		player.Nationality = nationality.String
+	}
+	if position.Valid {
+		player.Position = position.String
+	}
+
+	return &player, nil
+}
+
+// ListPlayerStats returns a player's season lines, most recent first,
+// restricted to one season when seasonID is set
+func (p *Postgres) ListPlayerStats(playerID, seasonID int) ([]models.PlayerStats, error) {
+	query := playerStatsQuery + " WHERE ps.player_id = $1"
+	args := []interface{}{playerID}
+
+	if seasonID > 0 {
+		query += " AND ps.season_id = $2"
+		args = append(args, seasonID)
+	}
+
+	query += " ORDER BY s.start_date DESC NULLS LAST, s.id DESC, ps.id DESC"
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query player stats: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPlayerStats(rows)
+}
+
+// playerStatsQuery selects season lines, one per player, season and team,
+// with their player, team and season names
+var playerStatsQuery = playerStatsFrom("player_season_stats")
+
+// playerStatsFrom selects season lines from a table or view with the columns
+// of player_stats
+func playerStatsFrom(table string) string {
+	return `
+	SELECT ps.id, ps.player_id, ps.season_id, ps.team_id, ps.appearances,
+	       ps.goals, ps.assists, ps.yellow_cards, ps.red_cards,
+	       COALESCE(ps.penalties, 0), COALESCE(ps.own_goals, 0), ps.minutes, ps.source,
+	       p.name as player_name, t.name as team_name, s.name as season_name
+	FROM ` + table + ` ps
+	JOIN players p ON ps.player_id = p.id
+	JOIN teams t ON ps.team_id = t.id
+	JOIN seasons s ON ps.season_id = s.id`
+}
+
+// scanPlayerStats reads the rows of a playerStatsQuery
+func scanPlayerStats(rows *sql.Rows) ([]models.PlayerStats, error) {
+	var stats []models.PlayerStats
+	for rows.Next() {
+		var s models.PlayerStats
+		var minutes sql.NullInt32
+		err := rows.Scan(
+			&s.ID, &s.PlayerID, &s.SeasonID, &s.TeamID,
+			&s.Appearances, &s.Goals, &s.Assists, &s.YellowCards, &s.RedCards,
+			&s.Penalties, &s.OwnGoals, &minutes, &s.Source,
+			&s.PlayerName, &s.TeamName, &s.SeasonName,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan player stats: %w", err)
+		}
+		s.Minutes = nullableInt(minutes)
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player stats rows: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetPlayerMilestones reads a player's first and last appearances and goals.
+// Rows without a date or minute sort last both ways, so DESC does not pick a
+// goal with an unknown minute as the last.
+func (p *Postgres) GetPlayerMilestones(playerID int) (PlayerMilestones, error) {
+	appearances := `
+		SELECT m.id, m.season_id, m.match_date, a.team_id, ht.name, at.name,
+		       m.home_score, m.away_score, 0
+		FROM (
+			SELECT match_id, team_id FROM match_lineups
+			WHERE player_id = $1 AND (is_starter OR minute_on IS NOT NULL)
+			UNION
+			SELECT match_id, team_id FROM goals WHERE player_id = $1
+			UNION
+			SELECT match_id, team_id FROM match_events WHERE player_id = $1
+		) a
+		JOIN matches m ON a.match_id = m.id
+		JOIN teams ht ON m.home_team_id = ht.id
+		JOIN teams at ON m.away_team_id = at.id
+		ORDER BY m.match_date %[1]s NULLS LAST, m.id %[1]s
+		LIMIT 1
+	`
+	goals := `
+		SELECT m.id, m.season_id, m.match_date, g.team_id, ht.name, at.name,
+		       m.home_score, m.away_score, g.minute
+		FROM goals g
+		JOIN matches m ON g.match_id = m.id
+		JOIN teams ht ON m.home_team_id = ht.id
+		JOIN teams at ON m.away_team_id = at.id
+		WHERE g.player_id = $1 AND NOT COALESCE(g.is_own_goal, FALSE)
+		ORDER BY m.match_date %[1]s NULLS LAST, m.id %[1]s, g.minute %[1]s NULLS LAST, g.id %[1]s
+		LIMIT 1
+	`
+
+	var milestones PlayerMilestones
+	for _, milestone := range []struct {
+		name   string
+		query  string
+		order  string
+		target **models.CareerMatch
+	}{
+		{"debut", appearances, "ASC", &milestones.Debut},
+		{"last appearance", appearances, "DESC", &milestones.LastAppearance},
+		{"first goal", goals, "ASC", &milestones.FirstGoal},
+		{"last goal", goals, "DESC", &milestones.LastGoal},
+	} {
+		var match models.CareerMatch
+		var teamID sql.NullInt32
+		var homeScore, awayScore sql.NullInt32
+		err := p.db.QueryRow(fmt.Sprintf(milestone.query, milestone.order), playerID).Scan(
+			&match.MatchID, &match.SeasonID, &match.Date, &teamID, &match.HomeTeam, &match.AwayTeam,
+			&homeScore, &awayScore, &match.Minute,
+		)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return milestones, fmt.Errorf("failed to get %s of player %d: %w", milestone.name, playerID, err)
+		}
+		match.TeamID = int(teamID.Int32)
+		match.HomeScore = nullableInt(homeScore)
+		match.AwayScore = nullableInt(awayScore)
+		*milestone.target = &match
+	}
+
+	return milestones, nil
+}
+
+// ListTopScorers returns a season's scorers ordered by goals then assists
+func (p *Postgres) ListTopScorers(seasonID, limit int) ([]models.TopScorer, error) {
+	query := `
+		SELECT ps.player_id, p.name as player_name, ps.team_id, t.name as team_name,
+		       ps.goals, ps.assists, ps.appearances, p.nationality, p.position
+		FROM player_season_stats ps
+		JOIN players p ON ps.player_id = p.id
+		JOIN teams t ON ps.team_id = t.id
+		WHERE ps.season_id = $1 AND ps.goals > 0
+		ORDER BY ps.goals DESC, ps.assists DESC
+		LIMIT $2
+	`
+
+	rows, err := p.db.Query(query, seasonID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top scorers: %w", err)
+	}
+	defer rows.Close()
+
+	var scorers []models.TopScorer
+	for rows.Next() {
+		var ts models.TopScorer
+		var nationality sql.NullString
+		var position sql.NullString
+
+		err := rows.Scan(
+			&ts.PlayerID, &ts.PlayerName, &ts.TeamID, &ts.TeamName,
+			&ts.Goals, &ts.Assists, &ts.Appearances, &nationality, &position,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan top scorer: %w", err)
+		}
+
+		if nationality.Valid {
+			ts.Nationality = nationality.String
+		}
+		if position.Valid {
+			ts.Position = position.String
+		}
+
+		scorers = append(scorers, ts)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating top scorer rows: %w", err)
+	}
+
+	return scorers, nil
+}
+
+// ListPlayerTotals adds up each player's season lines within a filter
+func (p *Postgres) ListPlayerTotals(filter PlayerTotalsFilter) ([]PlayerTotals, error) {
+	query := `
+		SELECT ps.player_id, p.name,
+		       CASE WHEN COUNT(DISTINCT ps.team_id) = 1 THEN MIN(ps.team_id) END,
+		       CASE WHEN COUNT(DISTINCT ps.team_id) = 1 THEN MIN(t.name) END,
+		       COUNT(DISTINCT ps.season_id),
+		       COALESCE(SUM(ps.appearances), 0), COALESCE(SUM(ps.goals), 0),
+		       COALESCE(SUM(ps.assists), 0), COALESCE(SUM(ps.penalties), 0),
+		       COALESCE(SUM(ps.yellow_cards), 0), COALESCE(SUM(ps.red_cards), 0),
+		       COALESCE(SUM(ps.minutes), 0),
+		       COALESCE(SUM(ps.goals) FILTER (WHERE ps.minutes IS NOT NULL), 0)
+		FROM player_season_stats ps
+		JOIN players p ON ps.player_id = p.id
+		LEFT JOIN teams t ON ps.team_id = t.id
+		WHERE 1=1
+	`
+
+	var args queryArgs
+	if filter.SeasonID > 0 {
+		query += " AND ps.season_id = " + args.add(filter.SeasonID)
+	}
+	query += seasonRange("ps.season_id", &args, filter.FromSeasonID, filter.ToSeasonID)
+	if filter.TeamID > 0 {
+		query += " AND ps.team_id = " + args.add(filter.TeamID)
+	}
+	query += " GROUP BY ps.player_id, p.name"
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query player totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []PlayerTotals
+	for rows.Next() {
+		var t PlayerTotals
+		var teamID sql.NullInt32
+		var teamName sql.NullString
+
+		err := rows.Scan(
+			&t.PlayerID, &t.PlayerName, &teamID, &teamName, &t.Seasons,
+			&t.Appearances, &t.Goals, &t.Assists, &t.Penalties,
+			&t.YellowCards, &t.RedCards, &t.Minutes, &t.TimedGoals,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan player totals: %w", err)
+		}
+
+		t.TeamID = int(teamID.Int32)
+		t.TeamName = teamName.String
+		totals = append(totals, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player totals rows: %w", err)
+	}
+
+	return totals, nil
+}
+
+// ListPositions returns all unique player positions
+func (p *Postgres) ListPositions() ([]string, error) {
+	values, err := p.distinctPlayerValues("position")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query positions: %w", err)
+	}
+	return values, nil
+}
+
+// ListNationalities returns all unique player nationalities
+func (p *Postgres) ListNationalities() ([]string, error) {
+	values, err := p.distinctPlayerValues("nationality")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query nationalities: %w", err)
+	}
+	return values, nil
+}
+
+// distinctPlayerValues lists the non-null values of a players column
+func (p *Postgres) distinctPlayerValues(column string) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT DISTINCT %[1]s
+		FROM players
+		WHERE %[1]s IS NOT NULL
+		ORDER BY %[1]s
+	`, column)
+
+	rows, err := p.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, rows.Err()
+}
+
+// Search finds players and then teams whose names contain the query
+func (p *Postgres) Search(filter SearchFilter) ([]models.SearchResult, error) {
+	var args queryArgs
+	term := args.add("%" + strings.TrimSpace(filter.Query) + "%")
+	searchQuery := `
+		SELECT r.type, r.id, r.name, r.subtitle, r.extra
+		FROM (
+			SELECT 'player' as type, p.id, p.name, p.position as subtitle, '' as extra
+			FROM players p
+			WHERE p.name ILIKE ` + term + `
+			UNION ALL
+			SELECT 'team' as type, t.id, t.name, t.stadium as subtitle,
+			       CASE WHEN t.name ILIKE ` + term + ` THEN '' ELSE (
+			           SELECT MIN(a.alias) FROM team_aliases a
+			           WHERE a.team_id = t.id AND a.alias ILIKE ` + term + `
+			       ) END as extra
+			FROM teams t
+			WHERE t.name ILIKE ` + term + ` OR EXISTS (
+				SELECT 1 FROM team_aliases a WHERE a.team_id = t.id AND a.alias ILIKE ` + term + `
+			)
+		) r
+		WHERE 1=1`
+
+	order := "ASC"
+	if filter.Seek != nil {
+		var op string
+		op, order = seekDirection(true, filter.Seek.Backward)
+		key := filter.Seek.Key
+		searchQuery += " AND (r.type, r.name, r.id) " + op + " (" + args.add(key.Type) + ", " + args.add(key.Name) + ", " + args.add(key.ID) + ")"
+	}
+	searchQuery += " ORDER BY r.type " + order + ", r.name " + order + ", r.id " + order
+
+	if filter.Limit > 0 {
+		searchQuery += " LIMIT " + args.add(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		searchQuery += " OFFSET " + args.add(filter.Offset)
+	}
+
+	rows, err := p.db.Query(searchQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		var r models.SearchResult
+		var subtitle sql.NullString
+		var extra sql.NullString
+
+		if err := rows.Scan(&r.Type, &r.ID, &r.Name, &subtitle, &extra); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+
+		if subtitle.Valid {
+			r.Subtitle = subtitle.String
+		}
+		if extra.Valid {
+			r.Extra = extra.String
+		}
+
+		results = append(results, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	if filter.Seek != nil && filter.Seek.Backward {
+		slices.Reverse(results)
+	}
+	return results, nil
+}
+
+// CountSearch returns the number of players and teams whose names, or team
+// aliases, contain the query
+func (p *Postgres) CountSearch(query string) (int, error) {
+	var count int
+	err := p.db.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM players WHERE name ILIKE $1) +
+		       (SELECT COUNT(*) FROM teams t WHERE t.name ILIKE $1 OR EXISTS (
+		           SELECT 1 FROM team_aliases a WHERE a.team_id = t.id AND a.alias ILIKE $1
+		       ))
+	`, "%"+strings.TrimSpace(query)+"%").Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	return count, nil
+}
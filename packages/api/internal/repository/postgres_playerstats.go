@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// ListPlayerAppearances reads what each player did in each match. A player
+// with a lineup entry takes that entry's side; otherwise the side comes from
+// their goals, with own goals counting for the opposition, then their events.
+func (p *Postgres) ListPlayerAppearances(seasonID int) ([]PlayerAppearance, error) {
+	var args []interface{}
+	where := ""
+	if seasonID > 0 {
+		where = " WHERE m.season_id = $1"
+		args = append(args, seasonID)
+	}
+
+	query := `
+		WITH involvement AS (
+			SELECT DISTINCT ON (player_id, match_id)
+			       player_id, match_id, team_id, lineup, is_starter, minute_on
+			FROM (
+				SELECT player_id, match_id, team_id, TRUE as lineup, is_starter, minute_on, 1 as priority
+				FROM match_lineups
+				WHERE player_id IS NOT NULL AND (is_starter OR minute_on IS NOT NULL)
+				UNION ALL
+				SELECT g.player_id, g.match_id,
+				       CASE WHEN NOT COALESCE(g.is_own_goal, FALSE) THEN g.team_id
+				            WHEN g.team_id = m.home_team_id THEN m.away_team_id
+				            ELSE m.home_team_id END,
+				       FALSE, FALSE, NULL, 2
+				FROM goals g
+				JOIN matches m ON g.match_id = m.id
+				WHERE g.player_id IS NOT NULL
+				UNION ALL
+				SELECT player_id, match_id, team_id, FALSE, FALSE, NULL, 3
+				FROM match_events
+				WHERE player_id IS NOT NULL
+			) sources
+			ORDER BY player_id, match_id, priority
+		)
+		SELECT i.player_id, p.name, i.match_id, m.season_id, s.name, i.team_id, t.name,
+		       i.lineup, i.is_starter, COALESCE(i.minute_on, 0),
+		       COALESCE(g.goals, 0), COALESCE(g.penalties, 0), COALESCE(g.own_goals, 0),
+		       COALESCE(c.yellow_cards, 0), COALESCE(c.red_cards, 0), COALESCE(c.sent_off, 0)
+		FROM involvement i
+		JOIN matches m ON i.match_id = m.id
+		JOIN players p ON i.player_id = p.id
+		JOIN teams t ON i.team_id = t.id
+		JOIN seasons s ON m.season_id = s.id
+		LEFT JOIN (
+			SELECT player_id, match_id,
+			       COUNT(*) FILTER (WHERE NOT COALESCE(is_own_goal, FALSE)) as goals,
+			       COUNT(*) FILTER (WHERE is_penalty AND NOT COALESCE(is_own_goal, FALSE)) as penalties,
+			       COUNT(*) FILTER (WHERE is_own_goal) as own_goals
+			FROM goals
+			GROUP BY player_id, match_id
+		) g ON g.player_id = i.player_id AND g.match_id = i.match_id
+		LEFT JOIN (
+			SELECT player_id, match_id,
+			       COUNT(*) FILTER (WHERE event_type = 'yellow_card') as yellow_cards,
+			       COUNT(*) FILTER (WHERE event_type = 'red_card') as red_cards,
+			       MIN(minute) FILTER (WHERE event_type = 'red_card') as sent_off
+			FROM match_events
+			GROUP BY player_id, match_id
+		) c ON c.player_id = i.player_id AND c.match_id = i.match_id` + where + `
+		ORDER BY s.start_date ASC, i.player_id ASC, m.match_date ASC
+	`
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query player appearances: %w", err)
+	}
+	defer rows.Close()
+
+	var appearances []PlayerAppearance
+	for rows.Next() {
+		var a PlayerAppearance
+		err := rows.Scan(
+			&a.PlayerID, &a.PlayerName, &a.MatchID, &a.SeasonID, &a.SeasonName, &a.TeamID, &a.TeamName,
+			&a.Lineup, &a.Starter, &a.MinuteOn,
+			&a.Goals, &a.Penalties, &a.OwnGoals, &a.YellowCards, &a.RedCards, &a.SentOff,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan player appearance: %w", err)
+		}
+		appearances = append(appearances, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player appearance rows: %w", err)
+	}
+
+	return appearances, nil
+}
+
+// ListSeasonPlayerStats reads the external season lines of one or every season
+func (p *Postgres) ListSeasonPlayerStats(seasonID int) ([]models.PlayerStats, error) {
+	var args []interface{}
+	query := playerStatsFrom("player_stats") + " WHERE ps.source = 'external'"
+	if seasonID > 0 {
+		query += " AND ps.season_id = $1"
+		args = append(args, seasonID)
+	}
+	query += " ORDER BY s.start_date ASC, ps.player_id ASC, ps.team_id ASC"
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query season player stats: %w", err)
+	}
+	defer rows.Close()
+
+	return scanPlayerStats(rows)
+}
+
+// SaveDerivedPlayerStats upserts derived lines in one transaction. Source is
+// part of the unique key, so external lines are never touched. Every line
+// written gets the transaction's timestamp, so derived lines left with an
+// older one had no match data this time and are removed.
+func (p *Postgres) SaveDerivedPlayerStats(seasonID int, stats []models.PlayerStats) (int, error) {
+	tx, err := p.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	upsert, err := tx.Prepare(`
+		INSERT INTO player_stats (player_id, season_id, team_id, appearances, goals, penalties,
+		                          own_goals, yellow_cards, red_cards, minutes, source, derived_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 'derived', CURRENT_TIMESTAMP)
+		ON CONFLICT (player_id, season_id, team_id, source) DO UPDATE SET
+			appearances = EXCLUDED.appearances,
+			goals = EXCLUDED.goals,
+			penalties = EXCLUDED.penalties,
+			own_goals = EXCLUDED.own_goals,
+			yellow_cards = EXCLUDED.yellow_cards,
+			red_cards = EXCLUDED.red_cards,
+			minutes = EXCLUDED.minutes,
+			derived_at = EXCLUDED.derived_at,
+			updated_at = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare player stats upsert: %w", err)
+	}
+	defer upsert.Close()
+
+	for _, s := range stats {
+		_, err := upsert.Exec(s.PlayerID, s.SeasonID, s.TeamID, s.Appearances, s.Goals, s.Penalties,
+			s.OwnGoals, s.YellowCards, s.RedCards, s.Minutes)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save stats of player %d in season %d: %w", s.PlayerID, s.SeasonID, err)
+		}
+	}
+
+	var args []interface{}
+	query := "DELETE FROM player_stats WHERE source = 'derived' AND derived_at < CURRENT_TIMESTAMP"
+	if seasonID > 0 {
+		query += " AND season_id = $1"
+		args = append(args, seasonID)
+	}
+	if _, err := tx.Exec(query, args...); err != nil {
+		return 0, fmt.Errorf("failed to remove stale derived stats: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit derived stats: %w", err)
+	}
+	return len(stats), nil
+}
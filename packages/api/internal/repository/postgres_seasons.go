@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+)
+
+// seasonColumns selects a season's metadata and how many of its matches have
+// been played. Unset values are left at zero for the services to default.
+const seasonColumns = `
+	s.id, s.name, COALESCE(s.year, EXTRACT(YEAR FROM s.start_date)::INTEGER),
+	s.start_date, s.end_date, COALESCE(s.team_count, 0),
+	(SELECT COUNT(*) FROM matches m
+	 WHERE m.season_id = s.id AND m.home_score IS NOT NULL AND m.away_score IS NOT NULL),
+	COALESCE(s.sponsor, ''), COALESCE(s.points_for_win, 0)`
+
+// ListSeasons retrieves all seasons in chronological order
+func (p *Postgres) ListSeasons() ([]models.Season, error) {
+	query := `SELECT ` + seasonColumns + `
+		FROM seasons s
+		ORDER BY s.start_date ASC, s.id ASC
+	`
+
+	rows, err := p.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seasons: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSeasons(rows)
+}
+
+// GetSeason retrieves a specific season by ID
+func (p *Postgres) GetSeason(seasonID int) (*models.Season, error) {
+	query := `SELECT ` + seasonColumns + `
+		FROM seasons s
+		WHERE s.id = $1
+	`
+
+	var season models.Season
+	err := scanSeason(p.db.QueryRow(query, seasonID), &season)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.NotFound("season with ID %d not found", seasonID)
+		}
+		return nil, fmt.Errorf("failed to query season: %w", err)
+	}
+
+	return &season, nil
+}
+
+// GetSeasonRules loads the league format configured for a season
+func (p *Postgres) GetSeasonRules(seasonID int) (SeasonRules, error) {
+	query := `
+		SELECT COALESCE(team_count, 0), COALESCE(relegation_places, 0),
+		       COALESCE(promotion_places, 0), COALESCE(european_places, '')
+		FROM seasons
+		WHERE id = $1
+	`
+
+	var rules SeasonRules
+	err := p.db.QueryRow(query, seasonID).Scan(
+		&rules.TeamCount, &rules.RelegationPlaces,
+		&rules.PromotionPlaces, &rules.EuropeanPlaces,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return rules, apperrors.NotFound("season with ID %d not found", seasonID)
+		}
+		return rules, fmt.Errorf("failed to get rules for season %d: %w", seasonID, err)
+	}
+	return rules, nil
+}
+
+// ListSeasonsWithResults retrieves the seasons that have completed matches
+func (p *Postgres) ListSeasonsWithResults() ([]models.Season, error) {
+	query := `SELECT ` + seasonColumns + `
+		FROM seasons s
+		WHERE EXISTS (
+			SELECT 1 FROM matches m
+			WHERE m.season_id = s.id AND m.home_score IS NOT NULL AND m.away_score IS NOT NULL
+		)
+		ORDER BY s.start_date ASC, s.id ASC
+	`
+
+	rows, err := p.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available seasons: %w", err)
+	}
+	defer rows.Close()
+
+	return scanSeasons(rows)
+}
+
+// scanSeason reads one row of seasonColumns
+func scanSeason(row rowScanner, season *models.Season) error {
+	return row.Scan(
+		&season.ID, &season.Name, &season.Year,
+		&season.StartDate, &season.EndDate, &season.TeamCount,
+		&season.PlayedMatches, &season.Sponsor, &season.PointsForWin,
+	)
+}
+
+// scanSeasons reads every row of seasonColumns
+func scanSeasons(rows *sql.Rows) ([]models.Season, error) {
+	var seasons []models.Season
+	for rows.Next() {
+		var season models.Season
+		if err := scanSeason(rows, &season); err != nil {
+			return nil, fmt.Errorf("failed to scan season row: %w", err)
+		}
+		seasons = append(seasons, season)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating season rows: %w", err)
+	}
+
+	return seasons, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// playerSpellQuery selects spells with their player, team and season names
+const playerSpellQuery = `
+	SELECT ps.id, ps.player_id, p.name, ps.team_id, t.name, ps.from_date, ps.to_date,
+	       ps.first_season_id, fs.name, ps.last_season_id, ls.name,
+	       COALESCE(ps.signed_from, ''), ps.fee, COALESCE(ps.fee_text, ''), ps.loan, ps.source
+	FROM player_spells ps
+	JOIN players p ON ps.player_id = p.id
+	JOIN teams t ON ps.team_id = t.id
+	JOIN seasons fs ON ps.first_season_id = fs.id
+	JOIN seasons ls ON ps.last_season_id = ls.id
+`
+
+// playerSpellOrder lists spells oldest first
+const playerSpellOrder = " ORDER BY fs.start_date, ps.from_date NULLS FIRST, ps.id"
+
+// ListPlayerSpells returns a player's spells, oldest first
+func (p *Postgres) ListPlayerSpells(playerID int) ([]models.PlayerSpell, error) {
+	return p.listPlayerSpells(playerSpellQuery+" WHERE ps.player_id = $1"+playerSpellOrder, playerID)
+}
+
+// ListTeamSpells returns every spell at a club, oldest first
+func (p *Postgres) ListTeamSpells(teamID int) ([]models.PlayerSpell, error) {
+	return p.listPlayerSpells(playerSpellQuery+" WHERE ps.team_id = $1"+playerSpellOrder, teamID)
+}
+
+// listPlayerSpells runs a spell query
+func (p *Postgres) listPlayerSpells(query string, args ...interface{}) ([]models.PlayerSpell, error) {
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query player spells: %w", err)
+	}
+	defer rows.Close()
+
+	spells := []models.PlayerSpell{}
+	for rows.Next() {
+		var spell models.PlayerSpell
+		var from, to sql.NullTime
+		var fee sql.NullInt64
+
+		err := rows.Scan(
+			&spell.ID, &spell.PlayerID, &spell.PlayerName, &spell.TeamID, &spell.TeamName, &from, &to,
+			&spell.FirstSeasonID, &spell.FirstSeason, &spell.LastSeasonID, &spell.LastSeason,
+			&spell.SignedFrom, &fee, &spell.FeeText, &spell.Loan, &spell.Source,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan player spell: %w", err)
+		}
+
+		if from.Valid {
+			spell.From = &from.Time
+		}
+		if to.Valid {
+			spell.To = &to.Time
+		}
+		if fee.Valid {
+			spell.Fee = &fee.Int64
+		}
+		spells = append(spells, spell)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player spell rows: %w", err)
+	}
+
+	return spells, nil
+}
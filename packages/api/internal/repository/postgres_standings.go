@@ -0,0 +1,275 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+)
+
+// scoreColumns maps each score to the home and away expressions it reads and
+// whether it needs the half-time score
+var scoreColumns = map[string]struct {
+	home, away    string
+	needsHalfTime bool
+}{
+	ScoreFullTime:   {"m.home_score", "m.away_score", false},
+	ScoreFirstHalf:  {"m.half_time_home", "m.half_time_away", true},
+	ScoreSecondHalf: {"m.home_score - m.half_time_home", "m.away_score - m.half_time_away", true},
+}
+
+// ListTableRows aggregates a season's results into one line per team, for a
+// single table or for every matchweek
+func (p *Postgres) ListTableRows(seasonID int, q TableQuery) ([]TableRow, error) {
+	score, ok := scoreColumns[q.score()]
+	if !ok {
+		return nil, apperrors.InvalidArgument("unknown table score %q", q.Score)
+	}
+
+	args := []interface{}{seasonID}
+	argIndex := 2
+
+	filter := "AND m.home_score IS NOT NULL AND m.away_score IS NOT NULL"
+	if score.needsHalfTime {
+		filter += " AND m.half_time_home IS NOT NULL AND m.half_time_away IS NOT NULL"
+	}
+	if q.Before != nil {
+		filter += fmt.Sprintf(" AND m.match_date < $%d", argIndex)
+		args = append(args, *q.Before)
+		argIndex++
+	}
+
+	// One row per team per match, from that team's point of view
+	var sides []string
+	if q.Home {
+		sides = append(sides, fmt.Sprintf(`
+			SELECT m.id, m.match_date, m.home_team_id as team_id,
+				%s as goals_for, %s as goals_against
+			FROM matches m
+			WHERE m.season_id = $1 %s`, score.home, score.away, filter))
+	}
+	if q.Away {
+		sides = append(sides, fmt.Sprintf(`
+			SELECT m.id, m.match_date, m.away_team_id as team_id,
+				%s as goals_for, %s as goals_against
+			FROM matches m
+			WHERE m.season_id = $1 %s`, score.away, score.home, filter))
+	}
+	if len(sides) == 0 {
+		return nil, apperrors.InvalidArgument("a table needs home or away matches")
+	}
+
+	// Bucket 0 means "every match"; any other bucket limits each team to
+	// its first N matches
+	buckets := "SELECT 0 as matchweek"
+	if q.PerMatchweek {
+		buckets = "SELECT generate_series(1, (SELECT COALESCE(MAX(game), 0) FROM numbered)) as matchweek"
+	} else if q.Matchweek > 0 {
+		buckets = fmt.Sprintf("SELECT $%d::int as matchweek", argIndex)
+		args = append(args, q.Matchweek)
+	}
+
+	query := fmt.Sprintf(`
+		WITH season_teams AS (
+			SELECT home_team_id as team_id FROM matches WHERE season_id = $1
+			UNION
+			SELECT away_team_id FROM matches WHERE season_id = $1
+		),
+		team_matches AS (%[1]s
+		),
+		numbered AS (
+			SELECT tm.*, ROW_NUMBER() OVER (PARTITION BY tm.team_id ORDER BY tm.match_date, tm.id) as game
+			FROM team_matches tm
+		),
+		buckets AS (
+			%[2]s
+		)
+		SELECT 
+			b.matchweek,
+			t.id as team_id,
+			t.name as team_name,
+			COUNT(n.id) as played,
+			COUNT(CASE WHEN n.goals_for > n.goals_against THEN 1 END) as won,
+			COUNT(CASE WHEN n.goals_for = n.goals_against THEN 1 END) as drawn,
+			COUNT(CASE WHEN n.goals_for < n.goals_against THEN 1 END) as lost,
+			COALESCE(SUM(n.goals_for), 0) as goals_for,
+			COALESCE(SUM(n.goals_against), 0) as goals_against,
+			MAX(n.match_date) as last_match
+		FROM buckets b
+		CROSS JOIN season_teams st
+		JOIN teams t ON t.id = st.team_id
+		LEFT JOIN numbered n ON n.team_id = t.id AND (b.matchweek = 0 OR n.game <= b.matchweek)
+		GROUP BY b.matchweek, t.id, t.name
+		ORDER BY b.matchweek ASC, t.name ASC
+	`, strings.Join(sides, "\n\t\t\tUNION ALL"), buckets)
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate standings for season %d: %w", seasonID, err)
+	}
+	defer rows.Close()
+
+	var tableRows []TableRow
+	for rows.Next() {
+		var row TableRow
+		var lastMatch sql.NullTime
+		err := rows.Scan(
+			&row.Matchweek,
+			&row.Entry.TeamID,
+			&row.Entry.Team,
+			&row.Entry.Played,
+			&row.Entry.Won,
+			&row.Entry.Drawn,
+			&row.Entry.Lost,
+			&row.Entry.GoalsFor,
+			&row.Entry.GoalsAgainst,
+			&lastMatch,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan standings row: %w", err)
+		}
+		if lastMatch.Valid {
+			row.LastMatch = &lastMatch.Time
+		}
+		tableRows = append(tableRows, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating standings rows: %w", err)
+	}
+
+	return tableRows, nil
+}
+
+// score returns the requested score, defaulting to full time
+func (q TableQuery) score() string {
+	if q.Score == "" {
+		return ScoreFullTime
+	}
+	return q.Score
+}
+
+// ListPointAdjustments loads a season's points deductions and awards keyed by team
+func (p *Postgres) ListPointAdjustments(seasonID int) (map[int][]models.PointAdjustment, error) {
+	query := `
+		SELECT id, team_id, season_id, points, reason, COALESCE(TO_CHAR(applied_on, 'YYYY-MM-DD'), '')
+		FROM point_adjustments
+		WHERE season_id = $1
+		ORDER BY applied_on ASC NULLS FIRST, id ASC
+	`
+
+	rows, err := p.db.Query(query, seasonID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point adjustments for season %d: %w", seasonID, err)
+	}
+	defer rows.Close()
+
+	adjustments := make(map[int][]models.PointAdjustment)
+	for rows.Next() {
+		var adjustment models.PointAdjustment
+		err := rows.Scan(
+			&adjustment.ID,
+			&adjustment.TeamID,
+			&adjustment.SeasonID,
+			&adjustment.Points,
+			&adjustment.Reason,
+			&adjustment.AppliedOn,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan point adjustment: %w", err)
+		}
+		adjustments[adjustment.TeamID] = append(adjustments[adjustment.TeamID], adjustment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating point adjustment rows: %w", err)
+	}
+
+	return adjustments, nil
+}
+
+// GetTieBreakRules loads the rule list configured for a season
+func (p *Postgres) GetTieBreakRules(seasonID int) (string, error) {
+	var raw string
+	err := p.db.QueryRow("SELECT COALESCE(tie_break_rules, '') FROM seasons WHERE id = $1", seasonID).Scan(&raw)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", apperrors.NotFound("season with ID %d not found", seasonID)
+		}
+		return "", fmt.Errorf("failed to get tie-break rules for season %d: %w", seasonID, err)
+	}
+	return raw, nil
+}
+
+// GetOfficialStandings loads the official table for a season keyed by team
+func (p *Postgres) GetOfficialStandings(seasonID int) (map[int]*models.StandingsEntry, string, error) {
+	query := `
+		SELECT os.team_id, t.name, os.position, os.played, os.won, os.drawn, os.lost,
+		       os.goals_for, os.goals_against, os.goal_difference, os.points, os.source
+		FROM official_standings os
+		JOIN teams t ON os.team_id = t.id
+		WHERE os.season_id = $1
+	`
+
+	rows, err := p.db.Query(query, seasonID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get official standings for season %d: %w", seasonID, err)
+	}
+	defer rows.Close()
+
+	official := make(map[int]*models.StandingsEntry)
+	var source string
+	for rows.Next() {
+		var entry models.StandingsEntry
+		err := rows.Scan(
+			&entry.TeamID,
+			&entry.Team,
+			&entry.Position,
+			&entry.Played,
+			&entry.Won,
+			&entry.Drawn,
+			&entry.Lost,
+			&entry.GoalsFor,
+			&entry.GoalsAgainst,
+			&entry.GoalDifference,
+			&entry.Points,
+			&source,
+		)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to scan official standings row: %w", err)
+		}
+		official[entry.TeamID] = &entry
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("error iterating official standings rows: %w", err)
+	}
+
+	return official, source, nil
+}
+
+// ListOfficialSeasonIDs returns the seasons that have an official table
+func (p *Postgres) ListOfficialSeasonIDs() ([]int, error) {
+	rows, err := p.db.Query("SELECT DISTINCT season_id FROM official_standings ORDER BY season_id ASC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query official seasons: %w", err)
+	}
+	defer rows.Close()
+
+	var seasonIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan season id: %w", err)
+		}
+		seasonIDs = append(seasonIDs, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating official seasons: %w", err)
+	}
+
+	return seasonIDs, nil
+}
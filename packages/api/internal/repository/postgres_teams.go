@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+)
+
+// ListTeams retrieves the teams matching a filter, ordered by name
+func (p *Postgres) ListTeams(filter TeamFilter) ([]models.Team, error) {
+	var args queryArgs
+	query := `
+		SELECT t.id, t.name, t.short_name, t.stadium, t.founded
+		FROM teams t
+		WHERE 1=1` + teamConditions(filter, &args)
+
+	order := "ASC"
+	if filter.Seek != nil {
+		var op string
+		op, order = seekDirection(true, filter.Seek.Backward)
+		query += " AND (t.name, t.id) " + op + " (" + args.add(filter.Seek.Key.Name) + ", " + args.add(filter.Seek.Key.ID) + ")"
+	}
+	query += " ORDER BY t.name " + order + ", t.id " + order
+
+	if filter.Limit > 0 {
+		query += " LIMIT " + args.add(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += " OFFSET " + args.add(filter.Offset)
+	}
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query teams: %w", err)
+	}
+	defer rows.Close()
+
+	teams, err := scanTeams(rows)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Seek != nil && filter.Seek.Backward {
+		slices.Reverse(teams)
+	}
+	return teams, nil
+}
+
+// CountTeams returns the number of teams matching a filter
+func (p *Postgres) CountTeams(filter TeamFilter) (int, error) {
+	var args queryArgs
+	query := "SELECT COUNT(*) FROM teams t WHERE 1=1" + teamConditions(filter, &args)
+
+	var count int
+	if err := p.db.QueryRow(query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count teams: %w", err)
+	}
+
+	return count, nil
+}
+
+// teamConditions builds the WHERE conditions shared by ListTeams and CountTeams
+func teamConditions(filter TeamFilter, args *queryArgs) string {
+	if filter.SeasonID == 0 {
+		return ""
+	}
+	return `
+		AND EXISTS (
+			SELECT 1 FROM matches m
+			WHERE m.season_id = ` + args.add(filter.SeasonID) + `
+			AND (m.home_team_id = t.id OR m.away_team_id = t.id)
+		)`
+}
+
+// GetTeam retrieves a specific team by ID
+func (p *Postgres) GetTeam(teamID int) (*models.Team, error) {
+	query := `
+		SELECT id, name, short_name, stadium, founded
+		FROM teams
+		WHERE id = $1
+	`
+
+	team, err := scanTeam(p.db.QueryRow(query, teamID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperrors.NotFound("team with ID %d not found", teamID)
+		}
+		return nil, fmt.Errorf("failed to query team: %w", err)
+	}
+
+	return team, nil
+}
+
+// ListTeamAliases retrieves a team's aliases, or every team's for a zero
+// teamID, ordered by kind and then by when they were in use
+func (p *Postgres) ListTeamAliases(teamID int) ([]models.TeamAlias, error) {
+	query := `
+		SELECT id, team_id, alias, kind, source, valid_from, valid_to
+		FROM team_aliases
+		WHERE ($1 = 0 OR team_id = $1)
+		ORDER BY kind, valid_from NULLS FIRST, alias, source
+	`
+
+	rows, err := p.db.Query(query, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query team aliases: %w", err)
+	}
+	defer rows.Close()
+
+	aliases := []models.TeamAlias{}
+	for rows.Next() {
+		var alias models.TeamAlias
+		if err := rows.Scan(&alias.ID, &alias.TeamID, &alias.Alias, &alias.Kind, &alias.Source, &alias.ValidFrom, &alias.ValidTo); err != nil {
+			return nil, fmt.Errorf("failed to scan team alias row: %w", err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team alias rows: %w", err)
+	}
+
+	return aliases, nil
+}
+
+// scanTeams reads every team row
+func scanTeams(rows *sql.Rows) ([]models.Team, error) {
+	var teams []models.Team
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team row: %w", err)
+		}
+		teams = append(teams, *team)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating team rows: %w", err)
+	}
+
+	return teams, nil
+}
+
+// scanTeam reads one team row
+func scanTeam(row rowScanner) (*models.Team, error) {
+	var team models.Team
+	var stadium sql.NullString
+	var founded sql.NullInt32
+
+	if err := row.Scan(&team.ID, &team.Name, &team.ShortName, &stadium, &founded); err != nil {
+		return nil, err
+	}
+
+	if stadium.Valid {
+		team.Stadium = stadium.String
+	}
+
+	if founded.Valid {
+		team.Founded = int(founded.Int32)
+	}
+
+	return &team, nil
+}
@@ -0,0 +1,404 @@
+// Package repository defines the data access interfaces used by the services,
+// with a PostgreSQL implementation for production and an in-memory one for tests.
+package repository
+
+import (
+	"time"
+
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+)
+
+// Both stores implement every repository
+var (
+	_ TeamRepository           = (*Postgres)(nil)
+	_ SeasonRepository         = (*Postgres)(nil)
+	_ MatchRepository          = (*Postgres)(nil)
+	_ StandingsRepository      = (*Postgres)(nil)
+	_ PlayerRepository         = (*Postgres)(nil)
+	_ PlayerIdentityRepository = (*Postgres)(nil)
+	_ PlayerStatsRepository    = (*Postgres)(nil)
+	_ PlayerSpellRepository    = (*Postgres)(nil)
+
+	_ TeamRepository           = (*Memory)(nil)
+	_ SeasonRepository         = (*Memory)(nil)
+	_ MatchRepository          = (*Memory)(nil)
+	_ StandingsRepository      = (*Memory)(nil)
+	_ PlayerRepository         = (*Memory)(nil)
+	_ PlayerIdentityRepository = (*Memory)(nil)
+	_ PlayerStatsRepository    = (*Memory)(nil)
+	_ PlayerSpellRepository    = (*Memory)(nil)
+)
+
+// TeamRepository reads teams
+type TeamRepository interface {
+	ListTeams(filter TeamFilter) ([]models.Team, error)
+	CountTeams(filter TeamFilter) (int, error)
+	// GetTeam returns an apperrors.NotFound error for unknown IDs
+	GetTeam(teamID int) (*models.Team, error)
+	// ListTeamAliases returns a team's registered aliases by kind, then
+	// date. A teamID of 0 returns every team's aliases.
+	ListTeamAliases(teamID int) ([]models.TeamAlias, error)
+}
+
+// SeasonRepository reads seasons
+type SeasonRepository interface {
+	ListSeasons() ([]models.Season, error)
+	// GetSeason returns an apperrors.NotFound error for unknown IDs
+	GetSeason(seasonID int) (*models.Season, error)
+	// ListSeasonsWithResults returns seasons with at least one completed match
+	ListSeasonsWithResults() ([]models.Season, error)
+	// GetSeasonRules returns a season's raw league format, with zero values
+	// for anything not configured, or an apperrors.NotFound error
+	GetSeasonRules(seasonID int) (SeasonRules, error)
+}
+
+// MatchRepository reads matches and what happened in them
+type MatchRepository interface {
+	ListMatches(filter MatchFilter) ([]models.Match, error)
+	CountMatches(filter MatchFilter) (int, error)
+	// GetMatch returns a match with its statistics, or an apperrors.NotFound error
+	GetMatch(matchID int) (*models.Match, error)
+	ListMatchEvents(matchID int) ([]models.MatchEvent, error)
+	// ListResults returns a season's completed matches in date order
+	ListResults(seasonID int) ([]Result, error)
+	ListTeamDiscipline(seasonID int) ([]models.TeamDiscipline, error)
+}
+
+// StandingsRepository aggregates results into table lines and reads the
+// season rules and official tables that standings are checked against
+type StandingsRepository interface {
+	ListTableRows(seasonID int, query TableQuery) ([]TableRow, error)
+	ListPointAdjustments(seasonID int) (map[int][]models.PointAdjustment, error)
+	// GetTieBreakRules returns a season's raw rule list, empty for the default
+	GetTieBreakRules(seasonID int) (string, error)
+	// GetOfficialStandings returns the official table keyed by team and its source
+	GetOfficialStandings(seasonID int) (map[int]*models.StandingsEntry, string, error)
+	ListOfficialSeasonIDs() ([]int, error)
+	// ListTeamSeasonAggregates returns the stored season totals of every team
+	// with a match in the season, ordered by team name
+	ListTeamSeasonAggregates(seasonID int) ([]TeamSeasonAggregate, error)
+}
+
+// PlayerRepository reads players, their statistics and search results
+type PlayerRepository interface {
+	ListPlayers(filter PlayerFilter) ([]models.Player, error)
+	CountPlayers(filter PlayerFilter) (int, error)
+	// GetPlayer returns an apperrors.NotFound error for unknown IDs
+	GetPlayer(playerID int) (*models.Player, error)
+	// ListPlayerStats returns a player's season lines, most recent first
+	ListPlayerStats(playerID, seasonID int) ([]models.PlayerStats, error)
+	// GetPlayerMilestones returns the first and last matches a player
+	// appeared and scored in
+	GetPlayerMilestones(playerID int) (PlayerMilestones, error)
+	// ListTopScorers returns a season's scorers by goals then assists, unranked
+	ListTopScorers(seasonID, limit int) ([]models.TopScorer, error)
+	// ListPlayerTotals adds up each player's season lines within a filter, unordered
+	ListPlayerTotals(filter PlayerTotalsFilter) ([]PlayerTotals, error)
+	ListPositions() ([]string, error)
+	ListNationalities() ([]string, error)
+	// Search finds players and then teams whose names, or team aliases,
+	// contain the query
+	Search(filter SearchFilter) ([]models.SearchResult, error)
+	CountSearch(query string) (int, error)
+}
+
+// PlayerIdentityRepository records decisions on suspected duplicate players
+// and merges the ones that are the same person
+type PlayerIdentityRepository interface {
+	// ListRejectedPlayerPairs returns the pairs reviewed as different people,
+	// lower ID first
+	ListRejectedPlayerPairs() ([][2]int, error)
+	RejectPlayerPair(playerID, duplicateID int, confidence float64) error
+	// MergePlayers moves everything recorded against duplicateID onto
+	// playerID and deletes the duplicate, all or nothing. Season lines both
+	// players have for the same team are added together, and every API
+	// process is told to drop its cache. Unknown IDs give an
+	// apperrors.NotFound error.
+	MergePlayers(playerID, duplicateID int, confidence float64) (models.PlayerMergeResult, error)
+}
+
+// PlayerStatsRepository reads the match data player season lines are derived
+// from, and the stored lines they are checked against. A seasonID of 0 means
+// every season.
+type PlayerStatsRepository interface {
+	// ListPlayerAppearances returns one record per player and match, by season
+	ListPlayerAppearances(seasonID int) ([]PlayerAppearance, error)
+	// ListSeasonPlayerStats returns the external season lines
+	ListSeasonPlayerStats(seasonID int) ([]models.PlayerStats, error)
+	// SaveDerivedPlayerStats replaces the derived lines, which are kept
+	// beside the external ones rather than over them, and removes lines
+	// derived earlier that no longer have match data. Reads of a player's
+	// lines show the derived numbers with the external assists. It returns
+	// the lines written.
+	SaveDerivedPlayerStats(seasonID int, stats []models.PlayerStats) (int, error)
+}
+
+// PlayerSpellRepository reads the spells players spent at clubs
+type PlayerSpellRepository interface {
+	// ListPlayerSpells returns a player's spells, oldest first
+	ListPlayerSpells(playerID int) ([]models.PlayerSpell, error)
+	// ListTeamSpells returns every spell at a club, oldest first
+	ListTeamSpells(teamID int) ([]models.PlayerSpell, error)
+}
+
+// TeamFilter selects teams, ordered by name
+type TeamFilter struct {
+	// SeasonID keeps teams with a match in that season
+	SeasonID int
+	Limit    int
+	Offset   int
+	Seek     *pagination.Seek[NameKey]
+}
+
+// MatchFilter selects matches. Zero values leave a criterion unset.
+type MatchFilter struct {
+	SeasonID int
+	TeamID   int
+	// OpponentID restricts TeamID's matches to those against one opponent
+	OpponentID int
+	// Venue is home or away from TeamID's point of view; empty means both
+	Venue        string
+	FromSeasonID int
+	ToSeasonID   int
+	// FromDate and ToDate bound the match date, both inclusive
+	FromDate *time.Time
+	ToDate   *time.Time
+	// Completed keeps only matches with a final score
+	Completed bool
+	// Result is W, D or L from TeamID's point of view; it needs TeamID
+	Result string
+	// Referee keeps matches whose referee's name contains this text, ignoring case
+	Referee string
+	// GoalsFor and GoalsAgainst select a final scoreline, from TeamID's point
+	// of view when it is set and home then away otherwise
+	GoalsFor     *int
+	GoalsAgainst *int
+	// MinGoals keeps matches with at least this many goals in total
+	MinGoals int
+	// Comeback keeps matches won by a side that trailed at half time, or
+	// only TeamID's comebacks when it is set
+	Comeback bool
+	// Sort is one of the MatchSort constants; empty means by date
+	Sort string
+	// Ascending orders oldest, or lowest, first; the default is newest first
+	Ascending bool
+	Limit     int
+	Offset    int
+	// Seek continues from a match in the filter's order
+	Seek *pagination.Seek[MatchKey]
+}
+
+// Orders a match listing can be sorted in. Every order falls back to date
+// then ID, and unplayed matches sort below any result.
+const (
+	MatchSortDate   = "date"
+	MatchSortGoals  = "goals"
+	MatchSortMargin = "margin"
+)
+
+// Match results from a team's point of view
+const (
+	ResultWin  = "W"
+	ResultDraw = "D"
+	ResultLoss = "L"
+)
+
+// PlayerFilter selects players. When SeasonID is set only players in a squad
+// for that season match, and TeamID and team names refer to that squad.
+type PlayerFilter struct {
+	Search      string
+	Position    string
+	Nationality string
+	TeamID      int
+	SeasonID    int
+	Limit       int
+	Offset      int
+	Seek        *pagination.Seek[NameKey]
+}
+
+// PlayerTotalsFilter selects the season lines a leaderboard adds up. Zero
+// values leave a criterion unset, so an empty filter covers every season and
+// club.
+type PlayerTotalsFilter struct {
+	SeasonID     int
+	FromSeasonID int
+	ToSeasonID   int
+	TeamID       int
+}
+
+// SearchFilter pages through search results
+type SearchFilter struct {
+	Query  string
+	Limit  int
+	Offset int
+	Seek   *pagination.Seek[SearchKey]
+}
+
+// MatchKey is the sort key of a match listing. Value holds the total goals or
+// winning margin when the listing is sorted by one.
+type MatchKey struct {
+	Value int       `json:"v,omitempty"`
+	Date  time.Time `json:"d"`
+	ID    int       `json:"i"`
+}
+
+// NameKey is the sort key of team and player listings
+type NameKey struct {
+	Name string `json:"n"`
+	ID   int    `json:"i"`
+}
+
+// SearchKey is the sort key of search results: players before teams, then by name
+type SearchKey struct {
+	Type string `json:"t"`
+	Name string `json:"n"`
+	ID   int    `json:"i"`
+}
+
+// Result is a completed match reduced to what tables and form need
+type Result struct {
+	MatchID    int
+	SeasonID   int
+	Date       time.Time
+	HomeTeamID int
+	AwayTeamID int
+	HomeScore  int
+	AwayScore  int
+}
+
+// Scores a table can be built from
+const (
+	ScoreFullTime   = "fullTime"
+	ScoreFirstHalf  = "firstHalf"
+	ScoreSecondHalf = "secondHalf"
+)
+
+// TableQuery selects the matches and score that count towards a table
+type TableQuery struct {
+	// Home and Away select which side of each match counts
+	Home bool
+	Away bool
+	// Score is one of the Score constants; empty means full time
+	Score string
+	// Before keeps only matches played before this time
+	Before *time.Time
+	// Matchweek limits each team to its first N matches
+	Matchweek int
+	// PerMatchweek returns rows for every matchweek instead of one table
+	PerMatchweek bool
+}
+
+// TableRow is one team's aggregated line. Matchweek is 0 for a single table.
+type TableRow struct {
+	Matchweek int
+	Entry     models.StandingsEntry
+	// LastMatch is the date of the latest match counted, if any
+	LastMatch *time.Time
+}
+
+// PlayerMilestones are the matches that open and close a player's career.
+// Appearances count lineups (starting or coming on), goals and match events;
+// goals leave out own goals. Each is nil when there is no such match.
+type PlayerMilestones struct {
+	Debut          *models.CareerMatch
+	LastAppearance *models.CareerMatch
+	FirstGoal      *models.CareerMatch
+	LastGoal       *models.CareerMatch
+}
+
+// PlayerAppearance is what one player did in one match, from lineups, goals
+// and match events. TeamID is the side the player was on, so an own goal
+// counts for the opposition.
+type PlayerAppearance struct {
+	PlayerID   int
+	PlayerName string
+	MatchID    int
+	SeasonID   int
+	SeasonName string
+	TeamID     int
+	TeamName   string
+	// Lineup is set when the player has a lineup entry for the match;
+	// Starter and MinuteOn then say how they came to play
+	Lineup      bool
+	Starter     bool
+	MinuteOn    int
+	Goals       int
+	Penalties   int
+	OwnGoals    int
+	YellowCards int
+	RedCards    int
+	// SentOff is the minute of the player's red card, 0 without one
+	SentOff int
+}
+
+// PlayerTotals is a player's season lines added up
+type PlayerTotals struct {
+	PlayerID   int
+	PlayerName string
+	// TeamID and TeamName are set when every line is for one club
+	TeamID      int
+	TeamName    string
+	Seasons     int
+	Appearances int
+	Goals       int
+	Assists     int
+	Penalties   int
+	YellowCards int
+	RedCards    int
+	// Minutes adds up the lines with minutes recorded and TimedGoals the
+	// goals in those lines, so rates are not inflated by untimed seasons
+	Minutes    int
+	TimedGoals int
+}
+
+// SeasonRules is a season's league format as stored. Zero values and an
+// empty EuropeanPlaces list mean the season uses the defaults.
+type SeasonRules struct {
+	TeamCount        int
+	RelegationPlaces int
+	PromotionPlaces  int
+	// EuropeanPlaces is an ordered list of competition:places pairs
+	EuropeanPlaces string
+}
+
+// TeamSeasonAggregate is one team's totals for a season, kept up to date as
+// matches change. Records count completed matches; cards and shots count
+// every match they were recorded for.
+type TeamSeasonAggregate struct {
+	SeasonID      int
+	TeamID        int
+	Team          string
+	Overall       AggregateRecord
+	Home          AggregateRecord
+	Away          AggregateRecord
+	YellowCards   int
+	RedCards      int
+	Shots         int
+	ShotsOnTarget int
+}
+
+// AggregateRecord is a team's record over its home, away or all matches
+type AggregateRecord struct {
+	Played       int
+	Won          int
+	Drawn        int
+	Lost         int
+	GoalsFor     int
+	GoalsAgainst int
+	// LastMatch is the date of the latest completed match, if any
+	LastMatch *time.Time
+}
+
+// AggregateMismatch is a team whose stored season totals disagree with its matches
+type AggregateMismatch struct {
+	SeasonID int
+	TeamID   int
+	Team     string
+	// Missing is set when no totals are stored for a team with matches, and
+	// Orphaned when totals are stored for a team without any
+	Missing  bool
+	Orphaned bool
+	// Columns lists the stored columns that differ
+	Columns []string
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+// DefaultFormLength is the number of matches in a form guide
+const DefaultFormLength = 6
+
+// Streak types reported by GetTeamStreaks
+var streakTypes = []struct {
+	name  string
+	holds func(models.FormMatch) bool
+}{
+	{"winning", func(m models.FormMatch) bool { return m.Result == "W" }},
+	{"unbeaten", func(m models.FormMatch) bool { return m.Result != "L" }},
+	{"winless", func(m models.FormMatch) bool { return m.Result != "W" }},
+	{"scoring", func(m models.FormMatch) bool { return m.GoalsFor > 0 }},
+	{"cleanSheet", func(m models.FormMatch) bool { return m.GoalsAgainst == 0 }},
+}
+
+// FormService computes form guides and streaks from match results
+type FormService struct {
+	teams   repository.TeamRepository
+	seasons repository.SeasonRepository
+	matches repository.MatchRepository
+}
+
+// NewFormService creates a new form service
+func NewFormService(teams repository.TeamRepository, seasons repository.SeasonRepository, matches repository.MatchRepository) *FormService {
+	return &FormService{teams: teams, seasons: seasons, matches: matches}
+}
+
+// GetTeamForm returns a team's last N results, within a season when seasonID
+// is set or across all seasons otherwise
+func (s *FormService) GetTeamForm(teamID, seasonID, last int) (*models.TeamForm, error) {
+	teamName, err := s.getTeamName(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.getTeamResults(teamID, seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) > last {
+		matches = matches[len(matches)-last:]
+	}
+
+	form := &models.TeamForm{
+		TeamID:   teamID,
+		Team:     teamName,
+		SeasonID: seasonID,
+		Last:     last,
+		Matches:  matches,
+	}
+
+	var results strings.Builder
+	for _, m := range matches {
+		results.WriteString(m.Result)
+		form.Points += resultPoints(m.Result)
+		form.GoalsFor += m.GoalsFor
+		form.GoalsAgainst += m.GoalsAgainst
+	}
+	form.Form = results.String()
+
+	return form, nil
+}
+
+// GetTeamStreaks returns a team's longest runs across all seasons and within each season
+func (s *FormService) GetTeamStreaks(teamID int) (*models.TeamStreaks, error) {
+	teamName, err := s.getTeamName(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := s.getTeamResults(teamID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	seasonNames, err := seasonNames(s.seasons)
+	if err != nil {
+		return nil, err
+	}
+
+	streaks := &models.TeamStreaks{
+		TeamID:  teamID,
+		Team:    teamName,
+		AllTime: longestStreaks(matches),
+		Seasons: []models.SeasonStreaks{},
+	}
+
+	start := 0
+	for i := 1; i <= len(matches); i++ {
+		if i < len(matches) && matches[i].SeasonID == matches[start].SeasonID {
+			continue
+		}
+		seasonID := matches[start].SeasonID
+		streaks.Seasons = append(streaks.Seasons, models.SeasonStreaks{
+			SeasonID: seasonID,
+			Season:   seasonNames[seasonID],
+			Streaks:  longestStreaks(matches[start:i]),
+		})
+		start = i
+	}
+
+	return streaks, nil
+}
+
+// getTeamResults loads a team's completed matches in date order
+func (s *FormService) getTeamResults(teamID, seasonID int) ([]models.FormMatch, error) {
+	played, err := s.matches.ListMatches(repository.MatchFilter{
+		SeasonID:  seasonID,
+		TeamID:    teamID,
+		Completed: true,
+		Ascending: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.FormMatch, 0, len(played))
+	for _, match := range played {
+		m := models.FormMatch{
+			MatchID:      match.ID,
+			SeasonID:     match.SeasonID,
+			Date:         match.MatchDate,
+			OpponentID:   match.AwayTeamID,
+			Opponent:     match.AwayTeam,
+			Venue:        "H",
+			GoalsFor:     *match.HomeScore,
+			GoalsAgainst: *match.AwayScore,
+		}
+		if match.HomeTeamID != teamID {
+			m.OpponentID, m.Opponent, m.Venue = match.HomeTeamID, match.HomeTeam, "A"
+			m.GoalsFor, m.GoalsAgainst = m.GoalsAgainst, m.GoalsFor
+		}
+		m.Result = resultLetter(m.GoalsFor, m.GoalsAgainst)
+		matches = append(matches, m)
+	}
+
+	return matches, nil
+}
+
+// getTeamName returns a team's name, or an error if it does not exist
+func (s *FormService) getTeamName(teamID int) (string, error) {
+	team, err := s.teams.GetTeam(teamID)
+	if err != nil {
+		return "", err
+	}
+	return team.Name, nil
+}
+
+// longestStreaks finds the longest run of each streak type in chronological matches
+func longestStreaks(matches []models.FormMatch) []models.Streak {
+	streaks := make([]models.Streak, 0, len(streakTypes))
+	for _, st := range streakTypes {
+		best := models.Streak{Type: st.name}
+		runStart := -1
+		for i, m := range matches {
+			if !st.holds(m) {
+				runStart = -1
+				continue
+			}
+			if runStart < 0 {
+				runStart = i
+			}
+			// Ties keep the later run so an ongoing record is reported as active
+			if length := i - runStart + 1; length >= best.Length {
+				startDate, endDate := matches[runStart].Date, m.Date
+				best.Length = length
+				best.StartDate = &startDate
+				best.EndDate = &endDate
+				best.Active = i == len(matches)-1
+			}
+		}
+		streaks = append(streaks, best)
+	}
+	return streaks
+}
+
+// recentForm returns the W/D/L string for a team's last n results among the
+// first played results in a season's chronological results
+func recentForm(results []matchResult, teamID, played, n int) string {
+	var letters []string
+	for _, r := range results {
+		if len(letters) == played {
+			break
+		}
+		switch teamID {
+		case r.homeTeamID:
+			letters = append(letters, resultLetter(r.homeScore, r.awayScore))
+		case r.awayTeamID:
+			letters = append(letters, resultLetter(r.awayScore, r.homeScore))
+		}
+	}
+	if len(letters) > n {
+		letters = letters[len(letters)-n:]
+	}
+	return strings.Join(letters, "")
+}
+
+// resultLetter returns W, D or L for a score from one side's point of view
+func resultLetter(goalsFor, goalsAgainst int) string {
+	switch {
+	case goalsFor > goalsAgainst:
+		return "W"
+	case goalsFor == goalsAgainst:
+		return "D"
+	}
+	return "L"
+}
+
+// resultPoints returns the league points for a W, D or L result
+func resultPoints(result string) int {
+	switch result {
+	case "W":
+		return 3
+	case "D":
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+// HeadToHeadOptions restricts which meetings count towards a head-to-head record
+type HeadToHeadOptions struct {
+	FromSeasonID int
+	ToSeasonID   int
+	// Venue is all, home or away from the first team's point of view
+	Venue string
+}
+
+// GetHeadToHead returns the record, biggest wins, per-season breakdown and
+// every completed meeting between two teams
+func (s *MatchService) GetHeadToHead(teamID, opponentID int, opts HeadToHeadOptions) (*models.HeadToHead, error) {
+	if opts.Venue == "" {
+		opts.Venue = "all"
+	}
+
+	h2h := &models.HeadToHead{
+		TeamID:     teamID,
+		OpponentID: opponentID,
+		Venue:      opts.Venue,
+		Seasons:    []models.HeadToHeadSeason{},
+		Matches:    []models.Match{},
+	}
+
+	team, err := s.teams.GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+	opponent, err := s.teams.GetTeam(opponentID)
+	if err != nil {
+		return nil, err
+	}
+	h2h.Team, h2h.Opponent = team.Name, opponent.Name
+
+	matches, err := s.listMatches(repository.MatchFilter{
+		TeamID:       teamID,
+		OpponentID:   opponentID,
+		Venue:        opts.Venue,
+		FromSeasonID: opts.FromSeasonID,
+		ToSeasonID:   opts.ToSeasonID,
+		Completed:    true,
+		Ascending:    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	h2h.Matches = append(h2h.Matches, matches...)
+
+	seasonNames, err := s.getSeasonNames()
+	if err != nil {
+		return nil, err
+	}
+
+	var biggestWin, opponentBiggestWin *models.Match
+	for i := range h2h.Matches {
+		match := &h2h.Matches[i]
+		goalsFor, goalsAgainst := *match.HomeScore, *match.AwayScore
+		if match.AwayTeamID == teamID {
+			goalsFor, goalsAgainst = goalsAgainst, goalsFor
+		}
+
+		if n := len(h2h.Seasons); n == 0 || h2h.Seasons[n-1].SeasonID != match.SeasonID {
+			h2h.Seasons = append(h2h.Seasons, models.HeadToHeadSeason{
+				SeasonID: match.SeasonID,
+				Season:   seasonNames[match.SeasonID],
+			})
+		}
+		addHeadToHeadResult(&h2h.HeadToHeadRecord, goalsFor, goalsAgainst)
+		addHeadToHeadResult(&h2h.Seasons[len(h2h.Seasons)-1].HeadToHeadRecord, goalsFor, goalsAgainst)
+
+		if goalsFor > goalsAgainst && isBiggerWin(match, biggestWin) {
+			biggestWin = match
+		}
+		if goalsAgainst > goalsFor && isBiggerWin(match, opponentBiggestWin) {
+			opponentBiggestWin = match
+		}
+	}
+	h2h.BiggestWin = biggestWin
+	h2h.OpponentBiggestWin = opponentBiggestWin
+
+	return h2h, nil
+}
+
+// getSeasonNames maps every season ID to its name
+func (s *MatchService) getSeasonNames() (map[int]string, error) {
+	return seasonNames(s.seasons)
+}
+
+// seasonNames maps every season ID in a repository to its name
+func seasonNames(seasons repository.SeasonRepository) (map[int]string, error) {
+	list, err := seasons.ListSeasons()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(list))
+	for _, season := range list {
+		names[season.ID] = season.Name
+	}
+	return names, nil
+}
+
+// addHeadToHeadResult adds one meeting to a record
+func addHeadToHeadResult(record *models.HeadToHeadRecord, goalsFor, goalsAgainst int) {
+	record.Played++
+	record.GoalsFor += goalsFor
+	record.GoalsAgainst += goalsAgainst
+	switch {
+	case goalsFor > goalsAgainst:
+		record.Wins++
+	case goalsFor == goalsAgainst:
+		record.Draws++
+	default:
+		record.Losses++
+	}
+}
+
+// isBiggerWin reports whether match has a wider margin than current, preferring
+// more goals scored and then the earlier meeting
+func isBiggerWin(match, current *models.Match) bool {
+	if current == nil {
+		return true
+	}
+	margin := abs(*match.HomeScore - *match.AwayScore)
+	currentMargin := abs(*current.HomeScore - *current.AwayScore)
+	if margin != currentMargin {
+		return margin > currentMargin
+	}
+	return max(*match.HomeScore, *match.AwayScore) > max(*current.HomeScore, *current.AwayScore)
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
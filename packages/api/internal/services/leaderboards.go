@@ -0,0 +1,221 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+// Leaderboard metrics
+const (
+	MetricGoals         = "goals"
+	MetricAssists       = "assists"
+	MetricContributions = "contributions"
+	MetricPenalties     = "penalties"
+	MetricYellowCards   = "yellowCards"
+	MetricRedCards      = "redCards"
+	MetricCards         = "cards"
+	MetricAppearances   = "appearances"
+	MetricGoalsPer90    = "goalsPer90"
+)
+
+// DefaultMinMinutes is the playing time a player needs by default to appear
+// on a per-90 leaderboard, roughly ten full matches
+const DefaultMinMinutes = 900
+
+// leaderboardMetrics maps each metric to the value players are ranked by
+var leaderboardMetrics = map[string]func(repository.PlayerTotals) float64{
+	MetricGoals:         func(t repository.PlayerTotals) float64 { return float64(t.Goals) },
+	MetricAssists:       func(t repository.PlayerTotals) float64 { return float64(t.Assists) },
+	MetricContributions: func(t repository.PlayerTotals) float64 { return float64(t.Goals + t.Assists) },
+	MetricPenalties:     func(t repository.PlayerTotals) float64 { return float64(t.Penalties) },
+	MetricYellowCards:   func(t repository.PlayerTotals) float64 { return float64(t.YellowCards) },
+	MetricRedCards:      func(t repository.PlayerTotals) float64 { return float64(t.RedCards) },
+	MetricCards:         func(t repository.PlayerTotals) float64 { return float64(t.YellowCards + t.RedCards) },
+	MetricAppearances:   func(t repository.PlayerTotals) float64 { return float64(t.Appearances) },
+	// Rounded so players showing the same rate share a rank
+	MetricGoalsPer90: func(t repository.PlayerTotals) float64 {
+		return math.Round(float64(t.TimedGoals)*90/float64(t.Minutes)*100) / 100
+	},
+}
+
+// LeaderboardMetrics lists the supported metrics in sorted order
+func LeaderboardMetrics() []string {
+	metrics := make([]string, 0, len(leaderboardMetrics))
+	for metric := range leaderboardMetrics {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	return metrics
+}
+
+// LeaderboardOptions selects a leaderboard's metric and scope. With no season
+// or season range the leaderboard covers all time.
+type LeaderboardOptions struct {
+	Metric       string
+	SeasonID     int
+	FromSeasonID int
+	ToSeasonID   int
+	TeamID       int
+	// MinMinutes applies to per-90 metrics only; 0 means DefaultMinMinutes
+	MinMinutes int
+	Limit      int
+}
+
+// LeaderboardService ranks players by their season lines
+type LeaderboardService struct {
+	players repository.PlayerRepository
+	seasons repository.SeasonRepository
+	cache   *cache.Cache
+}
+
+// NewLeaderboardService creates a new leaderboard service. Leaderboards are
+// kept in c, which may be nil.
+func NewLeaderboardService(players repository.PlayerRepository, seasons repository.SeasonRepository, c *cache.Cache) *LeaderboardService {
+	return &LeaderboardService{players: players, seasons: seasons, cache: c}
+}
+
+// seasonsReversed reports whether the from season starts after the to
+// season. Season IDs need not run in date order, so the seasons are looked
+// up; an unknown season simply matches no lines.
+func (s *LeaderboardService) seasonsReversed(fromSeasonID, toSeasonID int) (bool, error) {
+	var starts [2]time.Time
+	for i, id := range []int{fromSeasonID, toSeasonID} {
+		season, err := s.seasons.GetSeason(id)
+		if apperrors.Code(err) == apperrors.CodeNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		starts[i] = season.StartDate
+	}
+	return starts[0].After(starts[1]), nil
+}
+
+// GetLeaderboard ranks the players with a non-zero value for a metric,
+// highest first. Players with equal values share a rank, so the limit can
+// cut a group of joint entries short.
+func (s *LeaderboardService) GetLeaderboard(opts LeaderboardOptions) (*models.Leaderboard, error) {
+	value, ok := leaderboardMetrics[opts.Metric]
+	if !ok {
+		return nil, apperrors.InvalidArgument("unknown metric %q, expected one of %s", opts.Metric, strings.Join(LeaderboardMetrics(), ", "))
+	}
+	if opts.SeasonID > 0 && (opts.FromSeasonID > 0 || opts.ToSeasonID > 0) {
+		return nil, apperrors.InvalidArgument("season cannot be combined with fromSeason or toSeason")
+	}
+	if opts.FromSeasonID > 0 && opts.ToSeasonID > 0 {
+		reversed, err := s.seasonsReversed(opts.FromSeasonID, opts.ToSeasonID)
+		if err != nil {
+			return nil, err
+		}
+		if reversed {
+			return nil, apperrors.InvalidArgument("fromSeason must not be after toSeason")
+		}
+	}
+	if opts.MinMinutes < 0 {
+		return nil, apperrors.InvalidArgument("minMinutes must not be negative")
+	}
+	if opts.Metric == MetricGoalsPer90 {
+		if opts.MinMinutes == 0 {
+			opts.MinMinutes = DefaultMinMinutes
+		}
+	} else {
+		opts.MinMinutes = 0
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 20
+	}
+
+	seasonID := cache.AllSeasons
+	if opts.SeasonID > 0 {
+		seasonID = opts.SeasonID
+	}
+	key := fmt.Sprintf("leaderboard:%s:%d:%d:%d:%d:%d:%d", opts.Metric, opts.SeasonID, opts.FromSeasonID, opts.ToSeasonID, opts.TeamID, opts.MinMinutes, opts.Limit)
+
+	return cache.Fetch(s.cache, key, seasonID, func() (*models.Leaderboard, error) {
+		totals, err := s.players.ListPlayerTotals(repository.PlayerTotalsFilter{
+			SeasonID:     opts.SeasonID,
+			FromSeasonID: opts.FromSeasonID,
+			ToSeasonID:   opts.ToSeasonID,
+			TeamID:       opts.TeamID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries := []models.LeaderboardEntry{}
+		for _, t := range totals {
+			if opts.MinMinutes > 0 && t.Minutes < opts.MinMinutes {
+				continue
+			}
+			v := value(t)
+			if v <= 0 {
+				continue
+			}
+			entries = append(entries, models.LeaderboardEntry{
+				PlayerID:    t.PlayerID,
+				PlayerName:  t.PlayerName,
+				TeamID:      t.TeamID,
+				TeamName:    t.TeamName,
+				Value:       v,
+				Seasons:     t.Seasons,
+				Appearances: t.Appearances,
+				Minutes:     t.Minutes,
+			})
+		}
+
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].Value != entries[j].Value {
+				return entries[i].Value > entries[j].Value
+			}
+			if entries[i].PlayerName != entries[j].PlayerName {
+				return entries[i].PlayerName < entries[j].PlayerName
+			}
+			return entries[i].PlayerID < entries[j].PlayerID
+		})
+
+		ranks, joint := sharedRanks(len(entries), func(i, j int) bool {
+			return entries[i].Value == entries[j].Value
+		})
+		for i := range entries {
+			entries[i].Rank, entries[i].Joint = ranks[i], joint[i]
+		}
+		if len(entries) > opts.Limit {
+			entries = entries[:opts.Limit]
+		}
+
+		return &models.Leaderboard{
+			Metric:       opts.Metric,
+			SeasonID:     opts.SeasonID,
+			FromSeasonID: opts.FromSeasonID,
+			ToSeasonID:   opts.ToSeasonID,
+			TeamID:       opts.TeamID,
+			MinMinutes:   opts.MinMinutes,
+			Entries:      entries,
+		}, nil
+	})
+}
+
+// sharedRanks ranks n sorted entries the way tables print ties: an entry
+// equal to the one before shares its rank and the next rank skips past the
+// group (1, 2, 2, 4). joint marks the entries sharing a rank.
+func sharedRanks(n int, equal func(i, j int) bool) (ranks []int, joint []bool) {
+	ranks = make([]int, n)
+	joint = make([]bool, n)
+	for i := 0; i < n; i++ {
+		ranks[i] = i + 1
+		if i > 0 && equal(i-1, i) {
+			ranks[i] = ranks[i-1]
+			joint[i-1], joint[i] = true, true
+		}
+	}
+	return ranks, joint
+}
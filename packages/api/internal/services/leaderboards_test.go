@@ -0,0 +1,135 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+func TestGetLeaderboard(t *testing.T) {
+	repo := repository.NewMemory()
+	repo.AddSeason(models.Season{ID: 1, Name: "2002/03", StartDate: time.Date(2002, 8, 17, 0, 0, 0, 0, time.UTC)})
+	repo.AddSeason(models.Season{ID: 2, Name: "2003/04", StartDate: time.Date(2003, 8, 16, 0, 0, 0, 0, time.UTC)})
+	arsenal := repo.AddTeam(models.Team{Name: "Arsenal"})
+	chelsea := repo.AddTeam(models.Team{Name: "Chelsea"})
+	henry := repo.AddPlayer(models.Player{Name: "Thierry Henry"})
+	pires := repo.AddPlayer(models.Player{Name: "Robert Pires"})
+	lampard := repo.AddPlayer(models.Player{Name: "Frank Lampard"})
+	gudjohnsen := repo.AddPlayer(models.Player{Name: "Eidur Gudjohnsen"})
+	ninety := func(matches int) *int { minutes := matches * 90; return &minutes }
+
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: henry, SeasonID: 1, TeamID: arsenal, Appearances: 37, Goals: 24, Assists: 20})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: henry, SeasonID: 2, TeamID: arsenal, Appearances: 37, Goals: 30, Assists: 6, Minutes: ninety(37)})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: pires, SeasonID: 2, TeamID: arsenal, Appearances: 36, Goals: 14, YellowCards: 2, Minutes: ninety(30)})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: lampard, SeasonID: 2, TeamID: chelsea, Appearances: 38, Goals: 10, YellowCards: 6, Minutes: ninety(38)})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: gudjohnsen, SeasonID: 2, TeamID: chelsea, Appearances: 26, Goals: 6, Minutes: ninety(5)})
+	// A second club leaves the all-time line without a team
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: lampard, SeasonID: 1, TeamID: arsenal, Appearances: 1, Goals: 4, RedCards: 1})
+
+	service := NewLeaderboardService(repo, repo, nil)
+
+	allTime, err := service.GetLeaderboard(LeaderboardOptions{Metric: MetricGoals})
+	if err != nil {
+		t.Fatalf("GetLeaderboard: %v", err)
+	}
+	if len(allTime.Entries) != 4 || allTime.Entries[0].PlayerID != henry || allTime.Entries[0].Value != 54 || allTime.Entries[0].Seasons != 2 {
+		t.Fatalf("all-time goals = %+v, want Henry's 54 over two seasons first", allTime.Entries)
+	}
+	// Lampard and Pires are level on 14 and share 2nd; Gudjohnsen is 4th
+	ranks := map[int]models.LeaderboardEntry{}
+	for _, entry := range allTime.Entries {
+		ranks[entry.PlayerID] = entry
+	}
+	if l, p, g := ranks[lampard], ranks[pires], ranks[gudjohnsen]; l.Rank != 2 || !l.Joint || p.Rank != 2 || !p.Joint || g.Rank != 4 || g.Joint {
+		t.Errorf("ranks = Lampard %+v, Pires %+v, Gudjohnsen %+v, want joint 2nd then 4th", l, p, g)
+	}
+	if l := ranks[lampard]; l.TeamID != 0 || ranks[pires].TeamID != arsenal {
+		t.Errorf("teams = Lampard %d, Pires %d, want a team only for one-club players", l.TeamID, ranks[pires].TeamID)
+	}
+
+	club, err := service.GetLeaderboard(LeaderboardOptions{Metric: MetricCards, TeamID: chelsea})
+	if err != nil {
+		t.Fatalf("GetLeaderboard: %v", err)
+	}
+	if len(club.Entries) != 1 || club.Entries[0].PlayerID != lampard || club.Entries[0].Value != 6 {
+		t.Errorf("Chelsea cards = %+v, want only Lampard's 6 yellows", club.Entries)
+	}
+
+	season, err := service.GetLeaderboard(LeaderboardOptions{Metric: MetricContributions, SeasonID: 1})
+	if err != nil {
+		t.Fatalf("GetLeaderboard: %v", err)
+	}
+	if len(season.Entries) != 2 || season.Entries[0].Value != 44 {
+		t.Errorf("2002/03 contributions = %+v, want Henry's 44 first", season.Entries)
+	}
+
+	// Only timed seasons count towards the rate, and Gudjohnsen is under the threshold
+	perNinety, err := service.GetLeaderboard(LeaderboardOptions{Metric: MetricGoalsPer90})
+	if err != nil {
+		t.Fatalf("GetLeaderboard: %v", err)
+	}
+	if perNinety.MinMinutes != DefaultMinMinutes || len(perNinety.Entries) != 3 ||
+		perNinety.Entries[0].PlayerID != henry || perNinety.Entries[0].Value != 0.81 {
+		t.Errorf("goals per 90 = %+v, want Henry's 0.81 first without Gudjohnsen", perNinety)
+	}
+
+	limited, err := service.GetLeaderboard(LeaderboardOptions{Metric: MetricGoals, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetLeaderboard: %v", err)
+	}
+	if len(limited.Entries) != 2 {
+		t.Errorf("limited entries = %d, want 2", len(limited.Entries))
+	}
+
+	for _, opts := range []LeaderboardOptions{
+		{Metric: "shots"},
+		{Metric: MetricGoals, SeasonID: 1, ToSeasonID: 2},
+		{Metric: MetricGoals, FromSeasonID: 2, ToSeasonID: 1},
+		{Metric: MetricGoalsPer90, MinMinutes: -1},
+	} {
+		if _, err := service.GetLeaderboard(opts); apperrors.Code(err) != apperrors.CodeInvalidArgument {
+			t.Errorf("GetLeaderboard(%+v) error = %v, want invalid argument", opts, err)
+		}
+	}
+}
+
+func TestSharedRanks(t *testing.T) {
+	values := []int{9, 7, 7, 7, 3}
+	ranks, joint := sharedRanks(len(values), func(i, j int) bool { return values[i] == values[j] })
+
+	want := []int{1, 2, 2, 2, 5}
+	for i := range want {
+		if ranks[i] != want[i] || joint[i] != (i >= 1 && i <= 3) {
+			t.Errorf("entry %d: rank %d joint %v, want rank %d", i, ranks[i], joint[i], want[i])
+		}
+	}
+}
+
+func TestLeaderboardSeasonRangeByStartDate(t *testing.T) {
+	repo := repository.NewMemory()
+	// The earlier season was loaded last and has the higher ID
+	repo.AddSeason(models.Season{ID: 2, Name: "1993/94", StartDate: time.Date(1993, 8, 14, 0, 0, 0, 0, time.UTC)})
+	repo.AddSeason(models.Season{ID: 5, Name: "1992/93", StartDate: time.Date(1992, 8, 15, 0, 0, 0, 0, time.UTC)})
+	repo.AddSeason(models.Season{ID: 3, Name: "1994/95", StartDate: time.Date(1994, 8, 20, 0, 0, 0, 0, time.UTC)})
+	team := repo.AddTeam(models.Team{Name: "Blackburn Rovers"})
+	shearer := repo.AddPlayer(models.Player{Name: "Alan Shearer"})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: shearer, SeasonID: 5, TeamID: team, Goals: 16})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: shearer, SeasonID: 2, TeamID: team, Goals: 31})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: shearer, SeasonID: 3, TeamID: team, Goals: 34})
+
+	service := NewLeaderboardService(repo, repo, nil)
+	board, err := service.GetLeaderboard(LeaderboardOptions{Metric: MetricGoals, FromSeasonID: 5, ToSeasonID: 2})
+	if err != nil {
+		t.Fatalf("GetLeaderboard: %v", err)
+	}
+	if len(board.Entries) != 1 || board.Entries[0].Value != 47 {
+		t.Errorf("1992/93 to 1993/94 = %+v, want Shearer's 47 goals", board.Entries)
+	}
+
+	if _, err := service.GetLeaderboard(LeaderboardOptions{Metric: MetricGoals, FromSeasonID: 3, ToSeasonID: 5}); apperrors.Code(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("range ending before it starts: err = %v, want invalid argument", err)
+	}
+}
@@ -1,436 +1,180 @@
 package services
 
 import (
-	"database/sql"
-	"fmt"
-	"strconv"
+	"cmp"
+	"sort"
 	"time"
 
-	"github.com/premstats/api/internal/database"
 	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+	"github.com/premstats/api/internal/repository"
 )
 
-// MatchService handles match-related database operations
+// MatchService handles match-related operations
 type MatchService struct {
-	db *database.DB
+	matches repository.MatchRepository
+	teams   repository.TeamRepository
+	seasons repository.SeasonRepository
 }
 
 // NewMatchService creates a new match service
-func NewMatchService(db *database.DB) *MatchService {
-	return &MatchService{db: db}
+func NewMatchService(matches repository.MatchRepository, teams repository.TeamRepository, seasons repository.SeasonRepository) *MatchService {
+	return &MatchService{matches: matches, teams: teams, seasons: seasons}
 }
 
-// GetMatchesBySeasonID retrieves all matches for a specific season
-func (s *MatchService) GetMatchesBySeasonID(seasonID int, limit, offset int) ([]models.Match, error) {
-	query := `
-		SELECT 
-			m.id, m.season_id, m.home_team_id, m.away_team_id,
-			ht.name as home_team, at.name as away_team,
-			m.home_score, m.away_score, m.half_time_home, m.half_time_away,
-			m.match_date, m.referee
-		FROM matches m
-		JOIN teams ht ON m.home_team_id = ht.id
-		JOIN teams at ON m.away_team_id = at.id
-		WHERE m.season_id = $1
-		ORDER BY m.match_date ASC, m.id ASC
-		LIMIT $2 OFFSET $3
-	`
-
-	rows, err := s.db.Query(query, seasonID, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query matches for season %d: %w", seasonID, err)
-	}
-	defer rows.Close()
-
-	var matches []models.Match
-	for rows.Next() {
-		match, err := s.scanMatch(rows)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan match row: %w", err)
-		}
-		matches = append(matches, *match)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating match rows: %w", err)
-	}
-
-	return matches, nil
+// GetMatchesBySeasonID returns a page of a season's matches, oldest first
+func (s *MatchService) GetMatchesBySeasonID(seasonID int, req pagination.Request) (*pagination.Page[models.Match], error) {
+	return s.pageMatches(repository.MatchFilter{SeasonID: seasonID, Ascending: true}, req)
 }
 
 // GetMatchByID retrieves a specific match by ID
 func (s *MatchService) GetMatchByID(matchID int) (*models.Match, error) {
-	query := `
-		SELECT 
-			m.id, m.season_id, m.home_team_id, m.away_team_id,
-			ht.name as home_team, at.name as away_team,
-			m.home_score, m.away_score, m.half_time_home, m.half_time_away,
-			m.match_date, m.referee,
-			m.home_shots, m.away_shots, m.home_shots_on_target, m.away_shots_on_target,
-			m.home_corners, m.away_corners, m.home_fouls, m.away_fouls,
-			m.home_yellow_cards, m.away_yellow_cards, m.home_red_cards, m.away_red_cards
-		FROM matches m
-		JOIN teams ht ON m.home_team_id = ht.id
-		JOIN teams at ON m.away_team_id = at.id
-		WHERE m.id = $1
-	`
-
-	row := s.db.QueryRow(query, matchID)
-	match, err := s.scanMatchWithStats(row)
+	match, err := s.matches.GetMatch(matchID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("match with ID %d not found", matchID)
-		}
-		return nil, fmt.Errorf("failed to query match: %w", err)
+		return nil, err
 	}
 
+	match.Status = matchStatus(*match, time.Now())
 	return match, nil
 }
 
-// GetMatches retrieves matches with optional filters
-func (s *MatchService) GetMatches(teamID, seasonID, limit, offset int) ([]models.Match, error) {
-	query := `
-		SELECT 
-			m.id, m.season_id, m.home_team_id, m.away_team_id,
-			ht.name as home_team, at.name as away_team,
-			m.home_score, m.away_score, m.half_time_home, m.half_time_away,
-			m.match_date, m.referee
-		FROM matches m
-		JOIN teams ht ON m.home_team_id = ht.id
-		JOIN teams at ON m.away_team_id = at.id
-		WHERE 1=1
-	`
-
-	args := []interface{}{}
-	argIndex := 1
-
-	if seasonID > 0 {
-		query += " AND m.season_id = $" + strconv.Itoa(argIndex)
-		args = append(args, seasonID)
-		argIndex++
-	}
-
-	if teamID > 0 {
-		query += " AND (m.home_team_id = $" + strconv.Itoa(argIndex) + " OR m.away_team_id = $" + strconv.Itoa(argIndex) + ")"
-		args = append(args, teamID)
-		argIndex++
-	}
-
-	query += " ORDER BY m.match_date DESC, m.id DESC"
-
-	if limit > 0 {
-		query += " LIMIT $" + strconv.Itoa(argIndex)
-		args = append(args, limit)
-		argIndex++
-	}
-
-	if offset > 0 {
-		query += " OFFSET $" + strconv.Itoa(argIndex)
-		args = append(args, offset)
-	}
-
-	rows, err := s.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query matches: %w", err)
-	}
-	defer rows.Close()
-
-	var matches []models.Match
-	for rows.Next() {
-		match, err := s.scanMatch(rows)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan match row: %w", err)
-		}
-		matches = append(matches, *match)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating match rows: %w", err)
-	}
+// Match orders accepted by GetMatches
+const (
+	MatchSortDate   = repository.MatchSortDate
+	MatchSortGoals  = repository.MatchSortGoals
+	MatchSortMargin = repository.MatchSortMargin
+)
 
-	return matches, nil
+// MatchOptions filters and orders a match listing. Zero values leave a
+// filter unset; Venue, Result and the scoreline are from TeamID's point of view.
+type MatchOptions struct {
+	SeasonID   int
+	TeamID     int
+	OpponentID int
+	// Venue is home or away; empty means both
+	Venue string
+	// From and To bound the match date, both inclusive
+	From *time.Time
+	To   *time.Time
+	// Result is W, D or L
+	Result string
+	// Referee matches part of the referee's name, ignoring case
+	Referee string
+	// GoalsFor and GoalsAgainst select a scoreline; without a team they are
+	// the home and away scores
+	GoalsFor     *int
+	GoalsAgainst *int
+	MinGoals     int
+	// Comeback keeps matches won after trailing at half time
+	Comeback bool
+	// Sort is date, goals or margin; empty means date
+	Sort      string
+	Ascending bool
 }
 
-// scanMatch scans a database row into a Match model
-func (s *MatchService) scanMatch(scanner interface{}) (*models.Match, error) {
-	var match models.Match
-	var homeScore, awayScore, halftimeHome, halftimeAway sql.NullInt32
-	var referee sql.NullString
-
-	var err error
-	switch s := scanner.(type) {
-	case *sql.Rows:
-		err = s.Scan(
-			&match.ID, &match.SeasonID, &match.HomeTeamID, &match.AwayTeamID,
-			&match.HomeTeam, &match.AwayTeam,
-			&homeScore, &awayScore, &halftimeHome, &halftimeAway,
-			&match.MatchDate, &referee,
-		)
-	case *sql.Row:
-		err = s.Scan(
-			&match.ID, &match.SeasonID, &match.HomeTeamID, &match.AwayTeamID,
-			&match.HomeTeam, &match.AwayTeam,
-			&homeScore, &awayScore, &halftimeHome, &halftimeAway,
-			&match.MatchDate, &referee,
-		)
-	default:
-		return nil, fmt.Errorf("unsupported scanner type")
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Handle nullable fields
-	if homeScore.Valid {
-		score := int(homeScore.Int32)
-		match.HomeScore = &score
-	}
-	if awayScore.Valid {
-		score := int(awayScore.Int32)
-		match.AwayScore = &score
-	}
-	if halftimeHome.Valid {
-		ht := int(halftimeHome.Int32)
-		match.HalfTimeHome = &ht
-	}
-	if halftimeAway.Valid {
-		ht := int(halftimeAway.Int32)
-		match.HalfTimeAway = &ht
-	}
-	if referee.Valid {
-		match.Referee = referee.String
-	}
-
-	// Set status based on whether scores are available
-	if match.HomeScore != nil && match.AwayScore != nil {
-		match.Status = "completed"
-	} else if match.MatchDate.Before(time.Now()) {
-		match.Status = "pending"
-	} else {
-		match.Status = "scheduled"
-	}
-
-	return &match, nil
+// GetMatches returns a page of matches selected and ordered by opts. By
+// default the newest are listed first.
+func (s *MatchService) GetMatches(opts MatchOptions, req pagination.Request) (*pagination.Page[models.Match], error) {
+	return s.pageMatches(repository.MatchFilter{
+		SeasonID:     opts.SeasonID,
+		TeamID:       opts.TeamID,
+		OpponentID:   opts.OpponentID,
+		Venue:        opts.Venue,
+		FromDate:     opts.From,
+		ToDate:       opts.To,
+		Result:       opts.Result,
+		Referee:      opts.Referee,
+		GoalsFor:     opts.GoalsFor,
+		GoalsAgainst: opts.GoalsAgainst,
+		MinGoals:     opts.MinGoals,
+		Comeback:     opts.Comeback,
+		Sort:         opts.Sort,
+		Ascending:    opts.Ascending,
+	}, req)
 }
 
-// scanMatchWithStats scans a database row into a Match model including statistics
-func (s *MatchService) scanMatchWithStats(scanner interface{}) (*models.Match, error) {
-	var match models.Match
-	var homeScore, awayScore, halftimeHome, halftimeAway sql.NullInt32
-	var referee sql.NullString
-	var homeShots, awayShots, homeShotsOnTarget, awayShotsOnTarget sql.NullInt32
-	var homeCorners, awayCorners, homeFouls, awayFouls sql.NullInt32
-	var homeYellowCards, awayYellowCards, homeRedCards, awayRedCards sql.NullInt32
-
-	var err error
-	switch s := scanner.(type) {
-	case *sql.Rows:
-		err = s.Scan(
-			&match.ID, &match.SeasonID, &match.HomeTeamID, &match.AwayTeamID,
-			&match.HomeTeam, &match.AwayTeam,
-			&homeScore, &awayScore, &halftimeHome, &halftimeAway,
-			&match.MatchDate, &referee,
-			&homeShots, &awayShots, &homeShotsOnTarget, &awayShotsOnTarget,
-			&homeCorners, &awayCorners, &homeFouls, &awayFouls,
-			&homeYellowCards, &awayYellowCards, &homeRedCards, &awayRedCards,
-		)
-	case *sql.Row:
-		err = s.Scan(
-			&match.ID, &match.SeasonID, &match.HomeTeamID, &match.AwayTeamID,
-			&match.HomeTeam, &match.AwayTeam,
-			&homeScore, &awayScore, &halftimeHome, &halftimeAway,
-			&match.MatchDate, &referee,
-			&homeShots, &awayShots, &homeShotsOnTarget, &awayShotsOnTarget,
-			&homeCorners, &awayCorners, &homeFouls, &awayFouls,
-			&homeYellowCards, &awayYellowCards, &homeRedCards, &awayRedCards,
-		)
-	default:
-		return nil, fmt.Errorf("unsupported scanner type")
-	}
+// pageMatches loads one page of the matches selected by a filter
+func (s *MatchService) pageMatches(filter repository.MatchFilter, req pagination.Request) (*pagination.Page[models.Match], error) {
+	filter.Offset = req.Offset
+	return pagination.Load(req, repository.MatchKeyOf(filter.Sort),
+		func(seek *pagination.Seek[repository.MatchKey], limit int) ([]models.Match, error) {
+			filter.Seek, filter.Limit = seek, limit
+			return s.listMatches(filter)
+		},
+		func() (int, error) { return s.matches.CountMatches(filter) },
+	)
+}
 
+// listMatches retrieves matches and sets their status
+func (s *MatchService) listMatches(filter repository.MatchFilter) ([]models.Match, error) {
+	matches, err := s.matches.ListMatches(filter)
 	if err != nil {
 		return nil, err
 	}
 
-	// Handle nullable fields
-	if homeScore.Valid {
-		score := int(homeScore.Int32)
-		match.HomeScore = &score
-	}
-	if awayScore.Valid {
-		score := int(awayScore.Int32)
-		match.AwayScore = &score
-	}
-	if halftimeHome.Valid {
-		ht := int(halftimeHome.Int32)
-		match.HalfTimeHome = &ht
-	}
-	if halftimeAway.Valid {
-		ht := int(halftimeAway.Int32)
-		match.HalfTimeAway = &ht
-	}
-	if referee.Valid {
-		match.Referee = referee.String
-	}
-
-	// Handle statistics fields
-	if homeShots.Valid {
-		val := int(homeShots.Int32)
-		match.HomeShots = &val
-	}
-	if awayShots.Valid {
-		val := int(awayShots.Int32)
-		match.AwayShots = &val
-	}
-	if homeShotsOnTarget.Valid {
-		val := int(homeShotsOnTarget.Int32)
-		match.HomeShotsOnTarget = &val
-	}
-	if awayShotsOnTarget.Valid {
-		val := int(awayShotsOnTarget.Int32)
-		match.AwayShotsOnTarget = &val
-	}
-	if homeCorners.Valid {
-		val := int(homeCorners.Int32)
-		match.HomeCorners = &val
-	}
-	if awayCorners.Valid {
-		val := int(awayCorners.Int32)
-		match.AwayCorners = &val
-	}
-	if homeFouls.Valid {
-		val := int(homeFouls.Int32)
-		match.HomeFouls = &val
-	}
-	if awayFouls.Valid {
-		val := int(awayFouls.Int32)
-		match.AwayFouls = &val
-	}
-	if homeYellowCards.Valid {
-		val := int(homeYellowCards.Int32)
-		match.HomeYellowCards = &val
-	}
-	if awayYellowCards.Valid {
-		val := int(awayYellowCards.Int32)
-		match.AwayYellowCards = &val
-	}
-	if homeRedCards.Valid {
-		val := int(homeRedCards.Int32)
-		match.HomeRedCards = &val
-	}
-	if awayRedCards.Valid {
-		val := int(awayRedCards.Int32)
-		match.AwayRedCards = &val
+	now := time.Now()
+	for i := range matches {
+		matches[i].Status = matchStatus(matches[i], now)
 	}
+	return matches, nil
+}
 
-	// Set status based on whether scores are available
+// matchStatus reports whether a match is completed, awaiting a result or
+// still to be played at the given time
+func matchStatus(match models.Match, now time.Time) string {
 	if match.HomeScore != nil && match.AwayScore != nil {
-		match.Status = "completed"
-	} else if match.MatchDate.Before(time.Now()) {
-		match.Status = "pending"
-	} else {
-		match.Status = "scheduled"
+		return "completed"
 	}
-
-	return &match, nil
+	if match.MatchDate.Before(now) {
+		return "pending"
+	}
+	return "scheduled"
 }
 
-// GetMatchEvents returns all events for a match
-func (s *MatchService) GetMatchEvents(matchID int) ([]models.MatchEvent, error) {
-	var events []models.MatchEvent
+// eventKey is the sort key of a match's events
+type eventKey struct {
+	Minute int    `json:"m"`
+	Type   string `json:"t"`
+	ID     int    `json:"i"`
+}
 
-	// First, get goals from the goals table (deduplicate by minute, player, team)
-	goalQuery := `
-		SELECT MIN(g.id) as id, g.match_id, 'goal' as event_type, g.minute,
-			   g.player_id, p.name as player_name, g.team_id, 
-			   CASE WHEN bool_or(g.is_penalty) THEN 'Penalty' ELSE NULL END as detail
-		FROM goals g
-		LEFT JOIN players p ON g.player_id = p.id
-		WHERE g.match_id = $1
-		GROUP BY g.match_id, g.minute, g.player_id, p.name, g.team_id
-		ORDER BY g.minute, MIN(g.id)
-	`
+// eventKeyOf returns an event's position in its match's timeline
+func eventKeyOf(event models.MatchEvent) eventKey {
+	return eventKey{Minute: event.Minute, Type: event.EventType, ID: event.ID}
+}
 
-	goalRows, err := s.db.Query(goalQuery, matchID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query goals: %w", err)
+// compareEventKeys orders events by minute, then type and ID
+func compareEventKeys(a, b eventKey) int {
+	if c := cmp.Compare(a.Minute, b.Minute); c != 0 {
+		return c
 	}
-	defer goalRows.Close()
-
-	for goalRows.Next() {
-		var event models.MatchEvent
-		var playerName sql.NullString
-		var detail sql.NullString
-
-		err := goalRows.Scan(
-			&event.ID,
-			&event.MatchID,
-			&event.EventType,
-			&event.Minute,
-			&event.PlayerID,
-			&playerName,
-			&event.TeamID,
-			&detail,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan goal event: %w", err)
-		}
-
-		if playerName.Valid {
-			event.PlayerName = playerName.String
-		}
-		if detail.Valid {
-			event.Detail = detail.String
-		}
-
-		events = append(events, event)
+	if c := cmp.Compare(a.Type, b.Type); c != 0 {
+		return c
 	}
+	return cmp.Compare(a.ID, b.ID)
+}
 
-	// Then, get other events from match_events table (if it exists)
-	eventQuery := `
-		SELECT me.id, me.match_id, me.event_type, me.minute, 
-			   me.player_id, p.name as player_name, me.team_id, me.detail
-		FROM match_events me
-		LEFT JOIN players p ON me.player_id = p.id
-		WHERE me.match_id = $1
-	`
-
-	eventRows, err := s.db.Query(eventQuery, matchID)
+// GetMatchEvents returns a page of a match's events in minute order. A match
+// has few events, so they are loaded whole and paged in memory.
+func (s *MatchService) GetMatchEvents(matchID int, req pagination.Request) (*pagination.Page[models.MatchEvent], error) {
+	events, err := s.matches.ListMatchEvents(matchID)
 	if err != nil {
-		// If match_events table doesn't exist or query fails, just return goals
-		return events, nil
+		return nil, err
 	}
-	defer eventRows.Close()
+	sort.Slice(events, func(i, j int) bool {
+		return compareEventKeys(eventKeyOf(events[i]), eventKeyOf(events[j])) < 0
+	})
 
-	for eventRows.Next() {
-		var event models.MatchEvent
-		var playerName sql.NullString
-		var detail sql.NullString
-
-		err := eventRows.Scan(
-			&event.ID,
-			&event.MatchID,
-			&event.EventType,
-			&event.Minute,
-			&event.PlayerID,
-			&playerName,
-			&event.TeamID,
-			&detail,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan match event: %w", err)
-		}
-
-		if playerName.Valid {
-			event.PlayerName = playerName.String
-		}
-		if detail.Valid {
-			event.Detail = detail.String
-		}
-
-		events = append(events, event)
-	}
+	return pagination.Load(req, eventKeyOf,
+		func(seek *pagination.Seek[eventKey], limit int) ([]models.MatchEvent, error) {
+			return pagination.Apply(events, seek, req.Offset, limit, eventKeyOf, compareEventKeys), nil
+		},
+		func() (int, error) { return len(events), nil },
+	)
+}
 
-	return events, nil
+// GetTeamDiscipline totals cards and fouls per team for a season, counting
+// only matches where card statistics were recorded
+func (s *MatchService) GetTeamDiscipline(seasonID int) ([]models.TeamDiscipline, error) {
+	return s.matches.ListTeamDiscipline(seasonID)
 }
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+	"github.com/premstats/api/internal/repository"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestMatchStatus(t *testing.T) {
+	now := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		match models.Match
+		want  string
+	}{
+		{"scored", models.Match{HomeScore: intPtr(1), AwayScore: intPtr(0), MatchDate: now.AddDate(0, 0, -1)}, "completed"},
+		{"goalless", models.Match{HomeScore: intPtr(0), AwayScore: intPtr(0), MatchDate: now.AddDate(0, 0, -1)}, "completed"},
+		{"past without score", models.Match{MatchDate: now.AddDate(0, 0, -1)}, "pending"},
+		{"half a score", models.Match{HomeScore: intPtr(2), MatchDate: now.AddDate(0, 0, -1)}, "pending"},
+		{"future", models.Match{MatchDate: now.AddDate(0, 0, 1)}, "scheduled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchStatus(tt.match, now); got != tt.want {
+				t.Errorf("matchStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMatchesSetsStatus(t *testing.T) {
+	repo := repository.NewMemory()
+	repo.AddSeason(models.Season{ID: 1, Name: "1992/93"})
+	home := repo.AddTeam(models.Team{Name: "Arsenal"})
+	away := repo.AddTeam(models.Team{Name: "Chelsea"})
+	played := repo.AddMatch(models.Match{SeasonID: 1, HomeTeamID: home, AwayTeamID: away,
+		HomeScore: intPtr(3), AwayScore: intPtr(1), MatchDate: time.Now().AddDate(0, 0, -7)})
+	fixture := repo.AddMatch(models.Match{SeasonID: 1, HomeTeamID: away, AwayTeamID: home,
+		MatchDate: time.Now().AddDate(0, 0, 7)})
+
+	service := NewMatchService(repo, repo, repo)
+	page, err := service.GetMatchesBySeasonID(1, pagination.Request{Limit: 50})
+	if err != nil {
+		t.Fatalf("GetMatchesBySeasonID: %v", err)
+	}
+	want := map[int]string{played: "completed", fixture: "scheduled"}
+	for _, match := range page.Items {
+		if match.Status != want[match.ID] {
+			t.Errorf("match %d status = %q, want %q", match.ID, match.Status, want[match.ID])
+		}
+	}
+
+	match, err := service.GetMatchByID(fixture)
+	if err != nil {
+		t.Fatalf("GetMatchByID: %v", err)
+	}
+	if match.Status != "scheduled" {
+		t.Errorf("GetMatchByID status = %q, want scheduled", match.Status)
+	}
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"cmp"
+	"sort"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/identity"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+	"github.com/premstats/api/internal/repository"
+)
+
+// DefaultMatchConfidence is the lowest score the review queue shows by
+// default, and the lowest a pair must score to be merged
+const DefaultMatchConfidence = 0.5
+
+// candidatesCacheKey caches every scored pair, which is built from all players
+const candidatesCacheKey = "player-match-candidates"
+
+// PlayerIdentityService finds player records that may be the same person and
+// merges the ones a reviewer confirms
+type PlayerIdentityService struct {
+	players    repository.PlayerRepository
+	identities repository.PlayerIdentityRepository
+	cache      *cache.Cache
+}
+
+// NewPlayerIdentityService creates a new player identity service. Scored
+// pairs are cached until player data changes; merges change aggregates built
+// from player data, so they empty this process's cache at once and the
+// repository notifies every other process's.
+func NewPlayerIdentityService(players repository.PlayerRepository, identities repository.PlayerIdentityRepository, c *cache.Cache) *PlayerIdentityService {
+	return &PlayerIdentityService{players: players, identities: identities, cache: c}
+}
+
+// candidateKey is the sort key of the review queue
+type candidateKey struct {
+	Confidence float64 `json:"c"`
+	PlayerID   int     `json:"p"`
+	Duplicate  int     `json:"d"`
+}
+
+// candidateKeyOf returns a candidate's position in the review queue
+func candidateKeyOf(c models.PlayerMatchCandidate) candidateKey {
+	return candidateKey{Confidence: c.Confidence, PlayerID: c.Player.ID, Duplicate: c.Duplicate.ID}
+}
+
+// compareCandidateKeys orders candidates most likely first, then by IDs
+func compareCandidateKeys(a, b candidateKey) int {
+	if c := cmp.Compare(b.Confidence, a.Confidence); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.PlayerID, b.PlayerID); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Duplicate, b.Duplicate)
+}
+
+// GetReviewQueue returns a page of suspected duplicate pairs scoring at least
+// minConfidence, most likely first. Pairs already rejected are left out.
+// Candidates are scored once and cached, so they are paged in memory.
+func (s *PlayerIdentityService) GetReviewQueue(minConfidence float64, req pagination.Request) (*pagination.Page[models.PlayerMatchCandidate], error) {
+	if minConfidence < 0 || minConfidence > 1 {
+		return nil, apperrors.InvalidArgument("minConfidence must be between 0 and 1")
+	}
+
+	candidates, err := s.findCandidates(minConfidence)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.Load(req, candidateKeyOf,
+		func(seek *pagination.Seek[candidateKey], limit int) ([]models.PlayerMatchCandidate, error) {
+			return pagination.Apply(candidates, seek, req.Offset, limit, candidateKeyOf, compareCandidateKeys), nil
+		},
+		func() (int, error) { return len(candidates), nil },
+	)
+}
+
+// findCandidates returns the scored pairs at or above minConfidence that
+// have not been rejected, most likely first
+func (s *PlayerIdentityService) findCandidates(minConfidence float64) ([]models.PlayerMatchCandidate, error) {
+	scored, err := cache.Fetch(s.cache, candidatesCacheKey, cache.AllSeasons, s.scoreCandidates)
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := s.identities.ListRejectedPlayerPairs()
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[[2]int]bool, len(rejected))
+	for _, pair := range rejected {
+		skip[pair] = true
+	}
+
+	candidates := make([]models.PlayerMatchCandidate, 0, len(scored))
+	for _, candidate := range scored {
+		if candidate.Confidence >= minConfidence && !skip[[2]int{candidate.Player.ID, candidate.Duplicate.ID}] {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+// scoreCandidates scores every pair of players sharing a blocking key, so
+// only players with the same surname or date of birth are compared. Pairs
+// with any chance of being one person are kept, lower player ID first.
+func (s *PlayerIdentityService) scoreCandidates() ([]models.PlayerMatchCandidate, error) {
+	players, err := s.players.ListPlayers(repository.PlayerFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make(map[string][]models.Player)
+	for _, player := range players {
+		for _, key := range identity.PlayerBlockingKeys(player) {
+			blocks[key] = append(blocks[key], player)
+		}
+	}
+
+	compared := make(map[[2]int]bool)
+	var candidates []models.PlayerMatchCandidate
+	for _, block := range blocks {
+		for i, first := range block {
+			for _, second := range block[i+1:] {
+				a, b := first, second
+				if a.ID > b.ID {
+					a, b = b, a
+				}
+				pair := [2]int{a.ID, b.ID}
+				if a.ID == b.ID || compared[pair] {
+					continue
+				}
+				compared[pair] = true
+
+				match := identity.MatchPlayers(a, b)
+				if match.Confidence == 0 {
+					continue
+				}
+				candidates = append(candidates, models.PlayerMatchCandidate{
+					Player:     a,
+					Duplicate:  b,
+					Confidence: match.Confidence,
+					Reasons:    match.Reasons,
+				})
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return compareCandidateKeys(candidateKeyOf(candidates[i]), candidateKeyOf(candidates[j])) < 0
+	})
+	return candidates, nil
+}
+
+// MergePlayers folds duplicateID into playerID, moving its goals, events and
+// season lines, and returns what was moved. Merges cannot be undone, so only
+// a queued pair scoring at least DefaultMatchConfidence can be merged.
+func (s *PlayerIdentityService) MergePlayers(playerID, duplicateID int) (*models.PlayerMergeResult, error) {
+	if _, err := s.matchPair(playerID, duplicateID); err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.findCandidates(DefaultMatchConfidence)
+	if err != nil {
+		return nil, err
+	}
+	pair := [2]int{min(playerID, duplicateID), max(playerID, duplicateID)}
+	var candidate *models.PlayerMatchCandidate
+	for i := range candidates {
+		if candidates[i].Player.ID == pair[0] && candidates[i].Duplicate.ID == pair[1] {
+			candidate = &candidates[i]
+			break
+		}
+	}
+	if candidate == nil {
+		return nil, apperrors.InvalidArgument(
+			"players %d and %d are not a duplicate candidate scoring at least %g", playerID, duplicateID, DefaultMatchConfidence)
+	}
+
+	result, err := s.identities.MergePlayers(playerID, duplicateID, candidate.Confidence)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.InvalidateAll()
+	return &result, nil
+}
+
+// RejectCandidate records that two players are different people, removing
+// the pair from the review queue
+func (s *PlayerIdentityService) RejectCandidate(playerID, duplicateID int) error {
+	match, err := s.matchPair(playerID, duplicateID)
+	if err != nil {
+		return err
+	}
+	return s.identities.RejectPlayerPair(playerID, duplicateID, match.Confidence)
+}
+
+// matchPair scores two existing, distinct players
+func (s *PlayerIdentityService) matchPair(playerID, duplicateID int) (identity.PlayerMatch, error) {
+	if playerID == duplicateID {
+		return identity.PlayerMatch{}, apperrors.InvalidArgument("a player cannot be matched with itself")
+	}
+
+	player, err := s.players.GetPlayer(playerID)
+	if err != nil {
+		return identity.PlayerMatch{}, err
+	}
+	duplicate, err := s.players.GetPlayer(duplicateID)
+	if err != nil {
+		return identity.PlayerMatch{}, err
+	}
+	return identity.MatchPlayers(*player, *duplicate), nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/cache"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+	"github.com/premstats/api/internal/repository"
+)
+
+func TestPlayerReviewQueue(t *testing.T) {
+	repo := repository.NewMemory()
+	odegaard := repo.AddPlayer(models.Player{Name: "Martin Ødegaard", DateOfBirth: "1998-12-17"})
+	duplicate := repo.AddPlayer(models.Player{Name: "Martin Odegaard", DateOfBirth: "1998-12-17"})
+	andy := repo.AddPlayer(models.Player{Name: "Andy Cole"})
+	andrew := repo.AddPlayer(models.Player{Name: "Andrew Cole"})
+	repo.AddPlayer(models.Player{Name: "Ashley Cole", DateOfBirth: "1980-12-20"})
+
+	service := NewPlayerIdentityService(repo, repo, nil)
+	page, err := service.GetReviewQueue(DefaultMatchConfidence, pagination.Request{Limit: 10})
+	if err != nil {
+		t.Fatalf("GetReviewQueue: %v", err)
+	}
+	if page.Total != 2 {
+		t.Fatalf("queue has %d candidates, want 2: %+v", page.Total, page.Items)
+	}
+	if first := page.Items[0]; first.Player.ID != odegaard || first.Duplicate.ID != duplicate {
+		t.Errorf("first candidate = %d/%d, want the birth date match first", first.Player.ID, first.Duplicate.ID)
+	}
+
+	if err := service.RejectCandidate(andrew, andy); err != nil {
+		t.Fatalf("RejectCandidate: %v", err)
+	}
+	page, _ = service.GetReviewQueue(DefaultMatchConfidence, pagination.Request{Limit: 10})
+	if page.Total != 1 {
+		t.Errorf("rejected pair still queued: %+v", page.Items)
+	}
+	if _, err := service.MergePlayers(andy, andrew); apperrors.Code(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("merging a rejected pair: err = %v, want invalid argument", err)
+	}
+
+	if _, err := service.GetReviewQueue(1.5, pagination.Request{Limit: 10}); apperrors.Code(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("confidence above 1: err = %v, want invalid argument", err)
+	}
+}
+
+func TestMergePlayers(t *testing.T) {
+	repo := repository.NewMemory()
+	repo.AddSeason(models.Season{ID: 1, Name: "2003/04"})
+	repo.AddSeason(models.Season{ID: 2, Name: "2004/05"})
+	team := repo.AddTeam(models.Team{Name: "Arsenal"})
+	kept := repo.AddPlayer(models.Player{Name: "Thierry Henry"})
+	duplicate := repo.AddPlayer(models.Player{Name: "T. Henry", DateOfBirth: "1977-08-17", Nationality: "France"})
+	repo.AddMatchEvent(models.MatchEvent{MatchID: 1, EventType: "goal", PlayerID: duplicate, TeamID: team})
+	repo.AddMatchEvent(models.MatchEvent{MatchID: 1, EventType: "yellow_card", PlayerID: duplicate, TeamID: team})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: kept, SeasonID: 1, TeamID: team, Goals: 20, Penalties: 1})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: duplicate, SeasonID: 1, TeamID: team, Goals: 10, Penalties: 2,
+		OwnGoals: 1, Minutes: intPtr(900)})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: duplicate, SeasonID: 2, TeamID: team, Goals: 25})
+	repo.AddSquadMember(2, team, duplicate)
+
+	namesake := repo.AddPlayer(models.Player{Name: "Ashley Henry"})
+
+	service := NewPlayerIdentityService(repo, repo, nil)
+	if _, err := service.MergePlayers(kept, namesake); apperrors.Code(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("merging a pair below the confidence floor: err = %v, want invalid argument", err)
+	}
+	result, err := service.MergePlayers(kept, duplicate)
+	if err != nil {
+		t.Fatalf("MergePlayers: %v", err)
+	}
+	want := models.PlayerMergeResult{PlayerID: kept, MergedID: duplicate, Goals: 1, MatchEvents: 1, PlayerStats: 2, SquadMemberships: 1}
+	if *result != want {
+		t.Errorf("result = %+v, want %+v", *result, want)
+	}
+
+	if _, err := repo.GetPlayer(duplicate); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("merged player still exists: %v", err)
+	}
+	player, _ := repo.GetPlayer(kept)
+	if player.DateOfBirth != "1977-08-17" || player.Nationality != "France" {
+		t.Errorf("kept player = %+v, want the duplicate's details filled in", player)
+	}
+
+	stats, _ := repo.ListPlayerStats(kept, 0)
+	goals := map[int]int{}
+	for _, s := range stats {
+		goals[s.SeasonID] += s.Goals
+		if s.SeasonID == 1 && (s.Penalties != 3 || s.OwnGoals != 1 || s.Minutes == nil || *s.Minutes != 900) {
+			t.Errorf("merged 2003/04 line = %+v, want penalties, own goals and minutes added", s)
+		}
+	}
+	if len(stats) != 2 || goals[1] != 30 || goals[2] != 25 {
+		t.Errorf("stats after merge = %+v, want one line per season with goals added", stats)
+	}
+	events, _ := repo.ListMatchEvents(1)
+	for _, event := range events {
+		if event.PlayerID != kept {
+			t.Errorf("event %d still belongs to player %d", event.ID, event.PlayerID)
+		}
+	}
+
+	if _, err := service.MergePlayers(kept, kept); apperrors.Code(err) != apperrors.CodeInvalidArgument {
+		t.Errorf("self merge: err = %v, want invalid argument", err)
+	}
+	if _, err := service.MergePlayers(kept, duplicate); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("repeat merge: err = %v, want not found", err)
+	}
+}
+
+func TestPlayerReviewQueueCached(t *testing.T) {
+	repo := repository.NewMemory()
+	repo.AddPlayer(models.Player{Name: "Andy Cole"})
+	repo.AddPlayer(models.Player{Name: "Andrew Cole"})
+
+	service := NewPlayerIdentityService(repo, repo, cache.New(time.Minute))
+	if page, _ := service.GetReviewQueue(DefaultMatchConfidence, pagination.Request{Limit: 10}); page.Total != 1 {
+		t.Fatalf("queue has %d candidates, want 1", page.Total)
+	}
+
+	// Players added since are not scored until the cache is dropped
+	repo.AddPlayer(models.Player{Name: "Andy Cole"})
+	if page, _ := service.GetReviewQueue(DefaultMatchConfidence, pagination.Request{Limit: 10}); page.Total != 1 {
+		t.Errorf("queue rescored without invalidation: %d candidates", page.Total)
+	}
+	service.cache.InvalidateAll()
+	if page, _ := service.GetReviewQueue(DefaultMatchConfidence, pagination.Request{Limit: 10}); page.Total != 3 {
+		t.Errorf("queue after invalidation has %d candidates, want 3", page.Total)
+	}
+}
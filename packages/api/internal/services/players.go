@@ -1,257 +1,178 @@
 package services
 
 import (
-	"database/sql"
-	"fmt"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/apperrors"
 	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+	"github.com/premstats/api/internal/repository"
 )
 
-// PlayerService handles player-related database operations
+// PlayerService handles player-related operations
 type PlayerService struct {
-	db *database.DB
+	players repository.PlayerRepository
+	seasons repository.SeasonRepository
+	now     func() time.Time
 }
 
 // NewPlayerService creates a new player service instance
-func NewPlayerService(db *database.DB) *PlayerService {
-	return &PlayerService{db: db}
+func NewPlayerService(players repository.PlayerRepository, seasons repository.SeasonRepository) *PlayerService {
+	return &PlayerService{players: players, seasons: seasons, now: time.Now}
 }
 
-// GetPlayers returns all players with optional filters
-func (s *PlayerService) GetPlayers(limit, offset int, search, position, nationality, team string) ([]models.Player, error) {
-	query := `
-		SELECT DISTINCT p.id, p.name, p.date_of_birth, p.nationality, p.position, 
-		       p.current_team_id, t.name as team_name
-		FROM players p
-		LEFT JOIN teams t ON p.current_team_id = t.id
-		WHERE 1=1
-	`
-	args := []interface{}{}
-	argIndex := 1
-
-	// Add search filter
-	if search != "" {
-		query += fmt.Sprintf(" AND p.name ILIKE $%d", argIndex)
-		args = append(args, "%"+search+"%")
-		argIndex++
-	}
-
-	// Add position filter
-	if position != "" {
-		query += fmt.Sprintf(" AND p.position = $%d", argIndex)
-		args = append(args, position)
-		argIndex++
-	}
-
-	// Add nationality filter
-	if nationality != "" {
-		query += fmt.Sprintf(" AND p.nationality = $%d", argIndex)
-		args = append(args, nationality)
-		argIndex++
-	}
-
-	// Add team filter (by team ID)
-	if team != "" {
-		teamID, err := strconv.Atoi(team)
-		if err == nil {
-			query += fmt.Sprintf(" AND p.current_team_id = $%d", argIndex)
-			args = append(args, teamID)
-			argIndex++
-		}
-	}
-
-	query += " ORDER BY p.name"
+// GetPlayers returns a page of players with optional filters. When season is
+// set, only players in a squad for that season are returned and team filters
+// and team names refer to that season's squad rather than the current team.
+func (s *PlayerService) GetPlayers(req pagination.Request, search, position, nationality, team, season string) (*pagination.Page[models.Player], error) {
+	filter, err := playerFilter(search, position, nationality, team, season)
+	if err != nil {
+		return nil, err
+	}
+	filter.Offset = req.Offset
+	return pagination.Load(req, repository.PlayerKeyOf,
+		func(seek *pagination.Seek[repository.NameKey], limit int) ([]models.Player, error) {
+			filter.Seek, filter.Limit = seek, limit
+			return s.players.ListPlayers(filter)
+		},
+		func() (int, error) { return s.players.CountPlayers(filter) },
+	)
+}
 
-	// Add pagination
-	if limit > 0 {
-		query += fmt.Sprintf(" LIMIT $%d", argIndex)
-		args = append(args, limit)
-		argIndex++
+// playerFilter builds a player filter from query values; team and season
+// must be positive IDs when given
+func playerFilter(search, position, nationality, team, season string) (repository.PlayerFilter, error) {
+	filter := repository.PlayerFilter{
+		Search:      search,
+		Position:    position,
+		Nationality: nationality,
 	}
-	if offset > 0 {
-		query += fmt.Sprintf(" OFFSET $%d", argIndex)
-		args = append(args, offset)
+	ids := []struct {
+		param, value string
+		dest         *int
+	}{
+		{"season", season, &filter.SeasonID},
+		{"team", team, &filter.TeamID},
 	}
-
-	rows, err := s.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query players: %w", err)
-	}
-	defer rows.Close()
-
-	var players []models.Player
-	for rows.Next() {
-		var p models.Player
-		var dateOfBirth sql.NullString
-		var nationality sql.NullString
-		var position sql.NullString
-		var teamID sql.NullInt32
-		var teamName sql.NullString
-
-		err := rows.Scan(&p.ID, &p.Name, &dateOfBirth, &nationality, &position, &teamID, &teamName)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan player: %w", err)
-		}
-
-		if dateOfBirth.Valid {
-			p.DateOfBirth = dateOfBirth.String
+	for _, id := range ids {
+		if id.value == "" {
+			continue
 		}
-		if nationality.Valid {
-			p.Nationality = nationality.String
+		n, err := strconv.Atoi(id.value)
+		if err != nil || n <= 0 {
+			return filter, apperrors.InvalidArgument("invalid %s %q, expected a positive ID", id.param, id.value)
 		}
-		if position.Valid {
-			p.Position = position.String
-		}
-		if teamID.Valid {
-			p.TeamID = int(teamID.Int32)
-		}
-		if teamName.Valid {
-			p.Team = teamName.String
-		}
-
-		players = append(players, p)
+		*id.dest = n
 	}
-
-	return players, nil
+	return filter, nil
 }
 
-// GetPlayersCount returns the total count of players with filters
-func (s *PlayerService) GetPlayersCount(search, position, nationality, team string) (int, error) {
-	query := `
-		SELECT COUNT(DISTINCT p.id)
-		FROM players p
-		LEFT JOIN teams t ON p.current_team_id = t.id
-		WHERE 1=1
-	`
-	args := []interface{}{}
-	argIndex := 1
+// GetPlayerByID returns a single player by ID
+func (s *PlayerService) GetPlayerByID(id int) (*models.Player, error) {
+	return s.players.GetPlayer(id)
+}
 
-	// Add search filter
-	if search != "" {
-		query += fmt.Sprintf(" AND p.name ILIKE $%d", argIndex)
-		args = append(args, "%"+search+"%")
-		argIndex++
+// GetPlayerStats returns player statistics for a specific season
+func (s *PlayerService) GetPlayerStats(playerID int, seasonID int) (*models.PlayerStats, error) {
+	stats, err := s.players.ListPlayerStats(playerID, seasonID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Add position filter
-	if position != "" {
-		query += fmt.Sprintf(" AND p.position = $%d", argIndex)
-		args = append(args, position)
-		argIndex++
+	if len(stats) == 0 {
+		return nil, apperrors.NotFound("no stats found for player %d", playerID)
 	}
 
-	// Add nationality filter
-	if nationality != "" {
-		query += fmt.Sprintf(" AND p.nationality = $%d", argIndex)
-		args = append(args, nationality)
-		argIndex++
-	}
+	// Return the first (most recent) if no specific season requested
+	return &stats[0], nil
+}
 
-	// Add team filter (by team ID)
-	if team != "" {
-		teamID, err := strconv.Atoi(team)
-		if err == nil {
-			query += fmt.Sprintf(" AND p.current_team_id = $%d", argIndex)
-			args = append(args, teamID)
-			argIndex++
-		}
+// GetPlayerCareer returns every season and club line of a player, oldest
+// first, with club and career totals and the matches that mark their debut,
+// last appearance and first and last goals
+func (s *PlayerService) GetPlayerCareer(playerID int) (*models.PlayerCareer, error) {
+	player, err := s.players.GetPlayer(playerID)
+	if err != nil {
+		return nil, err
 	}
 
-	var count int
-	err := s.db.QueryRow(query, args...).Scan(&count)
+	stats, err := s.players.ListPlayerStats(playerID, 0)
 	if err != nil {
-		return 0, fmt.Errorf("failed to count players: %w", err)
+		return nil, err
 	}
+	slices.Reverse(stats)
 
-	return count, nil
-}
-
-// GetPlayerByID returns a single player by ID
-func (s *PlayerService) GetPlayerByID(id int) (*models.Player, error) {
-	query := `
-		SELECT id, name, date_of_birth, nationality, position
-		FROM players
-		WHERE id = $1
-	`
-
-	var p models.Player
-	var dateOfBirth sql.NullString
-	var nationality sql.NullString
-	var position sql.NullString
-
-	err := s.db.QueryRow(query, id).Scan(
-		&p.ID, &p.Name, &dateOfBirth, &nationality, &position,
-	)
+	milestones, err := s.players.GetPlayerMilestones(playerID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("player not found")
-		}
-		return nil, fmt.Errorf("failed to get player: %w", err)
-	}
+		return nil, err
+	}
+
+	career := &models.PlayerCareer{
+		Player:         *player,
+		Seasons:        stats,
+		Clubs:          []models.CareerClub{},
+		Debut:          milestones.Debut,
+		LastAppearance: milestones.LastAppearance,
+		FirstGoal:      milestones.FirstGoal,
+		LastGoal:       milestones.LastGoal,
+	}
+	if career.Seasons == nil {
+		career.Seasons = []models.PlayerStats{}
+	}
+
+	// Clubs are listed in the order the player first played for them
+	clubs := make(map[int]int)
+	seasons := make(map[int]bool)
+	for _, line := range stats {
+		i, ok := clubs[line.TeamID]
+		if !ok {
+			i = len(career.Clubs)
+			clubs[line.TeamID] = i
+			career.Clubs = append(career.Clubs, models.CareerClub{
+				TeamID:      line.TeamID,
+				TeamName:    line.TeamName,
+				FirstSeason: line.SeasonName,
+			})
+		}
+		club := &career.Clubs[i]
+		club.LastSeason = line.SeasonName
+		addCareerLine(&club.CareerTotals, line)
+		addCareerLine(&career.Totals, line)
+		seasons[line.SeasonID] = true
+	}
+	// A season split between clubs counts once in the career total
+	career.Totals.Seasons = len(seasons)
+
+	return career, nil
+}
 
-	if dateOfBirth.Valid {
-		p.DateOfBirth = dateOfBirth.String
+// addCareerLine adds a season line to a running total
+func addCareerLine(totals *models.CareerTotals, line models.PlayerStats) {
+	totals.Seasons++
+	totals.Appearances += line.Appearances
+	totals.Goals += line.Goals
+	totals.Assists += line.Assists
+	totals.YellowCards += line.YellowCards
+	totals.RedCards += line.RedCards
+	if totals.Appearances > 0 {
+		totals.GoalsPerGame = float64(totals.Goals) / float64(totals.Appearances)
 	}
-	if nationality.Valid {
-		p.Nationality = nationality.String
-	}
-	if position.Valid {
-		p.Position = position.String
-	}
-
-	return &p, nil
 }
 
-// GetPlayerStats returns player statistics for a specific season
-func (s *PlayerService) GetPlayerStats(playerID int, seasonID int) (*models.PlayerStats, error) {
-	query := `
-		SELECT ps.id, ps.player_id, ps.season_id, ps.team_id, ps.appearances, 
-		       ps.goals, ps.assists, ps.yellow_cards, ps.red_cards,
-		       p.name as player_name, t.name as team_name, s.name as season_name
-		FROM player_stats ps
-		JOIN players p ON ps.player_id = p.id
-		JOIN teams t ON ps.team_id = t.id
-		JOIN seasons s ON ps.season_id = s.id
-		WHERE ps.player_id = $1
-	`
-	args := []interface{}{playerID}
-
+// ResolveSeasonID returns seasonID, or the current season's ID when it is unset
+func (s *PlayerService) ResolveSeasonID(seasonID int) (int, error) {
 	if seasonID > 0 {
-		query += " AND ps.season_id = $2"
-		args = append(args, seasonID)
+		return seasonID, nil
 	}
 
-	query += " ORDER BY s.name DESC"
-
-	rows, err := s.db.Query(query, args...)
+	season, err := currentSeason(s.seasons, s.now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to query player stats: %w", err)
-	}
-	defer rows.Close()
-
-	var stats []models.PlayerStats
-	for rows.Next() {
-		var s models.PlayerStats
-		err := rows.Scan(
-			&s.ID, &s.PlayerID, &s.SeasonID, &s.TeamID,
-			&s.Appearances, &s.Goals, &s.Assists, &s.YellowCards, &s.RedCards,
-			&s.PlayerName, &s.TeamName, &s.SeasonName,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan player stats: %w", err)
-		}
-		stats = append(stats, s)
+		return 0, err
 	}
-
-	if len(stats) == 0 {
-		return nil, fmt.Errorf("no stats found for player")
-	}
-
-	// Return the first (most recent) if no specific season requested
-	return &stats[0], nil
+	return season.ID, nil
 }
 
 // GetTopScorers returns the top scorers for a season
@@ -260,48 +181,17 @@ func (s *PlayerService) GetTopScorers(seasonID int, limit int) ([]models.TopScor
 		limit = 20
 	}
 
-	query := `
-		SELECT ps.player_id, p.name as player_name, ps.team_id, t.name as team_name,
-		       ps.goals, ps.assists, ps.appearances, p.nationality, p.position
-		FROM player_stats ps
-		JOIN players p ON ps.player_id = p.id
-		JOIN teams t ON ps.team_id = t.id
-		WHERE ps.season_id = $1 AND ps.goals > 0
-		ORDER BY ps.goals DESC, ps.assists DESC
-		LIMIT $2
-	`
-
-	rows, err := s.db.Query(query, seasonID, limit)
+	scorers, err := s.players.ListTopScorers(seasonID, limit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query top scorers: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
-
-	var scorers []models.TopScorer
-	rank := 1
-	for rows.Next() {
-		var ts models.TopScorer
-		var nationality sql.NullString
-		var position sql.NullString
-
-		err := rows.Scan(
-			&ts.PlayerID, &ts.PlayerName, &ts.TeamID, &ts.TeamName,
-			&ts.Goals, &ts.Assists, &ts.Appearances, &nationality, &position,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan top scorer: %w", err)
-		}
-
-		ts.Rank = rank
-		if nationality.Valid {
-			ts.Nationality = nationality.String
-		}
-		if position.Valid {
-			ts.Position = position.String
-		}
 
-		scorers = append(scorers, ts)
-		rank++
+	// Scorers level on goals share a rank
+	ranks, joint := sharedRanks(len(scorers), func(i, j int) bool {
+		return scorers[i].Goals == scorers[j].Goals
+	})
+	for i := range scorers {
+		scorers[i].Rank, scorers[i].Joint = ranks[i], joint[i]
 	}
 
 	return scorers, nil
@@ -309,109 +199,22 @@ func (s *PlayerService) GetTopScorers(seasonID int, limit int) ([]models.TopScor
 
 // GetPlayerPositions returns all unique positions
 func (s *PlayerService) GetPlayerPositions() ([]string, error) {
-	query := `
-		SELECT DISTINCT position
-		FROM players
-		WHERE position IS NOT NULL
-		ORDER BY position
-	`
-
-	rows, err := s.db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query positions: %w", err)
-	}
-	defer rows.Close()
-
-	var positions []string
-	for rows.Next() {
-		var position string
-		if err := rows.Scan(&position); err != nil {
-			return nil, fmt.Errorf("failed to scan position: %w", err)
-		}
-		positions = append(positions, position)
-	}
-
-	return positions, nil
+	return s.players.ListPositions()
 }
 
 // GetPlayerNationalities returns all unique nationalities
 func (s *PlayerService) GetPlayerNationalities() ([]string, error) {
-	query := `
-		SELECT DISTINCT nationality
-		FROM players
-		WHERE nationality IS NOT NULL
-		ORDER BY nationality
-	`
-
-	rows, err := s.db.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query nationalities: %w", err)
-	}
-	defer rows.Close()
-
-	var nationalities []string
-	for rows.Next() {
-		var nationality string
-		if err := rows.Scan(&nationality); err != nil {
-			return nil, fmt.Errorf("failed to scan nationality: %w", err)
-		}
-		nationalities = append(nationalities, nationality)
-	}
-
-	return nationalities, nil
+	return s.players.ListNationalities()
 }
 
-// SearchPlayers performs a comprehensive search across players, teams, and matches
-func (s *PlayerService) SearchPlayers(query string, limit int) ([]models.SearchResult, error) {
-	if limit <= 0 {
-		limit = 20
-	}
-
-	searchQuery := `
-		(
-			SELECT 'player' as type, p.id, p.name, p.position as subtitle, '' as extra
-			FROM players p
-			WHERE p.name ILIKE $1
-			LIMIT $2
-		)
-		UNION ALL
-		(
-			SELECT 'team' as type, t.id, t.name, t.stadium as subtitle, '' as extra
-			FROM teams t
-			WHERE t.name ILIKE $1
-			LIMIT $2
-		)
-		ORDER BY type, name
-		LIMIT $2
-	`
-
-	searchTerm := "%" + strings.TrimSpace(query) + "%"
-	rows, err := s.db.Query(searchQuery, searchTerm, limit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search: %w", err)
-	}
-	defer rows.Close()
-
-	var results []models.SearchResult
-	for rows.Next() {
-		var r models.SearchResult
-		var subtitle sql.NullString
-		var extra sql.NullString
-
-		err := rows.Scan(&r.Type, &r.ID, &r.Name, &subtitle, &extra)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan search result: %w", err)
-		}
-
-		if subtitle.Valid {
-			r.Subtitle = subtitle.String
-		}
-		if extra.Valid {
-			r.Extra = extra.String
-		}
-
-		results = append(results, r)
-	}
-
-	return results, nil
+// SearchPlayers returns a page of the players and teams matching a query
+func (s *PlayerService) SearchPlayers(query string, req pagination.Request) (*pagination.Page[models.SearchResult], error) {
+	filter := repository.SearchFilter{Query: strings.TrimSpace(query), Offset: req.Offset}
+	return pagination.Load(req, repository.SearchKeyOf,
+		func(seek *pagination.Seek[repository.SearchKey], limit int) ([]models.SearchResult, error) {
+			filter.Seek, filter.Limit = seek, limit
+			return s.players.Search(filter)
+		},
+		func() (int, error) { return s.players.CountSearch(filter.Query) },
+	)
 }
\ No newline at end of file
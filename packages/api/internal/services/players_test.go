@@ -0,0 +1,72 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+func TestGetPlayerCareer(t *testing.T) {
+	repo := repository.NewMemory()
+	// Names that sort differently from the seasons' order
+	repo.AddSeason(models.Season{ID: 1, Name: "1999/00"})
+	repo.AddSeason(models.Season{ID: 2, Name: "2000/01"})
+	repo.AddSeason(models.Season{ID: 3, Name: "2001/02"})
+	villa := repo.AddTeam(models.Team{Name: "Aston Villa"})
+	leeds := repo.AddTeam(models.Team{Name: "Leeds United"})
+	player := repo.AddPlayer(models.Player{Name: "Robbie Keane"})
+
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: player, SeasonID: 3, TeamID: leeds, Appearances: 30, Goals: 15})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: player, SeasonID: 1, TeamID: villa, Appearances: 20, Goals: 2})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: player, SeasonID: 2, TeamID: villa, Appearances: 10, Goals: 1})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: player, SeasonID: 2, TeamID: leeds, Appearances: 10, Goals: 6})
+
+	first := repo.AddMatch(models.Match{SeasonID: 1, HomeTeamID: villa, AwayTeamID: leeds, MatchDate: time.Date(1999, 8, 7, 15, 0, 0, 0, time.UTC)})
+	last := repo.AddMatch(models.Match{SeasonID: 3, HomeTeamID: leeds, AwayTeamID: villa, MatchDate: time.Date(2002, 5, 11, 15, 0, 0, 0, time.UTC)})
+	repo.AddMatchEvent(models.MatchEvent{MatchID: last, EventType: "goal", Minute: 80, PlayerID: player, TeamID: leeds})
+	repo.AddMatchEvent(models.MatchEvent{MatchID: last, EventType: "goal", Minute: 12, PlayerID: player, TeamID: leeds})
+	repo.AddMatchEvent(models.MatchEvent{MatchID: first, EventType: "yellow_card", Minute: 30, PlayerID: player, TeamID: villa})
+
+	service := NewPlayerService(repo, repo)
+	career, err := service.GetPlayerCareer(player)
+	if err != nil {
+		t.Fatalf("GetPlayerCareer: %v", err)
+	}
+
+	var seasons []int
+	for _, line := range career.Seasons {
+		seasons = append(seasons, line.SeasonID)
+	}
+	if len(seasons) != 4 || seasons[0] != 1 || seasons[3] != 3 {
+		t.Errorf("seasons = %v, want every line oldest first", seasons)
+	}
+
+	want := models.CareerTotals{Seasons: 3, Appearances: 70, Goals: 24, GoalsPerGame: 24.0 / 70}
+	if career.Totals != want {
+		t.Errorf("totals = %+v, want %+v", career.Totals, want)
+	}
+	if len(career.Clubs) != 2 {
+		t.Fatalf("clubs = %+v, want Villa then Leeds", career.Clubs)
+	}
+	if villaClub := career.Clubs[0]; villaClub.TeamID != villa || villaClub.Seasons != 2 ||
+		villaClub.FirstSeason != "1999/00" || villaClub.LastSeason != "2000/01" || villaClub.Goals != 3 {
+		t.Errorf("first club = %+v", villaClub)
+	}
+	if leedsClub := career.Clubs[1]; leedsClub.GoalsPerGame != 0.525 {
+		t.Errorf("Leeds goals per game = %v, want 0.525", leedsClub.GoalsPerGame)
+	}
+
+	if career.Debut == nil || career.Debut.MatchID != first {
+		t.Errorf("debut = %+v, want match %d", career.Debut, first)
+	}
+	if career.FirstGoal == nil || career.FirstGoal.Minute != 12 || career.LastGoal.Minute != 80 {
+		t.Errorf("goals = %+v / %+v, want minutes 12 and 80", career.FirstGoal, career.LastGoal)
+	}
+
+	if _, err := service.GetPlayerCareer(999); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("unknown player: err = %v, want not found", err)
+	}
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"cmp"
+	"sort"
+
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/pagination"
+	"github.com/premstats/api/internal/repository"
+)
+
+// matchMinutes is how long a match is taken to last when counting minutes
+const matchMinutes = 90
+
+// PlayerStatsService derives player season lines from goals, match events
+// and lineups, and checks the stored lines against them
+type PlayerStatsService struct {
+	stats repository.PlayerStatsRepository
+}
+
+// NewPlayerStatsService creates a new player stats service
+func NewPlayerStatsService(stats repository.PlayerStatsRepository) *PlayerStatsService {
+	return &PlayerStatsService{stats: stats}
+}
+
+// DerivePlayerStats totals appearances into one line per player, season and
+// team. Substitutions only record who came on, so a starter is counted as
+// playing to the end of the match unless sent off. Minutes stay nil for
+// lines without any lineup entry. Assists are not recorded in match data and
+// are left at zero.
+func DerivePlayerStats(appearances []repository.PlayerAppearance) []models.PlayerStats {
+	lines := make(map[[3]int]*models.PlayerStats)
+	var order [][3]int
+	for _, a := range appearances {
+		key := [3]int{a.PlayerID, a.SeasonID, a.TeamID}
+		line, ok := lines[key]
+		if !ok {
+			line = &models.PlayerStats{
+				PlayerID:   a.PlayerID,
+				PlayerName: a.PlayerName,
+				SeasonID:   a.SeasonID,
+				SeasonName: a.SeasonName,
+				TeamID:     a.TeamID,
+				TeamName:   a.TeamName,
+				Source:     models.StatsDerived,
+			}
+			lines[key] = line
+			order = append(order, key)
+		}
+
+		line.Appearances++
+		line.Goals += a.Goals
+		line.Penalties += a.Penalties
+		line.OwnGoals += a.OwnGoals
+		line.YellowCards += a.YellowCards
+		line.RedCards += a.RedCards
+
+		if a.Lineup {
+			start, end := 0, matchMinutes
+			if !a.Starter {
+				start = a.MinuteOn
+			}
+			if a.SentOff > 0 {
+				end = a.SentOff
+			}
+			minutes := 0
+			if line.Minutes != nil {
+				minutes = *line.Minutes
+			}
+			minutes += max(end-start, 0)
+			line.Minutes = &minutes
+		}
+	}
+
+	stats := make([]models.PlayerStats, 0, len(order))
+	for _, key := range order {
+		stats = append(stats, *lines[key])
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return compareStatsDiffKeys(statsKeyOf(stats[i]), statsKeyOf(stats[j])) < 0
+	})
+	return stats
+}
+
+// DeriveStats derives the season lines of one season, or of every season
+// when seasonID is 0
+func (s *PlayerStatsService) DeriveStats(seasonID int) ([]models.PlayerStats, error) {
+	appearances, err := s.stats.ListPlayerAppearances(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	return DerivePlayerStats(appearances), nil
+}
+
+// RebuildStats derives the season lines of one or every season and writes
+// them to the store, returning how many were written
+func (s *PlayerStatsService) RebuildStats(seasonID int) (int, error) {
+	stats, err := s.DeriveStats(seasonID)
+	if err != nil {
+		return 0, err
+	}
+	return s.stats.SaveDerivedPlayerStats(seasonID, stats)
+}
+
+// statsDiffKey is the sort key of season lines and the differences between them
+type statsDiffKey struct {
+	SeasonID int `json:"s"`
+	TeamID   int `json:"t"`
+	PlayerID int `json:"p"`
+}
+
+// statsKeyOf returns a season line's key
+func statsKeyOf(line models.PlayerStats) statsDiffKey {
+	return statsDiffKey{SeasonID: line.SeasonID, TeamID: line.TeamID, PlayerID: line.PlayerID}
+}
+
+// statsDiffKeyOf returns a difference's position in the report
+func statsDiffKeyOf(diff models.PlayerStatsDiff) statsDiffKey {
+	return statsDiffKey{SeasonID: diff.SeasonID, TeamID: diff.TeamID, PlayerID: diff.PlayerID}
+}
+
+// compareStatsDiffKeys orders lines by season, team, then player
+func compareStatsDiffKeys(a, b statsDiffKey) int {
+	if c := cmp.Compare(a.SeasonID, b.SeasonID); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(a.TeamID, b.TeamID); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.PlayerID, b.PlayerID)
+}
+
+// DiffStats compares the external season lines of one or every season with
+// lines derived afresh. Stored lines without match data are only reported
+// for seasons that have some, since most early seasons have none.
+func (s *PlayerStatsService) DiffStats(seasonID int) ([]models.PlayerStatsDiff, error) {
+	derived, err := s.DeriveStats(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.stats.ListSeasonPlayerStats(seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	storedByKey := make(map[statsDiffKey]models.PlayerStats, len(stored))
+	for _, line := range stored {
+		storedByKey[statsKeyOf(line)] = line
+	}
+
+	diffs := []models.PlayerStatsDiff{}
+	seasons := make(map[int]bool)
+	for i := range derived {
+		line := &derived[i]
+		seasons[line.SeasonID] = true
+		key := statsKeyOf(*line)
+
+		diff := statsDiff(*line)
+		diff.Derived = line
+		if existing, ok := storedByKey[key]; ok {
+			delete(storedByKey, key)
+			diff.Stored = &existing
+			diff.Fields = diffPlayerStats(existing, *line)
+			if len(diff.Fields) == 0 {
+				continue
+			}
+		}
+		diffs = append(diffs, diff)
+	}
+
+	for _, line := range storedByKey {
+		if !seasons[line.SeasonID] {
+			continue
+		}
+		line := line
+		diff := statsDiff(line)
+		diff.Stored = &line
+		diffs = append(diffs, diff)
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return compareStatsDiffKeys(statsDiffKeyOf(diffs[i]), statsDiffKeyOf(diffs[j])) < 0
+	})
+	return diffs, nil
+}
+
+// GetStatsDiff returns a page of the season lines whose stored and derived
+// numbers differ
+func (s *PlayerStatsService) GetStatsDiff(seasonID int, req pagination.Request) (*pagination.Page[models.PlayerStatsDiff], error) {
+	diffs, err := s.DiffStats(seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.Load(req, statsDiffKeyOf,
+		func(seek *pagination.Seek[statsDiffKey], limit int) ([]models.PlayerStatsDiff, error) {
+			return pagination.Apply(diffs, seek, req.Offset, limit, statsDiffKeyOf, compareStatsDiffKeys), nil
+		},
+		func() (int, error) { return len(diffs), nil },
+	)
+}
+
+// statsDiff starts a difference for a season line
+func statsDiff(line models.PlayerStats) models.PlayerStatsDiff {
+	return models.PlayerStatsDiff{
+		PlayerID:   line.PlayerID,
+		PlayerName: line.PlayerName,
+		SeasonID:   line.SeasonID,
+		SeasonName: line.SeasonName,
+		TeamID:     line.TeamID,
+		TeamName:   line.TeamName,
+		Fields:     []string{},
+	}
+}
+
+// diffPlayerStats lists the numbers an external line gets wrong. External
+// lines carry no penalties, own goals or minutes, so only the numbers both
+// record are compared.
+func diffPlayerStats(stored, derived models.PlayerStats) []string {
+	fields := []string{}
+	compare := func(name string, a, b int) {
+		if a != b {
+			fields = append(fields, name)
+		}
+	}
+	compare("appearances", stored.Appearances, derived.Appearances)
+	compare("goals", stored.Goals, derived.Goals)
+	compare("yellowCards", stored.YellowCards, derived.YellowCards)
+	compare("redCards", stored.RedCards, derived.RedCards)
+	return fields
+}
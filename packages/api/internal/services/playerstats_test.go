@@ -0,0 +1,110 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+func TestDerivePlayerStats(t *testing.T) {
+	appearances := []repository.PlayerAppearance{
+		// Started and scored twice, one a penalty
+		{PlayerID: 1, MatchID: 1, SeasonID: 1, TeamID: 10, Lineup: true, Starter: true, Goals: 2, Penalties: 1},
+		// Came on at 60 and was sent off at 85
+		{PlayerID: 1, MatchID: 2, SeasonID: 1, TeamID: 10, Lineup: true, MinuteOn: 60, YellowCards: 1, RedCards: 1, SentOff: 85},
+		// Only known from an own goal, with no lineup
+		{PlayerID: 2, MatchID: 1, SeasonID: 1, TeamID: 20, OwnGoals: 1},
+	}
+
+	stats := DerivePlayerStats(appearances)
+	if len(stats) != 2 {
+		t.Fatalf("derived %d lines, want 2: %+v", len(stats), stats)
+	}
+
+	first := stats[0]
+	if first.Appearances != 2 || first.Goals != 2 || first.Penalties != 1 || first.YellowCards != 1 || first.RedCards != 1 {
+		t.Errorf("first line = %+v", first)
+	}
+	if first.Minutes == nil || *first.Minutes != 115 {
+		t.Errorf("minutes = %v, want 90 + 25", first.Minutes)
+	}
+	if first.Source != models.StatsDerived {
+		t.Errorf("source = %q, want derived", first.Source)
+	}
+
+	if second := stats[1]; second.OwnGoals != 1 || second.Goals != 0 || second.Minutes != nil {
+		t.Errorf("own goal line = %+v, want one own goal and no minutes", second)
+	}
+}
+
+func TestRebuildAndDiffPlayerStats(t *testing.T) {
+	repo := repository.NewMemory()
+	repo.AddSeason(models.Season{ID: 1, Name: "2001/02"})
+	repo.AddSeason(models.Season{ID: 2, Name: "2002/03"})
+	home := repo.AddTeam(models.Team{Name: "Arsenal"})
+	away := repo.AddTeam(models.Team{Name: "Chelsea"})
+	scorer := repo.AddPlayer(models.Player{Name: "Thierry Henry"})
+	booked := repo.AddPlayer(models.Player{Name: "Frank Lampard"})
+	unplayed := repo.AddPlayer(models.Player{Name: "Reserve Keeper"})
+	elsewhere := repo.AddPlayer(models.Player{Name: "Older Season"})
+
+	match := repo.AddMatch(models.Match{SeasonID: 1, HomeTeamID: home, AwayTeamID: away,
+		HomeScore: intPtr(2), AwayScore: intPtr(0), MatchDate: time.Date(2001, 8, 18, 15, 0, 0, 0, time.UTC)})
+	repo.AddMatchEvent(models.MatchEvent{MatchID: match, EventType: "goal", Minute: 10, PlayerID: scorer, TeamID: home})
+	repo.AddMatchEvent(models.MatchEvent{MatchID: match, EventType: "penalty", Minute: 70, PlayerID: scorer, TeamID: home})
+	repo.AddMatchEvent(models.MatchEvent{MatchID: match, EventType: "yellow_card", Minute: 30, PlayerID: booked, TeamID: away})
+
+	// The feed agrees on the booking but has one goal too few and an extra player
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: scorer, SeasonID: 1, TeamID: home, Appearances: 1, Goals: 1, Assists: 3})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: booked, SeasonID: 1, TeamID: away, Appearances: 1, YellowCards: 1})
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: unplayed, SeasonID: 1, TeamID: home, Appearances: 4})
+	// A season without match data is not reported
+	repo.AddPlayerStats(models.PlayerStats{PlayerID: elsewhere, SeasonID: 2, TeamID: home, Appearances: 30})
+
+	service := NewPlayerStatsService(repo)
+	diffs, err := service.DiffStats(0)
+	if err != nil {
+		t.Fatalf("DiffStats: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("diffs = %+v, want the scorer and the unplayed player", diffs)
+	}
+	if diffs[0].PlayerID != scorer || !slices.Equal(diffs[0].Fields, []string{"goals"}) {
+		t.Errorf("first diff = %+v, want the scorer's goals", diffs[0])
+	}
+	if diffs[1].PlayerID != unplayed || diffs[1].Derived != nil {
+		t.Errorf("second diff = %+v, want a stored line without match data", diffs[1])
+	}
+
+	written, err := service.RebuildStats(1)
+	if err != nil || written != 2 {
+		t.Fatalf("RebuildStats = %d, %v; want 2 lines", written, err)
+	}
+	stats, _ := repo.ListPlayerStats(scorer, 1)
+	if len(stats) != 1 || stats[0].Goals != 2 || stats[0].Penalties != 1 || stats[0].Assists != 3 || stats[0].Source != models.StatsDerived {
+		t.Errorf("scorer after rebuild = %+v, want derived goals and the stored assists", stats)
+	}
+
+	// The external lines are kept, so the report still compares against them
+	external, _ := repo.ListSeasonPlayerStats(1)
+	for _, line := range external {
+		if line.PlayerID == scorer && (line.Goals != 1 || line.Source != models.StatsExternal) {
+			t.Errorf("external line after rebuild = %+v, want it unchanged", line)
+		}
+	}
+	diffs, _ = service.DiffStats(1)
+	if len(diffs) != 2 || diffs[0].PlayerID != scorer || diffs[1].PlayerID != unplayed {
+		t.Errorf("diffs after rebuild = %+v, want the scorer and the unplayed player again", diffs)
+	}
+
+	// Rebuilding again replaces the derived lines rather than adding to them
+	if _, err := service.RebuildStats(1); err != nil {
+		t.Fatalf("RebuildStats: %v", err)
+	}
+	if stats, _ := repo.ListPlayerStats(scorer, 1); len(stats) != 1 || stats[0].Goals != 2 {
+		t.Errorf("scorer after second rebuild = %+v, want one derived line", stats)
+	}
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"sort"
+
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+// ReconciliationService compares computed standings with official tables
+type ReconciliationService struct {
+	official  repository.StandingsRepository
+	standings *StandingsService
+}
+
+// NewReconciliationService creates a new reconciliation service
+func NewReconciliationService(official repository.StandingsRepository, standings *StandingsService) *ReconciliationService {
+	return &ReconciliationService{official: official, standings: standings}
+}
+
+// ReconcileSeason diffs every team's computed line against the official table
+func (s *ReconciliationService) ReconcileSeason(seasonID int) (*models.StandingsReconciliation, error) {
+	computed, err := s.standings.GetStandingsBySeasonID(seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	official, source, err := s.official.GetOfficialStandings(seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.StandingsReconciliation{
+		SeasonID: seasonID,
+		Season:   computed.Season,
+		Source:   source,
+		Status:   "match",
+		Entries:  []models.ReconciliationEntry{},
+	}
+	if len(official) == 0 {
+		report.Status = "no_official_data"
+		return report, nil
+	}
+
+	seen := make(map[int]bool)
+	for i := range computed.Table {
+		line := computed.Table[i]
+		seen[line.TeamID] = true
+
+		entry := models.ReconciliationEntry{
+			TeamID:   line.TeamID,
+			Team:     line.Team,
+			Computed: &line,
+		}
+		if off, ok := official[line.TeamID]; ok {
+			entry.Official = off
+			entry.Discrepancies = diffStandingsLines(&line, off)
+			if off.Played > line.Played {
+				entry.MissingMatches = off.Played - line.Played
+			}
+			entry.Status = "match"
+			if len(entry.Discrepancies) > 0 {
+				entry.Status = "mismatch"
+			}
+		} else {
+			entry.Status = "missing_official"
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+
+	for teamID, off := range official {
+		if seen[teamID] {
+			continue
+		}
+		report.Entries = append(report.Entries, models.ReconciliationEntry{
+			TeamID:         teamID,
+			Team:           off.Team,
+			Status:         "missing_computed",
+			Official:       off,
+			MissingMatches: off.Played,
+		})
+	}
+
+	// Order by official position so the report reads like the published table
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		return reconciliationOrder(report.Entries[i]) < reconciliationOrder(report.Entries[j])
+	})
+
+	for _, entry := range report.Entries {
+		report.TeamsChecked++
+		report.MissingMatches += entry.MissingMatches
+		if entry.Status != "match" {
+			report.Mismatches++
+		}
+	}
+	if report.Mismatches > 0 {
+		report.Status = "mismatch"
+	}
+
+	return report, nil
+}
+
+// ReconcileAllSeasons reconciles every season that has an official table
+func (s *ReconciliationService) ReconcileAllSeasons() ([]models.StandingsReconciliation, error) {
+	seasonIDs, err := s.official.ListOfficialSeasonIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []models.StandingsReconciliation{}
+	for _, id := range seasonIDs {
+		report, err := s.ReconcileSeason(id)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+
+	return reports, nil
+}
+
+// diffStandingsLines lists the columns where the computed line differs from the official one
+func diffStandingsLines(computed, official *models.StandingsEntry) []models.StandingsDiscrepancy {
+	fields := []struct {
+		name     string
+		computed int
+		official int
+	}{
+		{"position", computed.Position, official.Position},
+		{"played", computed.Played, official.Played},
+		{"won", computed.Won, official.Won},
+		{"drawn", computed.Drawn, official.Drawn},
+		{"lost", computed.Lost, official.Lost},
+		{"goalsFor", computed.GoalsFor, official.GoalsFor},
+		{"goalsAgainst", computed.GoalsAgainst, official.GoalsAgainst},
+		{"goalDifference", computed.GoalDifference, official.GoalDifference},
+		{"points", computed.Points, official.Points},
+	}
+
+	var discrepancies []models.StandingsDiscrepancy
+	for _, f := range fields {
+		if f.computed != f.official {
+			discrepancies = append(discrepancies, models.StandingsDiscrepancy{
+				Field:      f.name,
+				Computed:   f.computed,
+				Official:   f.official,
+				Difference: f.computed - f.official,
+			})
+		}
+	}
+	return discrepancies
+}
+
+// reconciliationOrder sorts entries by official position, then computed position
+func reconciliationOrder(entry models.ReconciliationEntry) int {
+	if entry.Official != nil {
+		return entry.Official.Position
+	}
+	return 1000 + entry.Computed.Position
+}
@@ -1,202 +1,346 @@
 package services
 
 import (
-	"database/sql"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/cache"
 	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
 )
 
-// SeasonService handles season-related database operations
+// European competitions a league position can qualify for
+const (
+	CompetitionChampionsLeague  = "championsLeague"
+	CompetitionUEFACup          = "uefaCup"
+	CompetitionEuropaLeague     = "europaLeague"
+	CompetitionConferenceLeague = "conferenceLeague"
+)
+
+// Places that are not settled by a European competition
+const (
+	PlaceChampion   = "champion"
+	PlaceRelegation = "relegation"
+)
+
+// Formats for seasons without stored rules. The team count defaults to the
+// number of teams in the table.
+const (
+	defaultRelegationPlaces = 3
+	defaultPromotionPlaces  = 3
+	defaultEuropeanPlaces   = "championsLeague:4,europaLeague:1"
+)
+
+// Metadata for seasons that have none stored
+const (
+	defaultTeamCount    = 20
+	defaultPointsForWin = 3
+)
+
+var competitions = map[string]bool{
+	CompetitionChampionsLeague:  true,
+	CompetitionUEFACup:          true,
+	CompetitionEuropaLeague:     true,
+	CompetitionConferenceLeague: true,
+}
+
+// SeasonService handles season-related operations
 type SeasonService struct {
-	db *database.DB
+	seasons   repository.SeasonRepository
+	teams     repository.TeamRepository
+	standings *StandingsService
+	cache     *cache.Cache
+	now       func() time.Time
 }
 
-// NewSeasonService creates a new season service
-func NewSeasonService(db *database.DB) *SeasonService {
-	return &SeasonService{db: db}
+// NewSeasonService creates a new season service. Summaries are kept in c,
+// which may be nil.
+func NewSeasonService(seasons repository.SeasonRepository, teams repository.TeamRepository, standings *StandingsService, c *cache.Cache) *SeasonService {
+	return &SeasonService{seasons: seasons, teams: teams, standings: standings, cache: c, now: time.Now}
 }
 
 // GetAllSeasons retrieves all seasons
 func (s *SeasonService) GetAllSeasons() ([]models.Season, error) {
-	query := `
-		SELECT id, name
-		FROM seasons
-		ORDER BY id ASC
-	`
+	seasons, err := s.seasons.ListSeasons()
+	if err != nil {
+		return nil, err
+	}
+	return describeSeasons(seasons, s.now()), nil
+}
+
+// GetSeasonByID retrieves a specific season by ID
+func (s *SeasonService) GetSeasonByID(seasonID int) (*models.Season, error) {
+	season, err := s.seasons.GetSeason(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	describeSeason(season, s.now())
+	return season, nil
+}
+
+// GetCurrentSeason returns the season in progress, or the last one played
+// between seasons
+func (s *SeasonService) GetCurrentSeason() (*models.Season, error) {
+	return currentSeason(s.seasons, s.now())
+}
 
-	rows, err := s.db.Query(query)
+// currentSeason resolves the current season as of now: the latest season
+// that has started, or the first one when none has
+func currentSeason(seasons repository.SeasonRepository, now time.Time) (*models.Season, error) {
+	list, err := seasons.ListSeasons()
 	if err != nil {
-		return nil, fmt.Errorf("failed to query seasons: %w", err)
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, apperrors.NotFound("no seasons found")
 	}
-	defer rows.Close()
 
-	var seasons []models.Season
-	for rows.Next() {
-		var season models.Season
-		err := rows.Scan(&season.ID, &season.Name)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan season row: %w", err)
+	list = describeSeasons(list, now)
+	for i := len(list) - 1; i >= 0; i-- {
+		if list[i].Status != models.SeasonUpcoming {
+			return &list[i], nil
 		}
-		seasons = append(seasons, season)
 	}
+	return &list[0], nil
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating season rows: %w", err)
+// describeSeasons fills in every season's defaults and status as of now
+func describeSeasons(seasons []models.Season, now time.Time) []models.Season {
+	for i := range seasons {
+		describeSeason(&seasons[i], now)
 	}
+	return seasons
+}
+
+// describeSeason fills in a season's defaults, expected matches and status as
+// of now. A season is complete once every match is played or its end date
+// has passed, since older seasons may be missing results.
+func describeSeason(season *models.Season, now time.Time) {
+	season.TeamCount = orDefault(season.TeamCount, defaultTeamCount)
+	season.PointsForWin = orDefault(season.PointsForWin, defaultPointsForWin)
+	// Every team plays every other home and away
+	season.ExpectedMatches = season.TeamCount * (season.TeamCount - 1)
 
-	return seasons, nil
+	switch {
+	case season.PlayedMatches >= season.ExpectedMatches:
+		season.Status = models.SeasonComplete
+	case !season.EndDate.IsZero() && now.After(season.EndDate):
+		season.Status = models.SeasonComplete
+	case season.PlayedMatches > 0:
+		season.Status = models.SeasonInProgress
+	case !season.StartDate.IsZero() && !now.Before(season.StartDate):
+		season.Status = models.SeasonInProgress
+	default:
+		season.Status = models.SeasonUpcoming
+	}
 }
 
-// GetSeasonByID retrieves a specific season by ID
-func (s *SeasonService) GetSeasonByID(seasonID int) (*models.Season, error) {
-	query := `
-		SELECT id, name
-		FROM seasons
-		WHERE id = $1
-	`
-
-	var season models.Season
-	err := s.db.QueryRow(query, seasonID).Scan(&season.ID, &season.Name)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("season with ID %d not found", seasonID)
+// GetSeasonSummary retrieves summary statistics for a season. The champion,
+// European qualifiers and relegated teams are read from the final table
+// using the season's rules, so they respect point adjustments, tie-breaks
+// and the league's size in that season.
+func (s *SeasonService) GetSeasonSummary(seasonID int) (*models.SeasonSummary, error) {
+	return cache.Fetch(s.cache, fmt.Sprintf("season-summary:%d", seasonID), seasonID, func() (*models.SeasonSummary, error) {
+		return s.computeSeasonSummary(seasonID)
+	})
+}
+
+// ParseEuropeanPlaces parses an ordered, comma-separated list of
+// competition:places pairs; an empty list gives the default allocation
+func ParseEuropeanPlaces(raw string) ([]models.EuropeanPlace, error) {
+	if strings.TrimSpace(raw) == "" {
+		raw = defaultEuropeanPlaces
+	}
+
+	var places []models.EuropeanPlace
+	for _, pair := range strings.Split(raw, ",") {
+		competition, count, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || !competitions[competition] {
+			return nil, fmt.Errorf("unknown European place %q", pair)
 		}
-		return nil, fmt.Errorf("failed to query season: %w", err)
+		n, err := strconv.Atoi(count)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid number of places in %q", pair)
+		}
+		places = append(places, models.EuropeanPlace{Competition: competition, Places: n})
+	}
+	return places, nil
+}
+
+// GetSeasonRules returns a season's league format with defaults filled in.
+// teams is used as the team count when none is stored.
+func (s *SeasonService) GetSeasonRules(seasonID, teams int) (models.SeasonRules, error) {
+	raw, err := s.seasons.GetSeasonRules(seasonID)
+	if err != nil {
+		return models.SeasonRules{}, err
+	}
+
+	european, err := ParseEuropeanPlaces(raw.EuropeanPlaces)
+	if err != nil {
+		return models.SeasonRules{}, fmt.Errorf("invalid European places for season %d: %w", seasonID, err)
 	}
 
-	return &season, nil
+	rules := models.SeasonRules{
+		TeamCount:        orDefault(raw.TeamCount, teams),
+		RelegationPlaces: orDefault(raw.RelegationPlaces, defaultRelegationPlaces),
+		PromotionPlaces:  orDefault(raw.PromotionPlaces, defaultPromotionPlaces),
+		EuropeanPlaces:   european,
+	}
+	return rules, nil
 }
 
-// GetSeasonSummary retrieves summary statistics for a season
-func (s *SeasonService) GetSeasonSummary(seasonID int) (*models.SeasonSummary, error) {
-	// Get basic season info
+// orDefault returns value, or fallback when value is unset
+func orDefault(value, fallback int) int {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// computeSeasonSummary builds the summary GetSeasonSummary returns
+func (s *SeasonService) computeSeasonSummary(seasonID int) (*models.SeasonSummary, error) {
 	season, err := s.GetSeasonByID(seasonID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get match statistics
-	query := `
-		SELECT 
-			COUNT(*) as total_matches,
-			COALESCE(SUM(home_score + away_score), 0) as total_goals,
-			COALESCE(AVG(home_score + away_score), 0) as avg_goals_per_match
-		FROM matches
-		WHERE season_id = $1 
-		AND home_score IS NOT NULL 
-		AND away_score IS NOT NULL
-	`
-
-	var summary models.SeasonSummary
-	summary.SeasonID = season.ID
-	summary.Season = season.Name
-
-	err = s.db.QueryRow(query, seasonID).Scan(
-		&summary.TotalMatches,
-		&summary.TotalGoals,
-		&summary.AvgGoalsPerMatch,
-	)
+	standings, err := s.standings.GetStandingsBySeasonID(seasonID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get season summary: %w", err)
-	}
-
-	// Get champion (team with most points)
-	championQuery := `
-		WITH team_points AS (
-			SELECT 
-				t.name as team_name,
-				COUNT(CASE 
-					WHEN (m.home_team_id = t.id AND m.home_score > m.away_score) OR 
-						 (m.away_team_id = t.id AND m.away_score > m.home_score) 
-					THEN 1 
-				END) * 3 +
-				COUNT(CASE 
-					WHEN (m.home_team_id = t.id OR m.away_team_id = t.id) AND m.home_score = m.away_score 
-					THEN 1 
-				END) as points
-			FROM teams t
-			LEFT JOIN matches m ON (m.home_team_id = t.id OR m.away_team_id = t.id) 
-				AND m.season_id = $1 
-				AND m.home_score IS NOT NULL 
-				AND m.away_score IS NOT NULL
-			WHERE t.id IN (
-				SELECT DISTINCT home_team_id FROM matches WHERE season_id = $1
-				UNION
-				SELECT DISTINCT away_team_id FROM matches WHERE season_id = $1
-			)
-			GROUP BY t.id, t.name
-		)
-		SELECT team_name
-		FROM team_points
-		ORDER BY points DESC
-		LIMIT 1
-	`
-
-	var champion sql.NullString
-	err = s.db.QueryRow(championQuery, seasonID).Scan(&champion)
-	if err != nil && err != sql.ErrNoRows {
-		return nil, fmt.Errorf("failed to get champion: %w", err)
-	}
-	if champion.Valid {
-		summary.Champion = champion.String
-	}
-
-	// Get relegated teams (bottom 3 teams) - only for seasons with 20 teams
-	if summary.TotalMatches >= 380 { // 20 teams * 19 * 2 = 380 matches
-		relegatedQuery := `
-			WITH team_points AS (
-				SELECT 
-					t.name as team_name,
-					COUNT(CASE 
-						WHEN (m.home_team_id = t.id AND m.home_score > m.away_score) OR 
-							 (m.away_team_id = t.id AND m.away_score > m.home_score) 
-						THEN 1 
-					END) * 3 +
-					COUNT(CASE 
-						WHEN (m.home_team_id = t.id OR m.away_team_id = t.id) AND m.home_score = m.away_score 
-						THEN 1 
-					END) as points,
-					COALESCE(SUM(CASE WHEN m.home_team_id = t.id THEN m.home_score ELSE 0 END), 0) +
-					COALESCE(SUM(CASE WHEN m.away_team_id = t.id THEN m.away_score ELSE 0 END), 0) -
-					COALESCE(SUM(CASE WHEN m.home_team_id = t.id THEN m.away_score ELSE 0 END), 0) -
-					COALESCE(SUM(CASE WHEN m.away_team_id = t.id THEN m.home_score ELSE 0 END), 0) as goal_difference
-				FROM teams t
-				LEFT JOIN matches m ON (m.home_team_id = t.id OR m.away_team_id = t.id) 
-					AND m.season_id = $1 
-					AND m.home_score IS NOT NULL 
-					AND m.away_score IS NOT NULL
-				WHERE t.id IN (
-					SELECT DISTINCT home_team_id FROM matches WHERE season_id = $1
-					UNION
-					SELECT DISTINCT away_team_id FROM matches WHERE season_id = $1
-				)
-				GROUP BY t.id, t.name
-			)
-			SELECT team_name
-			FROM team_points
-			ORDER BY points ASC, goal_difference ASC
-			LIMIT 3
-		`
-
-		rows, err := s.db.Query(relegatedQuery, seasonID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get relegated teams: %w", err)
+		return nil, err
+	}
+
+	rules, err := s.GetSeasonRules(seasonID, len(standings.Table))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.SeasonSummary{
+		SeasonID: season.ID,
+		Season:   season.Name,
+		Rules:    rules,
+	}
+
+	// Every match appears once for each side
+	for _, entry := range standings.Table {
+		summary.TotalMatches += entry.Played
+		summary.TotalGoals += entry.GoalsFor
+	}
+	summary.TotalMatches /= 2
+	if summary.TotalMatches == 0 {
+		return summary, nil
+	}
+	summary.AvgGoalsPerMatch = float64(summary.TotalGoals) / float64(summary.TotalMatches)
+
+	summary.Promoted, err = s.promotedTeams(season, rules, standings.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every team plays every other home and away
+	summary.Completed = summary.TotalMatches >= rules.TeamCount*(rules.TeamCount-1)
+	if !summary.Completed {
+		summary.Leader = standings.Table[0].Team
+		return summary, nil
+	}
+
+	table := standings.Table
+	summary.Champion = table[0].Team
+	summary.PlayOffs = append(summary.PlayOffs, playOffAt(table, 1, PlaceChampion)...)
+
+	// European places are handed out from the top of the table
+	next := 0
+	for _, place := range rules.EuropeanPlaces {
+		end := min(next+place.Places, len(table))
+		qualifiers := models.EuropeanQualifiers{Competition: place.Competition}
+		for _, entry := range table[next:end] {
+			qualifiers.Teams = append(qualifiers.Teams, entry.Team)
 		}
-		defer rows.Close()
-
-		var relegated []string
-		for rows.Next() {
-			var teamName string
-			err := rows.Scan(&teamName)
-			if err != nil {
-				return nil, fmt.Errorf("failed to scan relegated team: %w", err)
-			}
-			relegated = append(relegated, teamName)
+		if len(qualifiers.Teams) > 0 {
+			summary.European = append(summary.European, qualifiers)
 		}
-		summary.Relegated = relegated
+		summary.PlayOffs = append(summary.PlayOffs, playOffAt(table, end, place.Competition)...)
+		next = end
+	}
+
+	// Listed from the bottom of the table up
+	firstRelegated := max(len(table)-rules.RelegationPlaces, 0)
+	for i := len(table) - 1; i >= firstRelegated; i-- {
+		summary.Relegated = append(summary.Relegated, table[i].Team)
 	}
+	summary.PlayOffs = append(summary.PlayOffs, playOffAt(table, firstRelegated, PlaceRelegation)...)
 
-	return &summary, nil
+	return summary, nil
+}
+
+// promotedTeams returns the teams in a table that did not play in the
+// season that started before it. It returns nil when that season has no
+// matches to compare with, or when the comparison finds a different number of
+// newcomers than the season's promotion places, as it does when the previous
+// season is only partly loaded.
+func (s *SeasonService) promotedTeams(season *models.Season, rules models.SeasonRules, table []models.StandingsEntry) ([]string, error) {
+	seasons, err := s.seasons.ListSeasons()
+	if err != nil {
+		return nil, err
+	}
+	var before *models.Season
+	for i, other := range seasons {
+		if other.StartDate.Before(season.StartDate) && (before == nil || other.StartDate.After(before.StartDate)) {
+			before = &seasons[i]
+		}
+	}
+	if before == nil {
+		return nil, nil
+	}
+
+	previous, err := s.teams.ListTeams(repository.TeamFilter{SeasonID: before.ID})
+	if err != nil {
+		return nil, err
+	}
+	if len(previous) == 0 {
+		return nil, nil
+	}
+
+	played := make(map[int]bool, len(previous))
+	for _, team := range previous {
+		played[team.ID] = true
+	}
+
+	var promoted []string
+	for _, entry := range table {
+		if !played[entry.TeamID] {
+			promoted = append(promoted, entry.Team)
+		}
+	}
+	if len(promoted) != rules.PromotionPlaces {
+		return nil, nil
+	}
+	sort.Strings(promoted)
+	return promoted, nil
+}
+
+// playOffAt returns the play-off for a place when the teams either side of
+// the boundary above table[boundary] are level on every tie-break
+func playOffAt(table []models.StandingsEntry, boundary int, place string) []models.SeasonPlayOff {
+	if boundary <= 0 || boundary >= len(table) || table[boundary].TieBreak != TieBreakPlayoff {
+		return nil
+	}
+
+	// The tied group runs up to the first team without the playoff label
+	first, last := boundary-1, boundary
+	for first > 0 && table[first].TieBreak == TieBreakPlayoff {
+		first--
+	}
+	for last+1 < len(table) && table[last+1].TieBreak == TieBreakPlayoff {
+		last++
+	}
+
+	playOff := models.SeasonPlayOff{Place: place}
+	for _, entry := range table[first : last+1] {
+		playOff.Teams = append(playOff.Teams, entry.Team)
+	}
+	return []models.SeasonPlayOff{playOff}
 }
@@ -0,0 +1,363 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+// seedRoundRobin adds a season in which every team plays every other home and
+// away, with the lower-numbered side always winning 1-0. Team01 wins the
+// league and Team N finishes bottom. It stops after limit matches when limit
+// is positive.
+func seedRoundRobin(repo *repository.Memory, seasonID, teams, limit int) []int {
+	ids := make([]int, teams)
+	for i := range ids {
+		ids[i] = repo.AddTeam(models.Team{Name: fmt.Sprintf("Team%02d", i+1)})
+	}
+	playRoundRobin(repo, seasonID, ids, limit)
+	return ids
+}
+
+// playRoundRobin adds a season played by existing teams, the earlier ones in
+// ids beating the later ones
+func playRoundRobin(repo *repository.Memory, seasonID int, ids []int, limit int) {
+	kickoff := time.Date(1991+seasonID, 8, 1, 15, 0, 0, 0, time.UTC)
+	repo.AddSeason(models.Season{ID: seasonID, Name: fmt.Sprintf("%d/%02d", 1991+seasonID, (1992+seasonID)%100), StartDate: kickoff})
+
+	added := 0
+	for i := range ids {
+		for j := range ids {
+			if i == j || (limit > 0 && added == limit) {
+				continue
+			}
+			home, away := 1, 0
+			if i > j {
+				home, away = 0, 1
+			}
+			repo.AddMatch(models.Match{
+				SeasonID:   seasonID,
+				HomeTeamID: ids[i],
+				AwayTeamID: ids[j],
+				HomeScore:  intPtr(home),
+				AwayScore:  intPtr(away),
+				MatchDate:  kickoff.Add(time.Duration(added) * time.Hour),
+			})
+			added++
+		}
+	}
+}
+
+func newSeasonService(repo *repository.Memory) *SeasonService {
+	return NewSeasonService(repo, repo, NewStandingsService(repo, repo, repo, repo, nil), nil)
+}
+
+func TestSeasonSummaryRelegation(t *testing.T) {
+	repo := repository.NewMemory()
+	seedRoundRobin(repo, 4, 20, 0)
+
+	summary, err := newSeasonService(repo).GetSeasonSummary(4)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if summary.TotalMatches != 380 || !summary.Completed {
+		t.Errorf("TotalMatches = %d, completed %v; want a complete 380-match season", summary.TotalMatches, summary.Completed)
+	}
+	if summary.TotalGoals != 380 || summary.AvgGoalsPerMatch != 1 {
+		t.Errorf("goals = %d at %.2f a match, want 380 at 1.00", summary.TotalGoals, summary.AvgGoalsPerMatch)
+	}
+	if summary.Champion != "Team01" || summary.Leader != "" {
+		t.Errorf("Champion = %q, Leader = %q; want Team01 as champion", summary.Champion, summary.Leader)
+	}
+	if want := []string{"Team20", "Team19", "Team18"}; !reflect.DeepEqual(summary.Relegated, want) {
+		t.Errorf("Relegated = %v, want %v", summary.Relegated, want)
+	}
+
+	// Seasons without stored rules get the default allocation
+	european := []models.EuropeanQualifiers{
+		{Competition: CompetitionChampionsLeague, Teams: []string{"Team01", "Team02", "Team03", "Team04"}},
+		{Competition: CompetitionEuropaLeague, Teams: []string{"Team05"}},
+	}
+	if !reflect.DeepEqual(summary.European, european) {
+		t.Errorf("European = %v, want %v", summary.European, european)
+	}
+	if summary.Rules.TeamCount != 20 || summary.Rules.RelegationPlaces != 3 {
+		t.Errorf("Rules = %+v, want 20 teams and 3 relegated", summary.Rules)
+	}
+	if len(summary.Promoted) != 0 {
+		t.Errorf("Promoted = %v, want none without a previous season", summary.Promoted)
+	}
+}
+
+func TestSeasonSummaryTwentyTwoTeamSeason(t *testing.T) {
+	rules := repository.SeasonRules{TeamCount: 22, RelegationPlaces: 4, PromotionPlaces: 2, EuropeanPlaces: "championsLeague:1,uefaCup:2"}
+
+	repo := repository.NewMemory()
+	seedRoundRobin(repo, 3, 22, 0)
+	repo.SetSeasonRules(3, rules)
+
+	summary, err := newSeasonService(repo).GetSeasonSummary(3)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if summary.TotalMatches != 462 || !summary.Completed {
+		t.Errorf("TotalMatches = %d, completed %v; want a complete 462-match season", summary.TotalMatches, summary.Completed)
+	}
+	if want := []string{"Team22", "Team21", "Team20", "Team19"}; !reflect.DeepEqual(summary.Relegated, want) {
+		t.Errorf("Relegated = %v, want %v", summary.Relegated, want)
+	}
+	european := []models.EuropeanQualifiers{
+		{Competition: CompetitionChampionsLeague, Teams: []string{"Team01"}},
+		{Competition: CompetitionUEFACup, Teams: []string{"Team02", "Team03"}},
+	}
+	if !reflect.DeepEqual(summary.European, european) {
+		t.Errorf("European = %v, want %v", summary.European, european)
+	}
+	if summary.Rules.PromotionPlaces != 2 {
+		t.Errorf("Rules = %+v, want 2 promoted", summary.Rules)
+	}
+
+	// A 20-team programme is not a full 22-team season
+	repo = repository.NewMemory()
+	seedRoundRobin(repo, 3, 22, 380)
+	repo.SetSeasonRules(3, rules)
+
+	summary, err = newSeasonService(repo).GetSeasonSummary(3)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if summary.Completed || summary.Champion != "" || len(summary.Relegated) != 0 || len(summary.European) != 0 {
+		t.Errorf("summary = %+v, want no final outcomes after 380 of 462 matches", summary)
+	}
+}
+
+func TestSeasonSummaryIncompleteSeasonHasNoRelegation(t *testing.T) {
+	repo := repository.NewMemory()
+	seedRoundRobin(repo, 4, 20, 379)
+
+	summary, err := newSeasonService(repo).GetSeasonSummary(4)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if summary.TotalMatches != 379 || summary.Completed {
+		t.Errorf("TotalMatches = %d, completed %v; want 379 and incomplete", summary.TotalMatches, summary.Completed)
+	}
+	if summary.Leader == "" || summary.Champion != "" {
+		t.Errorf("Leader = %q, Champion = %q; want a leader and no champion yet", summary.Leader, summary.Champion)
+	}
+	if len(summary.Relegated) != 0 {
+		t.Errorf("Relegated = %v, want none before the season is complete", summary.Relegated)
+	}
+}
+
+func TestSeasonSummaryPromotedTeams(t *testing.T) {
+	repo := repository.NewMemory()
+	ids := seedRoundRobin(repo, 4, 20, 0)
+
+	// The bottom three go down and three new teams come up
+	next := append([]int{}, ids[:17]...)
+	for _, name := range []string{"Riverside", "Bramall", "Ashton"} {
+		next = append(next, repo.AddTeam(models.Team{Name: name}))
+	}
+	playRoundRobin(repo, 5, next, 0)
+
+	summary, err := newSeasonService(repo).GetSeasonSummary(5)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if want := []string{"Ashton", "Bramall", "Riverside"}; !reflect.DeepEqual(summary.Promoted, want) {
+		t.Errorf("Promoted = %v, want %v", summary.Promoted, want)
+	}
+}
+
+func TestSeasonSummaryPromotedTeamsByStartDate(t *testing.T) {
+	repo := repository.NewMemory()
+	ids := seedRoundRobin(repo, 4, 20, 0)
+	// Season 3 was loaded later but starts after season 4
+	next := append([]int{}, ids[:17]...)
+	for _, name := range []string{"Riverside", "Bramall", "Ashton"} {
+		next = append(next, repo.AddTeam(models.Team{Name: name}))
+	}
+	playRoundRobin(repo, 3, next, 0)
+	repo.AddSeason(models.Season{ID: 3, Name: "1996/97", StartDate: time.Date(1996, 8, 1, 0, 0, 0, 0, time.UTC)})
+
+	summary, err := newSeasonService(repo).GetSeasonSummary(3)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if want := []string{"Ashton", "Bramall", "Riverside"}; !reflect.DeepEqual(summary.Promoted, want) {
+		t.Errorf("Promoted = %v, want %v", summary.Promoted, want)
+	}
+
+	// Season 4 starts first, so nothing came before it to compare with
+	summary, err = newSeasonService(repo).GetSeasonSummary(4)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if len(summary.Promoted) != 0 {
+		t.Errorf("Promoted = %v, want none for the first season", summary.Promoted)
+	}
+}
+
+func TestSeasonSummaryPromotedTeamsPartialPreviousSeason(t *testing.T) {
+	repo := repository.NewMemory()
+	ids := seedRoundRobin(repo, 4, 20, 0)
+	// The previous season has only the matches between four of the teams
+	playRoundRobin(repo, 3, ids[:4], 0)
+
+	summary, err := newSeasonService(repo).GetSeasonSummary(4)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if len(summary.Promoted) != 0 {
+		t.Errorf("Promoted = %v, want none when the previous season is partly loaded", summary.Promoted)
+	}
+}
+
+func TestSeasonSummaryPlayOff(t *testing.T) {
+	repo := repository.NewMemory()
+	ids := seedRoundRobin(repo, 4, 4, 0)
+	repo.SetTieBreakRules(4, "points,playoff")
+	repo.SetSeasonRules(4, repository.SeasonRules{RelegationPlaces: 1, EuropeanPlaces: "championsLeague:2"})
+	// Team01's six wins less six points leaves it level with Team02
+	repo.AddPointAdjustment(models.PointAdjustment{TeamID: ids[0], SeasonID: 4, Points: -6, Reason: "Breach of rules"})
+
+	summary, err := newSeasonService(repo).GetSeasonSummary(4)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if len(summary.PlayOffs) != 1 || summary.PlayOffs[0].Place != PlaceChampion {
+		t.Fatalf("PlayOffs = %+v, want a title play-off", summary.PlayOffs)
+	}
+	teams := append([]string{}, summary.PlayOffs[0].Teams...)
+	sort.Strings(teams)
+	if want := []string{"Team01", "Team02"}; !reflect.DeepEqual(teams, want) {
+		t.Errorf("play-off teams = %v, want %v", teams, want)
+	}
+}
+
+func TestDescribeSeason(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := time.Date(2024, 8, 16, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 5, 25, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		season   models.Season
+		expected int
+		status   string
+	}{
+		{"22-team season", models.Season{TeamCount: 22, PlayedMatches: 462}, 462, models.SeasonComplete},
+		{"default format", models.Season{PlayedMatches: 379}, 380, models.SeasonInProgress},
+		{"under way", models.Season{StartDate: start, EndDate: end, PlayedMatches: 190}, 380, models.SeasonInProgress},
+		{"started without results", models.Season{StartDate: start, EndDate: end}, 380, models.SeasonInProgress},
+		{"not started", models.Season{StartDate: end, EndDate: end.AddDate(1, 0, 0)}, 380, models.SeasonUpcoming},
+		{"past with missing results", models.Season{StartDate: start.AddDate(-1, 0, 0), EndDate: end.AddDate(-1, 0, 0), PlayedMatches: 300}, 380, models.SeasonComplete},
+	}
+	for _, tt := range tests {
+		season := tt.season
+		describeSeason(&season, now)
+		if season.ExpectedMatches != tt.expected || season.Status != tt.status {
+			t.Errorf("%s: expected %d matches with status %q, want %d and %q", tt.name, season.ExpectedMatches, season.Status, tt.expected, tt.status)
+		}
+		if season.PointsForWin != 3 {
+			t.Errorf("%s: PointsForWin = %d, want the default 3", tt.name, season.PointsForWin)
+		}
+	}
+}
+
+func TestGetCurrentSeason(t *testing.T) {
+	repo := repository.NewMemory()
+	for year := 2023; year <= 2025; year++ {
+		repo.AddSeason(models.Season{
+			ID:        year - 1991,
+			Name:      fmt.Sprintf("%d/%02d", year, (year+1)%100),
+			StartDate: time.Date(year, 8, 10, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(year+1, 5, 20, 0, 0, 0, 0, time.UTC),
+		})
+	}
+	// Loaded after the others, but the earliest season
+	repo.AddSeason(models.Season{
+		ID:        40,
+		Name:      "2022/23",
+		StartDate: time.Date(2022, 8, 5, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2023, 5, 28, 0, 0, 0, 0, time.UTC),
+	})
+	service := newSeasonService(repo)
+
+	tests := []struct {
+		now  time.Time
+		want int
+	}{
+		{time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC), 33},
+		// Between seasons the last one played stays current
+		{time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC), 33},
+		{time.Date(2025, 8, 10, 0, 0, 0, 0, time.UTC), 34},
+		{time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 40},
+		// Before any season has started, the first one is current
+		{time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), 40},
+	}
+	for _, tt := range tests {
+		service.now = func() time.Time { return tt.now }
+		season, err := service.GetCurrentSeason()
+		if err != nil {
+			t.Fatalf("GetCurrentSeason: %v", err)
+		}
+		if season.ID != tt.want {
+			t.Errorf("current season on %s = %d, want %d", tt.now.Format("2006-01-02"), season.ID, tt.want)
+		}
+	}
+
+	if _, err := newSeasonService(repository.NewMemory()).GetCurrentSeason(); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("GetCurrentSeason without seasons error = %v, want not found", err)
+	}
+}
+
+func TestParseEuropeanPlaces(t *testing.T) {
+	places, err := ParseEuropeanPlaces("")
+	if err != nil || len(places) != 2 || places[0].Competition != CompetitionChampionsLeague || places[0].Places != 4 {
+		t.Errorf("ParseEuropeanPlaces(\"\") = %v, %v; want the default allocation", places, err)
+	}
+
+	for _, raw := range []string{"championsLeague", "superLeague:4", "uefaCup:0", "europaLeague:x"} {
+		if _, err := ParseEuropeanPlaces(raw); err == nil {
+			t.Errorf("ParseEuropeanPlaces(%q) succeeded, want an error", raw)
+		}
+	}
+}
+
+func TestSeasonSummaryPointDeduction(t *testing.T) {
+	repo := repository.NewMemory()
+	ids := seedRoundRobin(repo, 4, 20, 0)
+	repo.AddPointAdjustment(models.PointAdjustment{TeamID: ids[0], SeasonID: 4, Points: -120, Reason: "Administration"})
+
+	summary, err := newSeasonService(repo).GetSeasonSummary(4)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if summary.Champion != "Team02" {
+		t.Errorf("Champion = %q, want Team02", summary.Champion)
+	}
+	if want := []string{"Team01", "Team20", "Team19"}; !reflect.DeepEqual(summary.Relegated, want) {
+		t.Errorf("Relegated = %v, want %v", summary.Relegated, want)
+	}
+}
+
+func TestSeasonSummaryWithoutResults(t *testing.T) {
+	repo := repository.NewMemory()
+	repo.AddSeason(models.Season{ID: 34, Name: "2025/26"})
+
+	summary, err := newSeasonService(repo).GetSeasonSummary(34)
+	if err != nil {
+		t.Fatalf("GetSeasonSummary: %v", err)
+	}
+	if summary.TotalMatches != 0 || summary.Champion != "" || len(summary.Relegated) != 0 {
+		t.Errorf("summary = %+v, want an empty season", summary)
+	}
+}
@@ -1,225 +1,403 @@
 package services
 
 import (
-	"database/sql"
 	"fmt"
+	"sort"
+	"time"
 
-	"github.com/premstats/api/internal/database"
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/cache"
 	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
 )
 
-// StandingsService handles standings-related database operations
+// StandingsService computes league tables from results
 type StandingsService struct {
-	db *database.DB
+	seasons   repository.SeasonRepository
+	teams     repository.TeamRepository
+	matches   repository.MatchRepository
+	standings repository.StandingsRepository
+	cache     *cache.Cache
+	now       func() time.Time
 }
 
-// NewStandingsService creates a new standings service
-func NewStandingsService(db *database.DB) *StandingsService {
-	return &StandingsService{db: db}
+// NewStandingsService creates a new standings service. Computed tables are
+// kept in c, which may be nil.
+func NewStandingsService(seasons repository.SeasonRepository, teams repository.TeamRepository, matches repository.MatchRepository, standings repository.StandingsRepository, c *cache.Cache) *StandingsService {
+	return &StandingsService{seasons: seasons, teams: teams, matches: matches, standings: standings, cache: c, now: time.Now}
+}
+
+// Table views; split views count only part of each match
+const (
+	StandingsViewOverall    = "overall"
+	StandingsViewHome       = "home"
+	StandingsViewAway       = "away"
+	StandingsViewFirstHalf  = "firstHalf"
+	StandingsViewSecondHalf = "secondHalf"
+	StandingsViewHalfTime   = "halfTime"
+)
+
+// standingsViews maps each view to the venues it includes and the score it
+// counts for each match
+var standingsViews = map[string]struct {
+	home, away bool
+	score      string
+}{
+	StandingsViewOverall: {true, true, repository.ScoreFullTime},
+	StandingsViewHome:    {true, false, repository.ScoreFullTime},
+	StandingsViewAway:    {false, true, repository.ScoreFullTime},
+	// First-half goals are read from the stored half-time score, so this view
+	// matches halfTime; it is kept separate so clients can pair it with secondHalf
+	StandingsViewFirstHalf:  {true, true, repository.ScoreFirstHalf},
+	StandingsViewSecondHalf: {true, true, repository.ScoreSecondHalf},
+	StandingsViewHalfTime:   {true, true, repository.ScoreFirstHalf},
+}
+
+// ValidStandingsView reports whether view names a supported table view
+func ValidStandingsView(view string) bool {
+	_, ok := standingsViews[view]
+	return ok
+}
+
+// StandingsOptions restricts which matches count towards a table
+type StandingsOptions struct {
+	// AsOf includes only matches played on or before this date
+	AsOf *time.Time
+	// Matchweek includes only each team's first N matches
+	Matchweek int
+	// View selects an overall, venue-only or split-half table; empty means overall
+	View string
 }
 
 // GetStandingsBySeasonID calculates and returns the league table for a specific season
 func (s *StandingsService) GetStandingsBySeasonID(seasonID int) (*models.Standings, error) {
-	// Get season information
-	var seasonName string
-	err := s.db.QueryRow("SELECT name FROM seasons WHERE id = $1", seasonID).Scan(&seasonName)
+	return s.GetStandings(seasonID, StandingsOptions{})
+}
+
+// GetStandings calculates the league table for a season, optionally as it
+// stood on a date or after a matchweek
+func (s *StandingsService) GetStandings(seasonID int, opts StandingsOptions) (*models.Standings, error) {
+	var asOf string
+	if opts.AsOf != nil {
+		asOf = opts.AsOf.Format("2006-01-02")
+	}
+	key := fmt.Sprintf("standings:%d:%s:%s:%d", seasonID, opts.view(), asOf, opts.Matchweek)
+
+	return cache.Fetch(s.cache, key, seasonID, func() (*models.Standings, error) {
+		return s.computeStandings(seasonID, opts)
+	})
+}
+
+// computeStandings builds the table GetStandings returns
+func (s *StandingsService) computeStandings(seasonID int, opts StandingsOptions) (*models.Standings, error) {
+	season, err := s.getSeason(seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := s.buildTables(season, opts, false)
+	if err != nil {
+		return nil, err
+	}
+
+	standings := &models.Standings{
+		SeasonID:  seasonID,
+		Season:    season.Name,
+		View:      opts.view(),
+		Matchweek: opts.Matchweek,
+		Table:     []models.StandingsEntry{},
+	}
+	if opts.AsOf != nil {
+		standings.AsOf = opts.AsOf.Format("2006-01-02")
+	}
+	if len(tables) > 0 {
+		standings.TieBreakRules = tables[0].rules
+		standings.Table = tables[0].entries
+	}
+
+	return standings, nil
+}
+
+// GetPositionSeries returns every team's position and points after each
+// matchweek of the given view
+func (s *StandingsService) GetPositionSeries(seasonID int, view string) (*models.PositionSeries, error) {
+	key := fmt.Sprintf("positions:%d:%s", seasonID, StandingsOptions{View: view}.view())
+
+	return cache.Fetch(s.cache, key, seasonID, func() (*models.PositionSeries, error) {
+		return s.computePositionSeries(seasonID, view)
+	})
+}
+
+// computePositionSeries builds the series GetPositionSeries returns
+func (s *StandingsService) computePositionSeries(seasonID int, view string) (*models.PositionSeries, error) {
+	season, err := s.getSeason(seasonID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("season with ID %d not found", seasonID)
+		return nil, err
+	}
+
+	opts := StandingsOptions{View: view}
+	tables, err := s.buildTables(season, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &models.PositionSeries{
+		SeasonID:   seasonID,
+		Season:     season.Name,
+		View:       opts.view(),
+		Matchweeks: len(tables),
+		Teams:      []models.TeamPositionSeries{},
+	}
+
+	byTeam := make(map[int]int)
+	for _, table := range tables {
+		for _, entry := range table.entries {
+			idx, ok := byTeam[entry.TeamID]
+			if !ok {
+				idx = len(series.Teams)
+				byTeam[entry.TeamID] = idx
+				series.Teams = append(series.Teams, models.TeamPositionSeries{
+					TeamID: entry.TeamID,
+					Team:   entry.Team,
+				})
+			}
+			series.Teams[idx].Positions = append(series.Teams[idx].Positions, entry.Position)
+			series.Teams[idx].Points = append(series.Teams[idx].Points, entry.Points)
 		}
-		return nil, fmt.Errorf("failed to get season: %w", err)
-	}
-
-	// Calculate standings from matches
-	query := `
-		WITH team_stats AS (
-			SELECT 
-				t.id as team_id,
-				t.name as team_name,
-				COUNT(CASE WHEN m.home_team_id = t.id OR m.away_team_id = t.id THEN 1 END) as played,
-				COUNT(CASE 
-					WHEN (m.home_team_id = t.id AND m.home_score > m.away_score) OR 
-						 (m.away_team_id = t.id AND m.away_score > m.home_score) 
-					THEN 1 
-				END) as won,
-				COUNT(CASE 
-					WHEN (m.home_team_id = t.id OR m.away_team_id = t.id) AND m.home_score = m.away_score 
-					THEN 1 
-				END) as drawn,
-				COUNT(CASE 
-					WHEN (m.home_team_id = t.id AND m.home_score < m.away_score) OR 
-						 (m.away_team_id = t.id AND m.away_score < m.home_score) 
-					THEN 1 
-				END) as lost,
-				COALESCE(SUM(CASE WHEN m.home_team_id = t.id THEN m.home_score ELSE 0 END), 0) +
-				COALESCE(SUM(CASE WHEN m.away_team_id = t.id THEN m.away_score ELSE 0 END), 0) as goals_for,
-				COALESCE(SUM(CASE WHEN m.home_team_id = t.id THEN m.away_score ELSE 0 END), 0) +
-				COALESCE(SUM(CASE WHEN m.away_team_id = t.id THEN m.home_score ELSE 0 END), 0) as goals_against
-			FROM teams t
-			LEFT JOIN matches m ON (m.home_team_id = t.id OR m.away_team_id = t.id) 
-				AND m.season_id = $1 
-				AND m.home_score IS NOT NULL 
-				AND m.away_score IS NOT NULL
-			WHERE t.id IN (
-				SELECT DISTINCT home_team_id FROM matches WHERE season_id = $1
-				UNION
-				SELECT DISTINCT away_team_id FROM matches WHERE season_id = $1
-			)
-			GROUP BY t.id, t.name
-		)
-		SELECT 
-			team_id,
-			team_name,
-			played,
-			won,
-			drawn,
-			lost,
-			goals_for,
-			goals_against,
-			(goals_for - goals_against) as goal_difference,
-			(won * 3 + drawn) as points
-		FROM team_stats
-		ORDER BY points DESC, goal_difference DESC, goals_for DESC, team_name ASC
-	`
-
-	rows, err := s.db.Query(query, seasonID)
+	}
+
+	// List teams in final table order
+	sort.SliceStable(series.Teams, func(i, j int) bool {
+		a, b := series.Teams[i].Positions, series.Teams[j].Positions
+		return a[len(a)-1] < b[len(b)-1]
+	})
+
+	return series, nil
+}
+
+// rankedTable is one computed table, along with the rules used to order it
+type rankedTable struct {
+	matchweek int
+	entries   []models.StandingsEntry
+	rules     []string
+}
+
+// buildTables aggregates results per team and ranks them, awarding the
+// season's points for a win. When perMatchweek is set a table is produced
+// after every matchweek, otherwise a single table.
+func (s *StandingsService) buildTables(season *models.Season, opts StandingsOptions, perMatchweek bool) ([]rankedTable, error) {
+	seasonID := season.ID
+	view, ok := standingsViews[opts.view()]
+	if !ok {
+		return nil, apperrors.InvalidArgument("unknown standings view %q", opts.View)
+	}
+
+	query := repository.TableQuery{
+		Home:         view.home,
+		Away:         view.away,
+		Score:        view.score,
+		Matchweek:    opts.Matchweek,
+		PerMatchweek: perMatchweek,
+	}
+	if opts.AsOf != nil {
+		before := opts.AsOf.AddDate(0, 0, 1)
+		query.Before = &before
+	}
+
+	rows, err := s.tableRows(seasonID, query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate standings for season %d: %w", seasonID, err)
-	}
-	defer rows.Close()
-
-	var entries []models.StandingsEntry
-	position := 1
-
-	for rows.Next() {
-		var entry models.StandingsEntry
-		err := rows.Scan(
-			&entry.TeamID,
-			&entry.Team,
-			&entry.Played,
-			&entry.Won,
-			&entry.Drawn,
-			&entry.Lost,
-			&entry.GoalsFor,
-			&entry.GoalsAgainst,
-			&entry.GoalDifference,
-			&entry.Points,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan standings row: %w", err)
+		return nil, err
+	}
+
+	var tables []rankedTable
+	var cutoffs []time.Time
+	for _, row := range rows {
+		if len(tables) == 0 || tables[len(tables)-1].matchweek != row.Matchweek {
+			tables = append(tables, rankedTable{matchweek: row.Matchweek})
+			cutoffs = append(cutoffs, time.Time{})
 		}
+		entry := row.Entry
+		entry.GoalDifference = entry.GoalsFor - entry.GoalsAgainst
+		entry.Points = entry.Won*season.PointsForWin + entry.Drawn
+		tables[len(tables)-1].entries = append(tables[len(tables)-1].entries, entry)
+		if row.LastMatch != nil && row.LastMatch.After(cutoffs[len(cutoffs)-1]) {
+			cutoffs[len(cutoffs)-1] = *row.LastMatch
+		}
+	}
 
-		entry.Position = position
-		entries = append(entries, entry)
-		position++
+	// Deductions belong to the overall table only
+	adjustments := map[int][]models.PointAdjustment{}
+	if opts.view() == StandingsViewOverall {
+		if adjustments, err = s.standings.ListPointAdjustments(seasonID); err != nil {
+			return nil, err
+		}
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating standings rows: %w", err)
+	// Order each table in Go so ties are settled by the season's own rules
+	rules, err := s.getTieBreakRules(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	if opts.view() != StandingsViewOverall {
+		rules = withoutHeadToHead(rules)
+	}
+	// Results feed head-to-head criteria and the overall table's form column
+	showForm := !perMatchweek && opts.view() == StandingsViewOverall
+	var results []matchResult
+	if needsResults(rules) || showForm {
+		if results, err = s.getMatchResults(seasonID); err != nil {
+			return nil, err
+		}
 	}
 
-	standings := &models.Standings{
-		SeasonID: seasonID,
-		Season:   seasonName,
-		Table:    entries,
+	var asOf time.Time
+	if opts.AsOf != nil {
+		asOf = *opts.AsOf
 	}
+	played := resultsUntil(results, asOf)
 
-	return standings, nil
+	for i := range tables {
+		// A full-season table takes every adjustment; partial tables only
+		// those applied by the date of their latest match
+		cutoff := asOf
+		if opts.AsOf == nil && tables[i].matchweek > 0 {
+			cutoff = cutoffs[i]
+		}
+		applyPointAdjustments(tables[i].entries, adjustments, cutoff)
+
+		// A matchweek table counts each team's first N games, which a date
+		// cutoff cannot express when teams have played at different paces
+		counted := played
+		if tables[i].matchweek > 0 {
+			counted = resultsWithinGames(played, tables[i].entries)
+		}
+		rankStandings(tables[i].entries, rules, counted, season.PointsForWin)
+		tables[i].rules = rules
+
+		if showForm {
+			for j := range tables[i].entries {
+				tables[i].entries[j].Form = recentForm(played, tables[i].entries[j].TeamID, tables[i].entries[j].Played, DefaultFormLength)
+			}
+		}
+	}
+
+	return tables, nil
 }
 
-// GetAvailableSeasons returns all seasons that have match data
-func (s *StandingsService) GetAvailableSeasons() ([]models.Season, error) {
-	query := `
-		SELECT DISTINCT s.id, s.name
-		FROM seasons s
-		JOIN matches m ON s.id = m.season_id
-		WHERE m.home_score IS NOT NULL AND m.away_score IS NOT NULL
-		ORDER BY s.id ASC
-	`
-
-	rows, err := s.db.Query(query)
+// tableRows loads the table lines for a query. Whole-season full-time tables
+// come from the stored season aggregates; partial and split-half tables are
+// aggregated from the matches themselves.
+func (s *StandingsService) tableRows(seasonID int, q repository.TableQuery) ([]repository.TableRow, error) {
+	whole := q.Before == nil && q.Matchweek == 0 && !q.PerMatchweek
+	if !whole || (q.Score != "" && q.Score != repository.ScoreFullTime) || (!q.Home && !q.Away) {
+		return s.standings.ListTableRows(seasonID, q)
+	}
+
+	aggregates, err := s.standings.ListTeamSeasonAggregates(seasonID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get available seasons: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	var seasons []models.Season
-	for rows.Next() {
-		var season models.Season
-		err := rows.Scan(&season.ID, &season.Name)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan season row: %w", err)
+	rows := make([]repository.TableRow, 0, len(aggregates))
+	for _, a := range aggregates {
+		record := a.Overall
+		if !q.Away {
+			record = a.Home
+		} else if !q.Home {
+			record = a.Away
 		}
-		seasons = append(seasons, season)
+		rows = append(rows, repository.TableRow{
+			Entry: models.StandingsEntry{
+				TeamID:       a.TeamID,
+				Team:         a.Team,
+				Played:       record.Played,
+				Won:          record.Won,
+				Drawn:        record.Drawn,
+				Lost:         record.Lost,
+				GoalsFor:     record.GoalsFor,
+				GoalsAgainst: record.GoalsAgainst,
+			},
+			LastMatch: record.LastMatch,
+		})
 	}
+	return rows, nil
+}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating season rows: %w", err)
+// view returns the requested view, defaulting to overall
+func (o StandingsOptions) view() string {
+	if o.View == "" {
+		return StandingsViewOverall
 	}
+	return o.View
+}
 
-	return seasons, nil
+// getSeason returns a season with its defaults filled in, or an error if it
+// does not exist
+func (s *StandingsService) getSeason(seasonID int) (*models.Season, error) {
+	season, err := s.seasons.GetSeason(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	describeSeason(season, s.now())
+	return season, nil
+}
+
+// GetAvailableSeasons returns all seasons that have match data
+func (s *StandingsService) GetAvailableSeasons() ([]models.Season, error) {
+	seasons, err := s.seasons.ListSeasonsWithResults()
+	if err != nil {
+		return nil, err
+	}
+	return describeSeasons(seasons, s.now()), nil
 }
 
-// GetTeamStatsForSeason returns detailed statistics for a specific team in a season
+// GetTeamStatsForSeason returns detailed statistics for a specific team in a
+// season. A team that played no matches in the season gets empty statistics.
 func (s *StandingsService) GetTeamStatsForSeason(teamID, seasonID int) (*models.TeamStats, error) {
-	query := `
-		SELECT 
-			t.id as team_id,
-			t.name as team_name,
-			s.id as season_id,
-			s.name as season_name,
-			COUNT(CASE WHEN m.home_team_id = t.id OR m.away_team_id = t.id THEN 1 END) as played,
-			COUNT(CASE 
-				WHEN (m.home_team_id = t.id AND m.home_score > m.away_score) OR 
-					 (m.away_team_id = t.id AND m.away_score > m.home_score) 
-				THEN 1 
-			END) as won,
-			COUNT(CASE 
-				WHEN (m.home_team_id = t.id OR m.away_team_id = t.id) AND m.home_score = m.away_score 
-				THEN 1 
-			END) as drawn,
-			COUNT(CASE 
-				WHEN (m.home_team_id = t.id AND m.home_score < m.away_score) OR 
-					 (m.away_team_id = t.id AND m.away_score < m.home_score) 
-				THEN 1 
-			END) as lost,
-			COALESCE(SUM(CASE WHEN m.home_team_id = t.id THEN m.home_score ELSE 0 END), 0) +
-			COALESCE(SUM(CASE WHEN m.away_team_id = t.id THEN m.away_score ELSE 0 END), 0) as goals_for,
-			COALESCE(SUM(CASE WHEN m.home_team_id = t.id THEN m.away_score ELSE 0 END), 0) +
-			COALESCE(SUM(CASE WHEN m.away_team_id = t.id THEN m.home_score ELSE 0 END), 0) as goals_against
-		FROM teams t
-		CROSS JOIN seasons s
-		LEFT JOIN matches m ON (m.home_team_id = t.id OR m.away_team_id = t.id) 
-			AND m.season_id = s.id 
-			AND m.home_score IS NOT NULL 
-			AND m.away_score IS NOT NULL
-		WHERE t.id = $1 AND s.id = $2
-		GROUP BY t.id, t.name, s.id, s.name
-	`
-
-	var stats models.TeamStats
-	err := s.db.QueryRow(query, teamID, seasonID).Scan(
-		&stats.TeamID,
-		&stats.Team,
-		&stats.SeasonID,
-		&stats.Season,
-		&stats.MatchesPlayed,
-		&stats.Wins,
-		&stats.Draws,
-		&stats.Losses,
-		&stats.GoalsFor,
-		&stats.GoalsAgainst,
-	)
+	season, err := s.getSeason(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	team, err := s.teams.GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := s.standings.ListTeamSeasonAggregates(seasonID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("team %d not found in season %d", teamID, seasonID)
+		return nil, err
+	}
+
+	stats := models.TeamStats{TeamID: team.ID, Team: team.Name, SeasonID: seasonID, Season: season.Name}
+	for _, a := range aggregates {
+		if a.TeamID != teamID {
+			continue
 		}
-		return nil, fmt.Errorf("failed to get team stats: %w", err)
+		stats.MatchesPlayed = a.Overall.Played
+		stats.Wins = a.Overall.Won
+		stats.Draws = a.Overall.Drawn
+		stats.Losses = a.Overall.Lost
+		stats.GoalsFor = a.Overall.GoalsFor
+		stats.GoalsAgainst = a.Overall.GoalsAgainst
+		stats.Home = venueRecord(a.Home)
+		stats.Away = venueRecord(a.Away)
+		stats.YellowCards = a.YellowCards
+		stats.RedCards = a.RedCards
+		stats.Shots = a.Shots
+		stats.ShotsOnTarget = a.ShotsOnTarget
+	}
+	adjustments, err := s.standings.ListPointAdjustments(seasonID)
+	if err != nil {
+		return nil, err
+	}
+	stats.Adjustments = adjustments[teamID]
+	for _, adjustment := range stats.Adjustments {
+		stats.PointsAdjustment += adjustment.Points
 	}
 
 	// Calculate derived stats
 	stats.GoalDifference = stats.GoalsFor - stats.GoalsAgainst
-	stats.Points = stats.Wins*3 + stats.Draws
+	stats.Points = stats.Wins*season.PointsForWin + stats.Draws + stats.PointsAdjustment
 
 	if stats.MatchesPlayed > 0 {
 		stats.WinPercentage = float64(stats.Wins) / float64(stats.MatchesPlayed) * 100
@@ -228,3 +406,32 @@ func (s *StandingsService) GetTeamStatsForSeason(teamID, seasonID int) (*models.
 
 	return &stats, nil
 }
+
+// venueRecord converts a stored home or away record for the API
+func venueRecord(r repository.AggregateRecord) models.VenueRecord {
+	return models.VenueRecord{
+		Played:       r.Played,
+		Won:          r.Won,
+		Drawn:        r.Drawn,
+		Lost:         r.Lost,
+		GoalsFor:     r.GoalsFor,
+		GoalsAgainst: r.GoalsAgainst,
+	}
+}
+
+// applyPointAdjustments folds adjustments applied on or before cutoff into each
+// entry's points; a zero cutoff applies every adjustment
+func applyPointAdjustments(entries []models.StandingsEntry, adjustments map[int][]models.PointAdjustment, cutoff time.Time) {
+	for i := range entries {
+		entries[i].Adjustments = nil
+		entries[i].PointsAdjustment = 0
+		for _, adjustment := range adjustments[entries[i].TeamID] {
+			if !cutoff.IsZero() && adjustment.AppliedOn != "" && adjustment.AppliedOn > cutoff.Format("2006-01-02") {
+				continue
+			}
+			entries[i].Adjustments = append(entries[i].Adjustments, adjustment)
+			entries[i].PointsAdjustment += adjustment.Points
+		}
+		entries[i].Points += entries[i].PointsAdjustment
+	}
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+// seedTieBreak adds a season in which Arsenal and Blackburn finish level on
+// points, Arsenal with the better goal difference and Blackburn having won
+// their meeting
+func seedTieBreak() *repository.Memory {
+	repo := repository.NewMemory()
+	repo.AddSeason(models.Season{ID: 3, Name: "1994/95"})
+	arsenal := repo.AddTeam(models.Team{Name: "Arsenal"})
+	blackburn := repo.AddTeam(models.Team{Name: "Blackburn"})
+	chelsea := repo.AddTeam(models.Team{Name: "Chelsea"})
+
+	for i, m := range []struct{ home, away, homeScore, awayScore int }{
+		{arsenal, chelsea, 5, 0},
+		{blackburn, arsenal, 1, 0},
+		{blackburn, chelsea, 0, 0},
+		{chelsea, arsenal, 0, 0},
+	} {
+		repo.AddMatch(models.Match{
+			SeasonID:   3,
+			HomeTeamID: m.home,
+			AwayTeamID: m.away,
+			HomeScore:  intPtr(m.homeScore),
+			AwayScore:  intPtr(m.awayScore),
+			MatchDate:  time.Date(1994, 8, 20+7*i, 15, 0, 0, 0, time.UTC),
+		})
+	}
+	return repo
+}
+
+func tableOrder(standings *models.Standings) []string {
+	var teams []string
+	for _, entry := range standings.Table {
+		teams = append(teams, entry.Team)
+	}
+	return teams
+}
+
+func TestStandingsTieBreakRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  string
+	}{
+		{"default uses goal difference", "", "Arsenal"},
+		{"head-to-head before goal difference", "points,headToHeadPoints,goalDifference", "Blackburn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := seedTieBreak()
+			repo.SetTieBreakRules(3, tt.rules)
+
+			standings, err := NewStandingsService(repo, repo, repo, repo, nil).GetStandingsBySeasonID(3)
+			if err != nil {
+				t.Fatalf("GetStandingsBySeasonID: %v", err)
+			}
+			if order := tableOrder(standings); order[0] != tt.want {
+				t.Errorf("table order = %v, want %s top", order, tt.want)
+			}
+			for _, entry := range standings.Table {
+				if entry.Points != 4 && entry.Team != "Chelsea" {
+					t.Errorf("%s has %d points, want 4", entry.Team, entry.Points)
+				}
+			}
+		})
+	}
+}
+
+func TestStandingsMatchweekHeadToHead(t *testing.T) {
+	repo := seedTieBreak()
+	repo.SetTieBreakRules(3, "points,headToHeadPoints,goalDifference")
+
+	// After one game each Arsenal and Blackburn have a win apiece. Their
+	// meeting was Blackburn's first game but Arsenal's second, so it does
+	// not count yet and goal difference puts Arsenal top.
+	standings, err := NewStandingsService(repo, repo, repo, repo, nil).GetStandings(3, StandingsOptions{Matchweek: 1})
+	if err != nil {
+		t.Fatalf("GetStandings: %v", err)
+	}
+	if order := tableOrder(standings); !reflect.DeepEqual(order[:2], []string{"Arsenal", "Blackburn"}) {
+		t.Errorf("matchweek 1 order = %v, want Arsenal then Blackburn", order)
+	}
+}
+
+func TestParseTieBreakRules(t *testing.T) {
+	tests := []struct {
+		raw     string
+		wantErr bool
+	}{
+		{"points,headToHeadPoints,goalDifference,playoff", false},
+		{"points,goalsAgainst", true},
+		{"goalDifference,points", true},
+		{"points,goalsFor,goalsFor", true},
+		{"points,playoff,goalsFor", true},
+	}
+	for _, tt := range tests {
+		_, err := ParseTieBreakRules(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTieBreakRules(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRankStandingsReappliesHeadToHead(t *testing.T) {
+	// Level on points, the four meet in a mini-league of A 7, B 4, C 4 and
+	// D 1. Among B and C alone B won their meeting, which must decide it
+	// before C's better goal difference is considered.
+	entries := []models.StandingsEntry{
+		{TeamID: 1, Team: "A", Points: 40},
+		{TeamID: 2, Team: "B", Points: 40, GoalDifference: 0},
+		{TeamID: 3, Team: "C", Points: 40, GoalDifference: 5},
+		{TeamID: 4, Team: "D", Points: 40},
+	}
+	var results []matchResult
+	for _, m := range []struct{ home, away, homeScore, awayScore int }{
+		{1, 2, 1, 0},
+		{1, 3, 1, 1},
+		{1, 4, 1, 0},
+		{2, 3, 1, 0},
+		{2, 4, 1, 1},
+		{3, 4, 1, 0},
+	} {
+		results = append(results, matchResult{homeTeamID: m.home, awayTeamID: m.away, homeScore: m.homeScore, awayScore: m.awayScore})
+	}
+
+	rankStandings(entries, []string{TieBreakPoints, TieBreakHeadToHeadPoints, TieBreakGoalDifference}, results, 3)
+
+	var order []string
+	for _, entry := range entries {
+		order = append(order, entry.Team)
+	}
+	if !reflect.DeepEqual(order, []string{"A", "B", "C", "D"}) {
+		t.Errorf("order = %v, want [A B C D]", order)
+	}
+	if entries[2].TieBreak != TieBreakHeadToHeadPoints {
+		t.Errorf("C separated by %q, want %q", entries[2].TieBreak, TieBreakHeadToHeadPoints)
+	}
+}
+
+func TestStandingsPointAdjustmentCutoff(t *testing.T) {
+	repo := seedTieBreak()
+	teams, _ := repo.ListTeams(repository.TeamFilter{})
+	repo.AddPointAdjustment(models.PointAdjustment{TeamID: teams[0].ID, SeasonID: 3, Points: -3, AppliedOn: "1994-09-30"})
+	service := NewStandingsService(repo, repo, repo, repo, nil)
+
+	asOf := time.Date(1994, 9, 10, 0, 0, 0, 0, time.UTC)
+	before, err := service.GetStandings(3, StandingsOptions{AsOf: &asOf})
+	if err != nil {
+		t.Fatalf("GetStandings: %v", err)
+	}
+	final, err := service.GetStandingsBySeasonID(3)
+	if err != nil {
+		t.Fatalf("GetStandingsBySeasonID: %v", err)
+	}
+
+	for _, tt := range []struct {
+		standings *models.Standings
+		want      int
+	}{{before, 0}, {final, -3}} {
+		for _, entry := range tt.standings.Table {
+			if entry.TeamID == teams[0].ID && entry.PointsAdjustment != tt.want {
+				t.Errorf("adjustment as of %q = %d, want %d", tt.standings.AsOf, entry.PointsAdjustment, tt.want)
+			}
+		}
+	}
+	if order := tableOrder(final); order[len(order)-1] != "Arsenal" {
+		t.Errorf("final table order = %v, want Arsenal bottom after the deduction", order)
+	}
+}
+
+func TestStandingsPointsForWin(t *testing.T) {
+	repo := seedTieBreak()
+	repo.AddSeason(models.Season{ID: 3, Name: "1994/95", PointsForWin: 2})
+	teams, _ := repo.ListTeams(repository.TeamFilter{})
+	service := NewStandingsService(repo, repo, repo, repo, nil)
+
+	standings, err := service.GetStandingsBySeasonID(3)
+	if err != nil {
+		t.Fatalf("GetStandingsBySeasonID: %v", err)
+	}
+	want := map[string]int{"Arsenal": 3, "Blackburn": 3, "Chelsea": 2}
+	for _, entry := range standings.Table {
+		if entry.Points != want[entry.Team] {
+			t.Errorf("%s has %d points, want %d", entry.Team, entry.Points, want[entry.Team])
+		}
+	}
+
+	stats, err := service.GetTeamStatsForSeason(teams[0].ID, 3)
+	if err != nil {
+		t.Fatalf("GetTeamStatsForSeason: %v", err)
+	}
+	if stats.Points != 3 {
+		t.Errorf("Arsenal team stats have %d points, want 3", stats.Points)
+	}
+}
+
+func TestStandingsUnknownSeason(t *testing.T) {
+	repo := repository.NewMemory()
+	_, err := NewStandingsService(repo, repo, repo, repo, nil).GetStandingsBySeasonID(99)
+	if code := apperrors.Code(err); code != apperrors.CodeNotFound {
+		t.Fatalf("error code = %q, want %q", code, apperrors.CodeNotFound)
+	}
+}
+
+func TestStandingsFromAggregatesMatchRawTables(t *testing.T) {
+	repo, err := repository.LoadFixtures("../../../../data")
+	if err != nil {
+		t.Fatalf("LoadFixtures: %v", err)
+	}
+	service := NewStandingsService(repo, repo, repo, repo, nil)
+
+	// A date after the season forces the table to be built from the matches
+	end := time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, view := range []string{StandingsViewOverall, StandingsViewHome, StandingsViewAway} {
+		stored, err := service.GetStandings(10, StandingsOptions{View: view})
+		if err != nil {
+			t.Fatalf("GetStandings(%s): %v", view, err)
+		}
+		raw, err := service.GetStandings(10, StandingsOptions{View: view, AsOf: &end})
+		if err != nil {
+			t.Fatalf("GetStandings(%s, asOf): %v", view, err)
+		}
+		if !reflect.DeepEqual(stored.Table, raw.Table) {
+			t.Errorf("%s table from aggregates differs from the raw table:\n%+v\n%+v", view, stored.Table, raw.Table)
+		}
+	}
+}
+
+func TestTeamStatsVenueSplits(t *testing.T) {
+	repo := seedTieBreak()
+	teams, _ := repo.ListTeams(repository.TeamFilter{})
+	arsenal := teams[0]
+
+	stats, err := NewStandingsService(repo, repo, repo, repo, nil).GetTeamStatsForSeason(arsenal.ID, 3)
+	if err != nil {
+		t.Fatalf("GetTeamStatsForSeason: %v", err)
+	}
+	// Arsenal beat Chelsea 5-0 at home, then lost 1-0 at Blackburn and drew 0-0 at Chelsea
+	want := models.VenueRecord{Played: 2, Drawn: 1, Lost: 1, GoalsAgainst: 1}
+	if stats.Home != (models.VenueRecord{Played: 1, Won: 1, GoalsFor: 5}) || stats.Away != want {
+		t.Errorf("home = %+v, away = %+v", stats.Home, stats.Away)
+	}
+	if stats.MatchesPlayed != 3 || stats.Points != 4 {
+		t.Errorf("overall record = %d played, %d points; want 3 and 4", stats.MatchesPlayed, stats.Points)
+	}
+}
+
+func TestTeamStatsWithoutMatches(t *testing.T) {
+	repo := seedTieBreak()
+	everton := repo.AddTeam(models.Team{Name: "Everton"})
+	service := NewStandingsService(repo, repo, repo, repo, nil)
+
+	stats, err := service.GetTeamStatsForSeason(everton, 3)
+	if err != nil {
+		t.Fatalf("GetTeamStatsForSeason: %v", err)
+	}
+	if stats.Team != "Everton" || stats.MatchesPlayed != 0 || stats.Points != 0 {
+		t.Errorf("stats = %+v, want an empty record for Everton", stats)
+	}
+
+	if _, err := service.GetTeamStatsForSeason(999, 3); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("unknown team error = %v, want not found", err)
+	}
+}
+
+func TestGetAvailableSeasons(t *testing.T) {
+	repo := repository.NewMemory()
+	seedRoundRobin(repo, 4, 20, 10)
+	repo.AddSeason(models.Season{ID: 4, Name: "1995/96",
+		StartDate: time.Date(1995, 8, 19, 0, 0, 0, 0, time.UTC), EndDate: time.Date(1996, 5, 5, 0, 0, 0, 0, time.UTC)})
+	service := NewStandingsService(repo, repo, repo, repo, nil)
+
+	tests := []struct {
+		now  time.Time
+		want string
+	}{
+		{time.Date(1995, 12, 26, 0, 0, 0, 0, time.UTC), models.SeasonInProgress},
+		// Past its end date a season is complete however many results are loaded
+		{time.Date(1996, 6, 1, 0, 0, 0, 0, time.UTC), models.SeasonComplete},
+	}
+	for _, tt := range tests {
+		service.now = func() time.Time { return tt.now }
+		seasons, err := service.GetAvailableSeasons()
+		if err != nil {
+			t.Fatalf("GetAvailableSeasons: %v", err)
+		}
+		if len(seasons) != 1 || seasons[0].Status != tt.want {
+			t.Errorf("seasons on %s = %+v, want one %s season", tt.now.Format("2006-01-02"), seasons, tt.want)
+		}
+	}
+}
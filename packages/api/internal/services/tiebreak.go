@@ -0,0 +1,318 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/premstats/api/internal/models"
+)
+
+// Tie-break criteria that can appear in a season's rule set
+const (
+	TieBreakPoints              = "points"
+	TieBreakGoalDifference      = "goalDifference"
+	TieBreakGoalsFor            = "goalsFor"
+	TieBreakHeadToHeadPoints    = "headToHeadPoints"
+	TieBreakHeadToHeadAwayGoals = "headToHeadAwayGoals"
+	TieBreakPlayoff             = "playoff"
+
+	// tieBreakAlphabetical labels teams still level once every rule is exhausted
+	tieBreakAlphabetical = "alphabetical"
+)
+
+// DefaultTieBreakRules applies to seasons without their own rule set
+var DefaultTieBreakRules = []string{
+	TieBreakPoints,
+	TieBreakGoalDifference,
+	TieBreakGoalsFor,
+	TieBreakPlayoff,
+}
+
+// matchResult is a completed match used by head-to-head criteria
+type matchResult struct {
+	homeTeamID int
+	awayTeamID int
+	homeScore  int
+	awayScore  int
+	date       time.Time
+}
+
+// tieBreaker scores each team in a tied group; higher scores rank higher.
+// Head-to-head points award pointsForWin for each win, as the table does.
+type tieBreaker func(group []models.StandingsEntry, results []matchResult, pointsForWin int) []int
+
+// tieBreakers holds every criterion that compares teams. The playoff flag is
+// handled separately because it stops evaluation rather than scoring teams.
+var tieBreakers = map[string]tieBreaker{
+	TieBreakPoints: func(group []models.StandingsEntry, _ []matchResult, _ int) []int {
+		return scoreEach(group, func(e models.StandingsEntry) int { return e.Points })
+	},
+	TieBreakGoalDifference: func(group []models.StandingsEntry, _ []matchResult, _ int) []int {
+		return scoreEach(group, func(e models.StandingsEntry) int { return e.GoalDifference })
+	},
+	TieBreakGoalsFor: func(group []models.StandingsEntry, _ []matchResult, _ int) []int {
+		return scoreEach(group, func(e models.StandingsEntry) int { return e.GoalsFor })
+	},
+	TieBreakHeadToHeadPoints: func(group []models.StandingsEntry, results []matchResult, pointsForWin int) []int {
+		return headToHead(group, results, func(r matchResult, teamID int) int {
+			scored, conceded := r.homeScore, r.awayScore
+			if teamID == r.awayTeamID {
+				scored, conceded = conceded, scored
+			}
+			switch {
+			case scored > conceded:
+				return pointsForWin
+			case scored == conceded:
+				return 1
+			}
+			return 0
+		})
+	},
+	TieBreakHeadToHeadAwayGoals: func(group []models.StandingsEntry, results []matchResult, _ int) []int {
+		return headToHead(group, results, func(r matchResult, teamID int) int {
+			if teamID == r.awayTeamID {
+				return r.awayScore
+			}
+			return 0
+		})
+	},
+}
+
+// ParseTieBreakRules parses a comma-separated rule set, returning the default
+// rules when raw is empty. A rule set must start with points, name each
+// criterion once and put playoff, if anywhere, last, since no rule after a
+// playoff could ever apply.
+func ParseTieBreakRules(raw string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return DefaultTieBreakRules, nil
+	}
+
+	var rules []string
+	seen := make(map[string]bool)
+	for _, rule := range strings.Split(raw, ",") {
+		rule = strings.TrimSpace(rule)
+		if _, ok := tieBreakers[rule]; !ok && rule != TieBreakPlayoff {
+			return nil, fmt.Errorf("unknown tie-break criterion %q", rule)
+		}
+		if seen[rule] {
+			return nil, fmt.Errorf("tie-break criterion %q is repeated", rule)
+		}
+		if seen[TieBreakPlayoff] {
+			return nil, fmt.Errorf("tie-break criterion %q follows %s, which must come last", rule, TieBreakPlayoff)
+		}
+		seen[rule] = true
+		rules = append(rules, rule)
+	}
+	if rules[0] != TieBreakPoints {
+		return nil, fmt.Errorf("tie-break rules must start with %s, not %q", TieBreakPoints, rules[0])
+	}
+	return rules, nil
+}
+
+// getTieBreakRules loads the rule set configured for a season
+func (s *StandingsService) getTieBreakRules(seasonID int) ([]string, error) {
+	raw, err := s.standings.GetTieBreakRules(seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := ParseTieBreakRules(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tie-break rules for season %d: %w", seasonID, err)
+	}
+	return rules, nil
+}
+
+// isHeadToHead reports whether a rule compares teams on their meetings
+func isHeadToHead(rule string) bool {
+	return rule == TieBreakHeadToHeadPoints || rule == TieBreakHeadToHeadAwayGoals
+}
+
+// needsResults reports whether any rule compares teams on their meetings
+func needsResults(rules []string) bool {
+	for _, rule := range rules {
+		if isHeadToHead(rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutHeadToHead drops head-to-head criteria, which are meaningless for
+// venue-only and split-half tables where each meeting is only partly counted
+func withoutHeadToHead(rules []string) []string {
+	var filtered []string
+	for _, rule := range rules {
+		if !isHeadToHead(rule) {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
+
+// getMatchResults loads every completed match of a season
+func (s *StandingsService) getMatchResults(seasonID int) ([]matchResult, error) {
+	rows, err := s.matches.ListResults(seasonID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]matchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, matchResult{
+			homeTeamID: row.HomeTeamID,
+			awayTeamID: row.AwayTeamID,
+			homeScore:  row.HomeScore,
+			awayScore:  row.AwayScore,
+			date:       row.Date,
+		})
+	}
+	return results, nil
+}
+
+// resultsUntil returns the results played on or before cutoff; a zero cutoff
+// returns every result
+func resultsUntil(results []matchResult, cutoff time.Time) []matchResult {
+	if cutoff.IsZero() {
+		return results
+	}
+	limit := cutoff.AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	var filtered []matchResult
+	for _, r := range results {
+		if r.date.Before(limit) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// resultsWithinGames returns the results that fall within both teams' games
+// counted in a table, given date-ordered results
+func resultsWithinGames(results []matchResult, entries []models.StandingsEntry) []matchResult {
+	counted := make(map[int]int, len(entries))
+	for _, entry := range entries {
+		counted[entry.TeamID] = entry.Played
+	}
+
+	games := make(map[int]int, len(entries))
+	var filtered []matchResult
+	for _, r := range results {
+		games[r.homeTeamID]++
+		games[r.awayTeamID]++
+		if games[r.homeTeamID] <= counted[r.homeTeamID] && games[r.awayTeamID] <= counted[r.awayTeamID] {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// rankStandings orders entries by the rule set, assigns positions and records
+// on each entry the criterion that separated it from the team above
+func rankStandings(entries []models.StandingsEntry, rules []string, results []matchResult, pointsForWin int) {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Team < entries[j].Team })
+	for i := range entries {
+		entries[i].TieBreak = ""
+	}
+
+	resolveTies(entries, rules, nil, results, pointsForWin)
+
+	for i := range entries {
+		entries[i].Position = i + 1
+	}
+}
+
+// resolveTies sorts a group of level teams by the first rule, then recurses
+// into any subgroups that are still level. When a head-to-head criterion
+// leaves a smaller group of teams level, the run of head-to-head criteria it
+// belongs to starts again on their meetings alone; restart holds the
+// rules from the start of that run, or nil outside one.
+func resolveTies(group []models.StandingsEntry, rules, restart []string, results []matchResult, pointsForWin int) {
+	if len(group) < 2 {
+		return
+	}
+
+	if len(rules) == 0 || rules[0] == TieBreakPlayoff {
+		label := tieBreakAlphabetical
+		if len(rules) > 0 {
+			label = TieBreakPlayoff
+		}
+		for i := 1; i < len(group); i++ {
+			group[i].TieBreak = label
+		}
+		return
+	}
+
+	if !isHeadToHead(rules[0]) {
+		restart = nil
+	} else if restart == nil {
+		restart = rules
+	}
+
+	scores := tieBreakers[rules[0]](group, results, pointsForWin)
+	order := make([]int, len(group))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+
+	// The label on the group's first slot describes the boundary above the
+	// group, so it stays with the slot rather than the team
+	lead := group[0].TieBreak
+	sorted := make([]models.StandingsEntry, len(group))
+	sortedScores := make([]int, len(group))
+	for i, idx := range order {
+		sorted[i] = group[idx]
+		sorted[i].TieBreak = ""
+		sortedScores[i] = scores[idx]
+	}
+	copy(group, sorted)
+	group[0].TieBreak = lead
+
+	start := 0
+	for i := 1; i <= len(group); i++ {
+		if i < len(group) && sortedScores[i] == sortedScores[start] {
+			continue
+		}
+		if i < len(group) {
+			group[i].TieBreak = rules[0]
+		}
+		next := rules[1:]
+		if restart != nil && i-start > 1 && i-start < len(group) {
+			next = restart
+		}
+		resolveTies(group[start:i], next, restart, results, pointsForWin)
+		start = i
+	}
+}
+
+// scoreEach scores every team in a group with the same accessor
+func scoreEach(group []models.StandingsEntry, value func(models.StandingsEntry) int) []int {
+	scores := make([]int, len(group))
+	for i, entry := range group {
+		scores[i] = value(entry)
+	}
+	return scores
+}
+
+// headToHead totals a per-match value over the matches played between
+// members of the group only
+func headToHead(group []models.StandingsEntry, results []matchResult, value func(matchResult, int) int) []int {
+	index := make(map[int]int, len(group))
+	for i, entry := range group {
+		index[entry.TeamID] = i
+	}
+
+	scores := make([]int, len(group))
+	for _, r := range results {
+		home, homeOK := index[r.homeTeamID]
+		away, awayOK := index[r.awayTeamID]
+		if !homeOK || !awayOK {
+			continue
+		}
+		scores[home] += value(r, r.homeTeamID)
+		scores[away] += value(r, r.awayTeamID)
+	}
+	return scores
+}
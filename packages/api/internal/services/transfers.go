@@ -0,0 +1,124 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+// TransferService reports the clubs players moved between
+type TransferService struct {
+	players repository.PlayerRepository
+	teams   repository.TeamRepository
+	seasons repository.SeasonRepository
+	spells  repository.PlayerSpellRepository
+	now     func() time.Time
+}
+
+// NewTransferService creates a new transfer service
+func NewTransferService(players repository.PlayerRepository, teams repository.TeamRepository, seasons repository.SeasonRepository, spells repository.PlayerSpellRepository) *TransferService {
+	return &TransferService{players: players, teams: teams, seasons: seasons, spells: spells, now: time.Now}
+}
+
+// GetPlayerTransfers returns a player's spells at each club, oldest first
+func (s *TransferService) GetPlayerTransfers(playerID int) (*models.PlayerTransfers, error) {
+	player, err := s.players.GetPlayer(playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	spells, err := s.spells.ListPlayerSpells(playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PlayerTransfers{Player: *player, Spells: spells}, nil
+}
+
+// GetTeamTransfers returns the players a club signed and lost between the
+// end of the previous season and the end of this one, or of the current
+// season when seasonID is 0. Spells without a known start or end date are
+// left out, since they cannot be placed in a season.
+func (s *TransferService) GetTeamTransfers(teamID, seasonID int) (*models.TeamTransfers, error) {
+	team, err := s.teams.GetTeam(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	var season *models.Season
+	if seasonID > 0 {
+		season, err = s.seasons.GetSeason(seasonID)
+	} else {
+		season, err = currentSeason(s.seasons, s.now())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	from, to, err := s.transferWindow(season)
+	if err != nil {
+		return nil, err
+	}
+
+	spells, err := s.spells.ListTeamSpells(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	within := func(date *time.Time) bool {
+		return date != nil && date.After(from) && !date.After(to)
+	}
+	transfers := &models.TeamTransfers{
+		TeamID:     team.ID,
+		TeamName:   team.Name,
+		SeasonID:   season.ID,
+		SeasonName: season.Name,
+		In:         []models.PlayerSpell{},
+		Out:        []models.PlayerSpell{},
+	}
+	for _, spell := range spells {
+		if within(spell.From) {
+			transfers.In = append(transfers.In, spell)
+		}
+		if within(spell.To) {
+			transfers.Out = append(transfers.Out, spell)
+		}
+	}
+
+	sort.SliceStable(transfers.In, func(i, j int) bool {
+		return transfers.In[i].From.Before(*transfers.In[j].From)
+	})
+	sort.SliceStable(transfers.Out, func(i, j int) bool {
+		return transfers.Out[i].To.Before(*transfers.Out[j].To)
+	})
+	return transfers, nil
+}
+
+// transferWindow returns the period a season's transfers fall in: after the
+// previous season ended, or the three months before the first season
+// started, up to the season's end
+func (s *TransferService) transferWindow(season *models.Season) (time.Time, time.Time, error) {
+	seasons, err := s.seasons.ListSeasons()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	from := season.StartDate.AddDate(0, -3, 0)
+	var previous time.Time
+	for _, other := range seasons {
+		if other.ID != season.ID && other.EndDate.Before(season.StartDate) && other.EndDate.After(previous) {
+			previous = other.EndDate
+		}
+	}
+	if !previous.IsZero() {
+		from = previous
+	}
+
+	to := season.EndDate
+	if to.IsZero() {
+		to = season.StartDate.AddDate(1, 0, 0)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/premstats/api/internal/apperrors"
+	"github.com/premstats/api/internal/models"
+	"github.com/premstats/api/internal/repository"
+)
+
+func TestGetTeamTransfers(t *testing.T) {
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+
+	repo := repository.NewMemory()
+	repo.AddSeason(models.Season{ID: 1, Name: "2003/04", StartDate: *date(2003, time.August, 16), EndDate: *date(2004, time.May, 15)})
+	repo.AddSeason(models.Season{ID: 2, Name: "2004/05", StartDate: *date(2004, time.August, 14), EndDate: *date(2005, time.May, 29)})
+	everton := repo.AddTeam(models.Team{Name: "Everton"})
+	united := repo.AddTeam(models.Team{Name: "Manchester United"})
+	rooney := repo.AddPlayer(models.Player{Name: "Wayne Rooney"})
+	cahill := repo.AddPlayer(models.Player{Name: "Tim Cahill"})
+	fee := int64(37000000)
+
+	repo.AddPlayerSpell(models.PlayerSpell{PlayerID: rooney, TeamID: everton, FirstSeasonID: 1, LastSeasonID: 1, To: date(2004, time.August, 31)})
+	repo.AddPlayerSpell(models.PlayerSpell{PlayerID: rooney, TeamID: united, FirstSeasonID: 2, LastSeasonID: 2,
+		From: date(2004, time.August, 31), Fee: &fee, FeeText: "€37.00m", SignedFrom: "Everton FC"})
+	// Signed in the summer, before the season started
+	repo.AddPlayerSpell(models.PlayerSpell{PlayerID: cahill, TeamID: everton, FirstSeasonID: 2, LastSeasonID: 2, From: date(2004, time.July, 22)})
+
+	service := NewTransferService(repo, repo, repo, repo)
+
+	transfers, err := service.GetTeamTransfers(everton, 2)
+	if err != nil {
+		t.Fatalf("GetTeamTransfers: %v", err)
+	}
+	if len(transfers.In) != 1 || transfers.In[0].PlayerID != cahill {
+		t.Errorf("ins = %+v, want Cahill", transfers.In)
+	}
+	if len(transfers.Out) != 1 || transfers.Out[0].PlayerID != rooney || transfers.SeasonName != "2004/05" {
+		t.Errorf("outs = %+v, want Rooney in 2004/05", transfers.Out)
+	}
+
+	// Rooney's first spell has no known start, so 2003/04 shows no signing
+	transfers, err = service.GetTeamTransfers(everton, 1)
+	if err != nil {
+		t.Fatalf("GetTeamTransfers: %v", err)
+	}
+	if len(transfers.In) != 0 || len(transfers.Out) != 0 {
+		t.Errorf("2003/04 transfers = %+v, want none", transfers)
+	}
+
+	history, err := service.GetPlayerTransfers(rooney)
+	if err != nil {
+		t.Fatalf("GetPlayerTransfers: %v", err)
+	}
+	if len(history.Spells) != 2 || history.Spells[0].TeamName != "Everton" || history.Spells[1].FirstSeason != "2004/05" ||
+		*history.Spells[1].Fee != fee {
+		t.Errorf("history = %+v, want Everton then United", history.Spells)
+	}
+
+	if _, err := service.GetTeamTransfers(999, 2); apperrors.Code(err) != apperrors.CodeNotFound {
+		t.Errorf("unknown team error = %v, want not found", err)
+	}
+}